@@ -40,6 +40,7 @@ func BackupCmd() *cobra.Command {
 	cmd.Flags().Bool("wait", true, "wait for the backup to finish")
 
 	cmd.AddCommand(BackupListCmd())
+	cmd.AddCommand(BackupSupportBundleCmd())
 
 	return cmd
 }
@@ -75,3 +76,40 @@ func BackupListCmd() *cobra.Command {
 
 	return cmd
 }
+
+func BackupSupportBundleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "support-bundle",
+		Short:         "Collect velero logs, status and the redacted store config into a tarball for snapshot support cases",
+		Long:          ``,
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlags(cmd.Flags())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := viper.GetViper()
+
+			namespace := v.GetString("namespace")
+			if err := validateNamespace(namespace); err != nil {
+				return err
+			}
+
+			options := snapshot.DownloadSupportBundleOptions{
+				Namespace:             namespace,
+				KubernetesConfigFlags: kubernetesConfigFlags,
+				OutputFile:            v.GetString("output"),
+			}
+			if err := snapshot.DownloadSupportBundle(options); err != nil {
+				return errors.Wrap(err, "failed to download snapshot support bundle")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("namespace", "n", "default", "namespace in which kots/kotsadm is installed")
+	cmd.Flags().StringP("output", "o", "velero-support.tar.gz", "file to save the support bundle to")
+
+	return cmd
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/replicatedhq/kots/kotsadm/pkg/handlers"
 	"github.com/replicatedhq/kots/kotsadm/pkg/informers"
 	"github.com/replicatedhq/kots/kotsadm/pkg/policy"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot"
 	"github.com/replicatedhq/kots/kotsadm/pkg/snapshotscheduler"
 	"github.com/replicatedhq/kots/kotsadm/pkg/socketservice"
 	"github.com/replicatedhq/kots/kotsadm/pkg/store"
@@ -50,6 +51,10 @@ func Start() {
 		log.Println("Failed to start informers", err)
 	}
 
+	if err := snapshot.ReconcileQuiescedWorkloads(context.Background()); err != nil {
+		log.Println("Failed to reconcile quiesced workloads", err)
+	}
+
 	if err := updatechecker.Start(); err != nil {
 		log.Println("Failed to start update checker", err)
 	}
@@ -58,6 +63,8 @@ func Start() {
 		log.Println("Failed to start snapshot scheduler", err)
 	}
 
+	snapshot.StartVeleroReconciler()
+
 	waitForAirgap, err := automation.NeedToWaitForAirgapApp()
 	if err != nil {
 		log.Println("Failed to check if airgap install is in progress", err)
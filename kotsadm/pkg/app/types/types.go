@@ -1,6 +1,10 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
 
 type UndeployStatus string
 
@@ -12,24 +16,37 @@ const (
 )
 
 type App struct {
-	ID                    string         `json:"id"`
-	Slug                  string         `json:"slug"`
-	Name                  string         `json:"name"`
-	License               string         `json:"license"`
-	IsAirgap              bool           `json:"isAirgap"`
-	CurrentSequence       int64          `json:"currentSequence"`
-	UpstreamURI           string         `json:"upstreamUri"`
-	IconURI               string         `json:"iconUri"`
-	UpdatedAt             *time.Time     `json:"createdAt"`
-	CreatedAt             time.Time      `json:"updatedAt"`
-	LastUpdateCheckAt     string         `json:"lastUpdateCheckAt"`
-	HasPreflight          bool           `json:"hasPreflight"`
-	IsConfigurable        bool           `json:"isConfigurable"`
-	SnapshotTTL           string         `json:"snapshotTtl"`
-	SnapshotSchedule      string         `json:"snapshotSchedule"`
-	RestoreInProgressName string         `json:"restoreInProgressName"`
-	RestoreUndeployStatus UndeployStatus `json:"restoreUndeloyStatus"`
-	UpdateCheckerSpec     string         `json:"updateCheckerSpec"`
-	IsGitOps              bool           `json:"isGitOps"`
-	InstallState          string         `json:"installState"`
+	ID                             string                         `json:"id"`
+	Slug                           string                         `json:"slug"`
+	Name                           string                         `json:"name"`
+	License                        string                         `json:"license"`
+	IsAirgap                       bool                           `json:"isAirgap"`
+	CurrentSequence                int64                          `json:"currentSequence"`
+	UpstreamURI                    string                         `json:"upstreamUri"`
+	IconURI                        string                         `json:"iconUri"`
+	UpdatedAt                      *time.Time                     `json:"createdAt"`
+	CreatedAt                      time.Time                      `json:"updatedAt"`
+	LastUpdateCheckAt              string                         `json:"lastUpdateCheckAt"`
+	HasPreflight                   bool                           `json:"hasPreflight"`
+	IsConfigurable                 bool                           `json:"isConfigurable"`
+	SnapshotTTL                    string                         `json:"snapshotTtl"`
+	SnapshotSchedule               string                         `json:"snapshotSchedule"`
+	SnapshotScheduleTTL            string                         `json:"snapshotScheduleTtl,omitempty"`
+	SnapshotScheduleJitterMinutes  int                            `json:"snapshotScheduleJitterMinutes,omitempty"`
+	SnapshotDefaultVolumesToRestic *bool                          `json:"snapshotDefaultVolumesToRestic,omitempty"`
+	SnapshotQuiesceActions         []snapshottypes.QuiesceAction  `json:"snapshotQuiesceActions,omitempty"`
+	SnapshotIncludedNamespaces     []string                       `json:"snapshotIncludedNamespaces,omitempty"`
+	SnapshotExcludedNamespaces     []string                       `json:"snapshotExcludedNamespaces,omitempty"`
+	SnapshotHookSettings           *snapshottypes.HookSettings    `json:"snapshotHookSettings,omitempty"`
+	SnapshotChecksumTargets        []snapshottypes.ChecksumTarget `json:"snapshotChecksumTargets,omitempty"`
+	SnapshotExcludedPVCs           []string                       `json:"snapshotExcludedPvcs,omitempty"`
+	SnapshotFanOutLocations        []string                       `json:"snapshotFanOutLocations,omitempty"`
+	SnapshotResticPodSelector      string                         `json:"snapshotResticPodSelector,omitempty"`
+	SnapshotMaxConcurrentBackups   int                            `json:"snapshotMaxConcurrentBackups,omitempty"`
+	RestoreInProgressName          string                         `json:"restoreInProgressName"`
+	RestoreUndeployStatus          UndeployStatus                 `json:"restoreUndeloyStatus"`
+	UpdateCheckerSpec              string                         `json:"updateCheckerSpec"`
+	LicenseSyncIntervalMinutes     int                            `json:"licenseSyncIntervalMinutes,omitempty"`
+	IsGitOps                       bool                           `json:"isGitOps"`
+	InstallState                   string                         `json:"installState"`
 }
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -10,11 +12,18 @@ import (
 	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot"
 	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
 	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 )
 
 type CreateApplicationBackupRequest struct {
 }
 
+// noStoreConfiguredMessage is returned instead of a generic failure when a backup can't be created without a store
+const noStoreConfiguredMessage = "no snapshot storage destination has been configured, configure one in the snapshot settings first"
+
+// backupInProgressMessage is returned when the app already has as many backups running as its snapshot config allows
+const backupInProgressMessage = "backup already in progress for this app"
+
 type CreateApplicationBackupResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
@@ -46,7 +55,15 @@ func (h *Handler) CreateApplicationBackup(w http.ResponseWriter, r *http.Request
 	}
 
 	_, err = snapshot.CreateApplicationBackup(r.Context(), foundApp, false)
-	if err != nil {
+	if snapshot.IsNoStoreConfiguredError(err) {
+		createApplicationBackupResponse.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, createApplicationBackupResponse)
+		return
+	} else if snapshot.IsBackupInProgressError(err) {
+		createApplicationBackupResponse.Error = backupInProgressMessage
+		JSON(w, http.StatusConflict, createApplicationBackupResponse)
+		return
+	} else if err != nil {
 		logger.Error(err)
 		createApplicationBackupResponse.Error = "failed to create backup"
 		JSON(w, http.StatusInternalServerError, createApplicationBackupResponse)
@@ -58,6 +75,58 @@ func (h *Handler) CreateApplicationBackup(w http.ResponseWriter, r *http.Request
 	JSON(w, http.StatusOK, createApplicationBackupResponse)
 }
 
+type RunScheduleNowResponse struct {
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	BackupName string `json:"backupName,omitempty"`
+}
+
+// RunScheduleNow creates the backup the app's schedule would create right away, so a new schedule's settings
+// can be tried out without waiting for it
+func (h *Handler) RunScheduleNow(w http.ResponseWriter, r *http.Request) {
+	runScheduleNowResponse := RunScheduleNowResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		runScheduleNowResponse.Error = "failed to get app from app slug"
+		JSON(w, http.StatusInternalServerError, runScheduleNowResponse)
+		return
+	}
+
+	if foundApp.SnapshotSchedule == "" {
+		runScheduleNowResponse.Error = "app does not have a snapshot schedule"
+		JSON(w, http.StatusBadRequest, runScheduleNowResponse)
+		return
+	}
+
+	backup, err := snapshot.RunApplicationScheduleNow(r.Context(), foundApp)
+	if snapshot.IsNoStoreConfiguredError(err) {
+		runScheduleNowResponse.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, runScheduleNowResponse)
+		return
+	} else if snapshot.IsBackupInProgressError(err) {
+		runScheduleNowResponse.Error = backupInProgressMessage
+		JSON(w, http.StatusConflict, runScheduleNowResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		runScheduleNowResponse.Error = "failed to create backup"
+		JSON(w, http.StatusInternalServerError, runScheduleNowResponse)
+		return
+	}
+	runScheduleNowResponse.BackupName = backup.Name
+
+	runScheduleNowResponse.Success = true
+
+	JSON(w, http.StatusOK, runScheduleNowResponse)
+}
+
 type ListBackupsResponse struct {
 	Error   string                  `json:"error,omitempty"`
 	Backups []*snapshottypes.Backup `json:"backups"`
@@ -99,6 +168,110 @@ func (h *Handler) ListBackups(w http.ResponseWriter, r *http.Request) {
 	JSON(w, 200, listBackupsResponse)
 }
 
+type GetAppBackupHistoryResponse struct {
+	Error   string                             `json:"error,omitempty"`
+	History []snapshottypes.BackupHistoryEntry `json:"history"`
+}
+
+// GetAppBackupHistory returns the backups that include the app, newest first, with the app version each was taken at
+func (h *Handler) GetAppBackupHistory(w http.ResponseWriter, r *http.Request) {
+	getAppBackupHistoryResponse := GetAppBackupHistoryResponse{}
+
+	veleroStatus, err := snapshot.DetectVelero()
+	if err != nil {
+		logger.Error(err)
+		getAppBackupHistoryResponse.Error = "failed to detect velero"
+		JSON(w, 500, getAppBackupHistoryResponse)
+		return
+	}
+
+	if veleroStatus == nil {
+		JSON(w, 200, getAppBackupHistoryResponse)
+		return
+	}
+
+	history, err := snapshot.GetAppBackupHistory(r.Context(), mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		getAppBackupHistoryResponse.Error = "failed to get backup history"
+		JSON(w, 500, getAppBackupHistoryResponse)
+		return
+	}
+	getAppBackupHistoryResponse.History = history
+
+	JSON(w, 200, getAppBackupHistoryResponse)
+}
+
+type GetAppBackupEstimateResponse struct {
+	Error    string                        `json:"error,omitempty"`
+	Estimate *snapshottypes.BackupEstimate `json:"estimate,omitempty"`
+}
+
+// GetAppBackupEstimate estimates how long the next backup of the app will take from its recent completed backups
+func (h *Handler) GetAppBackupEstimate(w http.ResponseWriter, r *http.Request) {
+	getAppBackupEstimateResponse := GetAppBackupEstimateResponse{}
+
+	veleroStatus, err := snapshot.DetectVelero()
+	if err != nil {
+		logger.Error(err)
+		getAppBackupEstimateResponse.Error = "failed to detect velero"
+		JSON(w, 500, getAppBackupEstimateResponse)
+		return
+	}
+
+	if veleroStatus == nil {
+		JSON(w, 200, getAppBackupEstimateResponse)
+		return
+	}
+
+	estimate, err := snapshot.GetAppBackupEstimate(r.Context(), mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		getAppBackupEstimateResponse.Error = "failed to get backup estimate"
+		JSON(w, 500, getAppBackupEstimateResponse)
+		return
+	}
+	getAppBackupEstimateResponse.Estimate = estimate
+
+	JSON(w, 200, getAppBackupEstimateResponse)
+}
+
+type GetAppResticVolumesResponse struct {
+	Error   string                       `json:"error,omitempty"`
+	Volumes []snapshottypes.ResticVolume `json:"volumes"`
+}
+
+// GetAppResticVolumes lists the app's persistent volume claims with whether restic will back them up
+func (h *Handler) GetAppResticVolumes(w http.ResponseWriter, r *http.Request) {
+	getAppResticVolumesResponse := GetAppResticVolumesResponse{
+		Volumes: []snapshottypes.ResticVolume{},
+	}
+
+	veleroStatus, err := snapshot.DetectVelero()
+	if err != nil {
+		logger.Error(err)
+		getAppResticVolumesResponse.Error = "failed to detect velero"
+		JSON(w, 500, getAppResticVolumesResponse)
+		return
+	}
+
+	if veleroStatus == nil {
+		JSON(w, 200, getAppResticVolumesResponse)
+		return
+	}
+
+	volumes, err := snapshot.CheckAppResticVolumes(r.Context(), mux.Vars(r)["appSlug"], veleroStatus.DefaultVolumesToRestic)
+	if err != nil {
+		logger.Error(err)
+		getAppResticVolumesResponse.Error = "failed to check restic volumes"
+		JSON(w, 500, getAppResticVolumesResponse)
+		return
+	}
+	getAppResticVolumesResponse.Volumes = volumes
+
+	JSON(w, 200, getAppResticVolumesResponse)
+}
+
 type ListInstanceBackupsResponse struct {
 	Error   string                  `json:"error,omitempty"`
 	Backups []*snapshottypes.Backup `json:"backups"`
@@ -143,25 +316,141 @@ func (h *Handler) GetBackup(w http.ResponseWriter, r *http.Request) {
 }
 
 type DeleteBackupResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success  bool                                `json:"success"`
+	Error    string                              `json:"error,omitempty"`
+	Deletion *snapshottypes.BackupDeletionStatus `json:"deletion,omitempty"`
 }
 
+const (
+	defaultBackupDeletionWaitTimeout = 5 * time.Minute
+	maxBackupDeletionWaitTimeout     = 30 * time.Minute
+)
+
+// DeleteBackup asks velero to delete the backup and its data from the store. With wait=true, it also waits up to
+// timeout (a duration, 5m by default) for the data to be removed, and responds with 202 if it hasn't been yet.
 func (h *Handler) DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	deleteBackupResponse := DeleteBackupResponse{}
 
-	if err := snapshot.DeleteBackup(mux.Vars(r)["snapshotName"]); err != nil {
+	wait := r.URL.Query().Get("wait") == "true"
+	timeout := defaultBackupDeletionWaitTimeout
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		parsed, err := time.ParseDuration(t)
+		if err != nil || parsed <= 0 || parsed > maxBackupDeletionWaitTimeout {
+			deleteBackupResponse.Error = fmt.Sprintf("timeout must be a duration no longer than %s", maxBackupDeletionWaitTimeout)
+			JSON(w, http.StatusBadRequest, deleteBackupResponse)
+			return
+		}
+		timeout = parsed
+	}
+
+	snapshotName := mux.Vars(r)["snapshotName"]
+
+	if err := snapshot.DeleteBackup(snapshotName); err != nil {
 		logger.Error(err)
 		deleteBackupResponse.Error = "failed to delete backup"
 		JSON(w, http.StatusInternalServerError, deleteBackupResponse)
 		return
 	}
 
+	if !wait {
+		deleteBackupResponse.Success = true
+		JSON(w, http.StatusOK, deleteBackupResponse)
+		return
+	}
+
+	deletion, err := snapshot.WaitForBackupDeletion(r.Context(), snapshotName, timeout)
+	if err != nil {
+		logger.Error(err)
+		deleteBackupResponse.Error = "failed to wait for backup deletion"
+		JSON(w, http.StatusInternalServerError, deleteBackupResponse)
+		return
+	}
+	deleteBackupResponse.Deletion = deletion
+
+	if !deletion.Done {
+		// the deletion was requested, the data is still being removed
+		deleteBackupResponse.Success = true
+		JSON(w, http.StatusAccepted, deleteBackupResponse)
+		return
+	}
+
+	if len(deletion.Errors) > 0 {
+		deleteBackupResponse.Error = "failed to delete backup data"
+		JSON(w, http.StatusInternalServerError, deleteBackupResponse)
+		return
+	}
+
 	deleteBackupResponse.Success = true
 
 	JSON(w, http.StatusOK, deleteBackupResponse)
 }
 
+type GetBackupDeletionStatusResponse struct {
+	Success  bool                                `json:"success"`
+	Error    string                              `json:"error,omitempty"`
+	Deletion *snapshottypes.BackupDeletionStatus `json:"deletion,omitempty"`
+}
+
+// GetBackupDeletionStatus returns how far velero has gotten removing a deleted backup's data from the store
+func (h *Handler) GetBackupDeletionStatus(w http.ResponseWriter, r *http.Request) {
+	getBackupDeletionStatusResponse := GetBackupDeletionStatusResponse{}
+
+	deletion, err := snapshot.GetBackupDeletionStatus(r.Context(), mux.Vars(r)["snapshotName"])
+	if err != nil {
+		logger.Error(err)
+		getBackupDeletionStatusResponse.Error = "failed to get backup deletion status"
+		JSON(w, http.StatusInternalServerError, getBackupDeletionStatusResponse)
+		return
+	}
+	if deletion == nil {
+		getBackupDeletionStatusResponse.Error = "backup deletion was not requested"
+		JSON(w, http.StatusNotFound, getBackupDeletionStatusResponse)
+		return
+	}
+	getBackupDeletionStatusResponse.Deletion = deletion
+
+	getBackupDeletionStatusResponse.Success = true
+
+	JSON(w, http.StatusOK, getBackupDeletionStatusResponse)
+}
+
+type RetryBackupResponse struct {
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	BackupName string `json:"backupName,omitempty"`
+}
+
+// RetryBackup creates a new backup with the same spec as a failed backup
+func (h *Handler) RetryBackup(w http.ResponseWriter, r *http.Request) {
+	retryBackupResponse := RetryBackupResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	backup, err := snapshot.RetryBackup(r.Context(), mux.Vars(r)["snapshotName"])
+	if snapshot.IsBackupNotRetryableError(err) {
+		retryBackupResponse.Error = err.Error()
+		JSON(w, http.StatusConflict, retryBackupResponse)
+		return
+	} else if snapshot.IsNoStoreConfiguredError(err) {
+		retryBackupResponse.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, retryBackupResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		retryBackupResponse.Error = "failed to retry backup"
+		JSON(w, http.StatusInternalServerError, retryBackupResponse)
+		return
+	}
+	retryBackupResponse.BackupName = backup.Name
+
+	retryBackupResponse.Success = true
+
+	JSON(w, http.StatusOK, retryBackupResponse)
+}
+
 type CreateInstanceBackupRequest struct {
 }
 
@@ -197,7 +486,11 @@ func (h *Handler) CreateInstanceBackup(w http.ResponseWriter, r *http.Request) {
 	c := clusters[0]
 
 	backup, err := snapshot.CreateInstanceBackup(context.TODO(), c, false)
-	if err != nil {
+	if snapshot.IsNoStoreConfiguredError(err) {
+		createInstanceBackupResponse.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, createInstanceBackupResponse)
+		return
+	} else if err != nil {
 		logger.Error(err)
 		createInstanceBackupResponse.Error = "failed to create instance backup"
 		JSON(w, http.StatusInternalServerError, createInstanceBackupResponse)
@@ -209,3 +502,223 @@ func (h *Handler) CreateInstanceBackup(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, createInstanceBackupResponse)
 }
+
+// backups that have not finished within this time are considered stuck unless the request specifies otherwise
+const defaultStuckBackupMaxAge = 6 * time.Hour
+
+type StuckBackup struct {
+	Name      string     `json:"name"`
+	Phase     string     `json:"phase"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+type ListStuckBackupsResponse struct {
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty"`
+	Backups []StuckBackup `json:"backups"`
+}
+
+func (h *Handler) ListStuckBackups(w http.ResponseWriter, r *http.Request) {
+	listStuckBackupsResponse := ListStuckBackupsResponse{}
+
+	maxAge, err := stuckBackupMaxAge(r)
+	if err != nil {
+		logger.Error(err)
+		listStuckBackupsResponse.Error = "invalid maxAge"
+		JSON(w, http.StatusBadRequest, listStuckBackupsResponse)
+		return
+	}
+
+	stuckBackups, err := findStuckBackups(r.Context(), maxAge)
+	if err != nil {
+		logger.Error(err)
+		listStuckBackupsResponse.Error = "failed to find stuck backups"
+		JSON(w, http.StatusInternalServerError, listStuckBackupsResponse)
+		return
+	}
+
+	listStuckBackupsResponse.Backups = []StuckBackup{}
+	for _, backup := range stuckBackups {
+		stuckBackup := StuckBackup{
+			Name:  backup.Name,
+			Phase: string(backup.Status.Phase),
+		}
+		if backup.Status.StartTimestamp != nil {
+			stuckBackup.StartedAt = &backup.Status.StartTimestamp.Time
+		}
+		listStuckBackupsResponse.Backups = append(listStuckBackupsResponse.Backups, stuckBackup)
+	}
+
+	listStuckBackupsResponse.Success = true
+
+	JSON(w, http.StatusOK, listStuckBackupsResponse)
+}
+
+type FailStuckBackupsResponse struct {
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Failed  []string `json:"failed"`
+}
+
+func (h *Handler) FailStuckBackups(w http.ResponseWriter, r *http.Request) {
+	failStuckBackupsResponse := FailStuckBackupsResponse{}
+
+	maxAge, err := stuckBackupMaxAge(r)
+	if err != nil {
+		logger.Error(err)
+		failStuckBackupsResponse.Error = "invalid maxAge"
+		JSON(w, http.StatusBadRequest, failStuckBackupsResponse)
+		return
+	}
+
+	stuckBackups, err := findStuckBackups(r.Context(), maxAge)
+	if err != nil {
+		logger.Error(err)
+		failStuckBackupsResponse.Error = "failed to find stuck backups"
+		JSON(w, http.StatusInternalServerError, failStuckBackupsResponse)
+		return
+	}
+
+	failStuckBackupsResponse.Failed = []string{}
+	for _, backup := range stuckBackups {
+		reason := fmt.Sprintf("backup did not complete within %s", maxAge)
+		if err := snapshot.MarkBackupFailed(r.Context(), backup.Namespace, backup.Name, reason); err != nil {
+			logger.Error(err)
+			failStuckBackupsResponse.Error = fmt.Sprintf("failed to mark backup %s as failed", backup.Name)
+			JSON(w, http.StatusInternalServerError, failStuckBackupsResponse)
+			return
+		}
+		failStuckBackupsResponse.Failed = append(failStuckBackupsResponse.Failed, backup.Name)
+	}
+
+	failStuckBackupsResponse.Success = true
+
+	JSON(w, http.StatusOK, failStuckBackupsResponse)
+}
+
+func stuckBackupMaxAge(r *http.Request) (time.Duration, error) {
+	if r.URL.Query().Get("maxAge") == "" {
+		return defaultStuckBackupMaxAge, nil
+	}
+	maxAge, err := time.ParseDuration(r.URL.Query().Get("maxAge"))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse max age")
+	}
+	return maxAge, nil
+}
+
+func findStuckBackups(ctx context.Context, maxAge time.Duration) ([]velerov1.Backup, error) {
+	bsl, err := snapshot.FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backup storage location")
+	}
+	stuckBackups, err := snapshot.FindStuckBackups(ctx, bsl.Namespace, maxAge)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find stuck backups")
+	}
+	return stuckBackups, nil
+}
+
+type GetSnapshotProgressResponse struct {
+	Success  bool                          `json:"success"`
+	Error    string                        `json:"error,omitempty"`
+	Progress *snapshottypes.BackupProgress `json:"progress,omitempty"`
+}
+
+func (h *Handler) GetSnapshotProgress(w http.ResponseWriter, r *http.Request) {
+	getSnapshotProgressResponse := GetSnapshotProgressResponse{}
+
+	progress, err := snapshot.GetBackupProgress(r.Context(), mux.Vars(r)["snapshotName"])
+	if err != nil {
+		logger.Error(err)
+		getSnapshotProgressResponse.Error = "failed to get snapshot progress"
+		JSON(w, http.StatusInternalServerError, getSnapshotProgressResponse)
+		return
+	}
+	getSnapshotProgressResponse.Progress = progress
+
+	getSnapshotProgressResponse.Success = true
+
+	JSON(w, http.StatusOK, getSnapshotProgressResponse)
+}
+
+// StreamBackupProgress sends the progress of a backup as server-sent events as it changes, and ends the stream
+// once the backup is done
+func (h *Handler) StreamBackupProgress(w http.ResponseWriter, r *http.Request) {
+	if _, ok := w.(http.Flusher); !ok {
+		JSON(w, http.StatusInternalServerError, GetSnapshotProgressResponse{Error: "streaming is not supported"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	err := snapshot.WatchBackupProgress(r.Context(), mux.Vars(r)["snapshotName"], func(progress *snapshottypes.BackupProgress) error {
+		return ServerSentEvent(w, "progress", progress)
+	})
+	if err != nil && r.Context().Err() == nil {
+		logger.Error(err)
+		if err := ServerSentEvent(w, "error", GetSnapshotProgressResponse{Error: "failed to get snapshot progress"}); err != nil {
+			logger.Error(err)
+		}
+	}
+}
+
+type VerifyBackupResponse struct {
+	Success        bool     `json:"success"`
+	Error          string   `json:"error,omitempty"`
+	Verified       bool     `json:"verified"`
+	MissingObjects []string `json:"missingObjects"`
+}
+
+func (h *Handler) VerifyBackup(w http.ResponseWriter, r *http.Request) {
+	verifyBackupResponse := VerifyBackupResponse{}
+
+	missingObjects, err := snapshot.VerifyBackupObjects(r.Context(), mux.Vars(r)["snapshotName"])
+	if err != nil {
+		logger.Error(err)
+		verifyBackupResponse.Error = "failed to verify backup objects"
+		JSON(w, http.StatusInternalServerError, verifyBackupResponse)
+		return
+	}
+	verifyBackupResponse.MissingObjects = missingObjects
+	verifyBackupResponse.Verified = len(missingObjects) == 0
+
+	verifyBackupResponse.Success = true
+
+	JSON(w, http.StatusOK, verifyBackupResponse)
+}
+
+type CompareBackupsResponse struct {
+	Success    bool                            `json:"success"`
+	Error      string                          `json:"error,omitempty"`
+	Comparison *snapshottypes.BackupComparison `json:"comparison,omitempty"`
+}
+
+// CompareBackups lists the resources that changed between the "from" and "to" backups
+func (h *Handler) CompareBackups(w http.ResponseWriter, r *http.Request) {
+	compareBackupsResponse := CompareBackupsResponse{}
+
+	fromBackupName := r.URL.Query().Get("from")
+	toBackupName := r.URL.Query().Get("to")
+	if fromBackupName == "" || toBackupName == "" {
+		compareBackupsResponse.Error = "from and to backups are required"
+		JSON(w, http.StatusBadRequest, compareBackupsResponse)
+		return
+	}
+
+	comparison, err := snapshot.CompareBackups(fromBackupName, toBackupName)
+	if err != nil {
+		logger.Error(err)
+		compareBackupsResponse.Error = "failed to compare backups"
+		JSON(w, http.StatusInternalServerError, compareBackupsResponse)
+		return
+	}
+	compareBackupsResponse.Comparison = comparison
+
+	compareBackupsResponse.Success = true
+
+	JSON(w, http.StatusOK, compareBackupsResponse)
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/policy"
 	"github.com/replicatedhq/kots/kotsadm/pkg/store"
@@ -33,6 +35,8 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.KOTSStore, handler
 	// Installation
 	r.Name("UploadNewLicense").Path("/api/v1/license").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.UploadNewLicense))
+	r.Name("PreviewLicense").Path("/api/v1/license/preview").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.PreviewLicense))
 	r.Name("ExchangePlatformLicense").Path("/api/v1/license/platform").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppCreate, handler.ExchangePlatformLicense))
 	r.Name("ResumeInstallOnline").Path("/api/v1/license/resume").Methods("PUT").
@@ -164,6 +168,12 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.KOTSStore, handler
 		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseWrite, handler.SyncLicense))
 	r.Name("GetLicense").Path("/api/v1/app/{appSlug}/license").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseRead, handler.GetLicense))
+	r.Name("ValidateAppLicense").Path("/api/v1/app/{appSlug}/license/validate").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseWrite, handler.ValidateAppLicense))
+	r.Name("ListLicenseChanges").Path("/api/v1/app/{appSlug}/license/history").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseRead, handler.ListLicenseChanges))
+	r.Name("SetLicenseSyncInterval").Path("/api/v1/app/{appSlug}/license/sync-interval").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.AppLicenseWrite, handler.SetLicenseSyncInterval))
 
 	r.Name("AppUpdateCheck").Path("/api/v1/app/{appSlug}/updatecheck").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppDownstreamWrite, handler.AppUpdateCheck))
@@ -175,6 +185,8 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.KOTSStore, handler
 	// App snapshot routes
 	r.Name("CreateApplicationBackup").Path("/api/v1/app/{appSlug}/snapshot/backup").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.AppBackupWrite, handler.CreateApplicationBackup))
+	r.Name("RunScheduleNow").Path("/api/v1/app/{appSlug}/snapshot/schedule/run").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.AppBackupWrite, handler.RunScheduleNow))
 	r.Name("GetRestoreStatus").Path("/api/v1/app/{appSlug}/snapshot/restore/status").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreRead, handler.GetRestoreStatus))
 	r.Name("CancelRestore").Path("/api/v1/app/{appSlug}/snapshot/restore").Methods("DELETE").
@@ -183,8 +195,16 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.KOTSStore, handler
 		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreWrite, handler.CreateApplicationRestore))
 	r.Name("GetRestoreDetails").Path("/api/v1/app/{appSlug}/snapshot/restore/{restoreName}").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreRead, handler.GetRestoreDetails))
+	r.Name("GetRestoreWarnings").Path("/api/v1/app/{appSlug}/snapshot/restore/{restoreName}/warnings").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppRestoreRead, handler.GetRestoreWarnings))
 	r.Name("ListBackups").Path("/api/v1/app/{appSlug}/snapshots").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppBackupRead, handler.ListBackups))
+	r.Name("GetAppBackupHistory").Path("/api/v1/app/{appSlug}/snapshots/history").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppBackupRead, handler.GetAppBackupHistory))
+	r.Name("GetAppBackupEstimate").Path("/api/v1/app/{appSlug}/snapshots/estimate").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppBackupRead, handler.GetAppBackupEstimate))
+	r.Name("GetAppResticVolumes").Path("/api/v1/app/{appSlug}/snapshots/restic-volumes").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.AppBackupRead, handler.GetAppResticVolumes))
 	r.Name("GetSnapshotConfig").Path("/api/v1/app/{appSlug}/snapshot/config").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.AppSnapshotsettingsRead, handler.GetSnapshotConfig))
 	r.Name("SaveSnapshotConfig").Path("/api/v1/app/{appSlug}/snapshot/config").Methods("PUT").
@@ -203,16 +223,78 @@ func RegisterSessionAuthRoutes(r *mux.Router, kotsStore store.KOTSStore, handler
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetGlobalSnapshotSettings))
 	r.Name("UpdateGlobalSnapshotSettings").Path("/api/v1/snapshots/settings").Methods("PUT").
 		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.UpdateGlobalSnapshotSettings))
+	r.Name("GetSupportedSnapshotProviders").Path("/api/v1/snapshots/providers").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetSupportedSnapshotProviders))
+	r.Name("GetSnapshotTTLUnits").Path("/api/v1/snapshots/ttl/units").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetSnapshotTTLUnits))
+	r.Name("GetBackupWebhook").Path("/api/v1/snapshots/webhook").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetBackupWebhook))
+	r.Name("UpdateBackupWebhook").Path("/api/v1/snapshots/webhook").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.UpdateBackupWebhook))
+	r.Name("ValidateStore").Path("/api/v1/snapshots/settings/validate").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.ValidateStore))
+	r.Name("RefreshInternalStore").Path("/api/v1/snapshots/settings/internal/refresh").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.RefreshInternalStore))
+	r.Name("SetStorageLocationReadOnly").Path("/api/v1/snapshots/locations/{name}/read-only").Methods("PUT").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.SetStorageLocationReadOnly))
+	r.Name("GetSnapshotDiagnostics").Path("/api/v1/snapshots/diagnostics").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetSnapshotDiagnostics))
+	r.Name("GetInstanceNamespaceCoverage").Path("/api/v1/snapshots/diagnostics/namespace-coverage").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetInstanceNamespaceCoverage))
+	r.Name("ReconcileBackupOwnership").Path("/api/v1/snapshots/reconcile-ownership").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.ReconcileBackupOwnership))
+	r.Name("GetResticLocks").Path("/api/v1/snapshots/restic/locks").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetResticLocks))
+	r.Name("UnlockResticRepository").Path("/api/v1/snapshots/restic/locks/{repoName}/unlock").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.UnlockResticRepository))
+	r.Name("PrePullVeleroImages").Path("/api/v1/snapshots/prepull").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsWrite, handler.PrePullVeleroImages))
+	r.Name("GetVeleroImagePrePullStatus").Path("/api/v1/snapshots/prepull").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetVeleroImagePrePullStatus))
+	r.Name("GetVeleroImages").Path("/api/v1/snapshots/images").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetVeleroImages))
+	r.Name("ListStuckBackups").Path("/api/v1/snapshots/stuck").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.ListStuckBackups))
+	r.Name("FailStuckBackups").Path("/api/v1/snapshots/stuck/fail").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.FailStuckBackups))
 	r.Name("GetBackup").Path("/api/v1/snapshot/{snapshotName}").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetBackup))
+	r.Name("GetSnapshotProgress").Path("/api/v1/snapshot/{snapshotName}/progress").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetSnapshotProgress))
+	r.Name("StreamBackupProgress").Path("/api/v1/snapshot/{snapshotName}/progress/stream").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.StreamBackupProgress))
+	r.Name("VerifyBackup").Path("/api/v1/snapshot/{snapshotName}/verify").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.VerifyBackup))
+	r.Name("CompareBackups").Path("/api/v1/snapshots/compare").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.CompareBackups))
 	r.Name("DeleteBackup").Path("/api/v1/snapshot/{snapshotName}/delete").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.DeleteBackup))
+	r.Name("GetBackupDeletionStatus").Path("/api/v1/snapshot/{snapshotName}/delete-status").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetBackupDeletionStatus))
+	r.Name("RetryBackup").Path("/api/v1/snapshot/{snapshotName}/retry").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.RetryBackup))
 	r.Name("RestoreApps").Path("/api/v1/snapshot/{snapshotName}/restore-apps").Methods("POST").
 		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.RestoreApps))
 	r.Name("GetRestoreAppsStatus").Path("/api/v1/snapshot/{snapshotName}/apps-restore-status").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.RestoreWrite, handler.GetRestoreAppsStatus))
+	r.Name("GetRestoreEstimate").Path("/api/v1/snapshot/{snapshotName}/restore-estimate").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.RestoreRead, handler.GetRestoreEstimate))
+	r.Name("VerifyRestore").Path("/api/v1/snapshot/{snapshotName}/verify-restore").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.RestoreRead, handler.VerifyRestore))
 	r.Name("DownloadSnapshotLogs").Path("/api/v1/snapshot/{backup}/logs").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.DownloadSnapshotLogs))
+	r.Name("CleanupOrphanedObjects").Path("/api/v1/snapshots/orphans/cleanup").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.CleanupOrphanedObjects))
+	r.Name("ImportExistingBackups").Path("/api/v1/snapshots/import").Methods("POST").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupWrite, handler.ImportExistingBackups))
+	r.Name("GetImportExistingBackupsStatus").Path("/api/v1/snapshots/import").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetImportExistingBackupsStatus))
+	r.Name("GetVeleroSupportData").Path("/api/v1/snapshots/support-data").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetVeleroSupportData))
+	r.Name("GetVeleroProfile").Path("/api/v1/snapshots/velero/pprof/{profile}").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.GetVeleroProfile))
+	r.Name("ListSnapshotAuditEvents").Path("/api/v1/snapshots/audit").Methods("GET").
+		HandlerFunc(middleware.EnforceAccess(policy.SnapshotsettingsRead, handler.ListSnapshotAuditEvents))
 	r.Name("GetVeleroStatus").Path("/api/v1/velero").Methods("GET").
 		HandlerFunc(middleware.EnforceAccess(policy.BackupRead, handler.GetVeleroStatus))
 
@@ -275,6 +357,24 @@ func StreamJSON(c *websocket.Conn, payload interface{}) {
 	}
 }
 
+// ServerSentEvent writes the payload as a json server-sent event and flushes it to the client
+func ServerSentEvent(w http.ResponseWriter, event string, payload interface{}) error {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, response); err != nil {
+		return errors.Wrap(err, "failed to write")
+	}
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
 func YAML(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := yaml.Marshal(payload)
 	if err != nil {
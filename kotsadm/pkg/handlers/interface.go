@@ -6,6 +6,7 @@ type KOTSHandler interface {
 	Ping(w http.ResponseWriter, r *http.Request)
 
 	UploadNewLicense(w http.ResponseWriter, r *http.Request)
+	PreviewLicense(w http.ResponseWriter, r *http.Request)
 	ExchangePlatformLicense(w http.ResponseWriter, r *http.Request)
 	ResumeInstallOnline(w http.ResponseWriter, r *http.Request)
 	GetOnlineInstallStatus(w http.ResponseWriter, r *http.Request)
@@ -79,6 +80,9 @@ type KOTSHandler interface {
 
 	SyncLicense(w http.ResponseWriter, r *http.Request)
 	GetLicense(w http.ResponseWriter, r *http.Request)
+	ValidateAppLicense(w http.ResponseWriter, r *http.Request)
+	ListLicenseChanges(w http.ResponseWriter, r *http.Request)
+	SetLicenseSyncInterval(w http.ResponseWriter, r *http.Request)
 
 	AppUpdateCheck(w http.ResponseWriter, r *http.Request)
 	UpdateCheckerSpec(w http.ResponseWriter, r *http.Request)
@@ -86,11 +90,16 @@ type KOTSHandler interface {
 
 	// App snapshot routes
 	CreateApplicationBackup(w http.ResponseWriter, r *http.Request)
+	RunScheduleNow(w http.ResponseWriter, r *http.Request)
 	GetRestoreStatus(w http.ResponseWriter, r *http.Request)
 	CancelRestore(w http.ResponseWriter, r *http.Request)
 	CreateApplicationRestore(w http.ResponseWriter, r *http.Request)
 	GetRestoreDetails(w http.ResponseWriter, r *http.Request)
+	GetRestoreWarnings(w http.ResponseWriter, r *http.Request)
 	ListBackups(w http.ResponseWriter, r *http.Request)
+	GetAppBackupHistory(w http.ResponseWriter, r *http.Request)
+	GetAppBackupEstimate(w http.ResponseWriter, r *http.Request)
+	GetAppResticVolumes(w http.ResponseWriter, r *http.Request)
 	GetSnapshotConfig(w http.ResponseWriter, r *http.Request)
 	SaveSnapshotConfig(w http.ResponseWriter, r *http.Request)
 
@@ -101,11 +110,42 @@ type KOTSHandler interface {
 	SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request)
 	GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
 	UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request)
+	GetSupportedSnapshotProviders(w http.ResponseWriter, r *http.Request)
+	GetSnapshotTTLUnits(w http.ResponseWriter, r *http.Request)
+	GetBackupWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateBackupWebhook(w http.ResponseWriter, r *http.Request)
+	ListStuckBackups(w http.ResponseWriter, r *http.Request)
+	FailStuckBackups(w http.ResponseWriter, r *http.Request)
+	ValidateStore(w http.ResponseWriter, r *http.Request)
+	RefreshInternalStore(w http.ResponseWriter, r *http.Request)
+	SetStorageLocationReadOnly(w http.ResponseWriter, r *http.Request)
+	GetSnapshotDiagnostics(w http.ResponseWriter, r *http.Request)
+	GetInstanceNamespaceCoverage(w http.ResponseWriter, r *http.Request)
+	ReconcileBackupOwnership(w http.ResponseWriter, r *http.Request)
+	GetResticLocks(w http.ResponseWriter, r *http.Request)
+	UnlockResticRepository(w http.ResponseWriter, r *http.Request)
+	PrePullVeleroImages(w http.ResponseWriter, r *http.Request)
+	GetVeleroImagePrePullStatus(w http.ResponseWriter, r *http.Request)
+	GetVeleroImages(w http.ResponseWriter, r *http.Request)
 	GetBackup(w http.ResponseWriter, r *http.Request)
+	GetSnapshotProgress(w http.ResponseWriter, r *http.Request)
+	StreamBackupProgress(w http.ResponseWriter, r *http.Request)
+	VerifyBackup(w http.ResponseWriter, r *http.Request)
+	CompareBackups(w http.ResponseWriter, r *http.Request)
 	DeleteBackup(w http.ResponseWriter, r *http.Request)
+	GetBackupDeletionStatus(w http.ResponseWriter, r *http.Request)
+	RetryBackup(w http.ResponseWriter, r *http.Request)
 	RestoreApps(w http.ResponseWriter, r *http.Request)
 	GetRestoreAppsStatus(w http.ResponseWriter, r *http.Request)
+	GetRestoreEstimate(w http.ResponseWriter, r *http.Request)
+	VerifyRestore(w http.ResponseWriter, r *http.Request)
 	DownloadSnapshotLogs(w http.ResponseWriter, r *http.Request)
+	GetVeleroSupportData(w http.ResponseWriter, r *http.Request)
+	GetVeleroProfile(w http.ResponseWriter, r *http.Request)
+	ListSnapshotAuditEvents(w http.ResponseWriter, r *http.Request)
+	CleanupOrphanedObjects(w http.ResponseWriter, r *http.Request)
+	ImportExistingBackups(w http.ResponseWriter, r *http.Request)
+	GetImportExistingBackupsStatus(w http.ResponseWriter, r *http.Request)
 	GetVeleroStatus(w http.ResponseWriter, r *http.Request)
 
 	// KURL
@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/kotsadm/pkg/license"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/online"
 	installationtypes "github.com/replicatedhq/kots/kotsadm/pkg/online/types"
@@ -29,9 +30,14 @@ import (
 
 type SyncLicenseRequest struct {
 	LicenseData string `json:"licenseData"`
+	// PreflightPolicy is "block" (the default) to respond with an error if preflights can't be run for the new
+	// version, or "warn" to respond with success and the preflight error. The license and version are saved
+	// either way.
+	PreflightPolicy string `json:"preflightPolicy,omitempty"`
 }
 
 type SyncLicenseResponse struct {
+	Error                      string                `json:"error,omitempty"`
 	ID                         string                `json:"id"`
 	Assignee                   string                `json:"assignee"`
 	ExpiresAt                  time.Time             `json:"expiresAt"`
@@ -44,6 +50,7 @@ type SyncLicenseResponse struct {
 	IsIdentityServiceSupported bool                  `json:"isIdentityServiceSupported"`
 	IsGeoaxisSupported         bool                  `json:"isGeoaxisSupported"`
 	IsSnapshotSupported        bool                  `json:"isSnapshotSupported"`
+	PreflightError             string                `json:"preflightError,omitempty"`
 }
 
 type GetLicenseResponse struct {
@@ -59,6 +66,7 @@ type GetLicenseResponse struct {
 	IsIdentityServiceSupported bool                  `json:"isIdentityServiceSupported"`
 	IsGeoaxisSupported         bool                  `json:"isGeoaxisSupported"`
 	IsSnapshotSupported        bool                  `json:"isSnapshotSupported"`
+	LicenseSyncIntervalMinutes int                   `json:"licenseSyncIntervalMinutes"`
 }
 
 type EntitlementResponse struct {
@@ -83,6 +91,29 @@ type UploadLicenseResponse struct {
 	IsConfigurable   bool   `json:"isConfigurable"`
 }
 
+type PreviewLicenseRequest struct {
+	LicenseData string `json:"licenseData"`
+}
+
+type PreviewLicenseResponse struct {
+	Success                    bool                  `json:"success"`
+	Error                      string                `json:"error,omitempty"`
+	ID                         string                `json:"id,omitempty"`
+	AppSlug                    string                `json:"appSlug,omitempty"`
+	Assignee                   string                `json:"assignee,omitempty"`
+	ChannelName                string                `json:"channelName,omitempty"`
+	LicenseSequence            int64                 `json:"licenseSequence"`
+	LicenseType                string                `json:"licenseType,omitempty"`
+	ExpiresAt                  time.Time             `json:"expiresAt"`
+	IsExpired                  bool                  `json:"isExpired"`
+	Entitlements               []EntitlementResponse `json:"entitlements"`
+	IsAirgapSupported          bool                  `json:"isAirgapSupported"`
+	IsGitOpsSupported          bool                  `json:"isGitOpsSupported"`
+	IsIdentityServiceSupported bool                  `json:"isIdentityServiceSupported"`
+	IsGeoaxisSupported         bool                  `json:"isGeoaxisSupported"`
+	IsSnapshotSupported        bool                  `json:"isSnapshotSupported"`
+}
+
 type ResumeInstallOnlineRequest struct {
 	Slug string `json:"slug"`
 }
@@ -114,8 +145,22 @@ func (h *Handler) SyncLicense(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	latestLicense, err := license.Sync(foundApp, syncLicenseRequest.LicenseData, true)
-	if err != nil {
+	preflightPolicy := license.PreflightPolicyBlock
+	switch syncLicenseRequest.PreflightPolicy {
+	case "", string(license.PreflightPolicyBlock):
+	case string(license.PreflightPolicyWarn):
+		preflightPolicy = license.PreflightPolicyWarn
+	default:
+		w.WriteHeader(400)
+		return
+	}
+
+	latestLicense, preflightResult, err := license.Sync(foundApp, syncLicenseRequest.LicenseData, true, preflightPolicy)
+	if errors.Cause(err) == kotspull.ErrUntrustedKey {
+		logger.Error(err)
+		JSON(w, 400, SyncLicenseResponse{Error: "license is not signed with a trusted key"})
+		return
+	} else if err != nil {
 		logger.Error(err)
 		w.WriteHeader(500)
 		return
@@ -142,6 +187,9 @@ func (h *Handler) SyncLicense(w http.ResponseWriter, r *http.Request) {
 		IsGeoaxisSupported:         latestLicense.Spec.IsGeoaxisSupported,
 		IsSnapshotSupported:        latestLicense.Spec.IsSnapshotSupported,
 	}
+	if preflightResult != nil {
+		syncLicenseResponse.PreflightError = preflightResult.Error
+	}
 
 	JSON(w, 200, syncLicenseResponse)
 }
@@ -182,11 +230,121 @@ func (h *Handler) GetLicense(w http.ResponseWriter, r *http.Request) {
 		IsIdentityServiceSupported: license.Spec.IsIdentityServiceSupported,
 		IsGeoaxisSupported:         license.Spec.IsGeoaxisSupported,
 		IsSnapshotSupported:        license.Spec.IsSnapshotSupported,
+		LicenseSyncIntervalMinutes: foundApp.LicenseSyncIntervalMinutes,
 	}
 
 	JSON(w, 200, getLicenseResponse)
 }
 
+type ValidateAppLicenseRequest struct {
+	LicenseData string `json:"licenseData"`
+}
+
+type ValidateAppLicenseResponse struct {
+	Success     bool   `json:"success"`
+	Error       string `json:"error,omitempty"`
+	ID          string `json:"id,omitempty"`
+	ChannelID   string `json:"channelId,omitempty"`
+	ChannelName string `json:"channelName,omitempty"`
+	// ChannelMismatchWarning is set if the license is for another channel than the app's current license
+	ChannelMismatchWarning *licensetypes.ChannelMismatchWarning `json:"channelMismatchWarning,omitempty"`
+}
+
+// ValidateAppLicense checks an uploaded license against the app before it is synced. A license for another
+// channel returns a warning for the operator to confirm, since channel changes are sometimes intended.
+func (h *Handler) ValidateAppLicense(w http.ResponseWriter, r *http.Request) {
+	validateAppLicenseResponse := ValidateAppLicenseResponse{}
+
+	validateAppLicenseRequest := ValidateAppLicenseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&validateAppLicenseRequest); err != nil {
+		logger.Error(err)
+		validateAppLicenseResponse.Error = "failed to decode request body"
+		JSON(w, 400, validateAppLicenseResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		validateAppLicenseResponse.Error = "failed to get app from slug"
+		JSON(w, 500, validateAppLicenseResponse)
+		return
+	}
+
+	currentLicense, err := store.GetStore().GetLatestLicenseForApp(foundApp.ID)
+	if err != nil {
+		logger.Error(err)
+		validateAppLicenseResponse.Error = "failed to get current license"
+		JSON(w, 500, validateAppLicenseResponse)
+		return
+	}
+
+	verifiedLicense, channelMismatchWarning, err := license.ValidateLicenseForApp(currentLicense, validateAppLicenseRequest.LicenseData)
+	if license.IsAppMismatchError(err) {
+		validateAppLicenseResponse.Error = errors.Cause(err).Error()
+		JSON(w, 400, validateAppLicenseResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		validateAppLicenseResponse.Error = "license is not valid"
+		JSON(w, 400, validateAppLicenseResponse)
+		return
+	}
+
+	validateAppLicenseResponse.ID = verifiedLicense.Spec.LicenseID
+	validateAppLicenseResponse.ChannelID = verifiedLicense.Spec.ChannelID
+	validateAppLicenseResponse.ChannelName = verifiedLicense.Spec.ChannelName
+	validateAppLicenseResponse.ChannelMismatchWarning = channelMismatchWarning
+	validateAppLicenseResponse.Success = true
+
+	JSON(w, 200, validateAppLicenseResponse)
+}
+
+const defaultLicenseChangesLimit = 100
+
+type ListLicenseChangesResponse struct {
+	Success bool                         `json:"success"`
+	Error   string                       `json:"error,omitempty"`
+	Changes []licensetypes.LicenseChange `json:"changes"`
+}
+
+// ListLicenseChanges returns the entitlements that changed in each license sync for the app, most recent first
+func (h *Handler) ListLicenseChanges(w http.ResponseWriter, r *http.Request) {
+	response := ListLicenseChangesResponse{}
+
+	limit := defaultLicenseChangesLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			response.Error = "invalid limit"
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		limit = parsed
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		response.Error = "failed to get app"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	changes, err := store.GetStore().ListLicenseChanges(foundApp.ID, limit)
+	if err != nil {
+		logger.Error(err)
+		response.Error = "failed to list license changes"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Changes = changes
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
 func getLicenseEntitlements(license *kotsv1beta1.License) ([]EntitlementResponse, time.Time, error) {
 	var expiresAt time.Time
 	entitlements := []EntitlementResponse{}
@@ -368,6 +526,71 @@ func (h *Handler) UploadNewLicense(w http.ResponseWriter, r *http.Request) {
 	JSON(w, 200, uploadLicenseResponse)
 }
 
+// PreviewLicense verifies a license's signature and returns what it entitles to, without installing it
+// or syncing it with the server
+func (h *Handler) PreviewLicense(w http.ResponseWriter, r *http.Request) {
+	previewLicenseResponse := PreviewLicenseResponse{
+		Success: false,
+	}
+
+	previewLicenseRequest := PreviewLicenseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&previewLicenseRequest); err != nil {
+		logger.Error(err)
+		previewLicenseResponse.Error = "failed to decode request body"
+		JSON(w, 400, previewLicenseResponse)
+		return
+	}
+
+	unverifiedLicense, err := kotsutil.LoadLicenseFromBytes([]byte(previewLicenseRequest.LicenseData))
+	if err != nil {
+		logger.Error(err)
+		previewLicenseResponse.Error = "failed to parse license"
+		JSON(w, 400, previewLicenseResponse)
+		return
+	}
+
+	verifiedLicense, err := kotspull.VerifySignature(unverifiedLicense)
+	if err != nil {
+		previewLicenseResponse.Error = "License signature is not valid"
+		JSON(w, 400, previewLicenseResponse)
+		return
+	}
+
+	entitlements, expiresAt, err := getLicenseEntitlements(verifiedLicense)
+	if err != nil {
+		logger.Error(err)
+		previewLicenseResponse.Error = "failed to get license entitlements"
+		JSON(w, 400, previewLicenseResponse)
+		return
+	}
+
+	expired, err := kotspull.LicenseIsExpired(verifiedLicense)
+	if err != nil {
+		logger.Error(err)
+		previewLicenseResponse.Error = "failed to check license expiration"
+		JSON(w, 400, previewLicenseResponse)
+		return
+	}
+
+	previewLicenseResponse.Success = true
+	previewLicenseResponse.ID = verifiedLicense.Spec.LicenseID
+	previewLicenseResponse.AppSlug = verifiedLicense.Spec.AppSlug
+	previewLicenseResponse.Assignee = verifiedLicense.Spec.CustomerName
+	previewLicenseResponse.ChannelName = verifiedLicense.Spec.ChannelName
+	previewLicenseResponse.LicenseSequence = verifiedLicense.Spec.LicenseSequence
+	previewLicenseResponse.LicenseType = verifiedLicense.Spec.LicenseType
+	previewLicenseResponse.ExpiresAt = expiresAt
+	previewLicenseResponse.IsExpired = expired
+	previewLicenseResponse.Entitlements = entitlements
+	previewLicenseResponse.IsAirgapSupported = verifiedLicense.Spec.IsAirgapSupported
+	previewLicenseResponse.IsGitOpsSupported = verifiedLicense.Spec.IsGitOpsSupported
+	previewLicenseResponse.IsIdentityServiceSupported = verifiedLicense.Spec.IsIdentityServiceSupported
+	previewLicenseResponse.IsGeoaxisSupported = verifiedLicense.Spec.IsGeoaxisSupported
+	previewLicenseResponse.IsSnapshotSupported = verifiedLicense.Spec.IsSnapshotSupported
+
+	JSON(w, 200, previewLicenseResponse)
+}
+
 func (h *Handler) ResumeInstallOnline(w http.ResponseWriter, r *http.Request) {
 	resumeInstallOnlineResponse := ResumeInstallOnlineResponse{
 		Success: false,
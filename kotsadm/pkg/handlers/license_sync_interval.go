@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	"github.com/replicatedhq/kots/kotsadm/pkg/updatechecker"
+)
+
+type LicenseSyncIntervalRequest struct {
+	// IntervalMinutes is how often to sync the license, 0 syncs it only when checking for updates
+	IntervalMinutes int `json:"intervalMinutes"`
+}
+
+type LicenseSyncIntervalResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) SetLicenseSyncInterval(w http.ResponseWriter, r *http.Request) {
+	licenseSyncIntervalResponse := &LicenseSyncIntervalResponse{}
+
+	licenseSyncIntervalRequest := LicenseSyncIntervalRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&licenseSyncIntervalRequest); err != nil {
+		logger.Error(err)
+		licenseSyncIntervalResponse.Error = "failed to decode request body"
+		JSON(w, 400, licenseSyncIntervalResponse)
+		return
+	}
+
+	if err := updatechecker.ValidateLicenseSyncInterval(licenseSyncIntervalRequest.IntervalMinutes); err != nil {
+		licenseSyncIntervalResponse.Error = err.Error()
+		JSON(w, 400, licenseSyncIntervalResponse)
+		return
+	}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		licenseSyncIntervalResponse.Error = "failed to get app from slug"
+		JSON(w, 500, licenseSyncIntervalResponse)
+		return
+	}
+
+	if foundApp.IsAirgap {
+		logger.Error(errors.New("airgap scheduled license syncs are not supported"))
+		licenseSyncIntervalResponse.Error = "airgap scheduled license syncs are not supported"
+		JSON(w, 400, licenseSyncIntervalResponse)
+		return
+	}
+
+	if err := store.GetStore().SetLicenseSyncInterval(foundApp.ID, licenseSyncIntervalRequest.IntervalMinutes); err != nil {
+		logger.Error(err)
+		licenseSyncIntervalResponse.Error = "failed to set license sync interval"
+		JSON(w, 500, licenseSyncIntervalResponse)
+		return
+	}
+
+	// reconfigure update checker for the app so that future license syncs run on the new interval
+	if err := updatechecker.Configure(foundApp.ID); err != nil {
+		logger.Error(err)
+		licenseSyncIntervalResponse.Error = "failed to reconfigure update checker cron job"
+		JSON(w, 500, licenseSyncIntervalResponse)
+		return
+	}
+
+	JSON(w, 204, "")
+}
@@ -57,6 +57,18 @@ func (mr *MockKOTSHandlerMockRecorder) UploadNewLicense(w, r interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadNewLicense", reflect.TypeOf((*MockKOTSHandler)(nil).UploadNewLicense), w, r)
 }
 
+// PreviewLicense mocks base method
+func (m *MockKOTSHandler) PreviewLicense(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PreviewLicense", w, r)
+}
+
+// PreviewLicense indicates an expected call of PreviewLicense
+func (mr *MockKOTSHandlerMockRecorder) PreviewLicense(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewLicense", reflect.TypeOf((*MockKOTSHandler)(nil).PreviewLicense), w, r)
+}
+
 // ExchangePlatformLicense mocks base method
 func (m *MockKOTSHandler) ExchangePlatformLicense(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -717,6 +729,42 @@ func (mr *MockKOTSHandlerMockRecorder) GetLicense(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLicense", reflect.TypeOf((*MockKOTSHandler)(nil).GetLicense), w, r)
 }
 
+// ValidateAppLicense mocks base method
+func (m *MockKOTSHandler) ValidateAppLicense(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ValidateAppLicense", w, r)
+}
+
+// ValidateAppLicense indicates an expected call of ValidateAppLicense
+func (mr *MockKOTSHandlerMockRecorder) ValidateAppLicense(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAppLicense", reflect.TypeOf((*MockKOTSHandler)(nil).ValidateAppLicense), w, r)
+}
+
+// ListLicenseChanges mocks base method
+func (m *MockKOTSHandler) ListLicenseChanges(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListLicenseChanges", w, r)
+}
+
+// ListLicenseChanges indicates an expected call of ListLicenseChanges
+func (mr *MockKOTSHandlerMockRecorder) ListLicenseChanges(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLicenseChanges", reflect.TypeOf((*MockKOTSHandler)(nil).ListLicenseChanges), w, r)
+}
+
+// SetLicenseSyncInterval mocks base method
+func (m *MockKOTSHandler) SetLicenseSyncInterval(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLicenseSyncInterval", w, r)
+}
+
+// SetLicenseSyncInterval indicates an expected call of SetLicenseSyncInterval
+func (mr *MockKOTSHandlerMockRecorder) SetLicenseSyncInterval(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLicenseSyncInterval", reflect.TypeOf((*MockKOTSHandler)(nil).SetLicenseSyncInterval), w, r)
+}
+
 // AppUpdateCheck mocks base method
 func (m *MockKOTSHandler) AppUpdateCheck(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -765,6 +813,18 @@ func (mr *MockKOTSHandlerMockRecorder) CreateApplicationBackup(w, r interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplicationBackup", reflect.TypeOf((*MockKOTSHandler)(nil).CreateApplicationBackup), w, r)
 }
 
+// RunScheduleNow mocks base method
+func (m *MockKOTSHandler) RunScheduleNow(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunScheduleNow", w, r)
+}
+
+// RunScheduleNow indicates an expected call of RunScheduleNow
+func (mr *MockKOTSHandlerMockRecorder) RunScheduleNow(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScheduleNow", reflect.TypeOf((*MockKOTSHandler)(nil).RunScheduleNow), w, r)
+}
+
 // GetRestoreStatus mocks base method
 func (m *MockKOTSHandler) GetRestoreStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -813,6 +873,18 @@ func (mr *MockKOTSHandlerMockRecorder) GetRestoreDetails(w, r interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestoreDetails", reflect.TypeOf((*MockKOTSHandler)(nil).GetRestoreDetails), w, r)
 }
 
+// GetRestoreWarnings mocks base method
+func (m *MockKOTSHandler) GetRestoreWarnings(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetRestoreWarnings", w, r)
+}
+
+// GetRestoreWarnings indicates an expected call of GetRestoreWarnings
+func (mr *MockKOTSHandlerMockRecorder) GetRestoreWarnings(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestoreWarnings", reflect.TypeOf((*MockKOTSHandler)(nil).GetRestoreWarnings), w, r)
+}
+
 // ListBackups mocks base method
 func (m *MockKOTSHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -825,6 +897,42 @@ func (mr *MockKOTSHandlerMockRecorder) ListBackups(w, r interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackups", reflect.TypeOf((*MockKOTSHandler)(nil).ListBackups), w, r)
 }
 
+// GetAppBackupHistory mocks base method
+func (m *MockKOTSHandler) GetAppBackupHistory(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppBackupHistory", w, r)
+}
+
+// GetAppBackupHistory indicates an expected call of GetAppBackupHistory
+func (mr *MockKOTSHandlerMockRecorder) GetAppBackupHistory(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppBackupHistory", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppBackupHistory), w, r)
+}
+
+// GetAppBackupEstimate mocks base method
+func (m *MockKOTSHandler) GetAppBackupEstimate(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppBackupEstimate", w, r)
+}
+
+// GetAppBackupEstimate indicates an expected call of GetAppBackupEstimate
+func (mr *MockKOTSHandlerMockRecorder) GetAppBackupEstimate(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppBackupEstimate", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppBackupEstimate), w, r)
+}
+
+// GetAppResticVolumes mocks base method
+func (m *MockKOTSHandler) GetAppResticVolumes(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetAppResticVolumes", w, r)
+}
+
+// GetAppResticVolumes indicates an expected call of GetAppResticVolumes
+func (mr *MockKOTSHandlerMockRecorder) GetAppResticVolumes(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppResticVolumes", reflect.TypeOf((*MockKOTSHandler)(nil).GetAppResticVolumes), w, r)
+}
+
 // GetSnapshotConfig mocks base method
 func (m *MockKOTSHandler) GetSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -921,6 +1029,210 @@ func (mr *MockKOTSHandlerMockRecorder) UpdateGlobalSnapshotSettings(w, r interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGlobalSnapshotSettings", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateGlobalSnapshotSettings), w, r)
 }
 
+// GetSupportedSnapshotProviders mocks base method
+func (m *MockKOTSHandler) GetSupportedSnapshotProviders(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSupportedSnapshotProviders", w, r)
+}
+
+// GetSupportedSnapshotProviders indicates an expected call of GetSupportedSnapshotProviders
+func (mr *MockKOTSHandlerMockRecorder) GetSupportedSnapshotProviders(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSupportedSnapshotProviders", reflect.TypeOf((*MockKOTSHandler)(nil).GetSupportedSnapshotProviders), w, r)
+}
+
+// GetSnapshotTTLUnits mocks base method
+func (m *MockKOTSHandler) GetSnapshotTTLUnits(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSnapshotTTLUnits", w, r)
+}
+
+// GetSnapshotTTLUnits indicates an expected call of GetSnapshotTTLUnits
+func (mr *MockKOTSHandlerMockRecorder) GetSnapshotTTLUnits(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotTTLUnits", reflect.TypeOf((*MockKOTSHandler)(nil).GetSnapshotTTLUnits), w, r)
+}
+
+// GetBackupWebhook mocks base method
+func (m *MockKOTSHandler) GetBackupWebhook(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetBackupWebhook", w, r)
+}
+
+// GetBackupWebhook indicates an expected call of GetBackupWebhook
+func (mr *MockKOTSHandlerMockRecorder) GetBackupWebhook(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupWebhook", reflect.TypeOf((*MockKOTSHandler)(nil).GetBackupWebhook), w, r)
+}
+
+// UpdateBackupWebhook mocks base method
+func (m *MockKOTSHandler) UpdateBackupWebhook(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdateBackupWebhook", w, r)
+}
+
+// UpdateBackupWebhook indicates an expected call of UpdateBackupWebhook
+func (mr *MockKOTSHandlerMockRecorder) UpdateBackupWebhook(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBackupWebhook", reflect.TypeOf((*MockKOTSHandler)(nil).UpdateBackupWebhook), w, r)
+}
+
+// ListStuckBackups mocks base method
+func (m *MockKOTSHandler) ListStuckBackups(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListStuckBackups", w, r)
+}
+
+// ListStuckBackups indicates an expected call of ListStuckBackups
+func (mr *MockKOTSHandlerMockRecorder) ListStuckBackups(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStuckBackups", reflect.TypeOf((*MockKOTSHandler)(nil).ListStuckBackups), w, r)
+}
+
+// FailStuckBackups mocks base method
+func (m *MockKOTSHandler) FailStuckBackups(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "FailStuckBackups", w, r)
+}
+
+// FailStuckBackups indicates an expected call of FailStuckBackups
+func (mr *MockKOTSHandlerMockRecorder) FailStuckBackups(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailStuckBackups", reflect.TypeOf((*MockKOTSHandler)(nil).FailStuckBackups), w, r)
+}
+
+// ValidateStore mocks base method
+func (m *MockKOTSHandler) ValidateStore(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ValidateStore", w, r)
+}
+
+// ValidateStore indicates an expected call of ValidateStore
+func (mr *MockKOTSHandlerMockRecorder) ValidateStore(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateStore", reflect.TypeOf((*MockKOTSHandler)(nil).ValidateStore), w, r)
+}
+
+// RefreshInternalStore mocks base method
+func (m *MockKOTSHandler) RefreshInternalStore(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RefreshInternalStore", w, r)
+}
+
+// RefreshInternalStore indicates an expected call of RefreshInternalStore
+func (mr *MockKOTSHandlerMockRecorder) RefreshInternalStore(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshInternalStore", reflect.TypeOf((*MockKOTSHandler)(nil).RefreshInternalStore), w, r)
+}
+
+// SetStorageLocationReadOnly mocks base method
+func (m *MockKOTSHandler) SetStorageLocationReadOnly(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetStorageLocationReadOnly", w, r)
+}
+
+// SetStorageLocationReadOnly indicates an expected call of SetStorageLocationReadOnly
+func (mr *MockKOTSHandlerMockRecorder) SetStorageLocationReadOnly(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStorageLocationReadOnly", reflect.TypeOf((*MockKOTSHandler)(nil).SetStorageLocationReadOnly), w, r)
+}
+
+// GetSnapshotDiagnostics mocks base method
+func (m *MockKOTSHandler) GetSnapshotDiagnostics(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSnapshotDiagnostics", w, r)
+}
+
+// GetSnapshotDiagnostics indicates an expected call of GetSnapshotDiagnostics
+func (mr *MockKOTSHandlerMockRecorder) GetSnapshotDiagnostics(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotDiagnostics", reflect.TypeOf((*MockKOTSHandler)(nil).GetSnapshotDiagnostics), w, r)
+}
+
+// GetInstanceNamespaceCoverage mocks base method
+func (m *MockKOTSHandler) GetInstanceNamespaceCoverage(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetInstanceNamespaceCoverage", w, r)
+}
+
+// GetInstanceNamespaceCoverage indicates an expected call of GetInstanceNamespaceCoverage
+func (mr *MockKOTSHandlerMockRecorder) GetInstanceNamespaceCoverage(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInstanceNamespaceCoverage", reflect.TypeOf((*MockKOTSHandler)(nil).GetInstanceNamespaceCoverage), w, r)
+}
+
+// ReconcileBackupOwnership mocks base method
+func (m *MockKOTSHandler) ReconcileBackupOwnership(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReconcileBackupOwnership", w, r)
+}
+
+// ReconcileBackupOwnership indicates an expected call of ReconcileBackupOwnership
+func (mr *MockKOTSHandlerMockRecorder) ReconcileBackupOwnership(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileBackupOwnership", reflect.TypeOf((*MockKOTSHandler)(nil).ReconcileBackupOwnership), w, r)
+}
+
+// GetResticLocks mocks base method
+func (m *MockKOTSHandler) GetResticLocks(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetResticLocks", w, r)
+}
+
+// GetResticLocks indicates an expected call of GetResticLocks
+func (mr *MockKOTSHandlerMockRecorder) GetResticLocks(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResticLocks", reflect.TypeOf((*MockKOTSHandler)(nil).GetResticLocks), w, r)
+}
+
+// UnlockResticRepository mocks base method
+func (m *MockKOTSHandler) UnlockResticRepository(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UnlockResticRepository", w, r)
+}
+
+// UnlockResticRepository indicates an expected call of UnlockResticRepository
+func (mr *MockKOTSHandlerMockRecorder) UnlockResticRepository(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockResticRepository", reflect.TypeOf((*MockKOTSHandler)(nil).UnlockResticRepository), w, r)
+}
+
+// PrePullVeleroImages mocks base method
+func (m *MockKOTSHandler) PrePullVeleroImages(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PrePullVeleroImages", w, r)
+}
+
+// PrePullVeleroImages indicates an expected call of PrePullVeleroImages
+func (mr *MockKOTSHandlerMockRecorder) PrePullVeleroImages(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrePullVeleroImages", reflect.TypeOf((*MockKOTSHandler)(nil).PrePullVeleroImages), w, r)
+}
+
+// GetVeleroImagePrePullStatus mocks base method
+func (m *MockKOTSHandler) GetVeleroImagePrePullStatus(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetVeleroImagePrePullStatus", w, r)
+}
+
+// GetVeleroImagePrePullStatus indicates an expected call of GetVeleroImagePrePullStatus
+func (mr *MockKOTSHandlerMockRecorder) GetVeleroImagePrePullStatus(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroImagePrePullStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetVeleroImagePrePullStatus), w, r)
+}
+
+// GetVeleroImages mocks base method
+func (m *MockKOTSHandler) GetVeleroImages(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetVeleroImages", w, r)
+}
+
+// GetVeleroImages indicates an expected call of GetVeleroImages
+func (mr *MockKOTSHandlerMockRecorder) GetVeleroImages(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroImages", reflect.TypeOf((*MockKOTSHandler)(nil).GetVeleroImages), w, r)
+}
+
 // GetBackup mocks base method
 func (m *MockKOTSHandler) GetBackup(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -933,6 +1245,54 @@ func (mr *MockKOTSHandlerMockRecorder) GetBackup(w, r interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackup", reflect.TypeOf((*MockKOTSHandler)(nil).GetBackup), w, r)
 }
 
+// GetSnapshotProgress mocks base method
+func (m *MockKOTSHandler) GetSnapshotProgress(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetSnapshotProgress", w, r)
+}
+
+// GetSnapshotProgress indicates an expected call of GetSnapshotProgress
+func (mr *MockKOTSHandlerMockRecorder) GetSnapshotProgress(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotProgress", reflect.TypeOf((*MockKOTSHandler)(nil).GetSnapshotProgress), w, r)
+}
+
+// StreamBackupProgress mocks base method
+func (m *MockKOTSHandler) StreamBackupProgress(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StreamBackupProgress", w, r)
+}
+
+// StreamBackupProgress indicates an expected call of StreamBackupProgress
+func (mr *MockKOTSHandlerMockRecorder) StreamBackupProgress(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamBackupProgress", reflect.TypeOf((*MockKOTSHandler)(nil).StreamBackupProgress), w, r)
+}
+
+// VerifyBackup mocks base method
+func (m *MockKOTSHandler) VerifyBackup(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyBackup", w, r)
+}
+
+// VerifyBackup indicates an expected call of VerifyBackup
+func (mr *MockKOTSHandlerMockRecorder) VerifyBackup(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBackup", reflect.TypeOf((*MockKOTSHandler)(nil).VerifyBackup), w, r)
+}
+
+// CompareBackups mocks base method
+func (m *MockKOTSHandler) CompareBackups(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CompareBackups", w, r)
+}
+
+// CompareBackups indicates an expected call of CompareBackups
+func (mr *MockKOTSHandlerMockRecorder) CompareBackups(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareBackups", reflect.TypeOf((*MockKOTSHandler)(nil).CompareBackups), w, r)
+}
+
 // DeleteBackup mocks base method
 func (m *MockKOTSHandler) DeleteBackup(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -945,6 +1305,30 @@ func (mr *MockKOTSHandlerMockRecorder) DeleteBackup(w, r interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBackup", reflect.TypeOf((*MockKOTSHandler)(nil).DeleteBackup), w, r)
 }
 
+// GetBackupDeletionStatus mocks base method
+func (m *MockKOTSHandler) GetBackupDeletionStatus(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetBackupDeletionStatus", w, r)
+}
+
+// GetBackupDeletionStatus indicates an expected call of GetBackupDeletionStatus
+func (mr *MockKOTSHandlerMockRecorder) GetBackupDeletionStatus(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupDeletionStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetBackupDeletionStatus), w, r)
+}
+
+// RetryBackup mocks base method
+func (m *MockKOTSHandler) RetryBackup(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RetryBackup", w, r)
+}
+
+// RetryBackup indicates an expected call of RetryBackup
+func (mr *MockKOTSHandlerMockRecorder) RetryBackup(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryBackup", reflect.TypeOf((*MockKOTSHandler)(nil).RetryBackup), w, r)
+}
+
 // RestoreApps mocks base method
 func (m *MockKOTSHandler) RestoreApps(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -969,6 +1353,30 @@ func (mr *MockKOTSHandlerMockRecorder) GetRestoreAppsStatus(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestoreAppsStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetRestoreAppsStatus), w, r)
 }
 
+// GetRestoreEstimate mocks base method
+func (m *MockKOTSHandler) GetRestoreEstimate(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetRestoreEstimate", w, r)
+}
+
+// GetRestoreEstimate indicates an expected call of GetRestoreEstimate
+func (mr *MockKOTSHandlerMockRecorder) GetRestoreEstimate(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRestoreEstimate", reflect.TypeOf((*MockKOTSHandler)(nil).GetRestoreEstimate), w, r)
+}
+
+// VerifyRestore mocks base method
+func (m *MockKOTSHandler) VerifyRestore(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "VerifyRestore", w, r)
+}
+
+// VerifyRestore indicates an expected call of VerifyRestore
+func (mr *MockKOTSHandlerMockRecorder) VerifyRestore(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRestore", reflect.TypeOf((*MockKOTSHandler)(nil).VerifyRestore), w, r)
+}
+
 // DownloadSnapshotLogs mocks base method
 func (m *MockKOTSHandler) DownloadSnapshotLogs(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -981,6 +1389,78 @@ func (mr *MockKOTSHandlerMockRecorder) DownloadSnapshotLogs(w, r interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadSnapshotLogs", reflect.TypeOf((*MockKOTSHandler)(nil).DownloadSnapshotLogs), w, r)
 }
 
+// GetVeleroSupportData mocks base method
+func (m *MockKOTSHandler) GetVeleroSupportData(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetVeleroSupportData", w, r)
+}
+
+// GetVeleroSupportData indicates an expected call of GetVeleroSupportData
+func (mr *MockKOTSHandlerMockRecorder) GetVeleroSupportData(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroSupportData", reflect.TypeOf((*MockKOTSHandler)(nil).GetVeleroSupportData), w, r)
+}
+
+// GetVeleroProfile mocks base method
+func (m *MockKOTSHandler) GetVeleroProfile(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetVeleroProfile", w, r)
+}
+
+// GetVeleroProfile indicates an expected call of GetVeleroProfile
+func (mr *MockKOTSHandlerMockRecorder) GetVeleroProfile(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroProfile", reflect.TypeOf((*MockKOTSHandler)(nil).GetVeleroProfile), w, r)
+}
+
+// ListSnapshotAuditEvents mocks base method
+func (m *MockKOTSHandler) ListSnapshotAuditEvents(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ListSnapshotAuditEvents", w, r)
+}
+
+// ListSnapshotAuditEvents indicates an expected call of ListSnapshotAuditEvents
+func (mr *MockKOTSHandlerMockRecorder) ListSnapshotAuditEvents(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshotAuditEvents", reflect.TypeOf((*MockKOTSHandler)(nil).ListSnapshotAuditEvents), w, r)
+}
+
+// CleanupOrphanedObjects mocks base method
+func (m *MockKOTSHandler) CleanupOrphanedObjects(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CleanupOrphanedObjects", w, r)
+}
+
+// CleanupOrphanedObjects indicates an expected call of CleanupOrphanedObjects
+func (mr *MockKOTSHandlerMockRecorder) CleanupOrphanedObjects(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanupOrphanedObjects", reflect.TypeOf((*MockKOTSHandler)(nil).CleanupOrphanedObjects), w, r)
+}
+
+// ImportExistingBackups mocks base method
+func (m *MockKOTSHandler) ImportExistingBackups(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ImportExistingBackups", w, r)
+}
+
+// ImportExistingBackups indicates an expected call of ImportExistingBackups
+func (mr *MockKOTSHandlerMockRecorder) ImportExistingBackups(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportExistingBackups", reflect.TypeOf((*MockKOTSHandler)(nil).ImportExistingBackups), w, r)
+}
+
+// GetImportExistingBackupsStatus mocks base method
+func (m *MockKOTSHandler) GetImportExistingBackupsStatus(w http.ResponseWriter, r *http.Request) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "GetImportExistingBackupsStatus", w, r)
+}
+
+// GetImportExistingBackupsStatus indicates an expected call of GetImportExistingBackupsStatus
+func (mr *MockKOTSHandlerMockRecorder) GetImportExistingBackupsStatus(w, r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportExistingBackupsStatus", reflect.TypeOf((*MockKOTSHandler)(nil).GetImportExistingBackupsStatus), w, r)
+}
+
 // GetVeleroStatus mocks base method
 func (m *MockKOTSHandler) GetVeleroStatus(w http.ResponseWriter, r *http.Request) {
 	m.ctrl.T.Helper()
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -19,9 +21,20 @@ import (
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+type CreateApplicationRestoreRequest struct {
+	// RestoreHelperImage overrides the restic restore helper image for this restore only
+	RestoreHelperImage string `json:"restoreHelperImage,omitempty"`
+	// CrossApp restores the backup even if it was taken by a different app
+	CrossApp bool `json:"crossApp,omitempty"`
+	// NamespaceMapping restores resources from the backed up namespaces into other namespaces, cross-app only
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+}
+
 type CreateApplicationRestoreResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success     bool     `json:"success"`
+	Error       string   `json:"error,omitempty"`
+	RestoreName string   `json:"restoreName,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
 }
 
 type GetRestoreStatusResponse struct {
@@ -38,6 +51,25 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 	appSlug := mux.Vars(r)["appSlug"]
 	snapshotName := mux.Vars(r)["snapshotName"]
 
+	// the request body is optional
+	createRestoreRequest := CreateApplicationRestoreRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&createRestoreRequest); err != nil && err != io.EOF {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	}
+
+	if createRestoreRequest.CrossApp {
+		createCrossAppRestore(w, r, appSlug, snapshotName, createRestoreRequest)
+		return
+	}
+	if len(createRestoreRequest.NamespaceMapping) > 0 {
+		createRestoreResponse.Error = "namespace mapping is only supported for cross-app restores"
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	}
+
 	backup, err := snapshot.GetBackup(snapshotName)
 	if err != nil {
 		logger.Error(err)
@@ -102,9 +134,34 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	restoreName, err := snapshot.GetApplicationRestoreName(snapshotName, kotsApp.Slug)
+	if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to get restore name"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	if createRestoreRequest.RestoreHelperImage != "" {
+		err := snapshot.OverrideRestoreHelperImage(r.Context(), restoreName, createRestoreRequest.RestoreHelperImage)
+		if snapshot.IsRestoreHelperOverrideInUseError(err) {
+			createRestoreResponse.Error = err.Error()
+			JSON(w, http.StatusConflict, createRestoreResponse)
+			return
+		} else if err != nil {
+			logger.Error(err)
+			createRestoreResponse.Error = "failed to override restore helper image"
+			JSON(w, http.StatusInternalServerError, createRestoreResponse)
+			return
+		}
+	}
+
 	err = app.InitiateRestore(snapshotName, kotsApp.ID)
 	if err != nil {
 		logger.Error(err)
+		if err := snapshot.RevertRestoreHelperImage(r.Context(), restoreName); err != nil {
+			logger.Error(errors.Wrap(err, "failed to revert restore helper image"))
+		}
 		createRestoreResponse.Error = "failed to initiate restore"
 		JSON(w, http.StatusInternalServerError, createRestoreResponse)
 		return
@@ -115,6 +172,54 @@ func (h *Handler) CreateApplicationRestore(w http.ResponseWriter, r *http.Reques
 	JSON(w, http.StatusOK, createRestoreResponse)
 }
 
+// createCrossAppRestore restores a backup taken by any app into the app's cluster. The app doesn't track the restore,
+// so it isn't undeployed or redeployed and its deployed version doesn't change.
+func createCrossAppRestore(w http.ResponseWriter, r *http.Request, appSlug string, snapshotName string, createRestoreRequest CreateApplicationRestoreRequest) {
+	createRestoreResponse := CreateApplicationRestoreResponse{
+		Success: false,
+	}
+
+	kotsApp, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to get app"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	if kotsApp.RestoreInProgressName != "" {
+		createRestoreResponse.Error = "restore is already in progress"
+		JSON(w, http.StatusConflict, createRestoreResponse)
+		return
+	}
+
+	if createRestoreRequest.RestoreHelperImage != "" {
+		createRestoreResponse.Error = "restore helper image overrides are not supported for cross-app restores"
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	}
+
+	restore, warnings, err := snapshot.CreateCrossAppRestore(r.Context(), snapshotName, kotsApp.ID, kotsApp.Slug, createRestoreRequest.NamespaceMapping)
+	if snapshot.IsBackupNotRestorableError(err) {
+		createRestoreResponse.Error = err.Error()
+		JSON(w, http.StatusBadRequest, createRestoreResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		createRestoreResponse.Error = "failed to create restore"
+		JSON(w, http.StatusInternalServerError, createRestoreResponse)
+		return
+	}
+
+	logger.Infof("Created cross-app restore %s of backup %s for app %s", restore.Name, snapshotName, kotsApp.Slug)
+
+	createRestoreResponse.RestoreName = restore.Name
+	createRestoreResponse.Warnings = warnings
+	createRestoreResponse.Success = true
+
+	JSON(w, http.StatusOK, createRestoreResponse)
+}
+
 type RestoreAppsResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
@@ -289,6 +394,15 @@ func (h *Handler) CancelRestore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if foundApp.RestoreInProgressName != "" {
+		restoreName, err := snapshot.GetApplicationRestoreName(foundApp.RestoreInProgressName, foundApp.Slug)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get restore name"))
+		} else if err := snapshot.RevertRestoreHelperImage(r.Context(), restoreName); err != nil {
+			logger.Error(errors.Wrap(err, "failed to revert restore helper image"))
+		}
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -349,3 +463,90 @@ func (h *Handler) GetRestoreDetails(w http.ResponseWriter, r *http.Request) {
 
 	JSON(w, http.StatusOK, response)
 }
+
+type GetRestoreWarningsResponse struct {
+	Success  bool                           `json:"success"`
+	Error    string                         `json:"error,omitempty"`
+	Warnings []snapshottypes.RestoreWarning `json:"warnings"`
+}
+
+func (h *Handler) GetRestoreWarnings(w http.ResponseWriter, r *http.Request) {
+	getRestoreWarningsResponse := GetRestoreWarningsResponse{}
+
+	foundApp, err := store.GetStore().GetAppFromSlug(mux.Vars(r)["appSlug"])
+	if err != nil {
+		logger.Error(err)
+		getRestoreWarningsResponse.Error = "failed to get app from app slug"
+		JSON(w, http.StatusInternalServerError, getRestoreWarningsResponse)
+		return
+	}
+
+	warnings, err := snapshot.GetRestoreWarnings(r.Context(), foundApp.ID, foundApp.Slug, mux.Vars(r)["restoreName"])
+	if kuberneteserrors.IsNotFound(errors.Cause(err)) {
+		getRestoreWarningsResponse.Error = "restore not found"
+		JSON(w, http.StatusNotFound, getRestoreWarningsResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		getRestoreWarningsResponse.Error = "failed to get restore warnings"
+		JSON(w, http.StatusInternalServerError, getRestoreWarningsResponse)
+		return
+	}
+	getRestoreWarningsResponse.Warnings = warnings
+
+	getRestoreWarningsResponse.Success = true
+
+	JSON(w, http.StatusOK, getRestoreWarningsResponse)
+}
+
+type GetRestoreEstimateResponse struct {
+	Success         bool                           `json:"success"`
+	Error           string                         `json:"error,omitempty"`
+	RestoreEstimate *snapshottypes.RestoreEstimate `json:"restoreEstimate,omitempty"`
+}
+
+func (h *Handler) GetRestoreEstimate(w http.ResponseWriter, r *http.Request) {
+	getRestoreEstimateResponse := GetRestoreEstimateResponse{}
+
+	estimate, err := snapshot.EstimateRestoreTime(context.TODO(), mux.Vars(r)["snapshotName"])
+	if err != nil {
+		logger.Error(err)
+		getRestoreEstimateResponse.Error = "failed to estimate restore time"
+		JSON(w, http.StatusInternalServerError, getRestoreEstimateResponse)
+		return
+	}
+	getRestoreEstimateResponse.RestoreEstimate = estimate
+
+	getRestoreEstimateResponse.Success = true
+
+	JSON(w, http.StatusOK, getRestoreEstimateResponse)
+}
+
+type VerifyRestoreResponse struct {
+	Success    bool                             `json:"success"`
+	Error      string                           `json:"error,omitempty"`
+	Verified   bool                             `json:"verified"`
+	Mismatches []snapshottypes.ChecksumMismatch `json:"mismatches"`
+}
+
+func (h *Handler) VerifyRestore(w http.ResponseWriter, r *http.Request) {
+	verifyRestoreResponse := VerifyRestoreResponse{}
+
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	mismatches, err := snapshot.VerifyRestore(r.Context(), mux.Vars(r)["snapshotName"])
+	if err != nil {
+		logger.Error(err)
+		verifyRestoreResponse.Error = "failed to verify restore checksums"
+		JSON(w, http.StatusInternalServerError, verifyRestoreResponse)
+		return
+	}
+	verifyRestoreResponse.Mismatches = mismatches
+	verifyRestoreResponse.Verified = len(mismatches) == 0
+
+	verifyRestoreResponse.Success = true
+
+	JSON(w, http.StatusOK, verifyRestoreResponse)
+}
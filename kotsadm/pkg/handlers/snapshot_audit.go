@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	"github.com/replicatedhq/kots/kotsadm/pkg/session"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot"
+	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
+)
+
+const defaultSnapshotAuditEventsLimit = 100
+
+type ListSnapshotAuditEventsResponse struct {
+	Success bool                               `json:"success"`
+	Error   string                             `json:"error,omitempty"`
+	Events  []snapshottypes.SnapshotAuditEvent `json:"events"`
+}
+
+func (h *Handler) ListSnapshotAuditEvents(w http.ResponseWriter, r *http.Request) {
+	response := ListSnapshotAuditEventsResponse{}
+
+	limit := defaultSnapshotAuditEventsLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 {
+			response.Error = "invalid limit"
+			JSON(w, http.StatusBadRequest, response)
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := store.GetStore().ListSnapshotAuditEvents(r.URL.Query().Get("appId"), limit)
+	if err != nil {
+		logger.Error(err)
+		response.Error = "failed to list snapshot audit events"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Events = events
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
+// recordSnapshotAuditEvent records who changed the snapshot settings. The change has already been applied,
+// so failing to record it is logged rather than failing the request.
+func recordSnapshotAuditEvent(r *http.Request, action string, appID string, before map[string]interface{}, after map[string]interface{}) {
+	actor := ""
+	if sess := session.ContextGetSession(r); sess != nil {
+		actor = sess.UserID
+	}
+
+	if err := snapshot.RecordAuditEvent(actor, action, appID, before, after); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to record snapshot audit event %s", action))
+	}
+}
+
+func globalSnapshotSettingsAuditState(settings GlobalSnapshotSettingsResponse, globalStore *snapshottypes.Store) (map[string]interface{}, error) {
+	return snapshot.AuditState(map[string]interface{}{
+		"store":                            globalStore,
+		"defaultVolumesToRestic":           settings.DefaultVolumesToRestic,
+		"veleroMetricsPort":                settings.VeleroMetricsPort,
+		"veleroPriorityClassName":          settings.VeleroPriorityClassName,
+		"veleroServiceAccountName":         settings.VeleroServiceAccountName,
+		"veleroStoreValidationFrequency":   settings.VeleroStoreValidationFrequency,
+		"veleroRestoreResourcePriorities":  settings.VeleroRestoreResourcePriorities,
+		"veleroProfilerEnabled":            settings.VeleroProfilerEnabled,
+		"veleroTerminatingResourceTimeout": settings.VeleroTerminatingResourceTimeout,
+		"veleroItemOperationTimeout":       settings.VeleroItemOperationTimeout,
+		"resticHostPodsPath":               settings.ResticHostPodsPath,
+		"maxConcurrentScheduledSnapshots":  settings.MaxConcurrentScheduledSnapshots,
+		"defaultSnapshotTtl":               settings.DefaultSnapshotTTL,
+		"defaultSnapshotSchedule":          settings.DefaultSnapshotSchedule,
+		"backupIncludedResources":          settings.BackupIncludedResources,
+		"backupExcludedResources":          settings.BackupExcludedResources,
+		"veleroExtraContainers":            settings.VeleroExtraContainers,
+		"veleroImageRegistry":              settings.VeleroImageRegistry,
+	})
+}
+
+func appSnapshotConfigAuditState(a *apptypes.App) (map[string]interface{}, error) {
+	return snapshot.AuditState(map[string]interface{}{
+		"ttl":                    a.SnapshotTTL,
+		"schedule":               a.SnapshotSchedule,
+		"scheduleTtl":            a.SnapshotScheduleTTL,
+		"scheduleJitterMinutes":  a.SnapshotScheduleJitterMinutes,
+		"defaultVolumesToRestic": a.SnapshotDefaultVolumesToRestic,
+		"quiesceActions":         a.SnapshotQuiesceActions,
+		"includedNamespaces":     a.SnapshotIncludedNamespaces,
+		"excludedNamespaces":     a.SnapshotExcludedNamespaces,
+		"hookSettings":           a.SnapshotHookSettings,
+		"checksumTargets":        a.SnapshotChecksumTargets,
+		"excludedPvcs":           a.SnapshotExcludedPVCs,
+		"fanOutLocations":        a.SnapshotFanOutLocations,
+		"resticPodSelector":      a.SnapshotResticPodSelector,
+		"maxConcurrentBackups":   a.SnapshotMaxConcurrentBackups,
+	})
+}
+
+func instanceSnapshotConfigAuditState(c *downstreamtypes.Downstream) (map[string]interface{}, error) {
+	return snapshot.AuditState(map[string]interface{}{
+		"ttl":                      c.SnapshotTTL,
+		"schedule":                 c.SnapshotSchedule,
+		"includedClusterResources": c.SnapshotIncludedClusterResources,
+		"excludedClusterResources": c.SnapshotExcludedClusterResources,
+		"excludedPvcs":             c.SnapshotExcludedPVCs,
+		"fanOutLocations":          c.SnapshotFanOutLocations,
+		"includeRegistryData":      c.SnapshotIncludeRegistryData,
+	})
+}
+
+func recordAppSnapshotConfigAuditEvent(r *http.Request, appID string, before map[string]interface{}) {
+	a, err := store.GetStore().GetApp(appID)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get app for snapshot audit event"))
+		return
+	}
+
+	after, err := appSnapshotConfigAuditState(a)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get app snapshot config audit state"))
+		return
+	}
+
+	recordSnapshotAuditEvent(r, snapshottypes.SnapshotAuditActionSaveAppConfig, appID, before, after)
+}
+
+func recordInstanceSnapshotConfigAuditEvent(r *http.Request, clusterID string, before map[string]interface{}) {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to list clusters for snapshot audit event"))
+		return
+	}
+
+	for _, c := range clusters {
+		if c.ClusterID != clusterID {
+			continue
+		}
+
+		after, err := instanceSnapshotConfigAuditState(c)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to get instance snapshot config audit state"))
+			return
+		}
+
+		recordSnapshotAuditEvent(r, snapshottypes.SnapshotAuditActionSaveInstanceConfig, "", before, after)
+		return
+	}
+}
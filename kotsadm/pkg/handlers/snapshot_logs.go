@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -42,3 +45,68 @@ func (h *Handler) DownloadSnapshotLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *Handler) GetVeleroSupportData(w http.ResponseWriter, r *http.Request) {
+	// build the archive in memory so that a failure can still be reported with a status code
+	buf := bytes.NewBuffer(nil)
+	if err := snapshot.WriteVeleroSupportData(r.Context(), buf); err != nil {
+		logger.Error(errors.Wrap(err, "failed to collect velero support data"))
+		w.WriteHeader(500)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", "attachment; filename=velero-support.tar.gz")
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	w.WriteHeader(200)
+
+	_, err := io.Copy(w, buf)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+}
+
+// GetVeleroProfile proxies a pprof profile from velero. The profiler has to be enabled in the global snapshot settings.
+func (h *Handler) GetVeleroProfile(w http.ResponseWriter, r *http.Request) {
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	profile := mux.Vars(r)["profile"]
+	if !snapshot.VeleroProfiles[profile] {
+		w.WriteHeader(400)
+		return
+	}
+
+	seconds := 0
+	if s := r.URL.Query().Get("seconds"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 0 || i > 300 {
+			w.WriteHeader(400)
+			return
+		}
+		seconds = i
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := snapshot.GetVeleroProfile(r.Context(), profile, seconds, buf); err != nil {
+		logger.Error(errors.Wrap(err, "failed to get velero profile"))
+		w.WriteHeader(500)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=velero-%s.pprof", profile))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	w.WriteHeader(200)
+
+	_, err := io.Copy(w, buf)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+}
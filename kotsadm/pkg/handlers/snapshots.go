@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot"
 	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshotscheduler"
 	"github.com/replicatedhq/kots/kotsadm/pkg/store"
 	"github.com/robfig/cron"
 	"k8s.io/apimachinery/pkg/util/rand"
@@ -27,9 +30,45 @@ type GlobalSnapshotSettingsResponse struct {
 	IsResticRunning bool     `json:"isResticRunning"`
 	IsKurl          bool     `json:"isKurl"`
 
-	Store   *snapshottypes.Store `json:"store,omitempty"`
-	Success bool                 `json:"success"`
-	Error   string               `json:"error,omitempty"`
+	DefaultVolumesToRestic   bool   `json:"defaultVolumesToRestic"`
+	VeleroMetricsPort        int    `json:"veleroMetricsPort"`
+	VeleroPriorityClassName  string `json:"veleroPriorityClassName,omitempty"`
+	VeleroServiceAccountName string `json:"veleroServiceAccountName,omitempty"`
+	// VeleroStoreValidationFrequency is the velero server default, the store's ValidationFrequency takes precedence
+	VeleroStoreValidationFrequency   string   `json:"veleroStoreValidationFrequency"`
+	VeleroRestoreResourcePriorities  []string `json:"veleroRestoreResourcePriorities"`
+	VeleroProfilerEnabled            bool     `json:"veleroProfilerEnabled"`
+	VeleroTerminatingResourceTimeout string   `json:"veleroTerminatingResourceTimeout"`
+	// VeleroItemOperationTimeout is empty when the velero version doesn't support it
+	VeleroItemOperationTimeout string `json:"veleroItemOperationTimeout,omitempty"`
+	ResticHostPodsPath         string `json:"resticHostPodsPath,omitempty"`
+	// MaxConcurrentScheduledSnapshots limits how many scheduled backups run at once across apps, 0 means no limit
+	MaxConcurrentScheduledSnapshots int `json:"maxConcurrentScheduledSnapshots"`
+	// DefaultSnapshotTTL and DefaultSnapshotSchedule are used by apps that haven't set their own
+	DefaultSnapshotTTL      string `json:"defaultSnapshotTtl"`
+	DefaultSnapshotSchedule string `json:"defaultSnapshotSchedule"`
+	// BackupIncludedResources and BackupExcludedResources are merged into every backup
+	BackupIncludedResources []string `json:"backupIncludedResources"`
+	BackupExcludedResources []string `json:"backupExcludedResources"`
+	// VeleroExtraContainers are added to the velero deployment, e.g. to bundle a custom CA
+	VeleroExtraContainers *snapshottypes.VeleroExtraContainers `json:"veleroExtraContainers,omitempty"`
+	// VeleroImageRegistry is where velero images are pulled from instead of the kotsadm registry, the hostname is empty if it isn't set
+	VeleroImageRegistry *snapshottypes.VeleroImageRegistry `json:"veleroImageRegistry,omitempty"`
+
+	Store               *snapshottypes.Store                 `json:"store,omitempty"`
+	StorePhase          string                               `json:"storePhase,omitempty"`
+	StoreLastValidated  *time.Time                           `json:"storeLastValidated,omitempty"`
+	ValidationFrequency string                               `json:"validationFrequency,omitempty"`
+	PrefixCollisions    []snapshottypes.StorePrefixCollision `json:"prefixCollisions,omitempty"`
+	// NoStoreConfigured is true when velero doesn't have a default backup storage location to store snapshots in
+	NoStoreConfigured bool `json:"noStoreConfigured,omitempty"`
+	// Warning lists snapshot retentions that are shorter than the bucket's object lock retention
+	Warning string `json:"warning,omitempty"`
+	// CRDVersionMismatch explains how the installed velero crds don't match the velero server version
+	CRDVersionMismatch string `json:"crdVersionMismatch,omitempty"`
+
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
 }
 
 type UpdateGlobalSnapshotSettingsRequest struct {
@@ -37,17 +76,96 @@ type UpdateGlobalSnapshotSettingsRequest struct {
 	Bucket   string `json:"bucket"`
 	Path     string `json:"path"`
 
-	AWS      *snapshottypes.StoreAWS    `json:"aws"`
+	AWS      *updateStoreAWSRequest     `json:"aws"`
 	Google   *snapshottypes.StoreGoogle `json:"gcp"`
 	Azure    *snapshottypes.StoreAzure  `json:"azure"`
-	Other    *snapshottypes.StoreOther  `json:"other"`
+	Other    *updateStoreOtherRequest   `json:"other"`
 	Internal bool                       `json:"internal"`
+	// InternalInsecureSkipTLSVerify skips verifying the kurl object store's certificate. It can only be set with the internal store.
+	InternalInsecureSkipTLSVerify *bool `json:"internalInsecureSkipTLSVerify,omitempty"`
+
+	DefaultVolumesToRestic  *bool   `json:"defaultVolumesToRestic,omitempty"`
+	VeleroMetricsPort       *int    `json:"veleroMetricsPort,omitempty"`
+	VeleroPriorityClassName *string `json:"veleroPriorityClassName,omitempty"`
+	// VeleroServiceAccountName runs velero and restic under an existing service account instead of the one they were installed with
+	VeleroServiceAccountName *string `json:"veleroServiceAccountName,omitempty"`
+	// ValidationFrequency is how often velero validates the store, e.g. "30s". "0s" disables validation.
+	ValidationFrequency *string `json:"validationFrequency,omitempty"`
+	// VeleroStoreValidationFrequency sets the velero server's --store-validation-frequency
+	VeleroStoreValidationFrequency *string `json:"veleroStoreValidationFrequency,omitempty"`
+	// VeleroRestoreResourcePriorities sets the order velero restores resources in. An empty list restores velero's default order.
+	VeleroRestoreResourcePriorities *[]string `json:"veleroRestoreResourcePriorities,omitempty"`
+	// VeleroProfilerEnabled exposes velero's unauthenticated pprof endpoint to the cluster network
+	VeleroProfilerEnabled *bool `json:"veleroProfilerEnabled,omitempty"`
+	// VeleroTerminatingResourceTimeout sets how long restores wait for terminating resources, e.g. "30m". The velero default is 10m.
+	VeleroTerminatingResourceTimeout *string `json:"veleroTerminatingResourceTimeout,omitempty"`
+	// VeleroItemOperationTimeout sets how long asynchronous backup operations, e.g. data movement, can run before
+	// velero cancels them, e.g. "12h". The velero default is 4h. Requires velero 1.11 or later.
+	VeleroItemOperationTimeout *string `json:"veleroItemOperationTimeout,omitempty"`
+	// ResticHostPodsPath is the kubelet pods directory on the nodes. An empty string restores the default /var/lib/kubelet/pods.
+	ResticHostPodsPath *string `json:"resticHostPodsPath,omitempty"`
+	// MaxConcurrentScheduledSnapshots holds due scheduled snapshots back while this many are running. 0 removes the limit.
+	MaxConcurrentScheduledSnapshots *int `json:"maxConcurrentScheduledSnapshots,omitempty"`
+	// DefaultSnapshotTTL is the retention, e.g. "720h", of apps that haven't set their own. An empty string restores 720h.
+	DefaultSnapshotTTL *string `json:"defaultSnapshotTtl,omitempty"`
+	// DefaultSnapshotSchedule is the schedule suggested to apps that haven't set their own. An empty string restores "0 0 * * MON".
+	DefaultSnapshotSchedule *string `json:"defaultSnapshotSchedule,omitempty"`
+	// BackupIncludedResources limits app backups that don't list their own resources to these. An empty list includes everything.
+	BackupIncludedResources *[]string `json:"backupIncludedResources,omitempty"`
+	// BackupExcludedResources are left out of every backup, e.g. "podmetrics.metrics.k8s.io"
+	BackupExcludedResources *[]string `json:"backupExcludedResources,omitempty"`
+	// VeleroExtraContainers replaces the init containers, volumes and velero container volume mounts kots adds to the
+	// velero deployment. Empty lists remove them.
+	VeleroExtraContainers *snapshottypes.VeleroExtraContainers `json:"veleroExtraContainers,omitempty"`
+	// VeleroImageRegistry rewrites velero, plugin and restic images to this registry and namespace instead of the kotsadm
+	// ones, for velero images mirrored under a different path. An empty hostname removes the override.
+	VeleroImageRegistry *snapshottypes.VeleroImageRegistry `json:"veleroImageRegistry,omitempty"`
+	// EncryptionKey is a base64 encoded AES-256 key to encrypt backup data with before it's stored. An empty string disables encryption.
+	EncryptionKey *string `json:"encryptionKey,omitempty"`
+
+	// AllowPrefixCollisions saves the store even if other backup storage locations use an overlapping prefix in the same bucket
+	AllowPrefixCollisions bool `json:"allowPrefixCollisions,omitempty"`
+	// CreateBucketIfMissing creates the bucket with the store's credentials if it doesn't exist
+	CreateBucketIfMissing bool `json:"createBucketIfMissing,omitempty"`
+	// AllowUnencryptedBackups sets an encryption key even if the store has unencrypted backups, which can't be read after
+	AllowUnencryptedBackups bool `json:"allowUnencryptedBackups,omitempty"`
+}
+
+// updateStoreAWSRequest tells the aws settings left out of the request, which are kept, apart from the ones cleared
+type updateStoreAWSRequest struct {
+	snapshottypes.StoreAWS
+
+	UseFIPSEndpoint *bool `json:"useFIPSEndpoint,omitempty"`
+}
+
+// updateStoreOtherRequest tells the s3 compatible settings left out of the request, which are kept, apart from the
+// ones cleared
+type updateStoreOtherRequest struct {
+	snapshottypes.StoreOther
+
+	Preset           *string `json:"preset,omitempty"`
+	Namespace        *string `json:"namespace,omitempty"`
+	SignatureVersion *string `json:"signatureVersion,omitempty"`
 }
 
 type SnapshotConfig struct {
-	AutoEnabled  bool                            `json:"autoEnabled"`
-	AutoSchedule *snapshottypes.SnapshotSchedule `json:"autoSchedule"`
-	TTl          *snapshottypes.SnapshotTTL      `json:"ttl"`
+	AutoEnabled            bool                            `json:"autoEnabled"`
+	AutoSchedule           *snapshottypes.SnapshotSchedule `json:"autoSchedule"`
+	TTl                    *snapshottypes.SnapshotTTL      `json:"ttl"`
+	ScheduleTTL            *snapshottypes.SnapshotTTL      `json:"scheduleTtl,omitempty"`
+	ScheduleJitterMinutes  int                             `json:"scheduleJitterMinutes"`
+	DefaultVolumesToRestic *bool                           `json:"defaultVolumesToRestic,omitempty"`
+	QuiesceActions         []snapshottypes.QuiesceAction   `json:"quiesceActions"`
+	ChecksumTargets        []snapshottypes.ChecksumTarget  `json:"checksumTargets"`
+	IncludedNamespaces     []string                        `json:"includedNamespaces"`
+	ExcludedNamespaces     []string                        `json:"excludedNamespaces"`
+	HookSettings           *snapshottypes.HookSettings     `json:"hookSettings,omitempty"`
+	ExcludedPVCs           []string                        `json:"excludedPvcs"`
+	FanOutLocations        []string                        `json:"fanOutLocations"`
+	ResticPodSelector      string                          `json:"resticPodSelector"`
+	MaxConcurrentBackups   int                             `json:"maxConcurrentBackups"`
+	BackupGapRisk          bool                            `json:"backupGapRisk"`
+	BackupGapRiskDetails   *snapshottypes.BackupGapRisk    `json:"backupGapRiskDetails,omitempty"`
 }
 
 type VeleroStatus struct {
@@ -72,6 +190,152 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if port := updateGlobalSnapshotSettingsRequest.VeleroMetricsPort; port != nil && (*port < 1 || *port > 65535) {
+		globalSnapshotSettingsResponse.Error = "velero metrics port must be between 1 and 65535"
+		JSON(w, 400, globalSnapshotSettingsResponse)
+		return
+	}
+
+	if path := updateGlobalSnapshotSettingsRequest.ResticHostPodsPath; path != nil && *path != "" {
+		if err := snapshot.ValidateResticHostPodsPath(*path); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	if maxConcurrent := updateGlobalSnapshotSettingsRequest.MaxConcurrentScheduledSnapshots; maxConcurrent != nil && *maxConcurrent < 0 {
+		globalSnapshotSettingsResponse.Error = "max concurrent scheduled snapshots must not be negative"
+		JSON(w, 400, globalSnapshotSettingsResponse)
+		return
+	}
+
+	if extra := updateGlobalSnapshotSettingsRequest.VeleroExtraContainers; extra != nil {
+		if err := snapshot.ValidateVeleroExtraContainers(extra); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	if veleroRegistry := updateGlobalSnapshotSettingsRequest.VeleroImageRegistry; veleroRegistry != nil {
+		if err := snapshot.ValidateVeleroImageRegistry(veleroRegistry); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	requestedDefaults := &snapshottypes.SnapshotDefaults{}
+	if ttl := updateGlobalSnapshotSettingsRequest.DefaultSnapshotTTL; ttl != nil {
+		requestedDefaults.TTL = *ttl
+	}
+	if schedule := updateGlobalSnapshotSettingsRequest.DefaultSnapshotSchedule; schedule != nil {
+		requestedDefaults.Schedule = *schedule
+	}
+	if err := snapshot.ValidateSnapshotDefaults(requestedDefaults); err != nil {
+		globalSnapshotSettingsResponse.Error = err.Error()
+		JSON(w, 400, globalSnapshotSettingsResponse)
+		return
+	}
+
+	for _, resources := range []*[]string{updateGlobalSnapshotSettingsRequest.BackupIncludedResources, updateGlobalSnapshotSettingsRequest.BackupExcludedResources} {
+		if resources == nil {
+			continue
+		}
+		if err := snapshot.ValidateClusterResourceNames(*resources); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	var validationFrequency time.Duration
+	if updateGlobalSnapshotSettingsRequest.ValidationFrequency != nil {
+		d, err := time.ParseDuration(*updateGlobalSnapshotSettingsRequest.ValidationFrequency)
+		if err != nil || d < 0 {
+			globalSnapshotSettingsResponse.Error = "invalid validation frequency"
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		validationFrequency = d
+	}
+
+	var veleroStoreValidationFrequency time.Duration
+	if updateGlobalSnapshotSettingsRequest.VeleroStoreValidationFrequency != nil {
+		d, err := time.ParseDuration(*updateGlobalSnapshotSettingsRequest.VeleroStoreValidationFrequency)
+		if err != nil || d < 0 {
+			globalSnapshotSettingsResponse.Error = "invalid velero store validation frequency"
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		veleroStoreValidationFrequency = d
+	}
+
+	var veleroTerminatingResourceTimeout time.Duration
+	if updateGlobalSnapshotSettingsRequest.VeleroTerminatingResourceTimeout != nil {
+		d, err := time.ParseDuration(*updateGlobalSnapshotSettingsRequest.VeleroTerminatingResourceTimeout)
+		if err != nil || d <= 0 {
+			globalSnapshotSettingsResponse.Error = "invalid velero terminating resource timeout"
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		veleroTerminatingResourceTimeout = d
+	}
+
+	var veleroItemOperationTimeout time.Duration
+	if updateGlobalSnapshotSettingsRequest.VeleroItemOperationTimeout != nil {
+		d, err := time.ParseDuration(*updateGlobalSnapshotSettingsRequest.VeleroItemOperationTimeout)
+		if err != nil || d <= 0 {
+			globalSnapshotSettingsResponse.Error = "invalid velero item operation timeout"
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		veleroItemOperationTimeout = d
+	}
+
+	if priorities := updateGlobalSnapshotSettingsRequest.VeleroRestoreResourcePriorities; priorities != nil {
+		if err := snapshot.ValidateRestoreResourcePriorities(*priorities); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	var encryptionKey []byte
+	if updateGlobalSnapshotSettingsRequest.EncryptionKey != nil && *updateGlobalSnapshotSettingsRequest.EncryptionKey != "" {
+		if strings.Contains(*updateGlobalSnapshotSettingsRequest.EncryptionKey, "REDACTED") {
+			globalSnapshotSettingsResponse.Error = "invalid encryption key"
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		key, err := snapshot.DecodeStoreEncryptionKey(*updateGlobalSnapshotSettingsRequest.EncryptionKey)
+		if err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		encryptionKey = key
+	}
+
+	if priorityClassName := updateGlobalSnapshotSettingsRequest.VeleroPriorityClassName; priorityClassName != nil {
+		if err := snapshot.ValidateVeleroPriorityClass(r.Context(), *priorityClassName); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = errors.Cause(err).Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	if serviceAccountName := updateGlobalSnapshotSettingsRequest.VeleroServiceAccountName; serviceAccountName != nil {
+		if err := snapshot.ValidateVeleroServiceAccount(r.Context(), *serviceAccountName); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = errors.Cause(err).Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
 	veleroStatus, err := snapshot.DetectVelero()
 	if err != nil {
 		logger.Error(err)
@@ -83,6 +347,17 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 		JSON(w, 200, globalSnapshotSettingsResponse)
 		return
 	}
+	if len(encryptionKey) > 0 && !snapshot.SupportsStoreEncryption(veleroStatus.AWSPluginVersion) {
+		globalSnapshotSettingsResponse.Error = "encryption requires velero-plugin-for-aws v1.2.0 or later"
+		JSON(w, 400, globalSnapshotSettingsResponse)
+		return
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroItemOperationTimeout != nil && !snapshot.SupportsItemOperationTimeout(veleroStatus.Version) {
+		globalSnapshotSettingsResponse.Error = fmt.Sprintf("velero %s does not support an item operation timeout, upgrade to velero 1.11 or later", veleroStatus.Version)
+		JSON(w, 400, globalSnapshotSettingsResponse)
+		return
+	}
 
 	globalSnapshotSettingsResponse.VeleroVersion = veleroStatus.Version
 	globalSnapshotSettingsResponse.VeleroPlugins = veleroStatus.Plugins
@@ -90,15 +365,89 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 	globalSnapshotSettingsResponse.ResticVersion = veleroStatus.ResticVersion
 	globalSnapshotSettingsResponse.IsResticRunning = veleroStatus.ResticStatus == "Ready"
 	globalSnapshotSettingsResponse.IsKurl = kurl.IsKurl()
+	globalSnapshotSettingsResponse.DefaultVolumesToRestic = veleroStatus.DefaultVolumesToRestic
+	globalSnapshotSettingsResponse.VeleroMetricsPort = veleroStatus.MetricsPort
+	globalSnapshotSettingsResponse.VeleroPriorityClassName = veleroStatus.PriorityClassName
+	globalSnapshotSettingsResponse.VeleroServiceAccountName = veleroStatus.ServiceAccountName
+	globalSnapshotSettingsResponse.VeleroStoreValidationFrequency = veleroStatus.StoreValidationFrequency.String()
+	globalSnapshotSettingsResponse.VeleroRestoreResourcePriorities = veleroStatus.RestoreResourcePriorities
+	globalSnapshotSettingsResponse.VeleroProfilerEnabled = veleroStatus.ProfilerEnabled
+	globalSnapshotSettingsResponse.VeleroTerminatingResourceTimeout = veleroStatus.TerminatingResourceTimeout.String()
+	if snapshot.SupportsItemOperationTimeout(veleroStatus.Version) {
+		globalSnapshotSettingsResponse.VeleroItemOperationTimeout = veleroStatus.ItemOperationTimeout.String()
+	}
+	globalSnapshotSettingsResponse.ResticHostPodsPath = veleroStatus.ResticHostPodsPath
 
-	store, err := snapshot.GetGlobalStore(nil)
+	maxConcurrentScheduledSnapshots, err := store.GetStore().GetMaxConcurrentScheduledSnapshots()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get max concurrent scheduled snapshots"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.MaxConcurrentScheduledSnapshots = maxConcurrentScheduledSnapshots
+
+	snapshotDefaults, err := snapshot.GetSnapshotDefaults()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get snapshot defaults"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.DefaultSnapshotTTL = snapshotDefaults.TTL
+	globalSnapshotSettingsResponse.DefaultSnapshotSchedule = snapshotDefaults.Schedule
+
+	resourceFilter, err := snapshot.GetBackupResourceFilter()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get backup resource filter"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.BackupIncludedResources = resourceFilter.IncludedResources
+	globalSnapshotSettingsResponse.BackupExcludedResources = resourceFilter.ExcludedResources
+
+	veleroExtraContainers, err := snapshot.GetVeleroExtraContainers()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get velero extra containers"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.VeleroExtraContainers = veleroExtraContainers
+
+	veleroImageRegistry, err := snapshot.GetVeleroImageRegistry()
 	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get velero image registry"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.VeleroImageRegistry = veleroImageRegistry
+	settingsBefore := globalSnapshotSettingsResponse
+
+	// the settings kept in the kots store are only written once the snapshot store has been validated and saved
+	kotsStore := store.GetStore()
+
+	store, err := snapshot.GetGlobalStore(nil)
+	if snapshot.IsNoStoreConfiguredError(err) {
+		// the location exists without object storage, or updating the store below will report it missing
+		store = &snapshottypes.Store{}
+	} else if err != nil {
 		logger.Error(err)
 		globalSnapshotSettingsResponse.Error = "failed to get store"
 		JSON(w, 500, globalSnapshotSettingsResponse)
 		return
 	}
 
+	auditBefore, err := globalSnapshotSettingsAuditState(settingsBefore, store)
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get audit state"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
 	store.Provider = updateGlobalSnapshotSettingsRequest.Provider
 	store.Bucket = updateGlobalSnapshotSettingsRequest.Bucket
 	store.Path = updateGlobalSnapshotSettingsRequest.Path
@@ -133,6 +482,12 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 				store.AWS.Region = updateGlobalSnapshotSettingsRequest.AWS.Region
 			}
 		}
+		if updateGlobalSnapshotSettingsRequest.AWS.ObjectLockRetentionDays != nil {
+			store.AWS.ObjectLockRetentionDays = updateGlobalSnapshotSettingsRequest.AWS.ObjectLockRetentionDays
+		}
+		if updateGlobalSnapshotSettingsRequest.AWS.UseFIPSEndpoint != nil {
+			store.AWS.UseFIPSEndpoint = *updateGlobalSnapshotSettingsRequest.AWS.UseFIPSEndpoint
+		}
 
 		if !store.AWS.UseInstanceRole {
 			if store.AWS.AccessKeyID == "" || store.AWS.SecretAccessKey == "" || store.AWS.Region == "" {
@@ -141,6 +496,42 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 				return
 			}
 		}
+
+		if store.AWS.UseFIPSEndpoint {
+			if err := snapshot.ValidateAWSFIPSRegion(store.AWS.Region); err != nil {
+				globalSnapshotSettingsResponse.Error = err.Error()
+				JSON(w, 400, globalSnapshotSettingsResponse)
+				return
+			}
+		}
+
+		if updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot == nil {
+			store.AWS.VolumeSnapshot = nil
+		} else {
+			if store.AWS.VolumeSnapshot == nil {
+				store.AWS.VolumeSnapshot = &snapshottypes.StoreAWSVolumeSnapshot{}
+			}
+			if updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.AccessKeyID != "" {
+				store.AWS.VolumeSnapshot.AccessKeyID = updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.AccessKeyID
+			}
+			if updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.SecretAccessKey != "" {
+				if strings.Contains(updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.SecretAccessKey, "REDACTED") {
+					globalSnapshotSettingsResponse.Error = "invalid volume snapshot secret access key"
+					JSON(w, 400, globalSnapshotSettingsResponse)
+					return
+				}
+				store.AWS.VolumeSnapshot.SecretAccessKey = updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.SecretAccessKey
+			}
+			if updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.Region != "" {
+				store.AWS.VolumeSnapshot.Region = updateGlobalSnapshotSettingsRequest.AWS.VolumeSnapshot.Region
+			}
+
+			if store.AWS.VolumeSnapshot.AccessKeyID == "" || store.AWS.VolumeSnapshot.SecretAccessKey == "" || store.AWS.VolumeSnapshot.Region == "" {
+				globalSnapshotSettingsResponse.Error = "missing volume snapshot access key id and/or secret access key and/or region"
+				JSON(w, 400, globalSnapshotSettingsResponse)
+				return
+			}
+		}
 	} else if updateGlobalSnapshotSettingsRequest.Google != nil {
 		if store.Google == nil {
 			store.Google = &snapshottypes.StoreGoogle{}
@@ -174,6 +565,11 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 				JSON(w, 400, globalSnapshotSettingsResponse)
 				return
 			}
+			if err := snapshot.ValidateGCPServiceAccount(store.Google.ServiceAccount); err != nil {
+				globalSnapshotSettingsResponse.Error = err.Error()
+				JSON(w, 400, globalSnapshotSettingsResponse)
+				return
+			}
 		} else {
 			if store.Google.JSONFile == "" {
 				globalSnapshotSettingsResponse.Error = "missing JSON file"
@@ -246,6 +642,27 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 		}
 		if updateGlobalSnapshotSettingsRequest.Other.Endpoint != "" {
 			store.Other.Endpoint = updateGlobalSnapshotSettingsRequest.Other.Endpoint
+		} else if preset := updateGlobalSnapshotSettingsRequest.Other.Preset; preset != nil && *preset != "" {
+			// derive the endpoint from the preset again in case the region or namespace changed
+			store.Other.Endpoint = ""
+		}
+		if preset := updateGlobalSnapshotSettingsRequest.Other.Preset; preset != nil {
+			store.Other.Preset = *preset
+		}
+		if namespace := updateGlobalSnapshotSettingsRequest.Other.Namespace; namespace != nil {
+			store.Other.Namespace = *namespace
+		}
+		if signatureVersion := updateGlobalSnapshotSettingsRequest.Other.SignatureVersion; signatureVersion != nil {
+			store.Other.SignatureVersion = *signatureVersion
+		}
+		if updateGlobalSnapshotSettingsRequest.Other.ObjectLockRetentionDays != nil {
+			store.Other.ObjectLockRetentionDays = updateGlobalSnapshotSettingsRequest.Other.ObjectLockRetentionDays
+		}
+
+		if err := snapshot.ApplyStorePreset(store.Other); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
 		}
 
 		if store.Other.AccessKeyID == "" || store.Other.SecretAccessKey == "" || store.Other.Endpoint == "" || store.Other.Region == "" {
@@ -260,14 +677,6 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		if store.Internal == nil {
-			store.Internal = &snapshottypes.StoreInternal{}
-		}
-		store.AWS = nil
-		store.Google = nil
-		store.Azure = nil
-		store.Other = nil
-
 		secret, err := kurl.GetS3Secret()
 		if err != nil {
 			logger.Error(err)
@@ -282,111 +691,868 @@ func (h *Handler) UpdateGlobalSnapshotSettings(w http.ResponseWriter, r *http.Re
 			return
 		}
 
-		store.Provider = "aws"
-		store.Bucket = string(secret.Data["velero-local-bucket"])
-		store.Path = ""
+		insecureSkipTLSVerify := store.Internal != nil && store.Internal.InsecureSkipTLSVerify
+		if updateGlobalSnapshotSettingsRequest.InternalInsecureSkipTLSVerify != nil {
+			insecureSkipTLSVerify = *updateGlobalSnapshotSettingsRequest.InternalInsecureSkipTLSVerify
+		}
 
-		store.Internal.AccessKeyID = string(secret.Data["access-key-id"])
-		store.Internal.SecretAccessKey = string(secret.Data["secret-access-key"])
-		store.Internal.Endpoint = string(secret.Data["endpoint"])
-		store.Internal.ObjectStoreClusterIP = string(secret.Data["object-store-cluster-ip"])
-		store.Internal.Region = "us-east-1"
+		snapshot.SetStoreInternal(store, secret)
+		store.Internal.InsecureSkipTLSVerify = insecureSkipTLSVerify
 	}
 
-	if err := snapshot.ValidateStore(store); err != nil {
-		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = errors.Cause(err).Error()
+	if skip := updateGlobalSnapshotSettingsRequest.InternalInsecureSkipTLSVerify; skip != nil && *skip && !updateGlobalSnapshotSettingsRequest.Internal {
+		globalSnapshotSettingsResponse.Error = "skipping tls verification is only supported for the internal store"
 		JSON(w, 400, globalSnapshotSettingsResponse)
 		return
 	}
 
-	updatedBackupStorageLocation, err := snapshot.UpdateGlobalStore(store)
-	if err != nil {
-		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to update global store"
-		JSON(w, 500, globalSnapshotSettingsResponse)
+	if snapshot.ObjectLockRetentionDays(store) < 0 {
+		globalSnapshotSettingsResponse.Error = "object lock retention days must not be negative"
+		JSON(w, 400, globalSnapshotSettingsResponse)
 		return
 	}
 
-	if err := snapshot.ResetResticRepositories(); err != nil {
+	encrypted := len(encryptionKey) > 0
+	if updateGlobalSnapshotSettingsRequest.EncryptionKey == nil {
+		// the current key is kept
+		encrypted, err = snapshot.HasStoreEncryptionKey(r.Context())
+		if err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to get store encryption key"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+	if encrypted {
+		if err := snapshot.ValidateStoreEncryption(store); err != nil {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	if updateGlobalSnapshotSettingsRequest.EncryptionKey != nil {
+		err := snapshot.CheckStoreEncryptionKeyChange(r.Context(), encryptionKey, updateGlobalSnapshotSettingsRequest.AllowUnencryptedBackups)
+		if snapshot.IsEncryptionKeyInUseError(err) {
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		} else if snapshot.IsUnencryptedBackupsExistError(err) {
+			// the request can be sent again with allowUnencryptedBackups
+			globalSnapshotSettingsResponse.Error = err.Error()
+			JSON(w, 409, globalSnapshotSettingsResponse)
+			return
+		} else if err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to check store encryption key"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	err = snapshot.ValidateStore(store)
+	if snapshot.IsBucketNotFoundError(err) && updateGlobalSnapshotSettingsRequest.CreateBucketIfMissing {
+		if err := snapshot.CreateStoreBucket(r.Context(), store); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = fmt.Sprintf("bucket %s does not exist and could not be created: %s", store.Bucket, errors.Cause(err).Error())
+			JSON(w, 400, globalSnapshotSettingsResponse)
+			return
+		}
+		err = snapshot.ValidateStore(store)
+	}
+	if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to try to reset restic repositories"
-		JSON(w, 500, globalSnapshotSettingsResponse)
+		globalSnapshotSettingsResponse.Error = errors.Cause(err).Error()
+		JSON(w, 400, globalSnapshotSettingsResponse)
 		return
 	}
 
-	// most plugins (all?) require that velero be restared after updating
-	if err := snapshot.RestartVelero(); err != nil {
+	// retentions that are too short aren't rejected here, the bucket is locked either way and the apps can be fixed after
+	objectLockConflicts, err := snapshot.FindObjectLockTTLConflicts(snapshot.ObjectLockRetentionDays(store))
+	if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to try to restart velero"
+		globalSnapshotSettingsResponse.Error = "failed to check snapshot retention against object lock"
 		JSON(w, 500, globalSnapshotSettingsResponse)
 		return
 	}
+	if len(objectLockConflicts) > 0 {
+		globalSnapshotSettingsResponse.Warning = fmt.Sprintf("velero will fail to delete expired backups: %s", strings.Join(objectLockConflicts, "; "))
+	}
 
-	updatedStore, err := snapshot.GetGlobalStore(updatedBackupStorageLocation)
+	prefixCollisions, err := snapshot.FindStorePrefixCollisions(r.Context(), store)
 	if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to update store"
+		globalSnapshotSettingsResponse.Error = "failed to check for prefix collisions"
 		JSON(w, 500, globalSnapshotSettingsResponse)
 		return
 	}
+	if len(prefixCollisions) > 0 && !updateGlobalSnapshotSettingsRequest.AllowPrefixCollisions {
+		globalSnapshotSettingsResponse.PrefixCollisions = prefixCollisions
+		globalSnapshotSettingsResponse.Error = "the path overlaps with other backup storage locations in the same bucket"
+		JSON(w, 409, globalSnapshotSettingsResponse)
+		return
+	}
 
-	if err := snapshot.Redact(updatedStore); err != nil {
+	updatedBackupStorageLocation, err := snapshot.UpdateGlobalStore(store)
+	if snapshot.IsNoStoreConfiguredError(err) {
+		globalSnapshotSettingsResponse.NoStoreConfigured = true
+		globalSnapshotSettingsResponse.Error = "velero does not have a backup storage location named \"default\", reinstall velero with a default backup storage location"
+		JSON(w, 409, globalSnapshotSettingsResponse)
+		return
+	} else if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to redact"
+		globalSnapshotSettingsResponse.Error = "failed to update global store"
 		JSON(w, 500, globalSnapshotSettingsResponse)
 		return
 	}
 
-	globalSnapshotSettingsResponse.Store = updatedStore
-	globalSnapshotSettingsResponse.Success = true
-
-	JSON(w, 200, globalSnapshotSettingsResponse)
-}
-
-func (h *Handler) GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request) {
-	globalSnapshotSettingsResponse := GlobalSnapshotSettingsResponse{
-		Success: false,
+	if updateGlobalSnapshotSettingsRequest.EncryptionKey != nil {
+		updatedBackupStorageLocation, err = snapshot.SetStoreEncryptionKey(r.Context(), encryptionKey)
+	} else {
+		updatedBackupStorageLocation, err = snapshot.ReapplyStoreEncryptionKey(r.Context())
 	}
-
-	veleroStatus, err := snapshot.DetectVelero()
 	if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to detect velero"
+		globalSnapshotSettingsResponse.Error = "failed to set store encryption key"
 		JSON(w, 500, globalSnapshotSettingsResponse)
 		return
 	}
-	if veleroStatus == nil {
+
+	if updateGlobalSnapshotSettingsRequest.ValidationFrequency != nil {
+		updatedBackupStorageLocation, err = snapshot.SetStoreValidationFrequency(r.Context(), validationFrequency)
+		if err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set store validation frequency"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+	}
+
+	if maxConcurrent := updateGlobalSnapshotSettingsRequest.MaxConcurrentScheduledSnapshots; maxConcurrent != nil {
+		if err := kotsStore.SetMaxConcurrentScheduledSnapshots(*maxConcurrent); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set max concurrent scheduled snapshots"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.MaxConcurrentScheduledSnapshots = *maxConcurrent
+	}
+
+	if updateGlobalSnapshotSettingsRequest.DefaultSnapshotTTL != nil || updateGlobalSnapshotSettingsRequest.DefaultSnapshotSchedule != nil {
+		storedDefaults, err := kotsStore.GetSnapshotDefaults()
+		if err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to get snapshot defaults"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		if updateGlobalSnapshotSettingsRequest.DefaultSnapshotTTL != nil {
+			storedDefaults.TTL = requestedDefaults.TTL
+		}
+		if updateGlobalSnapshotSettingsRequest.DefaultSnapshotSchedule != nil {
+			storedDefaults.Schedule = requestedDefaults.Schedule
+		}
+		if err := kotsStore.SetSnapshotDefaults(storedDefaults); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set snapshot defaults"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+
+		snapshotDefaults, err := snapshot.GetSnapshotDefaults()
+		if err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to get snapshot defaults"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.DefaultSnapshotTTL = snapshotDefaults.TTL
+		globalSnapshotSettingsResponse.DefaultSnapshotSchedule = snapshotDefaults.Schedule
+	}
+
+	if updateGlobalSnapshotSettingsRequest.BackupIncludedResources != nil || updateGlobalSnapshotSettingsRequest.BackupExcludedResources != nil {
+		if includedResources := updateGlobalSnapshotSettingsRequest.BackupIncludedResources; includedResources != nil {
+			resourceFilter.IncludedResources = *includedResources
+		}
+		if excludedResources := updateGlobalSnapshotSettingsRequest.BackupExcludedResources; excludedResources != nil {
+			resourceFilter.ExcludedResources = *excludedResources
+		}
+		if err := kotsStore.SetBackupResourceFilter(resourceFilter); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set backup resource filter"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.BackupIncludedResources = resourceFilter.IncludedResources
+		globalSnapshotSettingsResponse.BackupExcludedResources = resourceFilter.ExcludedResources
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroMetricsPort != nil {
+		if err := snapshot.SetVeleroMetricsPort(*updateGlobalSnapshotSettingsRequest.VeleroMetricsPort); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero metrics port"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroMetricsPort = *updateGlobalSnapshotSettingsRequest.VeleroMetricsPort
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroPriorityClassName != nil {
+		if err := snapshot.SetVeleroPriorityClass(*updateGlobalSnapshotSettingsRequest.VeleroPriorityClassName); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero priority class"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroPriorityClassName = *updateGlobalSnapshotSettingsRequest.VeleroPriorityClassName
+	}
+
+	if serviceAccountName := updateGlobalSnapshotSettingsRequest.VeleroServiceAccountName; serviceAccountName != nil {
+		if err := snapshot.SetVeleroServiceAccount(r.Context(), *serviceAccountName); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero service account"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroServiceAccountName = *serviceAccountName
+	}
+
+	if extra := updateGlobalSnapshotSettingsRequest.VeleroExtraContainers; extra != nil {
+		if err := snapshot.SetVeleroExtraContainers(r.Context(), extra); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero extra containers"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroExtraContainers = extra
+	}
+
+	if veleroRegistry := updateGlobalSnapshotSettingsRequest.VeleroImageRegistry; veleroRegistry != nil {
+		if err := snapshot.SetVeleroImageRegistry(r.Context(), veleroRegistry); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero image registry"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroImageRegistry = veleroRegistry
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroStoreValidationFrequency != nil {
+		if err := snapshot.SetVeleroStoreValidationFrequency(veleroStoreValidationFrequency); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero store validation frequency"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroStoreValidationFrequency = veleroStoreValidationFrequency.String()
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroTerminatingResourceTimeout != nil {
+		if err := snapshot.SetVeleroTerminatingResourceTimeout(veleroTerminatingResourceTimeout); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero terminating resource timeout"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroTerminatingResourceTimeout = veleroTerminatingResourceTimeout.String()
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroItemOperationTimeout != nil {
+		if err := snapshot.SetVeleroItemOperationTimeout(veleroItemOperationTimeout); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero item operation timeout"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroItemOperationTimeout = veleroItemOperationTimeout.String()
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroRestoreResourcePriorities != nil {
+		if err := snapshot.SetVeleroRestoreResourcePriorities(*updateGlobalSnapshotSettingsRequest.VeleroRestoreResourcePriorities); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero restore resource priorities"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroRestoreResourcePriorities = *updateGlobalSnapshotSettingsRequest.VeleroRestoreResourcePriorities
+	}
+
+	if updateGlobalSnapshotSettingsRequest.VeleroProfilerEnabled != nil {
+		if err := snapshot.SetVeleroProfilerEnabled(*updateGlobalSnapshotSettingsRequest.VeleroProfilerEnabled); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero profiler"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.VeleroProfilerEnabled = *updateGlobalSnapshotSettingsRequest.VeleroProfilerEnabled
+	}
+
+	if path := updateGlobalSnapshotSettingsRequest.ResticHostPodsPath; path != nil {
+		if err := snapshot.SetResticHostPodsPath(*path); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set restic host pods path"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.ResticHostPodsPath = *path
+		if *path == "" {
+			globalSnapshotSettingsResponse.ResticHostPodsPath = snapshot.DefaultResticHostPodsPath
+		}
+	}
+
+	if updateGlobalSnapshotSettingsRequest.DefaultVolumesToRestic != nil {
+		if err := snapshot.SetVeleroDefaultVolumesToRestic(*updateGlobalSnapshotSettingsRequest.DefaultVolumesToRestic); err != nil {
+			logger.Error(err)
+			globalSnapshotSettingsResponse.Error = "failed to set velero default volumes to restic"
+			JSON(w, 500, globalSnapshotSettingsResponse)
+			return
+		}
+		globalSnapshotSettingsResponse.DefaultVolumesToRestic = *updateGlobalSnapshotSettingsRequest.DefaultVolumesToRestic
+	}
+
+	if err := snapshot.ResetResticRepositories(); err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to try to reset restic repositories"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	// most plugins (all?) require that velero be restared after updating
+	if err := snapshot.RestartVelero(); err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to try to restart velero"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	updatedStore, err := snapshot.GetGlobalStore(updatedBackupStorageLocation)
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to update store"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	auditAfter, err := globalSnapshotSettingsAuditState(globalSnapshotSettingsResponse, updatedStore)
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get audit state"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	recordSnapshotAuditEvent(r, snapshottypes.SnapshotAuditActionUpdateGlobalSettings, "", auditBefore, auditAfter)
+
+	if err := snapshot.Redact(updatedStore); err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to redact"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	if updatedBackupStorageLocation.Spec.ValidationFrequency != nil {
+		globalSnapshotSettingsResponse.ValidationFrequency = updatedBackupStorageLocation.Spec.ValidationFrequency.Duration.String()
+	}
+
+	globalSnapshotSettingsResponse.Store = updatedStore
+	globalSnapshotSettingsResponse.Success = true
+
+	JSON(w, 200, globalSnapshotSettingsResponse)
+}
+
+func (h *Handler) GetGlobalSnapshotSettings(w http.ResponseWriter, r *http.Request) {
+	globalSnapshotSettingsResponse := GlobalSnapshotSettingsResponse{
+		Success: false,
+	}
+
+	veleroStatus, err := snapshot.DetectVelero()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to detect velero"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	if veleroStatus == nil {
 		JSON(w, 200, globalSnapshotSettingsResponse)
 		return
 	}
 
-	globalSnapshotSettingsResponse.VeleroVersion = veleroStatus.Version
-	globalSnapshotSettingsResponse.VeleroPlugins = veleroStatus.Plugins
-	globalSnapshotSettingsResponse.IsVeleroRunning = veleroStatus.Status == "Ready"
-	globalSnapshotSettingsResponse.ResticVersion = veleroStatus.ResticVersion
-	globalSnapshotSettingsResponse.IsResticRunning = veleroStatus.ResticStatus == "Ready"
-	globalSnapshotSettingsResponse.IsKurl = kurl.IsKurl()
+	globalSnapshotSettingsResponse.VeleroVersion = veleroStatus.Version
+	globalSnapshotSettingsResponse.VeleroPlugins = veleroStatus.Plugins
+	globalSnapshotSettingsResponse.IsVeleroRunning = veleroStatus.Status == "Ready"
+	globalSnapshotSettingsResponse.ResticVersion = veleroStatus.ResticVersion
+	globalSnapshotSettingsResponse.IsResticRunning = veleroStatus.ResticStatus == "Ready"
+	globalSnapshotSettingsResponse.IsKurl = kurl.IsKurl()
+	globalSnapshotSettingsResponse.DefaultVolumesToRestic = veleroStatus.DefaultVolumesToRestic
+	globalSnapshotSettingsResponse.VeleroMetricsPort = veleroStatus.MetricsPort
+	globalSnapshotSettingsResponse.VeleroPriorityClassName = veleroStatus.PriorityClassName
+	globalSnapshotSettingsResponse.VeleroServiceAccountName = veleroStatus.ServiceAccountName
+	globalSnapshotSettingsResponse.VeleroStoreValidationFrequency = veleroStatus.StoreValidationFrequency.String()
+	globalSnapshotSettingsResponse.VeleroRestoreResourcePriorities = veleroStatus.RestoreResourcePriorities
+	globalSnapshotSettingsResponse.VeleroProfilerEnabled = veleroStatus.ProfilerEnabled
+	globalSnapshotSettingsResponse.VeleroTerminatingResourceTimeout = veleroStatus.TerminatingResourceTimeout.String()
+	if snapshot.SupportsItemOperationTimeout(veleroStatus.Version) {
+		globalSnapshotSettingsResponse.VeleroItemOperationTimeout = veleroStatus.ItemOperationTimeout.String()
+	}
+	globalSnapshotSettingsResponse.ResticHostPodsPath = veleroStatus.ResticHostPodsPath
+
+	crdVersionMismatch, err := snapshot.CheckVeleroCRDs(r.Context(), veleroStatus.Version)
+	if err != nil {
+		// the crd check is advisory, don't fail the settings page if crds can't be read
+		logger.Error(errors.Wrap(err, "failed to check velero crds"))
+	}
+	globalSnapshotSettingsResponse.CRDVersionMismatch = crdVersionMismatch
+
+	maxConcurrentScheduledSnapshots, err := store.GetStore().GetMaxConcurrentScheduledSnapshots()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get max concurrent scheduled snapshots"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.MaxConcurrentScheduledSnapshots = maxConcurrentScheduledSnapshots
+
+	snapshotDefaults, err := snapshot.GetSnapshotDefaults()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get snapshot defaults"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.DefaultSnapshotTTL = snapshotDefaults.TTL
+	globalSnapshotSettingsResponse.DefaultSnapshotSchedule = snapshotDefaults.Schedule
+
+	resourceFilter, err := snapshot.GetBackupResourceFilter()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get backup resource filter"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.BackupIncludedResources = resourceFilter.IncludedResources
+	globalSnapshotSettingsResponse.BackupExcludedResources = resourceFilter.ExcludedResources
+
+	veleroExtraContainers, err := snapshot.GetVeleroExtraContainers()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get velero extra containers"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.VeleroExtraContainers = veleroExtraContainers
+
+	veleroImageRegistry, err := snapshot.GetVeleroImageRegistry()
+	if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get velero image registry"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+	globalSnapshotSettingsResponse.VeleroImageRegistry = veleroImageRegistry
+
+	kotsadmVeleroBackendStorageLocation, err := snapshot.FindBackupStoreLocation()
+	if snapshot.IsNoStoreConfiguredError(err) {
+		globalSnapshotSettingsResponse.NoStoreConfigured = true
+		globalSnapshotSettingsResponse.Success = true
+		JSON(w, 200, globalSnapshotSettingsResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to find backup storage location"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	store, err := snapshot.GetGlobalStore(kotsadmVeleroBackendStorageLocation)
+	if snapshot.IsNoStoreConfiguredError(err) {
+		globalSnapshotSettingsResponse.NoStoreConfigured = true
+		globalSnapshotSettingsResponse.Success = true
+		JSON(w, 200, globalSnapshotSettingsResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to get store"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	// the phase is set by velero when it validates that the store is reachable with the configured credentials
+	globalSnapshotSettingsResponse.StorePhase = string(kotsadmVeleroBackendStorageLocation.Status.Phase)
+	if kotsadmVeleroBackendStorageLocation.Status.LastValidationTime != nil {
+		globalSnapshotSettingsResponse.StoreLastValidated = &kotsadmVeleroBackendStorageLocation.Status.LastValidationTime.Time
+	}
+
+	if kotsadmVeleroBackendStorageLocation.Spec.ValidationFrequency != nil {
+		globalSnapshotSettingsResponse.ValidationFrequency = kotsadmVeleroBackendStorageLocation.Spec.ValidationFrequency.Duration.String()
+	}
+
+	if err := snapshot.Redact(store); err != nil {
+		logger.Error(err)
+		globalSnapshotSettingsResponse.Error = "failed to redact"
+		JSON(w, 500, globalSnapshotSettingsResponse)
+		return
+	}
+
+	globalSnapshotSettingsResponse.Store = store
+	globalSnapshotSettingsResponse.Success = true
+
+	JSON(w, 200, globalSnapshotSettingsResponse)
+}
+
+type GetSupportedSnapshotProvidersResponse struct {
+	Providers []snapshottypes.StoreProvider `json:"providers"`
+}
+
+func (h *Handler) GetSupportedSnapshotProviders(w http.ResponseWriter, r *http.Request) {
+	getSupportedSnapshotProvidersResponse := GetSupportedSnapshotProvidersResponse{
+		Providers: snapshot.ListStoreProviders(kurl.IsKurl()),
+	}
+
+	JSON(w, http.StatusOK, getSupportedSnapshotProvidersResponse)
+}
+
+type BackupWebhookResponse struct {
+	Success bool                         `json:"success"`
+	Error   string                       `json:"error,omitempty"`
+	Webhook *snapshottypes.BackupWebhook `json:"webhook,omitempty"`
+}
+
+func (h *Handler) GetBackupWebhook(w http.ResponseWriter, r *http.Request) {
+	backupWebhookResponse := BackupWebhookResponse{}
+
+	webhook, err := store.GetStore().GetBackupWebhook()
+	if err != nil {
+		logger.Error(err)
+		backupWebhookResponse.Error = "failed to get backup webhook"
+		JSON(w, http.StatusInternalServerError, backupWebhookResponse)
+		return
+	}
+	if webhook.Secret != "" {
+		webhook.Secret = "--- REDACTED ---"
+	}
+
+	backupWebhookResponse.Webhook = webhook
+	backupWebhookResponse.Success = true
+
+	JSON(w, http.StatusOK, backupWebhookResponse)
+}
+
+func (h *Handler) UpdateBackupWebhook(w http.ResponseWriter, r *http.Request) {
+	backupWebhookResponse := BackupWebhookResponse{}
+
+	webhook := snapshottypes.BackupWebhook{}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		logger.Error(err)
+		backupWebhookResponse.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, backupWebhookResponse)
+		return
+	}
+
+	if webhook.URL != "" {
+		u, err := url.ParseRequestURI(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			backupWebhookResponse.Error = "invalid webhook url"
+			JSON(w, http.StatusBadRequest, backupWebhookResponse)
+			return
+		}
+	}
+
+	currentWebhook, err := store.GetStore().GetBackupWebhook()
+	if err != nil {
+		logger.Error(err)
+		backupWebhookResponse.Error = "failed to get backup webhook"
+		JSON(w, http.StatusInternalServerError, backupWebhookResponse)
+		return
+	}
+	if strings.Contains(webhook.Secret, "REDACTED") {
+		webhook.Secret = currentWebhook.Secret
+	}
+
+	// backups that finished before the url was set are not sent to it
+	webhook.ConfiguredAt = currentWebhook.ConfiguredAt
+	if webhook.URL != currentWebhook.URL || webhook.ConfiguredAt == nil {
+		now := time.Now().UTC()
+		webhook.ConfiguredAt = &now
+	}
+
+	if err := store.GetStore().SetBackupWebhook(&webhook); err != nil {
+		logger.Error(err)
+		backupWebhookResponse.Error = "failed to set backup webhook"
+		JSON(w, http.StatusInternalServerError, backupWebhookResponse)
+		return
+	}
+
+	backupWebhookResponse.Success = true
+
+	JSON(w, http.StatusOK, backupWebhookResponse)
+}
+
+type GetSnapshotDiagnosticsResponse struct {
+	Diagnostics []snapshottypes.SnapshotDiagnostic `json:"diagnostics"`
+}
+
+func (h *Handler) GetSnapshotDiagnostics(w http.ResponseWriter, r *http.Request) {
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	getSnapshotDiagnosticsResponse := GetSnapshotDiagnosticsResponse{
+		Diagnostics: snapshot.GetSnapshotDiagnostics(r.Context()),
+	}
+
+	JSON(w, http.StatusOK, getSnapshotDiagnosticsResponse)
+}
+
+type GetInstanceNamespaceCoverageResponse struct {
+	Success             bool                               `json:"success"`
+	Error               string                             `json:"error,omitempty"`
+	UncoveredNamespaces []snapshottypes.UncoveredNamespace `json:"uncoveredNamespaces"`
+}
+
+// GetInstanceNamespaceCoverage lists the namespaces installed apps have resources in that the next instance
+// backup won't include
+func (h *Handler) GetInstanceNamespaceCoverage(w http.ResponseWriter, r *http.Request) {
+	response := GetInstanceNamespaceCoverageResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	coverage, err := snapshot.CheckInstanceBackupNamespaceCoverage(r.Context())
+	if snapshot.IsNoStoreConfiguredError(err) {
+		response.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, response)
+		return
+	} else if snapshot.IsNoInstanceBackupError(err) {
+		response.Error = err.Error()
+		JSON(w, http.StatusNotFound, response)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		response.Error = "failed to check instance backup namespace coverage"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.UncoveredNamespaces = coverage.UncoveredNamespaces
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
+type ReconcileBackupOwnershipResponse struct {
+	Success    bool                             `json:"success"`
+	Error      string                           `json:"error,omitempty"`
+	Reconciled []snapshottypes.ReconciledBackup `json:"reconciled"`
+}
+
+// ReconcileBackupOwnership restores the annotations kots recognizes its backups by, for backups that lost them
+func (h *Handler) ReconcileBackupOwnership(w http.ResponseWriter, r *http.Request) {
+	response := ReconcileBackupOwnershipResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	reconciled, err := snapshot.ReconcileBackupOwnership(r.Context())
+	if snapshot.IsNoStoreConfiguredError(err) {
+		response.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, response)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		response.Error = "failed to reconcile backup ownership"
+		JSON(w, http.StatusInternalServerError, response)
+		return
+	}
+
+	response.Reconciled = reconciled
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
+}
+
+type ValidateStoreResponse struct {
+	Success            bool       `json:"success"`
+	Error              string     `json:"error,omitempty"`
+	StorePhase         string     `json:"storePhase,omitempty"`
+	StoreLastValidated *time.Time `json:"storeLastValidated,omitempty"`
+}
+
+func (h *Handler) ValidateStore(w http.ResponseWriter, r *http.Request) {
+	validateStoreResponse := ValidateStoreResponse{
+		Success: false,
+	}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	kotsadmVeleroBackendStorageLocation, err := snapshot.RevalidateStore(r.Context(), 15*time.Second)
+	if snapshot.IsStoreValidationTimeoutError(err) {
+		validateStoreResponse.Error = err.Error()
+		JSON(w, http.StatusGatewayTimeout, validateStoreResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		validateStoreResponse.Error = "failed to validate store"
+		JSON(w, 500, validateStoreResponse)
+		return
+	}
+
+	validateStoreResponse.StorePhase = string(kotsadmVeleroBackendStorageLocation.Status.Phase)
+	if kotsadmVeleroBackendStorageLocation.Status.LastValidationTime != nil {
+		validateStoreResponse.StoreLastValidated = &kotsadmVeleroBackendStorageLocation.Status.LastValidationTime.Time
+	}
+	validateStoreResponse.Success = true
+
+	JSON(w, 200, validateStoreResponse)
+}
+
+type RefreshInternalStoreResponse struct {
+	Success bool                 `json:"success"`
+	Error   string               `json:"error,omitempty"`
+	Store   *snapshottypes.Store `json:"store,omitempty"`
+}
+
+// RefreshInternalStore configures the backup storage location with the current kurl object store, for when a
+// kurl upgrade moved the object store or rotated its credentials
+func (h *Handler) RefreshInternalStore(w http.ResponseWriter, r *http.Request) {
+	refreshInternalStoreResponse := RefreshInternalStoreResponse{
+		Success: false,
+	}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	storeBefore, err := snapshot.GetGlobalStore(nil)
+	if err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to get store"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+
+	updatedBackupStorageLocation, err := snapshot.RefreshInternalStore(r.Context())
+	if snapshot.IsNotInternalStoreError(err) {
+		refreshInternalStoreResponse.Error = err.Error()
+		JSON(w, 409, refreshInternalStoreResponse)
+		return
+	} else if err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to refresh internal store"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+
+	updatedStore, err := snapshot.GetGlobalStore(updatedBackupStorageLocation)
+	if err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to get updated store"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+
+	auditBefore, err := snapshot.AuditState(map[string]interface{}{"store": storeBefore})
+	if err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to get audit state"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+	auditAfter, err := snapshot.AuditState(map[string]interface{}{"store": updatedStore})
+	if err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to get audit state"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+	recordSnapshotAuditEvent(r, snapshottypes.SnapshotAuditActionRefreshInternalStore, "", auditBefore, auditAfter)
+
+	if err := snapshot.Redact(updatedStore); err != nil {
+		logger.Error(err)
+		refreshInternalStoreResponse.Error = "failed to redact"
+		JSON(w, 500, refreshInternalStoreResponse)
+		return
+	}
+
+	refreshInternalStoreResponse.Store = updatedStore
+	refreshInternalStoreResponse.Success = true
 
-	store, err := snapshot.GetGlobalStore(nil)
-	if err != nil {
+	JSON(w, 200, refreshInternalStoreResponse)
+}
+
+type SetStorageLocationReadOnlyRequest struct {
+	ReadOnly bool `json:"readOnly"`
+}
+
+type SetStorageLocationReadOnlyResponse struct {
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+	Name     string `json:"name,omitempty"`
+	ReadOnly bool   `json:"readOnly"`
+	// Warning is set when the default location is read-only, new backups fail until it's writable again
+	Warning string `json:"warning,omitempty"`
+}
+
+// SetStorageLocationReadOnly marks a velero backup storage location read-only, or writable again. While migrating
+// to a new store, the old store's location can be kept read-only so its backups stay restorable.
+func (h *Handler) SetStorageLocationReadOnly(w http.ResponseWriter, r *http.Request) {
+	response := SetStorageLocationReadOnlyResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	request := SetStorageLocationReadOnlyRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to get store"
-		JSON(w, 500, globalSnapshotSettingsResponse)
+		response.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, response)
 		return
 	}
 
-	if err := snapshot.Redact(store); err != nil {
+	name := mux.Vars(r)["name"]
+	bsl, err := snapshot.SetStorageLocationReadOnly(r.Context(), name, request.ReadOnly)
+	if snapshot.IsNoStoreConfiguredError(err) {
+		response.Error = noStoreConfiguredMessage
+		JSON(w, http.StatusConflict, response)
+		return
+	} else if snapshot.IsStorageLocationNotFoundError(err) {
+		response.Error = err.Error()
+		JSON(w, http.StatusNotFound, response)
+		return
+	} else if err != nil {
 		logger.Error(err)
-		globalSnapshotSettingsResponse.Error = "failed to redact"
-		JSON(w, 500, globalSnapshotSettingsResponse)
+		response.Error = "failed to set storage location access mode"
+		JSON(w, http.StatusInternalServerError, response)
 		return
 	}
 
-	globalSnapshotSettingsResponse.Store = store
-	globalSnapshotSettingsResponse.Success = true
+	recordSnapshotAuditEvent(r, snapshottypes.SnapshotAuditActionSetLocationReadOnly, "",
+		map[string]interface{}{"location": name},
+		map[string]interface{}{"location": name, "readOnly": request.ReadOnly})
 
-	JSON(w, 200, globalSnapshotSettingsResponse)
+	response.Name = bsl.Name
+	response.ReadOnly = snapshot.IsStorageLocationReadOnly(bsl)
+	if response.ReadOnly {
+		kotsadmVeleroBackendStorageLocation, err := snapshot.FindBackupStoreLocation()
+		if err != nil {
+			// the access mode is already set, the warning is advisory
+			logger.Error(errors.Wrap(err, "failed to find backupstoragelocations"))
+		} else if kotsadmVeleroBackendStorageLocation.Name == bsl.Name {
+			response.Warning = "new backups are written to the default storage location and will fail while it is read-only"
+		}
+	}
+	response.Success = true
+
+	JSON(w, http.StatusOK, response)
 }
 
 func (h *Handler) GetSnapshotConfig(w http.ResponseWriter, r *http.Request) {
@@ -398,39 +1564,106 @@ func (h *Handler) GetSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ttl := &snapshottypes.SnapshotTTL{}
-	if foundApp.SnapshotTTL != "" {
-		parsedTTL, err := snapshot.ParseTTL(foundApp.SnapshotTTL)
+	// apps that haven't set their own retention or schedule get the instance defaults
+	snapshotDefaults, err := snapshot.GetSnapshotDefaults()
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	snapshotTTL := foundApp.SnapshotTTL
+	if snapshotTTL == "" {
+		snapshotTTL = snapshotDefaults.TTL
+	}
+	parsedTTL, err := snapshot.ParseTTL(snapshotTTL)
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ttl := &snapshottypes.SnapshotTTL{
+		InputValue:    strconv.FormatInt(parsedTTL.Quantity, 10),
+		InputTimeUnit: parsedTTL.Unit,
+		Converted:     snapshotTTL,
+	}
+
+	var scheduleTTL *snapshottypes.SnapshotTTL
+	if foundApp.SnapshotScheduleTTL != "" {
+		parsedTTL, err := snapshot.ParseTTL(foundApp.SnapshotScheduleTTL)
 		if err != nil {
 			logger.Error(err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		ttl.InputValue = strconv.FormatInt(parsedTTL.Quantity, 10)
-		ttl.InputTimeUnit = parsedTTL.Unit
-		ttl.Converted = foundApp.SnapshotTTL
-	} else {
-		ttl.InputValue = "1"
-		ttl.InputTimeUnit = "month"
-		ttl.Converted = "720h"
+		scheduleTTL = &snapshottypes.SnapshotTTL{
+			InputValue:    strconv.FormatInt(parsedTTL.Quantity, 10),
+			InputTimeUnit: parsedTTL.Unit,
+			Converted:     foundApp.SnapshotScheduleTTL,
+		}
 	}
 
 	snapshotSchedule := &snapshottypes.SnapshotSchedule{}
 	if foundApp.SnapshotSchedule != "" {
 		snapshotSchedule.Schedule = foundApp.SnapshotSchedule
 	} else {
-		snapshotSchedule.Schedule = "0 0 * * MON"
+		snapshotSchedule.Schedule = snapshotDefaults.Schedule
 	}
 
 	getSnapshotConfigResponse := SnapshotConfig{}
 	getSnapshotConfigResponse.AutoEnabled = foundApp.SnapshotSchedule != ""
 	getSnapshotConfigResponse.AutoSchedule = snapshotSchedule
 	getSnapshotConfigResponse.TTl = ttl
+	getSnapshotConfigResponse.ScheduleTTL = scheduleTTL
+	getSnapshotConfigResponse.ScheduleJitterMinutes = foundApp.SnapshotScheduleJitterMinutes
+	getSnapshotConfigResponse.DefaultVolumesToRestic = foundApp.SnapshotDefaultVolumesToRestic
+	getSnapshotConfigResponse.QuiesceActions = foundApp.SnapshotQuiesceActions
+	getSnapshotConfigResponse.ChecksumTargets = foundApp.SnapshotChecksumTargets
+	getSnapshotConfigResponse.IncludedNamespaces = foundApp.SnapshotIncludedNamespaces
+	getSnapshotConfigResponse.ExcludedNamespaces = foundApp.SnapshotExcludedNamespaces
+	getSnapshotConfigResponse.HookSettings = foundApp.SnapshotHookSettings
+	getSnapshotConfigResponse.ExcludedPVCs = foundApp.SnapshotExcludedPVCs
+	getSnapshotConfigResponse.FanOutLocations = foundApp.SnapshotFanOutLocations
+	getSnapshotConfigResponse.ResticPodSelector = foundApp.SnapshotResticPodSelector
+	getSnapshotConfigResponse.MaxConcurrentBackups = snapshot.MaxConcurrentAppBackups(foundApp)
+
+	if foundApp.SnapshotSchedule != "" {
+		// the gap risk is informational, failing to compute it shouldn't fail the config
+		gapRisk, err := getBackupGapRisk(foundApp.ID)
+		if err != nil {
+			logger.Error(errors.Wrap(err, "failed to check backup gap risk"))
+		}
+		getSnapshotConfigResponse.BackupGapRisk = gapRisk != nil
+		getSnapshotConfigResponse.BackupGapRiskDetails = gapRisk
+	}
 
 	JSON(w, http.StatusOK, getSnapshotConfigResponse)
 }
 
+func getBackupGapRisk(appID string) (*snapshottypes.BackupGapRisk, error) {
+	pending, err := store.GetStore().ListPendingScheduledSnapshots(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pending scheduled snapshots")
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	nextScheduled := pending[0].ScheduledTimestamp
+	for _, p := range pending {
+		if p.ScheduledTimestamp.Before(nextScheduled) {
+			nextScheduled = p.ScheduledTimestamp
+		}
+	}
+
+	backups, err := snapshot.ListBackupsForApp(appID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	return snapshot.CheckBackupGapRisk(backups, nextScheduled), nil
+}
+
 func (h *Handler) GetVeleroStatus(w http.ResponseWriter, r *http.Request) {
 	getVeleroStatusResponse := VeleroStatus{}
 
@@ -452,17 +1685,50 @@ func (h *Handler) GetVeleroStatus(w http.ResponseWriter, r *http.Request) {
 	JSON(w, 200, getVeleroStatusResponse)
 }
 
+// SaveSnapshotConfigRequest leaves the settings that are missing from the request unchanged,
+// older clients don't send the ones that were added later
 type SaveSnapshotConfigRequest struct {
-	AppID         string `json:"appId"`
-	InputValue    string `json:"inputValue"`
-	InputTimeUnit string `json:"inputTimeUnit"`
-	Schedule      string `json:"schedule"`
-	AutoEnabled   bool   `json:"autoEnabled"`
+	AppID                 string  `json:"appId"`
+	InputValue            string  `json:"inputValue"`
+	InputTimeUnit         string  `json:"inputTimeUnit"`
+	Schedule              string  `json:"schedule"`
+	AutoEnabled           bool    `json:"autoEnabled"`
+	ScheduleInputValue    *string `json:"scheduleInputValue"`
+	ScheduleInputTimeUnit string  `json:"scheduleInputTimeUnit"`
+	ScheduleJitterMinutes *int    `json:"scheduleJitterMinutes"`
+	// DefaultVolumesToRestic set to null removes the app override
+	DefaultVolumesToRestic optionalBool                    `json:"defaultVolumesToRestic"`
+	QuiesceActions         *[]snapshottypes.QuiesceAction  `json:"quiesceActions"`
+	ChecksumTargets        *[]snapshottypes.ChecksumTarget `json:"checksumTargets"`
+	IncludedNamespaces     *[]string                       `json:"includedNamespaces"`
+	ExcludedNamespaces     *[]string                       `json:"excludedNamespaces"`
+	HookSettings           *snapshottypes.HookSettings     `json:"hookSettings"`
+	// ExcludedPVCs are pvc names, or namespace/name pairs, whose volumes restic skips
+	ExcludedPVCs *[]string `json:"excludedPvcs"`
+	// FanOutLocations are velero backup storage locations that scheduled backups are also copied to
+	FanOutLocations *[]string `json:"fanOutLocations"`
+	// ResticPodSelector is a label selector, restic only backs up the volumes of the pods matching it when set
+	ResticPodSelector *string `json:"resticPodSelector"`
+	// MaxConcurrentBackups is how many backups of the app, manual or scheduled, can run at once. 0 restores the default of 1.
+	MaxConcurrentBackups *int `json:"maxConcurrentBackups"`
+}
+
+// optionalBool tells a null value, which is kept in Value as nil, apart from a missing one
+type optionalBool struct {
+	Set   bool
+	Value *bool
+}
+
+func (b *optionalBool) UnmarshalJSON(data []byte) error {
+	b.Set = true
+	return json.Unmarshal(data, &b.Value)
 }
 
 type SaveSnapshotConfigResponse struct {
 	Success bool   `json:"success"`
 	Error   string `json:"error,omitempty"`
+	// Warning explains a retention gap when scheduled backups expire before the next one runs
+	Warning string `json:"warning,omitempty"`
 }
 
 func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
@@ -489,6 +1755,94 @@ func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	auditBefore, err := appSnapshotConfigAuditState(app)
+	if err != nil {
+		logger.Error(err)
+		responseBody.Error = "Failed to get audit state"
+		JSON(w, http.StatusInternalServerError, responseBody)
+		return
+	}
+
+	if requestBody.QuiesceActions != nil {
+		if err := snapshot.ValidateQuiesceActions(r.Context(), *requestBody.QuiesceActions); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid quiesce actions: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ChecksumTargets != nil {
+		if err := snapshot.ValidateChecksumTargets(*requestBody.ChecksumTargets); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid checksum targets: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	// the namespaces are checked together, the one missing from the request keeps its current value
+	includedNamespaces, excludedNamespaces := app.SnapshotIncludedNamespaces, app.SnapshotExcludedNamespaces
+	if requestBody.IncludedNamespaces != nil {
+		includedNamespaces = *requestBody.IncludedNamespaces
+	}
+	if requestBody.ExcludedNamespaces != nil {
+		excludedNamespaces = *requestBody.ExcludedNamespaces
+	}
+	if requestBody.IncludedNamespaces != nil || requestBody.ExcludedNamespaces != nil {
+		if err := snapshot.ValidateAppSnapshotNamespaces(r.Context(), includedNamespaces, excludedNamespaces); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid snapshot namespaces: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.HookSettings != nil {
+		if err := snapshot.ValidateHookSettings(requestBody.HookSettings); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid hook settings: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ExcludedPVCs != nil {
+		if err := snapshot.ValidateExcludedPVCs(*requestBody.ExcludedPVCs); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid excluded pvcs: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.FanOutLocations != nil {
+		if err := snapshot.ValidateFanOutLocations(r.Context(), *requestBody.FanOutLocations); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid fan-out locations: %s", errors.Cause(err).Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ResticPodSelector != nil {
+		if err := snapshot.ValidateResticPodSelector(*requestBody.ResticPodSelector); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid restic pod selector: %s", errors.Cause(err).Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	if requestBody.MaxConcurrentBackups != nil {
+		if err := snapshot.ValidateMaxConcurrentAppBackups(*requestBody.MaxConcurrentBackups); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid max concurrent backups: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
 	retention, err := snapshot.FormatTTL(requestBody.InputValue, requestBody.InputTimeUnit)
 	if err != nil {
 		logger.Error(err)
@@ -497,6 +1851,74 @@ func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// scheduled backups use the app retention unless the schedule has its own, an empty value removes it
+	scheduleRetention := app.SnapshotScheduleTTL
+	if requestBody.ScheduleInputValue != nil {
+		scheduleRetention = ""
+		if *requestBody.ScheduleInputValue != "" {
+			scheduleRetention, err = snapshot.FormatTTL(*requestBody.ScheduleInputValue, requestBody.ScheduleInputTimeUnit)
+			if err != nil {
+				logger.Error(err)
+				responseBody.Error = fmt.Sprintf("Invalid schedule snapshot retention: %s %s", *requestBody.ScheduleInputValue, requestBody.ScheduleInputTimeUnit)
+				JSON(w, http.StatusBadRequest, responseBody)
+				return
+			}
+		}
+	}
+
+	objectLockRetentionDays, err := snapshot.GetObjectLockRetentionDays()
+	if err != nil {
+		logger.Error(err)
+		responseBody.Error = "Failed to get object lock retention"
+		JSON(w, http.StatusInternalServerError, responseBody)
+		return
+	}
+	if err := snapshot.ValidateTTLForObjectLock(retention, objectLockRetentionDays); err != nil {
+		responseBody.Error = fmt.Sprintf("Invalid snapshot retention: %s", err.Error())
+		JSON(w, http.StatusBadRequest, responseBody)
+		return
+	}
+	if scheduleRetention != "" {
+		if err := snapshot.ValidateTTLForObjectLock(scheduleRetention, objectLockRetentionDays); err != nil {
+			responseBody.Error = fmt.Sprintf("Invalid schedule snapshot retention: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+
+	// the schedule is only saved when it's enabled
+	scheduleJitterMinutes := app.SnapshotScheduleJitterMinutes
+	if requestBody.ScheduleJitterMinutes != nil {
+		scheduleJitterMinutes = *requestBody.ScheduleJitterMinutes
+	}
+	if requestBody.AutoEnabled {
+		if _, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).Parse(requestBody.Schedule); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid cron schedule expression: %s", requestBody.Schedule)
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+
+		if err := snapshot.ValidateScheduleJitter(requestBody.Schedule, scheduleJitterMinutes, time.Now()); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid schedule jitter: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+
+		// scheduled backups are kept for the schedule retention if there is one
+		scheduledTTL := retention
+		if scheduleRetention != "" {
+			scheduledTTL = scheduleRetention
+		}
+		retentionGapWarning, err := snapshot.CheckScheduleRetentionGap(requestBody.Schedule, scheduledTTL, scheduleJitterMinutes, time.Now())
+		if err != nil {
+			logger.Error(err)
+		}
+		responseBody.Warning = retentionGapWarning
+	}
+
+	// the whole request is valid, nothing is saved before this point
 	if app.SnapshotTTL != retention {
 		app.SnapshotTTL = retention
 		if err := store.GetStore().SetSnapshotTTL(app.ID, retention); err != nil {
@@ -507,6 +1929,97 @@ func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if app.SnapshotScheduleTTL != scheduleRetention {
+		app.SnapshotScheduleTTL = scheduleRetention
+		if err := store.GetStore().SetSnapshotScheduleTTL(app.ID, scheduleRetention); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set schedule snapshot retention"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.DefaultVolumesToRestic.Set {
+		if err := store.GetStore().SetSnapshotDefaultVolumesToRestic(app.ID, requestBody.DefaultVolumesToRestic.Value); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot default volumes to restic"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.QuiesceActions != nil {
+		if err := store.GetStore().SetSnapshotQuiesceActions(app.ID, *requestBody.QuiesceActions); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot quiesce actions"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ChecksumTargets != nil {
+		if err := store.GetStore().SetSnapshotChecksumTargets(app.ID, *requestBody.ChecksumTargets); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot checksum targets"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.IncludedNamespaces != nil || requestBody.ExcludedNamespaces != nil {
+		if err := store.GetStore().SetSnapshotNamespaces(app.ID, includedNamespaces, excludedNamespaces); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot namespaces"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.HookSettings != nil {
+		if err := store.GetStore().SetSnapshotHookSettings(app.ID, requestBody.HookSettings); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot hook settings"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ExcludedPVCs != nil {
+		if err := store.GetStore().SetSnapshotExcludedPVCs(app.ID, *requestBody.ExcludedPVCs); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot excluded pvcs"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.FanOutLocations != nil {
+		if err := store.GetStore().SetSnapshotFanOutLocations(app.ID, *requestBody.FanOutLocations); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot fan-out locations"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ResticPodSelector != nil {
+		if err := store.GetStore().SetSnapshotResticPodSelector(app.ID, *requestBody.ResticPodSelector); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot restic pod selector"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.MaxConcurrentBackups != nil {
+		if err := store.GetStore().SetSnapshotMaxConcurrentBackups(app.ID, *requestBody.MaxConcurrentBackups); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set snapshot max concurrent backups"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
 	if !requestBody.AutoEnabled {
 		if err := store.GetStore().SetSnapshotSchedule(app.ID, ""); err != nil {
 			logger.Error(err)
@@ -520,53 +2033,69 @@ func (h *Handler) SaveSnapshotConfig(w http.ResponseWriter, r *http.Request) {
 			JSON(w, http.StatusInternalServerError, responseBody)
 			return
 		}
+		recordAppSnapshotConfigAuditEvent(r, app.ID, auditBefore)
 		responseBody.Success = true
 		JSON(w, 200, responseBody)
 		return
 	}
 
-	cronSchedule, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).Parse(requestBody.Schedule)
-	if err != nil {
-		logger.Error(err)
-		responseBody.Error = fmt.Sprintf("Invalid cron schedule expression: %s", requestBody.Schedule)
-		JSON(w, http.StatusBadRequest, responseBody)
-		return
-	}
-
-	if requestBody.Schedule != app.SnapshotSchedule {
-		if err := store.GetStore().DeletePendingScheduledSnapshots(app.ID); err != nil {
+	jitterChanged := scheduleJitterMinutes != app.SnapshotScheduleJitterMinutes
+	if jitterChanged {
+		if err := store.GetStore().SetSnapshotScheduleJitter(app.ID, scheduleJitterMinutes); err != nil {
 			logger.Error(err)
-			responseBody.Error = "Failed to delete scheduled snapshots"
+			responseBody.Error = "Failed to save snapshot schedule jitter"
 			JSON(w, http.StatusInternalServerError, responseBody)
 			return
 		}
+	}
+
+	if requestBody.Schedule != app.SnapshotSchedule || jitterChanged {
 		if err := store.GetStore().SetSnapshotSchedule(app.ID, requestBody.Schedule); err != nil {
 			logger.Error(err)
 			responseBody.Error = "Failed to save snapshot schedule"
 			JSON(w, http.StatusInternalServerError, responseBody)
 			return
 		}
-		queued := cronSchedule.Next(time.Now())
-		id := strings.ToLower(rand.String(32))
-		if err := store.GetStore().CreateScheduledSnapshot(id, app.ID, queued); err != nil {
+		app.SnapshotSchedule = requestBody.Schedule
+		app.SnapshotScheduleJitterMinutes = scheduleJitterMinutes
+		if err := snapshotscheduler.ReconcileApplicationSchedule(app); err != nil {
 			logger.Error(err)
-			responseBody.Error = "Failed to create first scheduled snapshot"
+			responseBody.Error = "Failed to reconcile scheduled snapshots"
 			JSON(w, http.StatusInternalServerError, responseBody)
 			return
 		}
 	}
 
+	recordAppSnapshotConfigAuditEvent(r, app.ID, auditBefore)
 	responseBody.Success = true
 	JSON(w, 200, responseBody)
 }
 
 type InstanceSnapshotConfig struct {
-	AutoEnabled  bool                            `json:"autoEnabled"`
-	AutoSchedule *snapshottypes.SnapshotSchedule `json:"autoSchedule"`
-	TTl          *snapshottypes.SnapshotTTL      `json:"ttl"`
+	AutoEnabled              bool                                      `json:"autoEnabled"`
+	AutoSchedule             *snapshottypes.SnapshotSchedule           `json:"autoSchedule"`
+	TTl                      *snapshottypes.SnapshotTTL                `json:"ttl"`
+	IncludedClusterResources []string                                  `json:"includedClusterResources"`
+	ExcludedClusterResources []string                                  `json:"excludedClusterResources"`
+	ExcludedPVCs             []string                                  `json:"excludedPvcs"`
+	FanOutLocations          []string                                  `json:"fanOutLocations"`
+	IncludeRegistryData      bool                                      `json:"includeRegistryData"`
+	Capability               *snapshottypes.InstanceSnapshotCapability `json:"capability"`
 }
 
 func (h *Handler) GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request) {
+	capability, err := snapshot.CanTakeInstanceSnapshots()
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if capability.Reason == snapshottypes.InstanceSnapshotsNoCluster {
+		// there's no config to return without a cluster
+		JSON(w, http.StatusOK, InstanceSnapshotConfig{Capability: capability})
+		return
+	}
+
 	clusters, err := store.GetStore().ListClusters()
 	if err != nil {
 		logger.Error(err)
@@ -609,20 +2138,37 @@ func (h *Handler) GetInstanceSnapshotConfig(w http.ResponseWriter, r *http.Reque
 	getInstanceSnapshotConfigResponse.AutoEnabled = c.SnapshotSchedule != ""
 	getInstanceSnapshotConfigResponse.AutoSchedule = snapshotSchedule
 	getInstanceSnapshotConfigResponse.TTl = ttl
+	getInstanceSnapshotConfigResponse.IncludedClusterResources = c.SnapshotIncludedClusterResources
+	getInstanceSnapshotConfigResponse.ExcludedClusterResources = c.SnapshotExcludedClusterResources
+	getInstanceSnapshotConfigResponse.ExcludedPVCs = c.SnapshotExcludedPVCs
+	getInstanceSnapshotConfigResponse.FanOutLocations = c.SnapshotFanOutLocations
+	getInstanceSnapshotConfigResponse.IncludeRegistryData = c.SnapshotIncludeRegistryData
+	getInstanceSnapshotConfigResponse.Capability = capability
 
 	JSON(w, http.StatusOK, getInstanceSnapshotConfigResponse)
 }
 
+// SaveInstanceSnapshotConfigRequest leaves the settings that are missing from the request unchanged,
+// older clients don't send the ones that were added later
 type SaveInstanceSnapshotConfigRequest struct {
-	InputValue    string `json:"inputValue"`
-	InputTimeUnit string `json:"inputTimeUnit"`
-	Schedule      string `json:"schedule"`
-	AutoEnabled   bool   `json:"autoEnabled"`
+	InputValue               string    `json:"inputValue"`
+	InputTimeUnit            string    `json:"inputTimeUnit"`
+	Schedule                 string    `json:"schedule"`
+	AutoEnabled              bool      `json:"autoEnabled"`
+	IncludedClusterResources *[]string `json:"includedClusterResources"`
+	ExcludedClusterResources *[]string `json:"excludedClusterResources"`
+	// ExcludedPVCs are pvc names, or namespace/name pairs, whose volumes restic skips
+	ExcludedPVCs *[]string `json:"excludedPvcs"`
+	// FanOutLocations are velero backup storage locations that scheduled backups are also copied to
+	FanOutLocations *[]string `json:"fanOutLocations"`
+	// IncludeRegistryData backs up the images pushed to the kurl registry, only supported on kurl clusters
+	IncludeRegistryData *bool `json:"includeRegistryData"`
 }
 
 type SaveInstanceSnapshotConfigResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success    bool                                      `json:"success"`
+	Error      string                                    `json:"error,omitempty"`
+	Capability *snapshottypes.InstanceSnapshotCapability `json:"capability,omitempty"`
 }
 
 func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Request) {
@@ -632,11 +2178,49 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
 		return
 	}
-
-	requestBody := SaveInstanceSnapshotConfigRequest{}
-	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
-		logger.Error(err)
-		responseBody.Error = "failed to decode request body"
+
+	requestBody := SaveInstanceSnapshotConfigRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		logger.Error(err)
+		responseBody.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, responseBody)
+		return
+	}
+
+	if requestBody.IncludedClusterResources != nil {
+		if err := snapshot.ValidateClusterResourceNames(*requestBody.IncludedClusterResources); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid included cluster resources: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+	if requestBody.ExcludedClusterResources != nil {
+		if err := snapshot.ValidateClusterResourceNames(*requestBody.ExcludedClusterResources); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid excluded cluster resources: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+	if requestBody.ExcludedPVCs != nil {
+		if err := snapshot.ValidateExcludedPVCs(*requestBody.ExcludedPVCs); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid excluded pvcs: %s", err.Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+	if requestBody.FanOutLocations != nil {
+		if err := snapshot.ValidateFanOutLocations(r.Context(), *requestBody.FanOutLocations); err != nil {
+			logger.Error(err)
+			responseBody.Error = fmt.Sprintf("Invalid fan-out locations: %s", errors.Cause(err).Error())
+			JSON(w, http.StatusBadRequest, responseBody)
+			return
+		}
+	}
+	if requestBody.IncludeRegistryData != nil && *requestBody.IncludeRegistryData && !kurl.IsKurl() {
+		responseBody.Error = "Registry data can only be included in backups on kurl clusters"
 		JSON(w, http.StatusBadRequest, responseBody)
 		return
 	}
@@ -649,13 +2233,46 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	if len(clusters) == 0 {
-		err := errors.New("No clusters found")
+		responseBody.Error = "No cluster has been registered"
+		responseBody.Capability = &snapshottypes.InstanceSnapshotCapability{
+			Reason:  snapshottypes.InstanceSnapshotsNoCluster,
+			Message: responseBody.Error,
+		}
+		JSON(w, http.StatusConflict, responseBody)
+		return
+	}
+	c := clusters[0]
+
+	auditBefore, err := instanceSnapshotConfigAuditState(c)
+	if err != nil {
 		logger.Error(err)
-		responseBody.Error = err.Error()
+		responseBody.Error = "Failed to get audit state"
 		JSON(w, http.StatusInternalServerError, responseBody)
 		return
 	}
-	c := clusters[0]
+
+	// the cluster resources are checked together, the list missing from the request keeps its current value
+	includedClusterResources, excludedClusterResources := c.SnapshotIncludedClusterResources, c.SnapshotExcludedClusterResources
+	if requestBody.IncludedClusterResources != nil {
+		includedClusterResources = *requestBody.IncludedClusterResources
+	}
+	if requestBody.ExcludedClusterResources != nil {
+		excludedClusterResources = *requestBody.ExcludedClusterResources
+	}
+	clusterResourcesChanged := requestBody.IncludedClusterResources != nil || requestBody.ExcludedClusterResources != nil
+	if clusterResourcesChanged {
+		if err := snapshot.ValidateClusterScopedResources(includedClusterResources, excludedClusterResources); err != nil {
+			logger.Error(err)
+			if snapshot.IsNamespacedResourceError(err) {
+				responseBody.Error = fmt.Sprintf("Invalid cluster resources: %s", errors.Cause(err).Error())
+				JSON(w, http.StatusBadRequest, responseBody)
+				return
+			}
+			responseBody.Error = "Failed to validate cluster resources"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
 
 	retention, err := snapshot.FormatTTL(requestBody.InputValue, requestBody.InputTimeUnit)
 	if err != nil {
@@ -665,6 +2282,19 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	objectLockRetentionDays, err := snapshot.GetObjectLockRetentionDays()
+	if err != nil {
+		logger.Error(err)
+		responseBody.Error = "Failed to get object lock retention"
+		JSON(w, http.StatusInternalServerError, responseBody)
+		return
+	}
+	if err := snapshot.ValidateTTLForObjectLock(retention, objectLockRetentionDays); err != nil {
+		responseBody.Error = fmt.Sprintf("Invalid instance snapshot retention: %s", err.Error())
+		JSON(w, http.StatusBadRequest, responseBody)
+		return
+	}
+
 	if c.SnapshotTTL != retention {
 		c.SnapshotTTL = retention
 		if err := store.GetStore().SetInstanceSnapshotTTL(c.ClusterID, retention); err != nil {
@@ -675,6 +2305,42 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	if clusterResourcesChanged {
+		if err := store.GetStore().SetInstanceSnapshotClusterResources(c.ClusterID, includedClusterResources, excludedClusterResources); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set instance snapshot cluster resources"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.ExcludedPVCs != nil {
+		if err := store.GetStore().SetInstanceSnapshotExcludedPVCs(c.ClusterID, *requestBody.ExcludedPVCs); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set instance snapshot excluded pvcs"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.FanOutLocations != nil {
+		if err := store.GetStore().SetInstanceSnapshotFanOutLocations(c.ClusterID, *requestBody.FanOutLocations); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set instance snapshot fan-out locations"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
+	if requestBody.IncludeRegistryData != nil {
+		if err := store.GetStore().SetInstanceSnapshotIncludeRegistryData(c.ClusterID, *requestBody.IncludeRegistryData); err != nil {
+			logger.Error(err)
+			responseBody.Error = "Failed to set instance snapshot include registry data"
+			JSON(w, http.StatusInternalServerError, responseBody)
+			return
+		}
+	}
+
 	if !requestBody.AutoEnabled {
 		if err := store.GetStore().SetInstanceSnapshotSchedule(c.ClusterID, ""); err != nil {
 			logger.Error(err)
@@ -688,6 +2354,7 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 			JSON(w, http.StatusInternalServerError, responseBody)
 			return
 		}
+		recordInstanceSnapshotConfigAuditEvent(r, c.ClusterID, auditBefore)
 		responseBody.Success = true
 		JSON(w, 200, responseBody)
 		return
@@ -724,6 +2391,7 @@ func (h *Handler) SaveInstanceSnapshotConfig(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
+	recordInstanceSnapshotConfigAuditEvent(r, c.ClusterID, auditBefore)
 	responseBody.Success = true
 	JSON(w, http.StatusOK, responseBody)
 }
@@ -750,3 +2418,340 @@ func requiresKotsadmVeleroAccess(w http.ResponseWriter, r *http.Request) error {
 	}
 	return nil
 }
+
+type PrePullVeleroImagesResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) PrePullVeleroImages(w http.ResponseWriter, r *http.Request) {
+	prePullVeleroImagesResponse := PrePullVeleroImagesResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	if err := snapshot.PrePullVeleroImages(r.Context()); err != nil {
+		logger.Error(err)
+		prePullVeleroImagesResponse.Error = "failed to start pulling velero images"
+		JSON(w, http.StatusInternalServerError, prePullVeleroImagesResponse)
+		return
+	}
+
+	prePullVeleroImagesResponse.Success = true
+
+	JSON(w, http.StatusOK, prePullVeleroImagesResponse)
+}
+
+type GetVeleroImagePrePullStatusResponse struct {
+	Success bool                              `json:"success"`
+	Error   string                            `json:"error,omitempty"`
+	Status  *snapshottypes.ImagePrePullStatus `json:"status,omitempty"`
+}
+
+func (h *Handler) GetVeleroImagePrePullStatus(w http.ResponseWriter, r *http.Request) {
+	getVeleroImagePrePullStatusResponse := GetVeleroImagePrePullStatusResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	status, err := snapshot.GetVeleroImagePrePullStatus(r.Context())
+	if err != nil {
+		logger.Error(err)
+		getVeleroImagePrePullStatusResponse.Error = "failed to get velero image pull status"
+		JSON(w, http.StatusInternalServerError, getVeleroImagePrePullStatusResponse)
+		return
+	}
+	getVeleroImagePrePullStatusResponse.Status = status
+
+	getVeleroImagePrePullStatusResponse.Success = true
+
+	JSON(w, http.StatusOK, getVeleroImagePrePullStatusResponse)
+}
+
+type GetSnapshotTTLUnitsResponse struct {
+	Success bool                          `json:"success"`
+	Error   string                        `json:"error,omitempty"`
+	Units   []snapshottypes.TTLConversion `json:"units"`
+}
+
+func (h *Handler) GetSnapshotTTLUnits(w http.ResponseWriter, r *http.Request) {
+	getSnapshotTTLUnitsResponse := GetSnapshotTTLUnitsResponse{}
+
+	ttl := r.URL.Query().Get("ttl")
+	units, err := snapshot.ConvertTTL(ttl)
+	if err != nil {
+		logger.Error(err)
+		getSnapshotTTLUnitsResponse.Error = fmt.Sprintf("Invalid snapshot retention: %s", ttl)
+		JSON(w, http.StatusBadRequest, getSnapshotTTLUnitsResponse)
+		return
+	}
+	getSnapshotTTLUnitsResponse.Units = units
+
+	getSnapshotTTLUnitsResponse.Success = true
+
+	JSON(w, http.StatusOK, getSnapshotTTLUnitsResponse)
+}
+
+type GetVeleroImagesResponse struct {
+	Success bool                        `json:"success"`
+	Error   string                      `json:"error,omitempty"`
+	Images  *snapshottypes.VeleroImages `json:"images,omitempty"`
+}
+
+func (h *Handler) GetVeleroImages(w http.ResponseWriter, r *http.Request) {
+	getVeleroImagesResponse := GetVeleroImagesResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	images, err := snapshot.GetVeleroImages(r.Context())
+	if err != nil {
+		logger.Error(err)
+		getVeleroImagesResponse.Error = "failed to get velero images"
+		JSON(w, http.StatusInternalServerError, getVeleroImagesResponse)
+		return
+	}
+	getVeleroImagesResponse.Images = images
+
+	getVeleroImagesResponse.Success = true
+
+	JSON(w, http.StatusOK, getVeleroImagesResponse)
+}
+
+type GetResticLocksResponse struct {
+	Success bool                                 `json:"success"`
+	Error   string                               `json:"error,omitempty"`
+	Locks   []snapshottypes.ResticRepositoryLock `json:"locks"`
+}
+
+func (h *Handler) GetResticLocks(w http.ResponseWriter, r *http.Request) {
+	getResticLocksResponse := GetResticLocksResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	locks, err := snapshot.CheckResticLocks(r.Context())
+	if err != nil {
+		logger.Error(err)
+		getResticLocksResponse.Error = "failed to check restic locks"
+		JSON(w, http.StatusInternalServerError, getResticLocksResponse)
+		return
+	}
+	getResticLocksResponse.Locks = locks
+
+	getResticLocksResponse.Success = true
+
+	JSON(w, http.StatusOK, getResticLocksResponse)
+}
+
+type UnlockResticRepositoryResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *Handler) UnlockResticRepository(w http.ResponseWriter, r *http.Request) {
+	unlockResticRepositoryResponse := UnlockResticRepositoryResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	if err := snapshot.UnlockResticRepository(r.Context(), mux.Vars(r)["repoName"]); err != nil {
+		logger.Error(err)
+		unlockResticRepositoryResponse.Error = "failed to unlock restic repository"
+		JSON(w, http.StatusInternalServerError, unlockResticRepositoryResponse)
+		return
+	}
+
+	unlockResticRepositoryResponse.Success = true
+
+	JSON(w, http.StatusOK, unlockResticRepositoryResponse)
+}
+
+type CleanupOrphanedObjectsRequest struct {
+	// DryRun defaults to true so objects are only deleted when explicitly asked for
+	DryRun *bool `json:"dryRun"`
+}
+
+type CleanupOrphanedObjectsResponse struct {
+	Success bool                                  `json:"success"`
+	Error   string                                `json:"error,omitempty"`
+	Cleanup *snapshottypes.OrphanedObjectsCleanup `json:"cleanup,omitempty"`
+}
+
+func (h *Handler) CleanupOrphanedObjects(w http.ResponseWriter, r *http.Request) {
+	cleanupOrphanedObjectsResponse := CleanupOrphanedObjectsResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	cleanupOrphanedObjectsRequest := CleanupOrphanedObjectsRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&cleanupOrphanedObjectsRequest); err != nil {
+		logger.Error(err)
+		cleanupOrphanedObjectsResponse.Error = "failed to decode request body"
+		JSON(w, http.StatusBadRequest, cleanupOrphanedObjectsResponse)
+		return
+	}
+
+	dryRun := true
+	if cleanupOrphanedObjectsRequest.DryRun != nil {
+		dryRun = *cleanupOrphanedObjectsRequest.DryRun
+	}
+
+	cleanup, err := snapshot.CleanupOrphanedObjects(r.Context(), dryRun)
+	if err != nil {
+		logger.Error(err)
+		cleanupOrphanedObjectsResponse.Error = "failed to clean up orphaned objects"
+		JSON(w, http.StatusInternalServerError, cleanupOrphanedObjectsResponse)
+		return
+	}
+	cleanupOrphanedObjectsResponse.Cleanup = cleanup
+
+	cleanupOrphanedObjectsResponse.Success = true
+
+	JSON(w, http.StatusOK, cleanupOrphanedObjectsResponse)
+}
+
+type ImportExistingBackupsResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ImportExistingBackups starts syncing the backups in the configured store, which may have been taken on another
+// cluster, so they can be restored from. Velero only syncs once per sync period, progress is polled with
+// GetImportExistingBackupsStatus.
+func (h *Handler) ImportExistingBackups(w http.ResponseWriter, r *http.Request) {
+	importExistingBackupsResponse := ImportExistingBackupsResponse{}
+
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	status, _, err := store.GetStore().GetTaskStatus("import-backups")
+	if err != nil {
+		logger.Error(err)
+		importExistingBackupsResponse.Error = "failed to get import status"
+		JSON(w, http.StatusInternalServerError, importExistingBackupsResponse)
+		return
+	}
+	if status == "running" {
+		importExistingBackupsResponse.Error = "backups are already being imported"
+		JSON(w, http.StatusConflict, importExistingBackupsResponse)
+		return
+	}
+
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		logger.Error(err)
+		importExistingBackupsResponse.Error = "failed to list installed apps"
+		JSON(w, http.StatusInternalServerError, importExistingBackupsResponse)
+		return
+	}
+
+	if err := store.GetStore().SetTaskStatus("import-backups", "Waiting for velero to sync backups from the store...", "running"); err != nil {
+		logger.Error(err)
+		importExistingBackupsResponse.Error = "failed to set import status"
+		JSON(w, http.StatusInternalServerError, importExistingBackupsResponse)
+		return
+	}
+
+	go func() {
+		finishedCh := make(chan struct{})
+		defer close(finishedCh)
+		go func() {
+			for {
+				select {
+				case <-time.After(time.Second):
+					if err := store.GetStore().UpdateTaskStatusTimestamp("import-backups"); err != nil {
+						logger.Error(err)
+					}
+				case <-finishedCh:
+					return
+				}
+			}
+		}()
+
+		var finalError error
+		defer func() {
+			if finalError == nil {
+				if err := store.GetStore().ClearTaskStatus("import-backups"); err != nil {
+					logger.Error(errors.Wrap(err, "failed to clear task status"))
+				}
+			} else {
+				if err := store.GetStore().SetTaskStatus("import-backups", finalError.Error(), "failed"); err != nil {
+					logger.Error(errors.Wrap(err, "failed to set task status error"))
+				}
+			}
+		}()
+
+		if err := snapshot.ImportExistingBackups(context.Background(), apps); err != nil {
+			if !snapshot.IsBackupSyncTimeoutError(err) {
+				logger.Error(err)
+			}
+			finalError = err
+		}
+	}()
+
+	importExistingBackupsResponse.Success = true
+
+	JSON(w, http.StatusAccepted, importExistingBackupsResponse)
+}
+
+type GetImportExistingBackupsStatusResponse struct {
+	Status         string                         `json:"status"`
+	CurrentMessage string                         `json:"currentMessage"`
+	Backups        []snapshottypes.ImportedBackup `json:"backups"`
+}
+
+// GetImportExistingBackupsStatus returns the status of the running import. Once it's done, the backups synced
+// from the store are returned, matched to the installed apps.
+func (h *Handler) GetImportExistingBackupsStatus(w http.ResponseWriter, r *http.Request) {
+	// check minimal rbac
+	if err := requiresKotsadmVeleroAccess(w, r); err != nil {
+		return
+	}
+
+	status, message, err := store.GetStore().GetTaskStatus("import-backups")
+	if err != nil {
+		logger.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	getImportExistingBackupsStatusResponse := GetImportExistingBackupsStatusResponse{
+		Status:         status,
+		CurrentMessage: message,
+		Backups:        []snapshottypes.ImportedBackup{},
+	}
+
+	if status != "running" {
+		apps, err := store.GetStore().ListInstalledApps()
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		backups, err := snapshot.ListImportedBackups(r.Context(), apps)
+		if err != nil {
+			logger.Error(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		getImportExistingBackupsStatusResponse.Backups = backups
+	}
+
+	JSON(w, http.StatusOK, getImportExistingBackupsStatusResponse)
+}
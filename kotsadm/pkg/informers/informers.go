@@ -7,6 +7,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
 	"github.com/replicatedhq/kots/kotsadm/pkg/supportbundle"
 	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
 	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
@@ -50,12 +52,28 @@ func Start() error {
 				}
 				break
 			}
+			if obj.Type == watch.Added {
+				// a watch starts with the existing backups, which catches the ones that finished while kotsadm wasn't running
+				backup, ok := obj.Object.(*velerov1.Backup)
+				if !ok {
+					logger.Errorf("failed to cast obj to backup")
+					continue
+				}
+
+				if snapshot.IsBackupDone(backup) {
+					handleBackupDone(veleroClient, backup)
+				}
+			}
 			if obj.Type == watch.Modified {
 				backup, ok := obj.Object.(*velerov1.Backup)
 				if !ok {
 					logger.Errorf("failed to cast obj to backup")
 				}
 
+				if snapshot.IsBackupDone(backup) {
+					backup = handleBackupDone(veleroClient, backup)
+				}
+
 				if backup.Status.Phase == velerov1.BackupPhaseFailed || backup.Status.Phase == velerov1.BackupPhasePartiallyFailed {
 					if backup.Annotations == nil {
 						backup.Annotations = map[string]string{}
@@ -103,3 +121,89 @@ func Start() error {
 
 	return nil
 }
+
+// handleBackupDone scales back the workloads quiesced for the backup and notifies the webhook, each only once.
+// The backup is returned as last updated.
+func handleBackupDone(veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup) *velerov1.Backup {
+	if updatedBackup := handleBackupUnquiesce(veleroClient, backup); updatedBackup != nil {
+		backup = updatedBackup
+	}
+	if updatedBackup := handleBackupWebhook(veleroClient, backup); updatedBackup != nil {
+		backup = updatedBackup
+	}
+	return backup
+}
+
+// handleBackupWebhook notifies the backup webhook, if one is configured, the first time a backup that finished
+// after the webhook was configured is seen.
+// The updated backup is returned when it had to be annotated.
+func handleBackupWebhook(veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup) *velerov1.Backup {
+	if _, ok := backup.Annotations["kots.io/webhook-notified"]; ok {
+		return nil
+	}
+
+	webhook, err := store.GetStore().GetBackupWebhook()
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get backup webhook"))
+		return nil
+	}
+	if !snapshot.ShouldNotifyBackupWebhook(webhook, backup) {
+		return nil
+	}
+
+	if backup.Annotations == nil {
+		backup.Annotations = map[string]string{}
+	}
+	backup.Annotations["kots.io/webhook-notified"] = time.Now().UTC().Format(time.RFC3339)
+
+	// annotate first so that the webhook is only called once even if the informer restarts
+	updatedBackup, err := veleroClient.Backups(backup.Namespace).Update(context.TODO(), backup, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to annotate backup"))
+		return nil
+	}
+
+	go func() {
+		if err := snapshot.NotifyBackupWebhook(webhook, updatedBackup); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to notify webhook for backup %s", updatedBackup.Name))
+		}
+	}()
+
+	return updatedBackup
+}
+
+// handleBackupUnquiesce scales workloads that were quiesced for the backup, or for the backup it was copied from,
+// back up once the fan-out copies are done too.
+// The updated backup is returned when it had to be annotated.
+func handleBackupUnquiesce(veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup) *velerov1.Backup {
+	if !snapshot.NeedsUnquiesce(backup) && !snapshot.IsFanOutBackup(backup) {
+		return nil
+	}
+
+	toUnquiesce, err := snapshot.GetBackupToUnquiesce(context.TODO(), veleroClient, backup)
+	if err != nil {
+		logger.Error(errors.Wrapf(err, "failed to get backup to unquiesce for backup %s", backup.Name))
+		return nil
+	}
+	if toUnquiesce == nil {
+		return nil
+	}
+
+	if err := snapshot.UnquiesceBackup(context.TODO(), toUnquiesce); err != nil {
+		logger.Error(errors.Wrapf(err, "failed to unquiesce workloads for backup %s", toUnquiesce.Name))
+		return nil
+	}
+
+	snapshot.MarkUnquiesced(toUnquiesce)
+	updatedBackup, err := veleroClient.Backups(toUnquiesce.Namespace).Update(context.TODO(), toUnquiesce, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to annotate backup"))
+		return nil
+	}
+
+	if updatedBackup.Name != backup.Name {
+		// the fan-out copy itself wasn't changed
+		return nil
+	}
+	return updatedBackup
+}
@@ -0,0 +1,71 @@
+package license
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"github.com/replicatedhq/kots/pkg/kotsutil"
+	kotspull "github.com/replicatedhq/kots/pkg/pull"
+)
+
+// AppMismatchError is a license for a different app than the one it was uploaded to
+type AppMismatchError struct {
+	AppSlug        string
+	LicenseAppSlug string
+}
+
+func (e AppMismatchError) Error() string {
+	return fmt.Sprintf("license is for app %s, not %s", e.LicenseAppSlug, e.AppSlug)
+}
+
+func IsAppMismatchError(err error) bool {
+	_, ok := errors.Cause(err).(AppMismatchError)
+	return ok
+}
+
+// ValidateLicenseForApp verifies an uploaded license and checks it against the app's current license. A
+// license for another app is an error, a license for another channel of the app only returns a warning.
+// The license is returned as signed, so the channel and the other fields are normalized to what the
+// vendor issued.
+func ValidateLicenseForApp(currentLicense *kotsv1beta1.License, licenseString string) (*kotsv1beta1.License, *licensetypes.ChannelMismatchWarning, error) {
+	unverifiedLicense, err := kotsutil.LoadLicenseFromBytes([]byte(licenseString))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse license")
+	}
+
+	trustedKeys, err := GetTrustedSigningKeys()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get trusted signing keys")
+	}
+
+	verifiedLicense, err := kotspull.VerifySignatureWithKeys(unverifiedLicense, trustedKeys)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to verify license")
+	}
+
+	if verifiedLicense.Spec.AppSlug != currentLicense.Spec.AppSlug {
+		return nil, nil, AppMismatchError{
+			AppSlug:        currentLicense.Spec.AppSlug,
+			LicenseAppSlug: verifiedLicense.Spec.AppSlug,
+		}
+	}
+
+	return verifiedLicense, CheckLicenseChannel(currentLicense, verifiedLicense), nil
+}
+
+// CheckLicenseChannel returns a warning if the license is for a different channel than the current license,
+// or nil if the channel is the same
+func CheckLicenseChannel(currentLicense *kotsv1beta1.License, license *kotsv1beta1.License) *licensetypes.ChannelMismatchWarning {
+	if currentLicense.Spec.ChannelID == license.Spec.ChannelID {
+		return nil
+	}
+
+	return &licensetypes.ChannelMismatchWarning{
+		CurrentChannelID:   currentLicense.Spec.ChannelID,
+		CurrentChannelName: currentLicense.Spec.ChannelName,
+		LicenseChannelID:   license.Spec.ChannelID,
+		LicenseChannelName: license.Spec.ChannelName,
+	}
+}
@@ -0,0 +1,57 @@
+package license
+
+import (
+	"reflect"
+	"testing"
+
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+)
+
+func TestCheckLicenseChannel(t *testing.T) {
+	currentLicense := &kotsv1beta1.License{
+		Spec: kotsv1beta1.LicenseSpec{ChannelID: "stable-id", ChannelName: "Stable"},
+	}
+
+	tests := []struct {
+		name    string
+		license *kotsv1beta1.License
+		want    *licensetypes.ChannelMismatchWarning
+	}{
+		{
+			name: "same channel",
+			license: &kotsv1beta1.License{
+				Spec: kotsv1beta1.LicenseSpec{ChannelID: "stable-id", ChannelName: "Stable"},
+			},
+			want: nil,
+		},
+		{
+			name: "renamed channel",
+			license: &kotsv1beta1.License{
+				Spec: kotsv1beta1.LicenseSpec{ChannelID: "stable-id", ChannelName: "Production"},
+			},
+			want: nil,
+		},
+		{
+			name: "different channel",
+			license: &kotsv1beta1.License{
+				Spec: kotsv1beta1.LicenseSpec{ChannelID: "beta-id", ChannelName: "Beta"},
+			},
+			want: &licensetypes.ChannelMismatchWarning{
+				CurrentChannelID:   "stable-id",
+				CurrentChannelName: "Stable",
+				LicenseChannelID:   "beta-id",
+				LicenseChannelName: "Beta",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := CheckLicenseChannel(currentLicense, test.license)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("CheckLicenseChannel() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package license
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// DiffEntitlements returns the entitlements that were added, removed or changed value between two licenses,
+// sorted by name
+func DiffEntitlements(previous *kotsv1beta1.License, updated *kotsv1beta1.License) []licensetypes.EntitlementChange {
+	names := map[string]bool{}
+	for name := range previous.Spec.Entitlements {
+		names[name] = true
+	}
+	for name := range updated.Spec.Entitlements {
+		names[name] = true
+	}
+
+	changes := []licensetypes.EntitlementChange{}
+	for name := range names {
+		change := licensetypes.EntitlementChange{Name: name}
+
+		previousEntitlement, inPrevious := previous.Spec.Entitlements[name]
+		if inPrevious {
+			change.Title = previousEntitlement.Title
+			change.OldValue = previousEntitlement.Value.Value()
+		}
+		updatedEntitlement, inUpdated := updated.Spec.Entitlements[name]
+		if inUpdated {
+			change.Title = updatedEntitlement.Title
+			change.NewValue = updatedEntitlement.Value.Value()
+		}
+
+		if inPrevious && inUpdated && reflect.DeepEqual(change.OldValue, change.NewValue) {
+			continue
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// recordLicenseChange stores the entitlements that changed in a license sync for the app's license history
+func recordLicenseChange(appID string, previous *kotsv1beta1.License, updated *kotsv1beta1.License, sequence int64) error {
+	change := &licensetypes.LicenseChange{
+		ID:                      strings.ToLower(rand.String(32)),
+		AppID:                   appID,
+		CreatedAt:               time.Now(),
+		PreviousLicenseSequence: previous.Spec.LicenseSequence,
+		LicenseSequence:         updated.Spec.LicenseSequence,
+		Sequence:                sequence,
+		Entitlements:            DiffEntitlements(previous, updated),
+	}
+	if err := store.GetStore().CreateLicenseChange(change); err != nil {
+		return errors.Wrap(err, "failed to create license change")
+	}
+
+	return nil
+}
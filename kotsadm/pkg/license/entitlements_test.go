@@ -0,0 +1,73 @@
+package license
+
+import (
+	"reflect"
+	"testing"
+
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
+	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
+)
+
+func TestDiffEntitlements(t *testing.T) {
+	previous := &kotsv1beta1.License{
+		Spec: kotsv1beta1.LicenseSpec{
+			Entitlements: map[string]kotsv1beta1.EntitlementField{
+				"seats": {
+					Title: "Seats",
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.Int, IntVal: 10},
+				},
+				"tier": {
+					Title: "Tier",
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.String, StrVal: "gold"},
+				},
+				"legacy_feature": {
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.Bool, BoolVal: true},
+				},
+			},
+		},
+	}
+	updated := &kotsv1beta1.License{
+		Spec: kotsv1beta1.LicenseSpec{
+			Entitlements: map[string]kotsv1beta1.EntitlementField{
+				"seats": {
+					Title: "Seats",
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.Int, IntVal: 25},
+				},
+				"tier": {
+					Title: "Tier",
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.String, StrVal: "gold"},
+				},
+				"new_feature": {
+					Title: "New Feature",
+					Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.Bool, BoolVal: true},
+				},
+			},
+		},
+	}
+
+	want := []licensetypes.EntitlementChange{
+		{Name: "legacy_feature", OldValue: true},
+		{Name: "new_feature", Title: "New Feature", NewValue: true},
+		{Name: "seats", Title: "Seats", OldValue: int64(10), NewValue: int64(25)},
+	}
+
+	got := DiffEntitlements(previous, updated)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffEntitlements() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffEntitlementsUnchanged(t *testing.T) {
+	license := &kotsv1beta1.License{
+		Spec: kotsv1beta1.LicenseSpec{
+			Entitlements: map[string]kotsv1beta1.EntitlementField{
+				"seats": {Value: kotsv1beta1.EntitlementValue{Type: kotsv1beta1.Int, IntVal: 10}},
+			},
+		},
+	}
+
+	got := DiffEntitlements(license, license)
+	if len(got) != 0 {
+		t.Errorf("DiffEntitlements() = %+v, want no changes", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/pkg/errors"
 	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/preflight"
 	"github.com/replicatedhq/kots/kotsadm/pkg/render"
 	"github.com/replicatedhq/kots/kotsadm/pkg/store"
@@ -17,10 +18,35 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 )
 
-func Sync(a *apptypes.App, licenseString string, failOnVersionCreate bool) (*kotsv1beta1.License, error) {
+// PreflightPolicy is what a license sync does when the preflights for the version it creates fail to run
+type PreflightPolicy string
+
+const (
+	// PreflightPolicyBlock returns the failure as the sync's error. Preflights run against the new version, so the
+	// license and version have already been saved by then, only the result the caller sees changes.
+	PreflightPolicyBlock PreflightPolicy = "block"
+	// PreflightPolicyWarn completes the sync and returns the failure for the caller to surface
+	PreflightPolicyWarn PreflightPolicy = "warn"
+)
+
+// SyncPreflightResult is how running preflights went for the version created by a license sync
+type SyncPreflightResult struct {
+	Sequence int64
+	// Error is why preflights could not be run, empty if they were started
+	Error string
+}
+
+// Sync updates the app's license, from licenseString or from the api if it's empty, and creates a new app version
+// if the license changed. The preflight result is nil if no version was created.
+func Sync(a *apptypes.App, licenseString string, failOnVersionCreate bool, preflightPolicy PreflightPolicy) (*kotsv1beta1.License, *SyncPreflightResult, error) {
 	currentLicense, err := store.GetStore().GetLatestLicenseForApp(a.ID)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get current license")
+		return nil, nil, errors.Wrap(err, "failed to get current license")
+	}
+
+	trustedKeys, err := GetTrustedSigningKeys()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get trusted signing keys")
 	}
 
 	var updatedLicense *kotsv1beta1.License
@@ -28,13 +54,13 @@ func Sync(a *apptypes.App, licenseString string, failOnVersionCreate bool) (*kot
 		decode := scheme.Codecs.UniversalDeserializer().Decode
 		obj, _, err := decode([]byte(licenseString), nil, nil)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse license")
+			return nil, nil, errors.Wrap(err, "failed to parse license")
 		}
 
 		unverifiedLicense := obj.(*kotsv1beta1.License)
-		verifiedLicense, err := kotspull.VerifySignature(unverifiedLicense)
+		verifiedLicense, err := kotspull.VerifySignatureWithKeys(unverifiedLicense, trustedKeys)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to verify license")
+			return nil, nil, errors.Wrap(err, "failed to verify license")
 		}
 
 		updatedLicense = verifiedLicense
@@ -42,36 +68,57 @@ func Sync(a *apptypes.App, licenseString string, failOnVersionCreate bool) (*kot
 		// get from the api
 		licenseData, err := kotslicense.GetLatestLicense(currentLicense)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to get latest license")
+			return nil, nil, errors.Wrap(err, "failed to get latest license")
 		}
 		updatedLicense = licenseData.License
 		licenseString = string(licenseData.LicenseBytes)
+
+		if trustedKeys != nil {
+			// licenses from the api are trusted as is, unless the signing keys are pinned
+			verifiedLicense, err := kotspull.VerifySignatureWithKeys(updatedLicense, trustedKeys)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "failed to verify latest license")
+			}
+			updatedLicense = verifiedLicense
+		}
 	}
 
+	var preflightResult *SyncPreflightResult
+
 	// Save and make a new version if the sequence has changed
 	if updatedLicense.Spec.LicenseSequence != currentLicense.Spec.LicenseSequence {
 		archiveDir, err := ioutil.TempDir("", "kotsadm")
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to create temp dir")
+			return nil, nil, errors.Wrap(err, "failed to create temp dir")
 		}
 		defer os.RemoveAll(archiveDir)
 
 		err = store.GetStore().GetAppVersionArchive(a.ID, a.CurrentSequence, archiveDir)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to get latest app version")
+			return nil, nil, errors.Wrap(err, "failed to get latest app version")
 		}
 
 		newSequence, err := store.GetStore().UpdateAppLicense(a.ID, a.CurrentSequence, archiveDir, updatedLicense, licenseString, failOnVersionCreate, &version.DownstreamGitOps{}, &render.Renderer{})
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to update license")
+			return nil, nil, errors.Wrap(err, "failed to update license")
+		}
+
+		// the license has already been updated, so failing to record the change is only logged
+		if err := recordLicenseChange(a.ID, currentLicense, updatedLicense, newSequence); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to record license change for app %s", a.Slug))
 		}
 
+		preflightResult = &SyncPreflightResult{Sequence: newSequence}
 		if err := preflight.Run(a.ID, a.Slug, newSequence, a.IsAirgap, archiveDir); err != nil {
-			return nil, errors.Wrap(err, "failed to run preflights")
+			if preflightPolicy != PreflightPolicyWarn {
+				return nil, nil, errors.Wrap(err, "failed to run preflights")
+			}
+			logger.Error(errors.Wrapf(err, "failed to run preflights for app %s sequence %d after license sync", a.Slug, newSequence))
+			preflightResult.Error = err.Error()
 		}
 	}
 
-	return updatedLicense, nil
+	return updatedLicense, preflightResult, nil
 }
 
 // Gets the license as it was at a given app sequence
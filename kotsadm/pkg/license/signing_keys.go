@@ -0,0 +1,55 @@
+package license
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/k8s"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// trustedSigningKeysSecretName is an optional secret in the kotsadm namespace that pins the keys licenses must be
+// signed with. Each key in the secret is a global key id, with the PEM encoded public key as its value.
+const trustedSigningKeysSecretName = "kotsadm-license-signing-keys"
+
+// GetTrustedSigningKeys returns the pinned license signing keys by key id, or nil if none are pinned and the
+// built-in keys are trusted
+func GetTrustedSigningKeys() (map[string][]byte, error) {
+	clientset, err := k8s.Clientset()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get k8s clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(os.Getenv("POD_NAMESPACE")).Get(context.TODO(), trustedSigningKeysSecretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get trusted signing keys secret")
+	}
+
+	return parseTrustedSigningKeys(secret.Data)
+}
+
+func parseTrustedSigningKeys(data map[string][]byte) (map[string][]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.Errorf("secret %s has no keys", trustedSigningKeysSecretName)
+	}
+
+	trustedKeys := map[string][]byte{}
+	for keyID, keyPEM := range data {
+		block, _ := pem.Decode(keyPEM)
+		if block == nil {
+			return nil, errors.Errorf("key %s is not PEM encoded", keyID)
+		}
+		if _, err := x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse key %s", keyID)
+		}
+		trustedKeys[keyID] = keyPEM
+	}
+
+	return trustedKeys, nil
+}
@@ -0,0 +1,68 @@
+package license
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
+	"testing"
+)
+
+func TestParseTrustedSigningKeys(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	tests := []struct {
+		name    string
+		data    map[string][]byte
+		want    map[string][]byte
+		wantErr bool
+	}{
+		{
+			name: "valid key",
+			data: map[string][]byte{"key-id": publicKeyPEM},
+			want: map[string][]byte{"key-id": publicKeyPEM},
+		},
+		{
+			name:    "empty secret",
+			data:    map[string][]byte{},
+			wantErr: true,
+		},
+		{
+			name:    "not PEM encoded",
+			data:    map[string][]byte{"key-id": []byte("not a key")},
+			wantErr: true,
+		},
+		{
+			name:    "not a public key",
+			data:    map[string][]byte{"key-id": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("garbage")})},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseTrustedSigningKeys(test.data)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseTrustedSigningKeys() expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTrustedSigningKeys() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseTrustedSigningKeys() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
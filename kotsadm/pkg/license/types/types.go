@@ -0,0 +1,37 @@
+package types
+
+import (
+	"time"
+)
+
+// LicenseChange records the entitlements that changed when a license sync updated an app's license
+type LicenseChange struct {
+	ID        string    `json:"id"`
+	AppID     string    `json:"appId"`
+	CreatedAt time.Time `json:"createdAt"`
+	// PreviousLicenseSequence and LicenseSequence are the license sequences before and after the sync
+	PreviousLicenseSequence int64 `json:"previousLicenseSequence"`
+	LicenseSequence         int64 `json:"licenseSequence"`
+	// Sequence is the app version created with the new license
+	Sequence     int64               `json:"sequence"`
+	Entitlements []EntitlementChange `json:"entitlements"`
+}
+
+// EntitlementChange is an entitlement that was added, removed or changed value
+type EntitlementChange struct {
+	Name  string `json:"name"`
+	Title string `json:"title,omitempty"`
+	// OldValue is nil if the entitlement was added
+	OldValue interface{} `json:"oldValue"`
+	// NewValue is nil if the entitlement was removed
+	NewValue interface{} `json:"newValue"`
+}
+
+// ChannelMismatchWarning is a license for a different channel than the app's current license. Switching
+// channels is sometimes intended, so it is only a warning.
+type ChannelMismatchWarning struct {
+	CurrentChannelID   string `json:"currentChannelId"`
+	CurrentChannelName string `json:"currentChannelName"`
+	LicenseChannelID   string `json:"licenseChannelId"`
+	LicenseChannelName string `json:"licenseChannelName"`
+}
@@ -112,6 +112,14 @@ func UpdateCollectorSpecsWithRegistryData(collectors []*troubleshootv1beta2.Coll
 	return updatedCollectors, nil
 }
 
+// RewriteImage points the image at the registry kotsadm is configured to use, if there is one
+func RewriteImage(registrySettings *types.RegistrySettings, image string) string {
+	if registrySettings == nil || registrySettings.Hostname == "" {
+		return image
+	}
+	return rewriteImage(registrySettings.Hostname, registrySettings.Namespace, image)
+}
+
 func rewriteImage(newHost string, newNamespace string, image string) string {
 	imageParts := strings.Split(image, "/")
 	imageNameWithOptionalTag := imageParts[len(imageParts)-1]
@@ -63,6 +63,7 @@ func Parse(kotsStore store.KOTSStore, signedToken string) (*types.Session, error
 
 		s := types.Session{
 			ID:        "kots-cli",
+			UserID:    "kots-cli",
 			IssuedAt:  time.Now(),
 			ExpiresAt: time.Now().Add(time.Minute),
 			// TODO: super user permissions
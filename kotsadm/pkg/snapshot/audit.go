@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const auditRedacted = "--- REDACTED ---"
+
+// auditRedactedFields are matched against the last part of a changed field's path, lowercased
+var auditRedactedFields = []string{"secret", "password", "jsonfile", "encryptionkey", "token"}
+
+// AuditState returns a copy of the settings to diff once they've been changed. The settings are anything that
+// marshals to a json object.
+func AuditState(settings interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal")
+	}
+
+	state := map[string]interface{}{}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return state, nil
+}
+
+// RecordAuditEvent saves an audit event with the redacted changes between two states from AuditState.
+// Nothing is recorded when nothing changed.
+func RecordAuditEvent(actor string, action string, appID string, before map[string]interface{}, after map[string]interface{}) error {
+	changes := diffAuditStates(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	event := &types.SnapshotAuditEvent{
+		ID:        strings.ToLower(rand.String(32)),
+		CreatedAt: time.Now(),
+		Actor:     actor,
+		Action:    action,
+		AppID:     appID,
+		Changes:   changes,
+	}
+	if err := store.GetStore().CreateSnapshotAuditEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create snapshot audit event")
+	}
+
+	return nil
+}
+
+func diffAuditStates(before map[string]interface{}, after map[string]interface{}) []types.SnapshotAuditChange {
+	beforeFields := map[string]interface{}{}
+	flattenAuditState("", before, beforeFields)
+	afterFields := map[string]interface{}{}
+	flattenAuditState("", after, afterFields)
+
+	fields := map[string]bool{}
+	for field := range beforeFields {
+		fields[field] = true
+	}
+	for field := range afterFields {
+		fields[field] = true
+	}
+
+	changes := []types.SnapshotAuditChange{}
+	for field := range fields {
+		oldValue, newValue := beforeFields[field], afterFields[field]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if isAuditRedactedField(field) {
+			if oldValue != nil {
+				oldValue = auditRedacted
+			}
+			if newValue != nil {
+				newValue = auditRedacted
+			}
+		}
+		changes = append(changes, types.SnapshotAuditChange{
+			Field: field,
+			Old:   oldValue,
+			New:   newValue,
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+func flattenAuditState(prefix string, value interface{}, fields map[string]interface{}) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flattenAuditState(join(key), child, fields)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenAuditState(join(strconv.Itoa(i)), child, fields)
+		}
+	case nil:
+		// a missing value and null are the same change
+	default:
+		fields[prefix] = v
+	}
+}
+
+func isAuditRedactedField(field string) bool {
+	parts := strings.Split(field, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		// list indexes say nothing about the field
+		if _, err := strconv.Atoi(parts[i]); err == nil {
+			continue
+		}
+		name := strings.ToLower(parts[i])
+		for _, redacted := range auditRedactedFields {
+			if strings.Contains(name, redacted) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestDiffAuditStates(t *testing.T) {
+	before, err := AuditState(types.Store{
+		Provider: "aws",
+		Bucket:   "snapshots",
+		AWS: &types.StoreAWS{
+			Region:          "us-east-1",
+			AccessKeyID:     "AKIA1",
+			SecretAccessKey: "secret1",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	after, err := AuditState(types.Store{
+		Provider: "aws",
+		Bucket:   "snapshots",
+		Path:     "kots",
+		AWS: &types.StoreAWS{
+			Region:          "us-east-1",
+			AccessKeyID:     "AKIA2",
+			SecretAccessKey: "secret2",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []types.SnapshotAuditChange{
+		{Field: "aws.accessKeyID", Old: "AKIA1", New: "AKIA2"},
+		{Field: "aws.secretAccessKey", Old: auditRedacted, New: auditRedacted},
+		{Field: "path", Old: "", New: "kots"},
+	}
+	got := diffAuditStates(before, after)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+}
+
+func TestDiffAuditStatesLists(t *testing.T) {
+	before := map[string]interface{}{
+		"includedNamespaces": []interface{}{"app"},
+	}
+	after := map[string]interface{}{
+		"includedNamespaces": []interface{}{"app", "db"},
+		"hookSettings": map[string]interface{}{
+			"webhookTokens": []interface{}{"abc"},
+		},
+	}
+
+	want := []types.SnapshotAuditChange{
+		{Field: "hookSettings.webhookTokens.0", Old: nil, New: auditRedacted},
+		{Field: "includedNamespaces.1", Old: nil, New: "db"},
+	}
+	got := diffAuditStates(before, after)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+}
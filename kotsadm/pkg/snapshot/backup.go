@@ -28,10 +28,36 @@ import (
 	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
 func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled bool) (*velerov1.Backup, error) {
+	snapshotTrigger := "manual"
+	if isScheduled {
+		snapshotTrigger = "schedule"
+	}
+	return createApplicationBackup(ctx, a, snapshotTrigger)
+}
+
+// RunApplicationScheduleNow creates the backup the app's schedule would create, without waiting for the schedule.
+// The pending scheduled snapshot is left as is.
+func RunApplicationScheduleNow(ctx context.Context, a *apptypes.App) (*velerov1.Backup, error) {
+	if a.SnapshotSchedule == "" {
+		return nil, errors.New("app does not have a snapshot schedule")
+	}
+	return createApplicationBackup(ctx, a, "schedule-manual")
+}
+
+func createApplicationBackup(ctx context.Context, a *apptypes.App, snapshotTrigger string) (*velerov1.Backup, error) {
+	// held until the backup is created, or counted as quiescing, so that it's seen by the next check
+	unlock := lockAppBackups(a.ID)
+	defer unlock()
+
+	if err := CheckAppBackupConcurrency(a); err != nil {
+		return nil, err
+	}
+
 	downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list downstreams for app")
@@ -100,11 +126,6 @@ func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled b
 		includedNamespaces = append(includedNamespaces, os.Getenv("POD_NAMESPACE"))
 	}
 
-	snapshotTrigger := "manual"
-	if isScheduled {
-		snapshotTrigger = "schedule"
-	}
-
 	veleroBackup.Name = ""
 	veleroBackup.GenerateName = a.Slug + "-"
 
@@ -115,6 +136,10 @@ func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled b
 		"kots.io/app-sequence":       strconv.FormatInt(parentSequence, 10),
 		"kots.io/snapshot-requested": time.Now().UTC().Format(time.RFC3339),
 	}
+	isScheduled := snapshotTrigger != "manual"
+	if isScheduled {
+		veleroBackup.Annotations["kots.io/snapshot-schedule"] = a.SnapshotSchedule
+	}
 
 	labelSelector := metav1.LabelSelector{
 		MatchLabels: map[string]string{
@@ -126,12 +151,31 @@ func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled b
 	}
 	veleroBackup.Spec.LabelSelector = &labelSelector
 
-	veleroBackup.Spec.IncludedNamespaces = includedNamespaces
+	veleroBackup.Spec.IncludedNamespaces, veleroBackup.Spec.ExcludedNamespaces = appBackupNamespaces(includedNamespaces, a.SnapshotIncludedNamespaces, a.SnapshotExcludedNamespaces)
 
 	veleroBackup.Spec.StorageLocation = "default"
 
-	if a.SnapshotTTL != "" {
-		ttlDuration, err := time.ParseDuration(a.SnapshotTTL)
+	if a.SnapshotDefaultVolumesToRestic != nil {
+		veleroBackup.Spec.DefaultVolumesToRestic = a.SnapshotDefaultVolumesToRestic
+	}
+
+	if err := applyHookSettings(&veleroBackup.Spec.Hooks, a.SnapshotHookSettings); err != nil {
+		return nil, errors.Wrap(err, "failed to apply hook settings")
+	}
+
+	snapshotTTL := a.SnapshotTTL
+	if isScheduled && a.SnapshotScheduleTTL != "" {
+		snapshotTTL = a.SnapshotScheduleTTL
+	}
+	if snapshotTTL == "" {
+		defaults, err := GetSnapshotDefaults()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get snapshot defaults")
+		}
+		snapshotTTL = defaults.TTL
+	}
+	if snapshotTTL != "" {
+		ttlDuration, err := time.ParseDuration(snapshotTTL)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to parse app snapshot ttl value as duration")
 		}
@@ -150,9 +194,48 @@ func CreateApplicationBackup(ctx context.Context, a *apptypes.App, isScheduled b
 		return nil, errors.Wrap(err, "failed to create clientset")
 	}
 
-	backup, err := veleroClient.Backups(kotsadmVeleroBackendStorageLocation.Namespace).Create(ctx, veleroBackup, metav1.CreateOptions{})
+	if err := excludePVCsFromBackup(ctx, veleroBackup.Spec.IncludedNamespaces, a.SnapshotExcludedPVCs); err != nil {
+		return nil, errors.Wrap(err, "failed to exclude pvcs from backup")
+	}
+
+	if err := applyResticPodSelector(ctx, veleroBackup, a.SnapshotResticPodSelector, a.SnapshotExcludedPVCs); err != nil {
+		return nil, errors.Wrap(err, "failed to apply restic pod selector")
+	}
+
+	resourceFilter, err := GetBackupResourceFilter()
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create velero backup")
+		return nil, errors.Wrap(err, "failed to get backup resource filter")
+	}
+	applyBackupResourceFilter(veleroBackup, resourceFilter)
+
+	if len(a.SnapshotChecksumTargets) > 0 {
+		entries, err := recordChecksums(ctx, appNamespace, a.SnapshotChecksumTargets)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to record checksums")
+		}
+		if err := setChecksumManifest(veleroBackup, entries); err != nil {
+			return nil, errors.Wrap(err, "failed to set checksum manifest")
+		}
+	}
+
+	var backup *velerov1.Backup
+	if len(a.SnapshotQuiesceActions) > 0 {
+		// the workloads record the name of the backup they're quiesced for before it's created
+		veleroBackup.Name = veleroBackup.GenerateName + rand.String(5)
+		veleroBackup.GenerateName = ""
+		backup, err = quiesceAndCreateBackup(veleroClient, a.ID, appNamespace, veleroBackup, a.SnapshotQuiesceActions)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to quiesce and create velero backup")
+		}
+	} else {
+		backup, err = veleroClient.Backups(kotsadmVeleroBackendStorageLocation.Namespace).Create(ctx, veleroBackup, metav1.CreateOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create velero backup")
+		}
+	}
+
+	if isScheduled && len(a.SnapshotFanOutLocations) > 0 {
+		createFanOutBackups(ctx, veleroClient, backup, a.SnapshotFanOutLocations)
 	}
 
 	return backup, nil
@@ -171,80 +254,26 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 		kotsadmNamespace = os.Getenv("KOTSADM_TARGET_NAMESPACE")
 	}
 
+	backupApps, err := listInstanceBackupApps(apps)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list apps to back up")
+	}
+
 	appsSequences := map[string]int64{}
-	includedNamespaces := []string{kotsadmNamespace}
 	labelSelector := metav1.LabelSelector{
 		MatchLabels: map[string]string{
 			kotsadmtypes.BackupLabel: kotsadmtypes.BackupLabelValue,
 		},
 	}
-
-	for _, a := range apps {
-		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to list downstreams for app %s", a.Slug)
-		}
-
-		if len(downstreams) == 0 {
-			logger.Error(errors.Wrapf(err, "no downstreams found for app %s", a.Slug))
-			continue
-		}
-
-		parentSequence, err := downstream.GetCurrentParentSequence(a.ID, downstreams[0].ClusterID)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get current downstream parent sequence for app %s", a.Slug)
-		}
-		if parentSequence == -1 {
-			// no version is deployed for this app yet
-			continue
-		}
-		appsSequences[a.Slug] = parentSequence
-
-		archiveDir, err := ioutil.TempDir("", "kotsadm")
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to create temp dir for app %s", a.Slug)
-		}
-		defer os.RemoveAll(archiveDir)
-
-		err = store.GetStore().GetAppVersionArchive(a.ID, parentSequence, archiveDir)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to get app version archive for app %s", a.Slug)
-		}
-
-		kotsKinds, err := kotsutil.LoadKotsKindsFromPath(archiveDir)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to load kots kinds from path")
-		}
-
-		backupSpec, err := kotsKinds.Marshal("velero.io", "v1", "Backup")
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to get backup spec from kotskinds")
-		}
-
-		renderedBackup, err := helper.RenderAppFile(a, nil, []byte(backupSpec), kotsKinds)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to render backup")
+	for _, backupApp := range backupApps {
+		appsSequences[backupApp.slug] = backupApp.sequence
+		if backupApp.labelSelector != nil {
+			labelSelector = mergeLabelSelector(labelSelector, *backupApp.labelSelector)
 		}
-		veleroBackup, err := kotsutil.LoadBackupFromContents(renderedBackup)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to load backup from contents")
-		}
-
-		if veleroBackup.Spec.LabelSelector != nil {
-			labelSelector = mergeLabelSelector(labelSelector, *veleroBackup.Spec.LabelSelector)
-		}
-
-		includedNamespaces = append(includedNamespaces, kotsKinds.KotsApplication.Spec.AdditionalNamespaces...)
 	}
 
 	isKurl := kurl.IsKurl()
-	if isKurl {
-		includedNamespaces = append(includedNamespaces, "kurl")
-	}
-
-	if os.Getenv("KOTSADM_ENV") == "dev" {
-		includedNamespaces = append(includedNamespaces, os.Getenv("POD_NAMESPACE"))
-	}
+	includedNamespaces := instanceBackupNamespaces(kotsadmNamespace, backupApps, isKurl)
 
 	kotsadmVeleroBackendStorageLocation, err := FindBackupStoreLocation()
 	if err != nil {
@@ -295,6 +324,12 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 			return nil, errors.Wrap(err, "failed to get kurl registry host")
 		}
 		veleroBackup.ObjectMeta.Annotations["kots.io/kurl-registry"] = registryHost
+
+		if cluster.SnapshotIncludeRegistryData {
+			if err := includeKurlRegistry(ctx, veleroBackup); err != nil {
+				return nil, errors.Wrap(err, "failed to include kurl registry")
+			}
+		}
 	}
 
 	if cluster.SnapshotTTL != "" {
@@ -307,6 +342,21 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 		}
 	}
 
+	if err := applyClusterResourceFilters(veleroBackup, cluster.SnapshotIncludedClusterResources, cluster.SnapshotExcludedClusterResources); err != nil {
+		return nil, errors.Wrap(err, "failed to apply cluster resource filters")
+	}
+
+	resourceFilter, err := GetBackupResourceFilter()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup resource filter")
+	}
+	// the admin console can only be restored from an instance backup that has all of its resources, so only the exclusions apply
+	applyBackupResourceFilter(veleroBackup, &types.BackupResourceFilter{ExcludedResources: resourceFilter.ExcludedResources})
+
+	if err := excludePVCsFromBackup(ctx, veleroBackup.Spec.IncludedNamespaces, cluster.SnapshotExcludedPVCs); err != nil {
+		return nil, errors.Wrap(err, "failed to exclude pvcs from backup")
+	}
+
 	cfg, err := config.GetConfig()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get cluster config")
@@ -322,9 +372,103 @@ func CreateInstanceBackup(ctx context.Context, cluster *downstreamtypes.Downstre
 		return nil, errors.Wrap(err, "failed to create velero backup")
 	}
 
+	if isScheduled && len(cluster.SnapshotFanOutLocations) > 0 {
+		createFanOutBackups(ctx, veleroClient, backup, cluster.SnapshotFanOutLocations)
+	}
+
 	return backup, nil
 }
 
+// instanceBackupApp is what an instance backup takes from an app's deployed version
+type instanceBackupApp struct {
+	slug                 string
+	sequence             int64
+	additionalNamespaces []string
+	labelSelector        *metav1.LabelSelector
+}
+
+// listInstanceBackupApps loads the deployed version of each app, apps that don't have one aren't backed up
+func listInstanceBackupApps(apps []*apptypes.App) ([]instanceBackupApp, error) {
+	backupApps := []instanceBackupApp{}
+	for _, a := range apps {
+		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list downstreams for app %s", a.Slug)
+		}
+
+		if len(downstreams) == 0 {
+			logger.Error(errors.Wrapf(err, "no downstreams found for app %s", a.Slug))
+			continue
+		}
+
+		parentSequence, err := downstream.GetCurrentParentSequence(a.ID, downstreams[0].ClusterID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get current downstream parent sequence for app %s", a.Slug)
+		}
+		if parentSequence == -1 {
+			// no version is deployed for this app yet
+			continue
+		}
+
+		archiveDir, err := ioutil.TempDir("", "kotsadm")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create temp dir for app %s", a.Slug)
+		}
+		defer os.RemoveAll(archiveDir)
+
+		err = store.GetStore().GetAppVersionArchive(a.ID, parentSequence, archiveDir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get app version archive for app %s", a.Slug)
+		}
+
+		kotsKinds, err := kotsutil.LoadKotsKindsFromPath(archiveDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load kots kinds from path")
+		}
+
+		backupSpec, err := kotsKinds.Marshal("velero.io", "v1", "Backup")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup spec from kotskinds")
+		}
+
+		renderedBackup, err := helper.RenderAppFile(a, nil, []byte(backupSpec), kotsKinds)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to render backup")
+		}
+		veleroBackup, err := kotsutil.LoadBackupFromContents(renderedBackup)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load backup from contents")
+		}
+
+		backupApps = append(backupApps, instanceBackupApp{
+			slug:                 a.Slug,
+			sequence:             parentSequence,
+			additionalNamespaces: kotsKinds.KotsApplication.Spec.AdditionalNamespaces,
+			labelSelector:        veleroBackup.Spec.LabelSelector,
+		})
+	}
+
+	return backupApps, nil
+}
+
+// instanceBackupNamespaces returns the namespaces an instance backup of the apps includes
+func instanceBackupNamespaces(kotsadmNamespace string, backupApps []instanceBackupApp, isKurl bool) []string {
+	includedNamespaces := []string{kotsadmNamespace}
+	for _, backupApp := range backupApps {
+		includedNamespaces = append(includedNamespaces, backupApp.additionalNamespaces...)
+	}
+
+	if isKurl {
+		includedNamespaces = append(includedNamespaces, "kurl")
+	}
+
+	if os.Getenv("KOTSADM_ENV") == "dev" {
+		includedNamespaces = append(includedNamespaces, os.Getenv("POD_NAMESPACE"))
+	}
+
+	return includedNamespaces
+}
+
 func ListBackupsForApp(appID string) ([]*types.Backup, error) {
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -391,6 +535,8 @@ func ListBackupsForApp(appID string) ([]*types.Backup, error) {
 			backup.SupportBundleID = supportBundleID
 		}
 
+		backup.FanOutOf = veleroBackup.Annotations[fanOutOfAnnotation]
+
 		volumeCount, volumeCountOk := veleroBackup.Annotations["kots.io/snapshot-volume-count"]
 		if volumeCountOk {
 			i, err := strconv.Atoi(volumeCount)
@@ -654,8 +800,8 @@ func DeleteBackup(snapshotName string) error {
 	return nil
 }
 
-func HasUnfinishedApplicationBackup(appID string) (bool, error) {
-	backups, err := ListBackupsForApp(appID)
+func HasUnfinishedInstanceBackup() (bool, error) {
+	backups, err := ListInstanceBackups()
 	if err != nil {
 		return false, errors.Wrap(err, "failed to list backups")
 	}
@@ -669,19 +815,202 @@ func HasUnfinishedApplicationBackup(appID string) (bool, error) {
 	return false, nil
 }
 
-func HasUnfinishedInstanceBackup() (bool, error) {
-	backups, err := ListInstanceBackups()
+// CountRunningScheduledBackups returns the number of backups started by the snapshot scheduler, for any app
+// or the instance, that haven't finished yet
+func CountRunningScheduledBackups() (int, error) {
+	cfg, err := config.GetConfig()
 	if err != nil {
-		return false, errors.Wrap(err, "failed to list backups")
+		return 0, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroBackups, err := veleroClient.Backups(backendStorageLocation.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list velero backups")
 	}
 
+	return countRunningScheduledBackups(veleroBackups.Items), nil
+}
+
+// countRunningScheduledBackups counts fan-out copies with the scheduled backup they were copied from, which is
+// running until its copies are done too
+func countRunningScheduledBackups(backups []velerov1.Backup) int {
+	running := map[string]bool{}
 	for _, backup := range backups {
-		if backup.Status == "New" || backup.Status == "InProgress" {
-			return true, nil
+		if backup.Annotations["kots.io/snapshot-trigger"] != "schedule" {
+			continue
+		}
+		switch backup.Status.Phase {
+		case "", velerov1.BackupPhaseNew, velerov1.BackupPhaseInProgress:
+			name := backup.Name
+			if fanOutOf, ok := backup.Annotations[fanOutOfAnnotation]; ok {
+				name = fanOutOf
+			}
+			running[name] = true
 		}
 	}
+	return len(running)
+}
 
-	return false, nil
+// FindStuckBackups returns the backups in the namespace that have not reached a terminal phase
+// within maxAge of being started, which usually means the velero or restic pod working on them died
+func FindStuckBackups(ctx context.Context, namespace string, maxAge time.Duration) ([]velerov1.Backup, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backups, err := veleroClient.Backups(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	stuckBackups := []velerov1.Backup{}
+	now := time.Now()
+	for _, backup := range backups.Items {
+		if isBackupStuck(backup, maxAge, now) {
+			stuckBackups = append(stuckBackups, backup)
+		}
+	}
+
+	return stuckBackups, nil
+}
+
+func isBackupStuck(backup velerov1.Backup, maxAge time.Duration, now time.Time) bool {
+	switch backup.Status.Phase {
+	case "", velerov1.BackupPhaseNew, velerov1.BackupPhaseInProgress:
+	default:
+		return false
+	}
+
+	startedAt := backup.CreationTimestamp.Time
+	if backup.Status.StartTimestamp != nil {
+		startedAt = backup.Status.StartTimestamp.Time
+	}
+
+	return now.Sub(startedAt) > maxAge
+}
+
+// MarkBackupFailed sets the phase of a backup that will never complete to failed so that it no longer
+// blocks new backups from being taken
+func MarkBackupFailed(ctx context.Context, namespace string, backupName string, reason string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	backup, err := veleroClient.Backups(namespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get backup")
+	}
+
+	backup.Status.Phase = velerov1.BackupPhaseFailed
+	backup.Status.FailureReason = reason
+	backup.Status.CompletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	if _, err := veleroClient.Backups(namespace).Update(ctx, backup, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update backup")
+	}
+
+	return nil
+}
+
+// GetBackupProgress returns how far along a backup is based on the items and restic volume bytes velero has backed up
+func GetBackupProgress(ctx context.Context, backupName string) (*types.BackupProgress, error) {
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup")
+	}
+
+	backupVolumes, err := veleroClient.PodVolumeBackups(veleroNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("velero.io/backup-name=%s", velerolabel.GetValidName(backupName)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	return backupProgress(backup, backupVolumes.Items), nil
+}
+
+func backupProgress(backup *velerov1.Backup, backupVolumes []velerov1.PodVolumeBackup) *types.BackupProgress {
+	progress := &types.BackupProgress{
+		Name:  backup.Name,
+		Phase: string(backup.Status.Phase),
+	}
+	if backup.Status.Progress != nil {
+		progress.TotalItems = backup.Status.Progress.TotalItems
+		progress.ItemsBackedUp = backup.Status.Progress.ItemsBackedUp
+	}
+	for _, backupVolume := range backupVolumes {
+		progress.VolumeTotalBytes += backupVolume.Status.Progress.TotalBytes
+		progress.VolumeBytesDone += backupVolume.Status.Progress.BytesDone
+	}
+	progress.CompletionPercent = backupCompletionPercent(progress)
+
+	return progress
+}
+
+// backupCompletionPercent averages item and volume progress, since volume data is usually the bulk of a backup
+func backupCompletionPercent(progress *types.BackupProgress) int {
+	switch velerov1.BackupPhase(progress.Phase) {
+	case velerov1.BackupPhaseCompleted, velerov1.BackupPhasePartiallyFailed, velerov1.BackupPhaseFailed:
+		return 100
+	}
+
+	fractions := []float64{}
+	if progress.TotalItems > 0 {
+		fractions = append(fractions, float64(progress.ItemsBackedUp)/float64(progress.TotalItems))
+	}
+	if progress.VolumeTotalBytes > 0 {
+		fractions = append(fractions, float64(progress.VolumeBytesDone)/float64(progress.VolumeTotalBytes))
+	}
+	if len(fractions) == 0 {
+		return 0
+	}
+
+	total := float64(0)
+	for _, fraction := range fractions {
+		total += fraction
+	}
+	// do not report 100 until velero marks the backup as done
+	return int(math.Min(99, math.Floor(total/float64(len(fractions))*100)))
 }
 
 func GetBackupDetail(ctx context.Context, backupName string) (*types.BackupDetail, error) {
@@ -0,0 +1,103 @@
+package snapshot
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+// DefaultMaxConcurrentAppBackups is the number of backups an app can have running at once when it hasn't set
+// its own limit. Running backups of the same app at once runs its quiesce hooks twice.
+const DefaultMaxConcurrentAppBackups = 1
+
+var (
+	// appBackupLocks serialize checking an app's backup concurrency and creating its backup
+	appBackupLocks   = map[string]*sync.Mutex{}
+	appBackupLocksMu sync.Mutex
+)
+
+// BackupInProgressError is returned when an app already has as many backups running as it allows
+type BackupInProgressError struct {
+	AppSlug string
+	Running int
+	Max     int
+}
+
+func (e BackupInProgressError) Error() string {
+	return fmt.Sprintf("backup already in progress for app %s (%d of %d running)", e.AppSlug, e.Running, e.Max)
+}
+
+// IsBackupInProgressError returns true if the cause of the error is a BackupInProgressError
+func IsBackupInProgressError(err error) bool {
+	_, ok := errors.Cause(err).(BackupInProgressError)
+	return ok
+}
+
+// ValidateMaxConcurrentAppBackups checks an app's backup concurrency limit. 0 restores the default.
+func ValidateMaxConcurrentAppBackups(maxConcurrent int) error {
+	if maxConcurrent < 0 {
+		return errors.New("max concurrent backups cannot be negative")
+	}
+	return nil
+}
+
+// MaxConcurrentAppBackups returns how many backups of the app can run at once
+func MaxConcurrentAppBackups(a *apptypes.App) int {
+	if a.SnapshotMaxConcurrentBackups <= 0 {
+		return DefaultMaxConcurrentAppBackups
+	}
+	return a.SnapshotMaxConcurrentBackups
+}
+
+// lockAppBackups holds the app's backup lock until the returned func is called, so that two backups of the app
+// can't both pass CheckAppBackupConcurrency before either is created
+func lockAppBackups(appID string) func() {
+	appBackupLocksMu.Lock()
+	lock, ok := appBackupLocks[appID]
+	if !ok {
+		lock = &sync.Mutex{}
+		appBackupLocks[appID] = lock
+	}
+	appBackupLocksMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// CheckAppBackupConcurrency returns a BackupInProgressError if the app can't start another backup yet. Backups are
+// created holding the app's backup lock, so the result is only final there.
+func CheckAppBackupConcurrency(a *apptypes.App) error {
+	backups, err := ListBackupsForApp(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list backups")
+	}
+
+	// backups still quiescing workloads haven't been created yet
+	running := countUnfinishedBackups(backups) + countQuiescingBackups(a.ID)
+	maxConcurrent := MaxConcurrentAppBackups(a)
+	if running >= maxConcurrent {
+		return BackupInProgressError{AppSlug: a.Slug, Running: running, Max: maxConcurrent}
+	}
+
+	return nil
+}
+
+// countUnfinishedBackups counts the snapshots that haven't finished. Fan-out copies are part of the snapshot they
+// were copied from, which is running until its copies are done too.
+func countUnfinishedBackups(backups []*types.Backup) int {
+	unfinished := map[string]bool{}
+	for _, backup := range backups {
+		switch backup.Status {
+		case "", "New", "InProgress":
+			name := backup.Name
+			if backup.FanOutOf != "" {
+				name = backup.FanOutOf
+			}
+			unfinished[name] = true
+		}
+	}
+	return len(unfinished)
+}
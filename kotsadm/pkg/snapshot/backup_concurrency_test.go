@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestCountUnfinishedBackups(t *testing.T) {
+	backups := []*types.Backup{
+		{Name: "new", Status: "New"},
+		{Name: "in-progress", Status: "InProgress"},
+		{Name: "not-yet-reconciled", Status: ""},
+		{Name: "completed", Status: "Completed"},
+		{Name: "failed", Status: "Failed"},
+		{Name: "partially-failed", Status: "PartiallyFailed"},
+		{Name: "in-progress-abcde", Status: "InProgress", FanOutOf: "in-progress"},
+		{Name: "completed-abcde", Status: "InProgress", FanOutOf: "completed"},
+	}
+
+	// the copy of the completed backup keeps its snapshot running, the copy of the running one doesn't add to it
+	if got := countUnfinishedBackups(backups); got != 4 {
+		t.Errorf("countUnfinishedBackups() = %d, want 4", got)
+	}
+}
+
+func TestMaxConcurrentAppBackups(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		want          int
+	}{
+		{"unset", 0, DefaultMaxConcurrentAppBackups},
+		{"negative", -1, DefaultMaxConcurrentAppBackups},
+		{"set", 3, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MaxConcurrentAppBackups(&apptypes.App{SnapshotMaxConcurrentBackups: test.maxConcurrent})
+			if got != test.want {
+				t.Errorf("MaxConcurrentAppBackups() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLockAppBackups(t *testing.T) {
+	unlock := lockAppBackups("app-1")
+
+	// another app isn't blocked
+	lockAppBackups("app-2")()
+
+	locked := make(chan struct{})
+	go func() {
+		lockAppBackups("app-1")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("Expected the second lock of the app to wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the second lock of the app to be taken after unlocking")
+	}
+}
@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsBackupStuck(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	backup := func(phase velerov1.BackupPhase, created time.Time, started *time.Time) velerov1.Backup {
+		b := velerov1.Backup{}
+		b.CreationTimestamp = metav1.Time{Time: created}
+		b.Status.Phase = phase
+		if started != nil {
+			b.Status.StartTimestamp = &metav1.Time{Time: *started}
+		}
+		return b
+	}
+	recent := now.Add(-time.Hour)
+	old := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		backup velerov1.Backup
+		want   bool
+	}{
+		{"old in progress", backup(velerov1.BackupPhaseInProgress, old, &old), true},
+		{"recent in progress", backup(velerov1.BackupPhaseInProgress, old, &recent), false},
+		{"old new without start", backup(velerov1.BackupPhaseNew, old, nil), true},
+		{"old completed", backup(velerov1.BackupPhaseCompleted, old, &old), false},
+		{"old failed", backup(velerov1.BackupPhaseFailed, old, &old), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := isBackupStuck(test.backup, 6*time.Hour, now)
+			if got != test.want {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestBackupCompletionPercent(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress types.BackupProgress
+		want     int
+	}{
+		{"new", types.BackupProgress{Phase: "New"}, 0},
+		{"items only", types.BackupProgress{Phase: "InProgress", TotalItems: 200, ItemsBackedUp: 50}, 25},
+		{"items and volumes", types.BackupProgress{Phase: "InProgress", TotalItems: 100, ItemsBackedUp: 100, VolumeTotalBytes: 1000, VolumeBytesDone: 500}, 75},
+		{"not done until completed", types.BackupProgress{Phase: "InProgress", TotalItems: 100, ItemsBackedUp: 100}, 99},
+		{"completed", types.BackupProgress{Phase: "Completed"}, 100},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := backupCompletionPercent(&test.progress)
+			if got != test.want {
+				t.Errorf("Expected %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCountRunningScheduledBackups(t *testing.T) {
+	backup := func(trigger string, phase velerov1.BackupPhase) velerov1.Backup {
+		b := velerov1.Backup{}
+		b.Annotations = map[string]string{"kots.io/snapshot-trigger": trigger}
+		b.Status.Phase = phase
+		return b
+	}
+
+	named := func(name string, b velerov1.Backup) velerov1.Backup {
+		b.Name = name
+		return b
+	}
+	fanOut := func(name string, of string, phase velerov1.BackupPhase) velerov1.Backup {
+		b := named(name, backup("schedule", phase))
+		b.Annotations[fanOutOfAnnotation] = of
+		return b
+	}
+
+	backups := []velerov1.Backup{
+		named("a", backup("schedule", "")),
+		named("b", backup("schedule", velerov1.BackupPhaseNew)),
+		named("c", backup("schedule", velerov1.BackupPhaseInProgress)),
+		named("d", backup("schedule", velerov1.BackupPhaseCompleted)),
+		named("e", backup("schedule", velerov1.BackupPhasePartiallyFailed)),
+		named("f", backup("manual", velerov1.BackupPhaseInProgress)),
+		named("g", backup("schedule-manual", velerov1.BackupPhaseInProgress)),
+		{},
+		// copies count with the backup they were copied from, even once it's done
+		fanOut("c-1", "c", velerov1.BackupPhaseInProgress),
+		fanOut("c-2", "c", velerov1.BackupPhaseNew),
+		fanOut("d-1", "d", velerov1.BackupPhaseNew),
+		fanOut("e-1", "e", velerov1.BackupPhaseCompleted),
+	}
+
+	got := countRunningScheduledBackups(backups)
+	if got != 4 {
+		t.Errorf("Expected 4 running scheduled backups, got %d", got)
+	}
+}
+
+func TestInstanceBackupNamespaces(t *testing.T) {
+	backupApps := []instanceBackupApp{
+		{slug: "app-1", additionalNamespaces: []string{"app-1-data"}},
+		{slug: "app-2"},
+	}
+
+	got := instanceBackupNamespaces("default", backupApps, false)
+	want := []string{"default", "app-1-data"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	got = instanceBackupNamespaces("default", backupApps, true)
+	want = []string{"default", "app-1-data", "kurl"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
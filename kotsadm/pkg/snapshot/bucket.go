@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	gcpstorage "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"google.golang.org/api/option"
+)
+
+// BucketNotFoundError is returned when the store's bucket doesn't exist. Velero doesn't create missing buckets,
+// backups to the store fail until it's created.
+type BucketNotFoundError struct {
+	Bucket string
+}
+
+func (e BucketNotFoundError) Error() string {
+	return fmt.Sprintf("bucket %s does not exist, create it or retry with createBucketIfMissing", e.Bucket)
+}
+
+// IsBucketNotFoundError returns true if the cause of the error is a BucketNotFoundError
+func IsBucketNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(BucketNotFoundError)
+	return ok
+}
+
+func isS3BucketNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if reqErr, ok := err.(awserr.RequestFailure); ok && reqErr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == s3.ErrCodeNoSuchBucket {
+		return true
+	}
+	return false
+}
+
+// CreateStoreBucket creates the store's bucket with the store's credentials, which need permission to create
+// buckets. Buckets of google stores using workload identity can't be created here.
+func CreateStoreBucket(ctx context.Context, store *types.Store) error {
+	if store.AWS != nil {
+		input := &s3.CreateBucketInput{
+			Bucket: aws.String(store.Bucket),
+		}
+		// us-east-1 is the default and is rejected as a location constraint
+		if store.AWS.Region != "" && store.AWS.Region != "us-east-1" {
+			input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+				LocationConstraint: aws.String(store.AWS.Region),
+			}
+		}
+		if _, err := newAWSS3Client(store.AWS).CreateBucketWithContext(ctx, input); err != nil {
+			return errors.Wrap(err, "failed to create aws bucket")
+		}
+		return nil
+	}
+
+	if store.Other != nil {
+		if _, err := newOtherS3Client(store.Other).CreateBucketWithContext(ctx, &s3.CreateBucketInput{Bucket: aws.String(store.Bucket)}); err != nil {
+			return errors.Wrap(err, "failed to create bucket")
+		}
+		return nil
+	}
+
+	if store.Internal != nil {
+		if _, err := newInternalS3Client(store.Internal).CreateBucketWithContext(ctx, &s3.CreateBucketInput{Bucket: aws.String(store.Bucket)}); err != nil {
+			return errors.Wrap(err, "failed to create bucket")
+		}
+		return nil
+	}
+
+	if store.Azure != nil {
+		container, err := getAzureContainer(store.Azure, store.Bucket)
+		if err != nil {
+			return errors.Wrap(err, "failed to get container")
+		}
+		if _, err := container.CreateIfNotExists(nil); err != nil {
+			return errors.Wrap(err, "failed to create container")
+		}
+		return nil
+	}
+
+	if store.Google != nil {
+		if store.Google.UseInstanceRole {
+			return errors.New("buckets can't be created for google stores using workload identity")
+		}
+
+		serviceAccountKey := struct {
+			ProjectID string `json:"project_id"`
+		}{}
+		if err := json.Unmarshal([]byte(store.Google.JSONFile), &serviceAccountKey); err != nil {
+			return errors.Wrap(err, "failed to parse service account key")
+		}
+		if serviceAccountKey.ProjectID == "" {
+			return errors.New("service account key has no project id")
+		}
+
+		client, err := gcpstorage.NewClient(ctx, option.WithCredentialsJSON([]byte(store.Google.JSONFile)))
+		if err != nil {
+			return errors.Wrap(err, "failed to create storage client")
+		}
+		defer client.Close()
+
+		if err := client.Bucket(store.Bucket).Create(ctx, serviceAccountKey.ProjectID, nil); err != nil {
+			return errors.Wrap(err, "failed to create gcs bucket")
+		}
+		return nil
+	}
+
+	return errors.New("no valid configuration found")
+}
@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestIsS3BucketNotFoundError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"head bucket not found", awserr.NewRequestFailure(awserr.New("NotFound", "Not Found", nil), http.StatusNotFound, "id"), true},
+		{"no such bucket", awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil), true},
+		{"forbidden", awserr.NewRequestFailure(awserr.New("Forbidden", "Forbidden", nil), http.StatusForbidden, "id"), false},
+		{"other error", errors.New("connection refused"), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isS3BucketNotFoundError(test.err); got != test.want {
+				t.Errorf("isS3BucketNotFoundError() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+)
+
+// CanTakeInstanceSnapshots checks everything instance snapshots depend on, in the order an operator would need
+// to set them up, and returns the first thing that's missing
+func CanTakeInstanceSnapshots() (*types.InstanceSnapshotCapability, error) {
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	if len(clusters) == 0 {
+		return &types.InstanceSnapshotCapability{
+			Reason:  types.InstanceSnapshotsNoCluster,
+			Message: "No cluster has been registered",
+		}, nil
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return &types.InstanceSnapshotCapability{
+			Reason:  types.InstanceSnapshotsVeleroNotInstalled,
+			Message: "Velero is not installed",
+		}, nil
+	}
+
+	requiresAccess, _, err := CheckKotsadmVeleroAccess()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check if kotsadm requires access to velero")
+	}
+	if requiresAccess {
+		return &types.InstanceSnapshotCapability{
+			Reason:          types.InstanceSnapshotsRequiresVeleroAccess,
+			Message:         "kotsadm does not have access to velero",
+			VeleroNamespace: veleroNamespace,
+		}, nil
+	}
+
+	if _, err := FindBackupStoreLocation(); err != nil {
+		if IsNoStoreConfiguredError(err) {
+			return &types.InstanceSnapshotCapability{
+				Reason:  types.InstanceSnapshotsNoStore,
+				Message: "No snapshot storage destination has been configured",
+			}, nil
+		}
+		return nil, errors.Wrap(err, "failed to find backup store location")
+	}
+
+	return &types.InstanceSnapshotCapability{
+		Supported: true,
+	}, nil
+}
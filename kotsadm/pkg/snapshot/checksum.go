@@ -0,0 +1,265 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/kurl"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const checksumManifestAnnotation = "kots.io/checksum-manifest"
+
+type checksumTargetKey struct {
+	namespace   string
+	podSelector string
+	container   string
+}
+
+// ValidateChecksumTargets checks that every target has a valid pod selector and absolute file paths
+func ValidateChecksumTargets(targets []types.ChecksumTarget) error {
+	for _, target := range targets {
+		if target.PodSelector == "" {
+			return errors.New("pod selector is required")
+		}
+		if _, err := labels.Parse(target.PodSelector); err != nil {
+			return errors.Wrapf(err, "invalid pod selector %q", target.PodSelector)
+		}
+		if len(target.Paths) == 0 {
+			return errors.Errorf("no paths for pod selector %q", target.PodSelector)
+		}
+		for _, p := range target.Paths {
+			if !path.IsAbs(p) {
+				return errors.Errorf("path %q must be absolute", p)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordChecksums computes the sha256 of every target file in a running pod matching the target. Checksums are
+// recorded before workloads are quiesced, since a quiesced workload may have no pods to read from, so targets
+// should be files that don't change while the backup is taken.
+func recordChecksums(ctx context.Context, defaultNamespace string, targets []types.ChecksumTarget) ([]types.ChecksumEntry, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	entries := []types.ChecksumEntry{}
+	for _, target := range targets {
+		namespace := target.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+
+		checksums, stderr, err := checksumFiles(ctx, clientset, cfg, namespace, target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to checksum files in pods matching %q", target.PodSelector)
+		}
+		// an empty checksum would match a file that is just as missing after the restore
+		if missing := missingChecksums(target.Paths, checksums); len(missing) > 0 {
+			return nil, errors.Errorf("failed to checksum %s in pods matching %q: %s", strings.Join(missing, ", "), target.PodSelector, strings.TrimSpace(stderr))
+		}
+
+		for _, p := range target.Paths {
+			entries = append(entries, types.ChecksumEntry{
+				Namespace:   namespace,
+				PodSelector: target.PodSelector,
+				Container:   target.Container,
+				Path:        p,
+				SHA256:      checksums[p],
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// VerifyRestore re-computes the checksums recorded when the backup was taken against the restored pods, and
+// returns every file that is missing or doesn't match
+func VerifyRestore(ctx context.Context, backupName string) ([]types.ChecksumMismatch, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup")
+	}
+
+	entries, err := getChecksumManifest(backup)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get checksum manifest")
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("backup has no checksum manifest")
+	}
+
+	// group the entries back into targets so each pod is exec'd into once
+	targets := map[checksumTargetKey]*types.ChecksumTarget{}
+	for _, entry := range entries {
+		key := checksumTargetKey{namespace: entry.Namespace, podSelector: entry.PodSelector, container: entry.Container}
+		if _, ok := targets[key]; !ok {
+			targets[key] = &types.ChecksumTarget{Namespace: entry.Namespace, PodSelector: entry.PodSelector, Container: entry.Container}
+		}
+		targets[key].Paths = append(targets[key].Paths, entry.Path)
+	}
+
+	actual := map[checksumTargetKey]map[string]string{}
+	for key, target := range targets {
+		checksums, _, err := checksumFiles(ctx, clientset, cfg, target.Namespace, *target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to checksum files in pods matching %q", target.PodSelector)
+		}
+		actual[key] = checksums
+	}
+
+	mismatches := []types.ChecksumMismatch{}
+	for _, entry := range entries {
+		key := checksumTargetKey{namespace: entry.Namespace, podSelector: entry.PodSelector, container: entry.Container}
+		// files that can't be read are always reported, even if the manifest has no checksum for them
+		sum := actual[key][entry.Path]
+		if sum == "" || sum != entry.SHA256 {
+			mismatches = append(mismatches, types.ChecksumMismatch{
+				Namespace: entry.Namespace,
+				Path:      entry.Path,
+				Expected:  entry.SHA256,
+				Actual:    sum,
+			})
+		}
+	}
+
+	return mismatches, nil
+}
+
+// checksumFiles returns the sha256 of each of the target's files it could read, and sha256sum's stderr explaining
+// the ones it couldn't
+func checksumFiles(ctx context.Context, clientset *kubernetes.Clientset, cfg *rest.Config, namespace string, target types.ChecksumTarget) (map[string]string, string, error) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: target.PodSelector,
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to list pods")
+	}
+
+	var pod *corev1.Pod
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning {
+			pod = &pods.Items[i]
+			break
+		}
+	}
+	if pod == nil {
+		return nil, "", errors.New("no running pod")
+	}
+
+	container := target.Container
+	if container == "" {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	// a missing file makes sha256sum exit non-zero, but it still prints the files it could read, so the exit code
+	// is left to the callers checking which paths have no checksum
+	command := append([]string{"sha256sum", "--"}, target.Paths...)
+	_, stdout, stderr, err := kurl.SyncExec(clientset.CoreV1(), cfg, namespace, pod.Name, container, command...)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to exec sha256sum")
+	}
+
+	return parseSha256sumOutput(stdout), stderr, nil
+}
+
+// missingChecksums returns the paths that have no checksum
+func missingChecksums(paths []string, checksums map[string]string) []string {
+	missing := []string{}
+	for _, p := range paths {
+		if checksums[p] == "" {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+func parseSha256sumOutput(output string) map[string]string {
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		// lines are "<sum>  <path>", or "<sum> *<path>" for files read in binary mode
+		fields := strings.SplitN(scanner.Text(), " ", 2)
+		if len(fields) != 2 || len(fields[0]) != 64 {
+			continue
+		}
+		p := strings.TrimLeft(fields[1], " *")
+		checksums[p] = fields[0]
+	}
+	return checksums
+}
+
+func setChecksumManifest(backup *velerov1.Backup, entries []types.ChecksumEntry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Namespace != entries[j].Namespace {
+			return entries[i].Namespace < entries[j].Namespace
+		}
+		return entries[i].Path < entries[j].Path
+	})
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal checksum manifest")
+	}
+	if backup.Annotations == nil {
+		backup.Annotations = map[string]string{}
+	}
+	backup.Annotations[checksumManifestAnnotation] = string(b)
+	return nil
+}
+
+func getChecksumManifest(backup *velerov1.Backup) ([]types.ChecksumEntry, error) {
+	value, ok := backup.Annotations[checksumManifestAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	entries := []types.ChecksumEntry{}
+	if err := json.Unmarshal([]byte(value), &entries); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal checksum manifest")
+	}
+	return entries, nil
+}
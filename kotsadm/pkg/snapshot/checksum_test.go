@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestParseSha256sumOutput(t *testing.T) {
+	output := `e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  /data/empty
+9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08 */data/test file
+sha256sum: /data/missing: No such file or directory
+`
+	want := map[string]string{
+		"/data/empty":     "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"/data/test file": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+	}
+
+	got := parseSha256sumOutput(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestMissingChecksums(t *testing.T) {
+	checksums := parseSha256sumOutput(`e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  /data/empty
+sha256sum: /data/missing: No such file or directory
+sha256sum: /data/unreadable: Permission denied
+`)
+
+	got := missingChecksums([]string{"/data/empty", "/data/missing", "/data/unreadable"}, checksums)
+	want := []string{"/data/missing", "/data/unreadable"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestValidateChecksumTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		targets   []types.ChecksumTarget
+		wantError bool
+	}{
+		{"valid", []types.ChecksumTarget{{PodSelector: "app=postgres", Paths: []string{"/var/lib/postgresql/data/PG_VERSION"}}}, false},
+		{"missing selector", []types.ChecksumTarget{{Paths: []string{"/data"}}}, true},
+		{"invalid selector", []types.ChecksumTarget{{PodSelector: "app==", Paths: []string{"/data"}}}, true},
+		{"relative path", []types.ChecksumTarget{{PodSelector: "app=postgres", Paths: []string{"data/file"}}}, true},
+		{"no paths", []types.ChecksumTarget{{PodSelector: "app=postgres"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateChecksumTargets(test.targets)
+			if (err != nil) != test.wantError {
+				t.Errorf("Expected error %v, got %v", test.wantError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// backupResourceIgnoredMetadata are set by the api server and change without anyone changing the resource
+var backupResourceIgnoredMetadata = []string{"resourceVersion", "uid", "creationTimestamp", "generation", "managedFields", "selfLink"}
+
+// CompareBackups returns the resources that were added, removed or changed in the "to" backup since the "from" backup.
+// Status and server managed metadata are ignored.
+func CompareBackups(fromBackupName string, toBackupName string) (*types.BackupComparison, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+
+	fromResources, err := downloadBackupResourceDigests(bsl.Namespace, fromBackupName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get resources of backup %s", fromBackupName)
+	}
+
+	toResources, err := downloadBackupResourceDigests(bsl.Namespace, toBackupName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get resources of backup %s", toBackupName)
+	}
+
+	comparison := compareBackupResources(fromResources, toResources)
+	comparison.FromBackup = fromBackupName
+	comparison.ToBackup = toBackupName
+
+	return comparison, nil
+}
+
+func downloadBackupResourceDigests(veleroNamespace string, backupName string) (map[types.BackupResource]string, error) {
+	gzipReader, err := DownloadRequest(veleroNamespace, velerov1.DownloadTargetKindBackupContents, backupName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download backup contents")
+	}
+	defer gzipReader.Close()
+
+	return backupResourceDigests(tar.NewReader(gzipReader))
+}
+
+// backupResourceDigests reads the resources out of a velero backup tarball, keyed by resource with a digest of
+// the parts of the manifest that are compared
+func backupResourceDigests(tarReader *tar.Reader) (map[types.BackupResource]string, error) {
+	digests := map[types.BackupResource]string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read tar")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		resource, ok := parseBackupResourcePath(header.Name)
+		if !ok {
+			continue
+		}
+
+		digest, err := backupResourceDigest(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", header.Name)
+		}
+		digests[resource] = digest
+	}
+
+	return digests, nil
+}
+
+// parseBackupResourcePath parses resources/<group resource>/[<version>-preferredversion/]namespaces/<namespace>/<name>.json
+// and resources/<group resource>/[<version>-preferredversion/]cluster/<name>.json. Versions other than the preferred
+// one are skipped so each resource is only compared once.
+func parseBackupResourcePath(name string) (types.BackupResource, bool) {
+	parts := strings.Split(strings.TrimPrefix(path.Clean(name), "./"), "/")
+	if len(parts) < 4 || parts[0] != "resources" || path.Ext(name) != ".json" {
+		return types.BackupResource{}, false
+	}
+
+	resource := types.BackupResource{
+		GroupResource: parts[1],
+		Name:          strings.TrimSuffix(parts[len(parts)-1], ".json"),
+	}
+
+	scope := parts[2:]
+	if scope[0] != "namespaces" && scope[0] != "cluster" {
+		if !strings.HasSuffix(scope[0], "-preferredversion") {
+			return types.BackupResource{}, false
+		}
+		scope = scope[1:]
+	}
+
+	switch {
+	case len(scope) == 3 && scope[0] == "namespaces":
+		resource.Namespace = scope[1]
+	case len(scope) == 2 && scope[0] == "cluster":
+	default:
+		return types.BackupResource{}, false
+	}
+
+	return resource, true
+}
+
+func backupResourceDigest(r io.Reader) (string, error) {
+	obj := map[string]interface{}{}
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return "", errors.Wrap(err, "failed to decode")
+	}
+
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, field := range backupResourceIgnoredMetadata {
+			delete(metadata, field)
+		}
+	}
+
+	// map keys are marshalled in order, so the same manifest always has the same digest
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal")
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(b)), nil
+}
+
+func compareBackupResources(from map[types.BackupResource]string, to map[types.BackupResource]string) *types.BackupComparison {
+	comparison := &types.BackupComparison{
+		Added:   []types.BackupResource{},
+		Removed: []types.BackupResource{},
+		Changed: []types.BackupResource{},
+	}
+
+	for resource, toDigest := range to {
+		fromDigest, ok := from[resource]
+		if !ok {
+			comparison.Added = append(comparison.Added, resource)
+		} else if fromDigest != toDigest {
+			comparison.Changed = append(comparison.Changed, resource)
+		}
+	}
+	for resource := range from {
+		if _, ok := to[resource]; !ok {
+			comparison.Removed = append(comparison.Removed, resource)
+		}
+	}
+
+	sortBackupResources(comparison.Added)
+	sortBackupResources(comparison.Removed)
+	sortBackupResources(comparison.Changed)
+
+	return comparison
+}
+
+func sortBackupResources(resources []types.BackupResource) {
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].GroupResource != resources[j].GroupResource {
+			return resources[i].GroupResource < resources[j].GroupResource
+		}
+		if resources[i].Namespace != resources[j].Namespace {
+			return resources[i].Namespace < resources[j].Namespace
+		}
+		return resources[i].Name < resources[j].Name
+	})
+}
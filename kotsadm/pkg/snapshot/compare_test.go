@@ -0,0 +1,92 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestParseBackupResourcePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   types.BackupResource
+		wantOK bool
+	}{
+		{
+			name:   "resources/deployments.apps/namespaces/default/web.json",
+			want:   types.BackupResource{GroupResource: "deployments.apps", Namespace: "default", Name: "web"},
+			wantOK: true,
+		},
+		{
+			name:   "resources/namespaces/cluster/default.json",
+			want:   types.BackupResource{GroupResource: "namespaces", Name: "default"},
+			wantOK: true,
+		},
+		{
+			name:   "resources/deployments.apps/v1-preferredversion/namespaces/default/web.json",
+			want:   types.BackupResource{GroupResource: "deployments.apps", Namespace: "default", Name: "web"},
+			wantOK: true,
+		},
+		{
+			name:   "resources/deployments.apps/v1beta1/namespaces/default/web.json",
+			wantOK: false,
+		},
+		{
+			name:   "metadata/version",
+			wantOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseBackupResourcePath(test.name)
+			if ok != test.wantOK || got != test.want {
+				t.Errorf("Expected %+v %v, got %+v %v", test.want, test.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestCompareBackupResources(t *testing.T) {
+	backupTar := func(files map[string]string) *tar.Reader {
+		var buf bytes.Buffer
+		w := tar.NewWriter(&buf)
+		for name, content := range files {
+			w.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+			w.Write([]byte(content))
+		}
+		w.Close()
+		return tar.NewReader(&buf)
+	}
+
+	from, err := backupResourceDigests(backupTar(map[string]string{
+		"resources/configmaps/namespaces/default/kept.json":    `{"metadata":{"name":"kept","resourceVersion":"1"},"data":{"a":"b"}}`,
+		"resources/configmaps/namespaces/default/changed.json": `{"metadata":{"name":"changed"},"data":{"a":"b"}}`,
+		"resources/configmaps/namespaces/default/removed.json": `{"metadata":{"name":"removed"}}`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to, err := backupResourceDigests(backupTar(map[string]string{
+		"resources/configmaps/namespaces/default/kept.json":    `{"metadata":{"name":"kept","resourceVersion":"2"},"data":{"a":"b"},"status":{}}`,
+		"resources/configmaps/namespaces/default/changed.json": `{"metadata":{"name":"changed"},"data":{"a":"c"}}`,
+		"resources/namespaces/cluster/added.json":              `{"metadata":{"name":"added"}}`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := compareBackupResources(from, to)
+	want := &types.BackupComparison{
+		Added:   []types.BackupResource{{GroupResource: "namespaces", Name: "added"}},
+		Removed: []types.BackupResource{{GroupResource: "configmaps", Namespace: "default", Name: "removed"}},
+		Changed: []types.BackupResource{{GroupResource: "configmaps", Namespace: "default", Name: "changed"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// veleroCRDs are the custom resource definitions every supported velero server version needs
+var veleroCRDs = []string{
+	"backups.velero.io",
+	"backupstoragelocations.velero.io",
+	"deletebackuprequests.velero.io",
+	"downloadrequests.velero.io",
+	"podvolumebackups.velero.io",
+	"podvolumerestores.velero.io",
+	"resticrepositories.velero.io",
+	"restores.velero.io",
+	"schedules.velero.io",
+	"serverstatusrequests.velero.io",
+	"volumesnapshotlocations.velero.io",
+}
+
+// veleroCRDField is a field a velero server version relies on, missing when the crds are older than the server
+type veleroCRDField struct {
+	minMajor int
+	minMinor int
+	crd      string
+	// path is the property path in the crd's spec schema
+	path []string
+}
+
+var veleroCRDFields = []veleroCRDField{
+	{minMajor: 1, minMinor: 5, crd: "backups.velero.io", path: []string{"defaultVolumesToRestic"}},
+}
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1beta1",
+	Resource: "customresourcedefinitions",
+}
+
+// CheckVeleroCRDs compares the installed velero crds against what the velero server version needs. Returns a
+// message explaining the mismatch, or an empty string if the crds match.
+func CheckVeleroCRDs(ctx context.Context, veleroVersion string) (string, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get cluster config")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create dynamic client")
+	}
+
+	crds := map[string]*unstructured.Unstructured{}
+	for _, name := range veleroCRDs {
+		crd, err := dynamicClient.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if kuberneteserrors.IsNotFound(err) {
+				continue
+			}
+			return "", errors.Wrapf(err, "failed to get crd %s", name)
+		}
+		crds[name] = crd
+	}
+
+	mismatches := findVeleroCRDMismatches(veleroVersion, crds)
+	if len(mismatches) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("The velero custom resource definitions don't match velero %s: %s. Upgrade the crds by running \"velero install --crds-only --dry-run -o yaml | kubectl apply -f -\" with the velero %s cli, or re-run the velero install or upgrade.",
+		veleroVersion, strings.Join(mismatches, ", "), veleroVersion), nil
+}
+
+// findVeleroCRDMismatches returns the crds that are missing or older than the velero server version
+func findVeleroCRDMismatches(veleroVersion string, crds map[string]*unstructured.Unstructured) []string {
+	mismatches := []string{}
+
+	for _, name := range veleroCRDs {
+		if _, ok := crds[name]; !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s is missing", name))
+		}
+	}
+
+	major, minor, ok := parseVeleroVersion(veleroVersion)
+	if ok {
+		for _, field := range veleroCRDFields {
+			if major < field.minMajor || (major == field.minMajor && minor < field.minMinor) {
+				continue
+			}
+			crd, exists := crds[field.crd]
+			if !exists {
+				continue
+			}
+			if !crdHasSpecField(crd, field.path) {
+				mismatches = append(mismatches, fmt.Sprintf("%s has no spec.%s field", field.crd, strings.Join(field.path, ".")))
+			}
+		}
+	}
+
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// crdHasSpecField looks for the field in the crd's schemas, v1beta1 crds can have a schema for all versions or one per version
+func crdHasSpecField(crd *unstructured.Unstructured, path []string) bool {
+	schemas := []map[string]interface{}{}
+	if s, found, _ := unstructured.NestedMap(crd.Object, "spec", "validation", "openAPIV3Schema"); found {
+		schemas = append(schemas, s)
+	}
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, version := range versions {
+		versionMap, ok := version.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if s, found, _ := unstructured.NestedMap(versionMap, "schema", "openAPIV3Schema"); found {
+			schemas = append(schemas, s)
+		}
+	}
+
+	fields := []string{"properties", "spec"}
+	for _, p := range path {
+		fields = append(fields, "properties", p)
+	}
+
+	for _, s := range schemas {
+		if _, found, _ := unstructured.NestedFieldNoCopy(s, fields...); found {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVeleroVersion parses the major and minor version from a velero image tag, e.g. "v1.5.1"
+func parseVeleroVersion(version string) (int, int, bool) {
+	var major, minor int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(version, "v"), "%d.%d", &major, &minor); err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
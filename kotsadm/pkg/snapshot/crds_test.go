@@ -0,0 +1,103 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFindVeleroCRDMismatches(t *testing.T) {
+	backupsSchema := func(specProperties map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"properties": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"properties": specProperties,
+				},
+			},
+		}
+	}
+
+	allCRDs := func(backups *unstructured.Unstructured) map[string]*unstructured.Unstructured {
+		crds := map[string]*unstructured.Unstructured{}
+		for _, name := range veleroCRDs {
+			crds[name] = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		}
+		crds["backups.velero.io"] = backups
+		return crds
+	}
+
+	oldBackups := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"validation": map[string]interface{}{
+				"openAPIV3Schema": backupsSchema(map[string]interface{}{
+					"ttl": map[string]interface{}{},
+				}),
+			},
+		},
+	}}
+	newBackups := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"versions": []interface{}{
+				map[string]interface{}{
+					"name": "v1",
+					"schema": map[string]interface{}{
+						"openAPIV3Schema": backupsSchema(map[string]interface{}{
+							"defaultVolumesToRestic": map[string]interface{}{},
+						}),
+					},
+				},
+			},
+		},
+	}}
+
+	missingSchedules := allCRDs(newBackups)
+	delete(missingSchedules, "schedules.velero.io")
+
+	tests := []struct {
+		name          string
+		veleroVersion string
+		crds          map[string]*unstructured.Unstructured
+		want          []string
+	}{
+		{
+			name:          "crds match",
+			veleroVersion: "v1.5.1",
+			crds:          allCRDs(newBackups),
+			want:          []string{},
+		},
+		{
+			name:          "old crds with an old server",
+			veleroVersion: "v1.4.2",
+			crds:          allCRDs(oldBackups),
+			want:          []string{},
+		},
+		{
+			name:          "old crds with a new server",
+			veleroVersion: "v1.5.1",
+			crds:          allCRDs(oldBackups),
+			want:          []string{"backups.velero.io has no spec.defaultVolumesToRestic field"},
+		},
+		{
+			name:          "missing crd",
+			veleroVersion: "v1.5.1",
+			crds:          missingSchedules,
+			want:          []string{"schedules.velero.io is missing"},
+		},
+		{
+			name:          "unknown version",
+			veleroVersion: "latest",
+			crds:          allCRDs(oldBackups),
+			want:          []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := findVeleroCRDMismatches(test.veleroVersion, test.crds)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("findVeleroCRDMismatches() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/providers"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"gopkg.in/ini.v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cloudCredentialsSecretName is the secret velero and its plugins read the store credentials from
+const cloudCredentialsSecretName = "cloud-credentials"
+
+// CredentialsProvider produces the credentials velero uses to reach a store, for one way of authenticating
+type CredentialsProvider interface {
+	// CloudCredentials returns the "cloud" file of the velero credentials secret, or nil when velero
+	// authenticates without a secret, e.g. with an instance role or workload identity
+	CloudCredentials() ([]byte, error)
+}
+
+// NewCredentialsProvider returns the credentials provider for the store's provider and auth mode
+func NewCredentialsProvider(store *types.Store) (CredentialsProvider, error) {
+	switch {
+	case store.AWS != nil:
+		provider := &AWSCredentialsProvider{
+			AccessKeyID:     store.AWS.AccessKeyID,
+			SecretAccessKey: store.AWS.SecretAccessKey,
+			UseInstanceRole: store.AWS.UseInstanceRole,
+		}
+		if store.AWS.VolumeSnapshot != nil {
+			provider.VolumeSnapshot = &AWSCredentialsProvider{
+				AccessKeyID:     store.AWS.VolumeSnapshot.AccessKeyID,
+				SecretAccessKey: store.AWS.VolumeSnapshot.SecretAccessKey,
+			}
+		}
+		return provider, nil
+	case store.Other != nil:
+		return &AWSCredentialsProvider{
+			AccessKeyID:     store.Other.AccessKeyID,
+			SecretAccessKey: store.Other.SecretAccessKey,
+		}, nil
+	case store.Internal != nil:
+		return &AWSCredentialsProvider{
+			AccessKeyID:     store.Internal.AccessKeyID,
+			SecretAccessKey: store.Internal.SecretAccessKey,
+		}, nil
+	case store.Google != nil:
+		if store.Google.UseInstanceRole {
+			return WorkloadIdentityCredentialsProvider{}, nil
+		}
+		return GoogleCredentialsProvider{JSONFile: store.Google.JSONFile}, nil
+	case store.Azure != nil:
+		return AzureCredentialsProvider{
+			Config: providers.Azure{
+				SubscriptionID: store.Azure.SubscriptionID,
+				TenantID:       store.Azure.TenantID,
+				ClientID:       store.Azure.ClientID,
+				ClientSecret:   store.Azure.ClientSecret,
+				ResourceGroup:  store.Azure.ResourceGroup,
+				CloudName:      store.Azure.CloudName,
+			},
+		}, nil
+	}
+
+	return nil, errors.New("store has no provider configured")
+}
+
+// AWSCredentialsProvider writes static keys to an aws credentials file, for aws and s3 compatible stores
+type AWSCredentialsProvider struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// UseInstanceRole leaves the keys out, velero uses the instance role or IRSA for the store instead
+	UseInstanceRole bool
+	// VolumeSnapshot is written to its own profile, since velero only mounts the one credentials secret
+	VolumeSnapshot *AWSCredentialsProvider
+}
+
+func (p *AWSCredentialsProvider) CloudCredentials() ([]byte, error) {
+	awsCfg := ini.Empty()
+
+	if !p.UseInstanceRole {
+		if err := addAWSCredentialsProfile(awsCfg, "default", p.AccessKeyID, p.SecretAccessKey); err != nil {
+			return nil, errors.Wrap(err, "failed to add default profile")
+		}
+	}
+
+	if p.VolumeSnapshot != nil {
+		if err := addAWSCredentialsProfile(awsCfg, volumeSnapshotCredentialsProfile, p.VolumeSnapshot.AccessKeyID, p.VolumeSnapshot.SecretAccessKey); err != nil {
+			return nil, errors.Wrap(err, "failed to add volume snapshot profile")
+		}
+	}
+
+	if len(awsCfg.SectionStrings()) == 1 {
+		// only the implicit DEFAULT section
+		return nil, nil
+	}
+
+	var awsCredentials bytes.Buffer
+	writer := bufio.NewWriter(&awsCredentials)
+	if _, err := awsCfg.WriteTo(writer); err != nil {
+		return nil, errors.Wrap(err, "failed to write ini")
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, errors.Wrap(err, "failed to flush buffer")
+	}
+
+	return awsCredentials.Bytes(), nil
+}
+
+func addAWSCredentialsProfile(awsCfg *ini.File, profile string, accessKeyID string, secretAccessKey string) error {
+	section, err := awsCfg.NewSection(profile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s section", profile)
+	}
+	if _, err := section.NewKey("aws_access_key_id", accessKeyID); err != nil {
+		return errors.Wrap(err, "failed to create access key id")
+	}
+	if _, err := section.NewKey("aws_secret_access_key", secretAccessKey); err != nil {
+		return errors.Wrap(err, "failed to create secret access key")
+	}
+	return nil
+}
+
+// GoogleCredentialsProvider uses a service account json key
+type GoogleCredentialsProvider struct {
+	JSONFile string
+}
+
+func (p GoogleCredentialsProvider) CloudCredentials() ([]byte, error) {
+	return []byte(p.JSONFile), nil
+}
+
+// AzureCredentialsProvider uses a service principal
+type AzureCredentialsProvider struct {
+	Config providers.Azure
+}
+
+func (p AzureCredentialsProvider) CloudCredentials() ([]byte, error) {
+	return providers.RenderAzureConfig(p.Config), nil
+}
+
+// WorkloadIdentityCredentialsProvider is for stores velero reaches through an identity bound to its service
+// account, which needs no secret
+type WorkloadIdentityCredentialsProvider struct{}
+
+func (p WorkloadIdentityCredentialsProvider) CloudCredentials() ([]byte, error) {
+	return nil, nil
+}
+
+// applyCloudCredentials creates or updates the velero credentials secret, or deletes it when the provider
+// doesn't need one
+func applyCloudCredentials(clientset *kubernetes.Clientset, namespace string, provider CredentialsProvider) error {
+	cloudCredentials, err := provider.CloudCredentials()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cloud credentials")
+	}
+
+	currentSecret, err := clientset.CoreV1().Secrets(namespace).Get(context.TODO(), cloudCredentialsSecretName, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to read cloud credentials secret")
+	}
+	secretExists := err == nil
+
+	if cloudCredentials == nil {
+		if secretExists {
+			err := clientset.CoreV1().Secrets(namespace).Delete(context.TODO(), cloudCredentialsSecretName, metav1.DeleteOptions{})
+			if err != nil {
+				return errors.Wrap(err, "failed to delete cloud credentials secret")
+			}
+		}
+		return nil
+	}
+
+	if !secretExists {
+		toCreate := corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "Secret",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cloudCredentialsSecretName,
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{
+				"cloud": cloudCredentials,
+			},
+		}
+		_, err := clientset.CoreV1().Secrets(namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to create cloud credentials secret")
+		}
+		return nil
+	}
+
+	if currentSecret.Data == nil {
+		currentSecret.Data = map[string][]byte{}
+	}
+	currentSecret.Data["cloud"] = cloudCredentials
+	_, err = clientset.CoreV1().Secrets(namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to update cloud credentials secret")
+	}
+
+	return nil
+}
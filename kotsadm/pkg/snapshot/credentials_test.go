@@ -0,0 +1,128 @@
+package snapshot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"gopkg.in/ini.v1"
+)
+
+func TestCloudCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		store        *types.Store
+		wantNil      bool
+		wantProfiles map[string]string
+		wantContains string
+	}{
+		{
+			name: "aws static keys",
+			store: &types.Store{
+				AWS: &types.StoreAWS{AccessKeyID: "key", SecretAccessKey: "secret"},
+			},
+			wantProfiles: map[string]string{"default": "key"},
+		},
+		{
+			name: "aws instance role",
+			store: &types.Store{
+				AWS: &types.StoreAWS{AccessKeyID: "ignored", SecretAccessKey: "ignored", UseInstanceRole: true},
+			},
+			wantNil: true,
+		},
+		{
+			name: "aws instance role with volume snapshot keys",
+			store: &types.Store{
+				AWS: &types.StoreAWS{
+					UseInstanceRole: true,
+					VolumeSnapshot:  &types.StoreAWSVolumeSnapshot{AccessKeyID: "vs-key", SecretAccessKey: "vs-secret"},
+				},
+			},
+			wantProfiles: map[string]string{volumeSnapshotCredentialsProfile: "vs-key"},
+		},
+		{
+			name: "s3 compatible",
+			store: &types.Store{
+				Other: &types.StoreOther{AccessKeyID: "key", SecretAccessKey: "secret"},
+			},
+			wantProfiles: map[string]string{"default": "key"},
+		},
+		{
+			name: "internal",
+			store: &types.Store{
+				Internal: &types.StoreInternal{AccessKeyID: "key", SecretAccessKey: "secret"},
+			},
+			wantProfiles: map[string]string{"default": "key"},
+		},
+		{
+			name: "google json key",
+			store: &types.Store{
+				Google: &types.StoreGoogle{JSONFile: `{"type": "service_account"}`},
+			},
+			wantContains: "service_account",
+		},
+		{
+			name: "google workload identity",
+			store: &types.Store{
+				Google: &types.StoreGoogle{UseInstanceRole: true, ServiceAccount: "velero@project.iam.gserviceaccount.com"},
+			},
+			wantNil: true,
+		},
+		{
+			name: "azure",
+			store: &types.Store{
+				Azure: &types.StoreAzure{ClientID: "client-id", ClientSecret: "client-secret"},
+			},
+			wantContains: "AZURE_CLIENT_ID=client-id",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, err := NewCredentialsProvider(test.store)
+			if err != nil {
+				t.Fatalf("NewCredentialsProvider() error = %v", err)
+			}
+
+			got, err := provider.CloudCredentials()
+			if err != nil {
+				t.Fatalf("CloudCredentials() error = %v", err)
+			}
+
+			if test.wantNil {
+				if got != nil {
+					t.Errorf("Expected no credentials, got %q", string(got))
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("Expected credentials, got none")
+			}
+
+			if test.wantContains != "" && !strings.Contains(string(got), test.wantContains) {
+				t.Errorf("Expected credentials to contain %q, got %q", test.wantContains, string(got))
+			}
+
+			if test.wantProfiles != nil {
+				cfg, err := ini.Load(got)
+				if err != nil {
+					t.Fatalf("failed to load credentials: %v", err)
+				}
+				for profile, accessKeyID := range test.wantProfiles {
+					if value := cfg.Section(profile).Key("aws_access_key_id").Value(); value != accessKeyID {
+						t.Errorf("Expected profile %s access key id %q, got %q", profile, accessKeyID, value)
+					}
+				}
+				if len(cfg.SectionStrings())-1 != len(test.wantProfiles) {
+					t.Errorf("Expected profiles %v, got %v", test.wantProfiles, cfg.SectionStrings())
+				}
+			}
+		})
+	}
+}
+
+func TestNewCredentialsProviderNoProvider(t *testing.T) {
+	if _, err := NewCredentialsProvider(&types.Store{}); err == nil {
+		t.Error("Expected an error for a store without a provider")
+	}
+}
@@ -0,0 +1,158 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	"go.uber.org/zap"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// crossAppRestoreSourceAnnotation records the app that took the backup on a cross-app restore
+	crossAppRestoreSourceAnnotation = "kots.io/cross-app-restore-source-app-id"
+	// crossAppRestoreTargetAnnotation records the app the backup was restored for
+	crossAppRestoreTargetAnnotation = "kots.io/cross-app-restore-target-app-slug"
+)
+
+// BackupNotRestorableError is returned when a backup can't be restored across apps
+type BackupNotRestorableError struct {
+	Message string
+}
+
+func (e BackupNotRestorableError) Error() string {
+	return e.Message
+}
+
+// IsBackupNotRestorableError returns true if the cause of the error is a BackupNotRestorableError
+func IsBackupNotRestorableError(err error) bool {
+	_, ok := errors.Cause(err).(BackupNotRestorableError)
+	return ok
+}
+
+// CreateCrossAppRestore restores an app backup without checking which app took it, for moving data into a
+// differently named app. The restore isn't tracked on the app, so the app is not undeployed before or redeployed
+// after. The returned warnings describe the app metadata that won't match.
+func CreateCrossAppRestore(ctx context.Context, backupName string, appID string, appSlug string, namespaceMapping map[string]string) (*velerov1.Restore, []string, error) {
+	logger.Debug("creating cross-app restore",
+		zap.String("backupName", backupName),
+		zap.String("appSlug", appSlug))
+
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return nil, nil, BackupNotRestorableError{Message: fmt.Sprintf("backup %s not found", backupName)}
+	} else if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get backup")
+	}
+
+	if err := validateCrossAppRestore(backup, namespaceMapping); err != nil {
+		return nil, nil, err
+	}
+
+	restore := newCrossAppRestore(backup, appSlug, namespaceMapping)
+	created, err := veleroClient.Restores(veleroNamespace).Create(ctx, restore, metav1.CreateOptions{})
+	if kuberneteserrors.IsAlreadyExists(err) {
+		return nil, nil, BackupNotRestorableError{Message: fmt.Sprintf("restore %s already exists", restore.Name)}
+	} else if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create restore")
+	}
+
+	return created, crossAppRestoreWarnings(backup, appID, namespaceMapping), nil
+}
+
+func validateCrossAppRestore(backup *velerov1.Backup, namespaceMapping map[string]string) error {
+	if backup.Status.Phase != velerov1.BackupPhaseCompleted {
+		return BackupNotRestorableError{Message: fmt.Sprintf("backup %s is %s, only completed backups can be restored", backup.Name, backup.Status.Phase)}
+	}
+	if backup.Annotations["kots.io/instance"] == "true" {
+		return BackupNotRestorableError{Message: fmt.Sprintf("backup %s is an instance backup, restore the instance instead", backup.Name)}
+	}
+
+	targets := map[string]string{}
+	for source, target := range namespaceMapping {
+		if !backupIncludesNamespace(backup, source) {
+			return BackupNotRestorableError{Message: fmt.Sprintf("namespace %s is not in backup %s", source, backup.Name)}
+		}
+		if errs := validation.IsDNS1123Label(target); len(errs) > 0 {
+			return BackupNotRestorableError{Message: fmt.Sprintf("invalid namespace %q: %s", target, strings.Join(errs, ", "))}
+		}
+		if other, ok := targets[target]; ok {
+			return BackupNotRestorableError{Message: fmt.Sprintf("namespaces %s and %s are both mapped to %s", other, source, target)}
+		}
+		targets[target] = source
+	}
+
+	return nil
+}
+
+func backupIncludesNamespace(backup *velerov1.Backup, namespace string) bool {
+	if len(backup.Spec.IncludedNamespaces) == 0 {
+		return true
+	}
+	for _, included := range backup.Spec.IncludedNamespaces {
+		if included == "*" || included == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func newCrossAppRestore(backup *velerov1.Backup, appSlug string, namespaceMapping map[string]string) *velerov1.Restore {
+	trueVal := true
+	return &velerov1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: backup.Namespace,
+			Name:      fmt.Sprintf("%s.%s", backup.Name, appSlug),
+			Annotations: map[string]string{
+				crossAppRestoreSourceAnnotation: backup.Annotations["kots.io/app-id"],
+				crossAppRestoreTargetAnnotation: appSlug,
+			},
+		},
+		Spec: velerov1.RestoreSpec{
+			BackupName:              backup.Name,
+			NamespaceMapping:        namespaceMapping,
+			RestorePVs:              &trueVal,
+			IncludeClusterResources: &trueVal,
+		},
+	}
+}
+
+func crossAppRestoreWarnings(backup *velerov1.Backup, appID string, namespaceMapping map[string]string) []string {
+	warnings := []string{}
+
+	sourceAppID := backup.Annotations["kots.io/app-id"]
+	if sourceAppID != appID {
+		warnings = append(warnings, fmt.Sprintf("backup %s was taken by app %s, restored resources keep that app's labels and annotations and will not match this app", backup.Name, sourceAppID))
+		if len(namespaceMapping) == 0 {
+			warnings = append(warnings, "no namespace mapping was given, resources are restored to the namespaces they were backed up from")
+		}
+	}
+	warnings = append(warnings, "the app is not redeployed after a cross-app restore, its deployed version is unchanged")
+
+	return warnings
+}
@@ -0,0 +1,133 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateCrossAppRestore(t *testing.T) {
+	backup := func(phase velerov1.BackupPhase, annotations map[string]string, namespaces ...string) *velerov1.Backup {
+		b := &velerov1.Backup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "old-app-abcde",
+				Annotations: annotations,
+			},
+			Spec: velerov1.BackupSpec{
+				IncludedNamespaces: namespaces,
+			},
+		}
+		b.Status.Phase = phase
+		return b
+	}
+	appAnnotations := map[string]string{"kots.io/app-id": "old-app-id"}
+
+	tests := []struct {
+		name             string
+		backup           *velerov1.Backup
+		namespaceMapping map[string]string
+		wantErr          bool
+	}{
+		{
+			name:   "completed",
+			backup: backup(velerov1.BackupPhaseCompleted, appAnnotations, "old-app"),
+		},
+		{
+			name:             "mapped namespace",
+			backup:           backup(velerov1.BackupPhaseCompleted, appAnnotations, "old-app"),
+			namespaceMapping: map[string]string{"old-app": "new-app"},
+		},
+		{
+			name:             "all namespaces",
+			backup:           backup(velerov1.BackupPhaseCompleted, appAnnotations, "*"),
+			namespaceMapping: map[string]string{"old-app": "new-app"},
+		},
+		{
+			name:    "partially failed",
+			backup:  backup(velerov1.BackupPhasePartiallyFailed, appAnnotations, "old-app"),
+			wantErr: true,
+		},
+		{
+			name:    "instance backup",
+			backup:  backup(velerov1.BackupPhaseCompleted, map[string]string{"kots.io/instance": "true"}, "default"),
+			wantErr: true,
+		},
+		{
+			name:             "namespace not in backup",
+			backup:           backup(velerov1.BackupPhaseCompleted, appAnnotations, "old-app"),
+			namespaceMapping: map[string]string{"other": "new-app"},
+			wantErr:          true,
+		},
+		{
+			name:             "invalid target namespace",
+			backup:           backup(velerov1.BackupPhaseCompleted, appAnnotations, "old-app"),
+			namespaceMapping: map[string]string{"old-app": "New_App"},
+			wantErr:          true,
+		},
+		{
+			name:             "two namespaces mapped to one",
+			backup:           backup(velerov1.BackupPhaseCompleted, appAnnotations, "old-app", "old-app-db"),
+			namespaceMapping: map[string]string{"old-app": "new-app", "old-app-db": "new-app"},
+			wantErr:          true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateCrossAppRestore(test.backup, test.namespaceMapping)
+			if (err != nil) != test.wantErr {
+				t.Errorf("validateCrossAppRestore() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil && !IsBackupNotRestorableError(err) {
+				t.Errorf("Expected BackupNotRestorableError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewCrossAppRestore(t *testing.T) {
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "old-app-abcde",
+			Namespace:   "velero",
+			Annotations: map[string]string{"kots.io/app-id": "old-app-id"},
+		},
+	}
+	namespaceMapping := map[string]string{"old-app": "new-app"}
+
+	got := newCrossAppRestore(backup, "new-app", namespaceMapping)
+
+	if got.Name != "old-app-abcde.new-app" || got.Namespace != "velero" {
+		t.Errorf("Expected restore old-app-abcde.new-app in velero, got %s in %s", got.Name, got.Namespace)
+	}
+	if got.Spec.BackupName != "old-app-abcde" {
+		t.Errorf("Expected backup name old-app-abcde, got %s", got.Spec.BackupName)
+	}
+	if !reflect.DeepEqual(got.Spec.NamespaceMapping, namespaceMapping) {
+		t.Errorf("Expected namespace mapping %v, got %v", namespaceMapping, got.Spec.NamespaceMapping)
+	}
+	if got.Annotations[crossAppRestoreSourceAnnotation] != "old-app-id" || got.Annotations[crossAppRestoreTargetAnnotation] != "new-app" {
+		t.Errorf("Expected source and target app annotations, got %v", got.Annotations)
+	}
+}
+
+func TestCrossAppRestoreWarnings(t *testing.T) {
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "old-app-abcde",
+			Annotations: map[string]string{"kots.io/app-id": "old-app-id"},
+		},
+	}
+
+	if got := crossAppRestoreWarnings(backup, "new-app-id", nil); len(got) != 3 {
+		t.Errorf("Expected 3 warnings for another app without a namespace mapping, got %v", got)
+	}
+	if got := crossAppRestoreWarnings(backup, "new-app-id", map[string]string{"old-app": "new-app"}); len(got) != 2 {
+		t.Errorf("Expected 2 warnings for another app with a namespace mapping, got %v", got)
+	}
+	if got := crossAppRestoreWarnings(backup, "old-app-id", nil); len(got) != 1 {
+		t.Errorf("Expected 1 warning for the same app, got %v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package snapshot
+
+import (
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	cron "github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultSnapshotTTL is the retention of apps that haven't set their own when no instance default is set
+	DefaultSnapshotTTL = "720h"
+	// DefaultSnapshotSchedule is suggested to apps that haven't set their own when no instance default is set
+	DefaultSnapshotSchedule = "0 0 * * MON"
+)
+
+// GetSnapshotDefaults returns the instance retention and schedule for apps that haven't set their own,
+// falling back to DefaultSnapshotTTL and DefaultSnapshotSchedule
+func GetSnapshotDefaults() (*types.SnapshotDefaults, error) {
+	defaults, err := store.GetStore().GetSnapshotDefaults()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get snapshot defaults")
+	}
+
+	return withSnapshotDefaults(defaults), nil
+}
+
+// ValidateSnapshotDefaults checks the instance defaults, empty fields restore the built in defaults
+func ValidateSnapshotDefaults(defaults *types.SnapshotDefaults) error {
+	if defaults.TTL != "" {
+		if _, err := ParseTTL(defaults.TTL); err != nil {
+			return errors.Errorf("invalid default retention %q", defaults.TTL)
+		}
+	}
+	if defaults.Schedule != "" {
+		if _, err := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor).Parse(defaults.Schedule); err != nil {
+			return errors.Errorf("invalid default cron schedule expression %q", defaults.Schedule)
+		}
+	}
+	return nil
+}
+
+func withSnapshotDefaults(defaults *types.SnapshotDefaults) *types.SnapshotDefaults {
+	result := &types.SnapshotDefaults{
+		TTL:      DefaultSnapshotTTL,
+		Schedule: DefaultSnapshotSchedule,
+	}
+	if defaults == nil {
+		return result
+	}
+	if defaults.TTL != "" {
+		result.TTL = defaults.TTL
+	}
+	if defaults.Schedule != "" {
+		result.Schedule = defaults.Schedule
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestWithSnapshotDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults *types.SnapshotDefaults
+		want     *types.SnapshotDefaults
+	}{
+		{
+			name:     "nothing set",
+			defaults: &types.SnapshotDefaults{},
+			want:     &types.SnapshotDefaults{TTL: "720h", Schedule: "0 0 * * MON"},
+		},
+		{
+			name:     "nil",
+			defaults: nil,
+			want:     &types.SnapshotDefaults{TTL: "720h", Schedule: "0 0 * * MON"},
+		},
+		{
+			name:     "ttl set",
+			defaults: &types.SnapshotDefaults{TTL: "168h"},
+			want:     &types.SnapshotDefaults{TTL: "168h", Schedule: "0 0 * * MON"},
+		},
+		{
+			name:     "both set",
+			defaults: &types.SnapshotDefaults{TTL: "2160h", Schedule: "0 2 * * *"},
+			want:     &types.SnapshotDefaults{TTL: "2160h", Schedule: "0 2 * * *"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := withSnapshotDefaults(test.defaults); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("withSnapshotDefaults() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateSnapshotDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults *types.SnapshotDefaults
+		wantErr  bool
+	}{
+		{
+			name:     "empty restores built in defaults",
+			defaults: &types.SnapshotDefaults{},
+		},
+		{
+			name:     "valid",
+			defaults: &types.SnapshotDefaults{TTL: "168h", Schedule: "0 2 * * *"},
+		},
+		{
+			name:     "descriptor schedule",
+			defaults: &types.SnapshotDefaults{Schedule: "@daily"},
+		},
+		{
+			name:     "ttl with days unit",
+			defaults: &types.SnapshotDefaults{TTL: "7d"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid schedule",
+			defaults: &types.SnapshotDefaults{Schedule: "every monday"},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateSnapshotDefaults(test.defaults)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateSnapshotDefaults() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// GetBackupDeletionStatus returns how far velero has gotten deleting the backup that DeleteBackup requested,
+// or nil if the backup's deletion was never requested. Velero deletes the delete backup requests of a backup once
+// one of them is processed successfully, so a backup that is gone without a request left is reported as deleted.
+func GetBackupDeletionStatus(ctx context.Context, snapshotName string) (*types.BackupDeletionStatus, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	deleteBackupRequest, err := veleroClient.DeleteBackupRequests(bsl.Namespace).Get(ctx, snapshotName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		deleteBackupRequest = nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get delete backup request")
+	}
+
+	backupExists := true
+	if deleteBackupRequest == nil {
+		_, err := veleroClient.Backups(bsl.Namespace).Get(ctx, snapshotName, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			backupExists = false
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup")
+		}
+	}
+
+	return resolveBackupDeletionStatus(deleteBackupRequest, backupExists), nil
+}
+
+// WaitForBackupDeletion waits for velero to finish deleting the backup and removing its data from the store.
+// If the deletion isn't done within the timeout, the status so far is returned.
+func WaitForBackupDeletion(ctx context.Context, snapshotName string, timeout time.Duration) (*types.BackupDeletionStatus, error) {
+	getStatus := func() (*types.BackupDeletionStatus, error) {
+		return GetBackupDeletionStatus(ctx, snapshotName)
+	}
+	status, err := waitForBackupDeletion(ctx, getStatus, timeout, time.Second)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to wait for deletion of backup %s", snapshotName)
+	}
+	return status, nil
+}
+
+func waitForBackupDeletion(ctx context.Context, getStatus func() (*types.BackupDeletionStatus, error), timeout time.Duration, interval time.Duration) (*types.BackupDeletionStatus, error) {
+	start := time.Now()
+	for {
+		status, err := getStatus()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup deletion status")
+		}
+		if status == nil {
+			return nil, errors.New("deletion was not requested")
+		}
+
+		if status.Done {
+			return status, nil
+		}
+
+		if time.Now().Sub(start) > timeout {
+			// return the status as is, velero keeps going and it can be checked again later
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// resolveBackupDeletionStatus returns the deletion status from the backup's delete backup request, nil if there
+// is none. Without a request, a backup that no longer exists has been deleted.
+func resolveBackupDeletionStatus(deleteBackupRequest *velerov1.DeleteBackupRequest, backupExists bool) *types.BackupDeletionStatus {
+	if deleteBackupRequest != nil {
+		return getBackupDeletionStatus(deleteBackupRequest)
+	}
+	if backupExists {
+		return nil
+	}
+	return &types.BackupDeletionStatus{
+		Phase: string(velerov1.DeleteBackupRequestPhaseProcessed),
+		Done:  true,
+	}
+}
+
+func getBackupDeletionStatus(deleteBackupRequest *velerov1.DeleteBackupRequest) *types.BackupDeletionStatus {
+	status := &types.BackupDeletionStatus{
+		Phase:  string(deleteBackupRequest.Status.Phase),
+		Errors: deleteBackupRequest.Status.Errors,
+	}
+	if status.Phase == "" {
+		status.Phase = string(velerov1.DeleteBackupRequestPhaseNew)
+	}
+
+	// velero marks the request processed once it's done with it, whether or not the data could be removed
+	status.Done = deleteBackupRequest.Status.Phase == velerov1.DeleteBackupRequestPhaseProcessed
+
+	return status
+}
@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+func TestGetBackupDeletionStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status velerov1.DeleteBackupRequestStatus
+		want   *types.BackupDeletionStatus
+	}{
+		{
+			name:   "not picked up yet",
+			status: velerov1.DeleteBackupRequestStatus{},
+			want:   &types.BackupDeletionStatus{Phase: "New"},
+		},
+		{
+			name:   "in progress",
+			status: velerov1.DeleteBackupRequestStatus{Phase: velerov1.DeleteBackupRequestPhaseInProgress},
+			want:   &types.BackupDeletionStatus{Phase: "InProgress"},
+		},
+		{
+			name:   "processed",
+			status: velerov1.DeleteBackupRequestStatus{Phase: velerov1.DeleteBackupRequestPhaseProcessed},
+			want:   &types.BackupDeletionStatus{Phase: "Processed", Done: true},
+		},
+		{
+			name: "processed with errors",
+			status: velerov1.DeleteBackupRequestStatus{
+				Phase:  velerov1.DeleteBackupRequestPhaseProcessed,
+				Errors: []string{"error deleting backup from backup storage"},
+			},
+			want: &types.BackupDeletionStatus{
+				Phase:  "Processed",
+				Done:   true,
+				Errors: []string{"error deleting backup from backup storage"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getBackupDeletionStatus(&velerov1.DeleteBackupRequest{Status: test.status})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getBackupDeletionStatus() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWaitForBackupDeletion(t *testing.T) {
+	inProgress := &velerov1.DeleteBackupRequest{
+		Status: velerov1.DeleteBackupRequestStatus{Phase: velerov1.DeleteBackupRequestPhaseInProgress},
+	}
+
+	// velero removes the request along with the backup once it's processed successfully
+	polls := []struct {
+		deleteBackupRequest *velerov1.DeleteBackupRequest
+		backupExists        bool
+	}{
+		{deleteBackupRequest: inProgress, backupExists: true},
+		{deleteBackupRequest: inProgress, backupExists: true},
+		{deleteBackupRequest: nil, backupExists: false},
+	}
+
+	i := 0
+	getStatus := func() (*types.BackupDeletionStatus, error) {
+		poll := polls[i]
+		if i < len(polls)-1 {
+			i++
+		}
+		return resolveBackupDeletionStatus(poll.deleteBackupRequest, poll.backupExists), nil
+	}
+
+	got, err := waitForBackupDeletion(context.Background(), getStatus, time.Minute, time.Millisecond)
+	if err != nil {
+		t.Fatalf("waitForBackupDeletion() error = %v", err)
+	}
+	want := &types.BackupDeletionStatus{Phase: "Processed", Done: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("waitForBackupDeletion() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveBackupDeletionStatusNotRequested(t *testing.T) {
+	if got := resolveBackupDeletionStatus(nil, true); got != nil {
+		t.Errorf("resolveBackupDeletionStatus() = %+v, want nil", got)
+	}
+}
@@ -21,15 +21,9 @@ import (
 )
 
 func DownloadRestoreResults(veleroNamespace, restoreName string) ([]types.SnapshotError, []types.SnapshotError, error) {
-	r, err := DownloadRequest(veleroNamespace, veleroapiv1.DownloadTargetKindRestoreResults, restoreName)
+	resultMap, err := downloadRestoreResultMap(veleroNamespace, restoreName)
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed to make download request")
-	}
-	defer r.Close()
-
-	resultMap := map[string]pkgrestore.Result{}
-	if err := json.NewDecoder(r).Decode(&resultMap); err != nil {
-		return nil, nil, errors.Wrap(err, "failed to decode restore results")
+		return nil, nil, err
 	}
 
 	warnings, errors := []types.SnapshotError{}, []types.SnapshotError{}
@@ -55,6 +49,21 @@ func DownloadRestoreResults(veleroNamespace, restoreName string) ([]types.Snapsh
 	return warnings, errors, nil
 }
 
+func downloadRestoreResultMap(veleroNamespace, restoreName string) (map[string]pkgrestore.Result, error) {
+	r, err := DownloadRequest(veleroNamespace, veleroapiv1.DownloadTargetKindRestoreResults, restoreName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make download request")
+	}
+	defer r.Close()
+
+	resultMap := map[string]pkgrestore.Result{}
+	if err := json.NewDecoder(r).Decode(&resultMap); err != nil {
+		return nil, errors.Wrap(err, "failed to decode restore results")
+	}
+
+	return resultMap, nil
+}
+
 func DownloadRequest(veleroNamespace string, kind velerov1.DownloadTargetKind, name string) (io.ReadCloser, error) {
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -0,0 +1,372 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// The customer key is used by the velero aws plugin for SSE-C, from velero-plugin-for-aws v1.2.0 on. Older
+// plugins reject the config key, which makes the backup storage location unavailable. Every object the plugin writes to the bucket
+// (backup tarballs, logs, resource lists, volume snapshot metadata and restore results) is encrypted with the key,
+// which never leaves the cluster except in the requests to the store and is not stored by it.
+// Restic pod volume data is written by restic directly and is not covered, it is encrypted with the restic
+// repository password instead. Only S3 and S3-compatible stores that support SSE-C over TLS can be used, and
+// backups can only be read with the key they were written with.
+const (
+	customerKeySecretName   = "velero-customer-key"
+	customerKeySecretKey    = "customer-key"
+	customerKeyVolumeName   = "customer-key"
+	customerKeyMountPath    = "/customer-key"
+	customerKeyConfigKey    = "customerKeyEncryptionFile"
+	customerKeyLength       = 32
+	customerKeyProviderName = "aws"
+)
+
+// EncryptionKeyInUseError is returned when changing or removing the encryption key while backups written with
+// it exist, since they couldn't be read anymore
+type EncryptionKeyInUseError struct {
+	BackupCount int
+}
+
+func (e EncryptionKeyInUseError) Error() string {
+	return fmt.Sprintf("the encryption key can't be changed or removed while %d backups encrypted with it exist", e.BackupCount)
+}
+
+// IsEncryptionKeyInUseError returns true if the cause of the error is an EncryptionKeyInUseError
+func IsEncryptionKeyInUseError(err error) bool {
+	_, ok := errors.Cause(err).(EncryptionKeyInUseError)
+	return ok
+}
+
+// UnencryptedBackupsExistError is returned when setting an encryption key while backups written without one exist.
+// S3 rejects reads of unencrypted objects that send a customer key, so they couldn't be read anymore.
+type UnencryptedBackupsExistError struct {
+	BackupCount int
+}
+
+func (e UnencryptedBackupsExistError) Error() string {
+	return fmt.Sprintf("%d unencrypted backups exist in the store and can't be read once an encryption key is set", e.BackupCount)
+}
+
+// IsUnencryptedBackupsExistError returns true if the cause of the error is an UnencryptedBackupsExistError
+func IsUnencryptedBackupsExistError(err error) bool {
+	_, ok := errors.Cause(err).(UnencryptedBackupsExistError)
+	return ok
+}
+
+// SupportsStoreEncryption returns true if the velero aws plugin version reads the customer key config
+func SupportsStoreEncryption(awsPluginVersion string) bool {
+	major, minor, ok := parseVeleroVersion(awsPluginVersion)
+	if !ok {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= 2)
+}
+
+// ValidateStoreEncryption checks that the store can be encrypted with a customer key. S3 only accepts SSE-C
+// over TLS, which rules out the internal store and s3 compatible endpoints without https.
+func ValidateStoreEncryption(store *types.Store) error {
+	switch {
+	case store.AWS != nil:
+		return nil
+	case store.Other != nil:
+		if !strings.HasPrefix(strings.ToLower(store.Other.Endpoint), "https://") {
+			return errors.New("encryption requires an https endpoint")
+		}
+		return nil
+	case store.Internal != nil:
+		return errors.New("encryption is not supported for the internal store")
+	}
+	return errors.New("encryption is only supported for S3 compatible stores")
+}
+
+// HasStoreEncryptionKey returns true if backups are configured to be encrypted
+func HasStoreEncryptionKey(ctx context.Context) (bool, error) {
+	currentKey, _, err := getCurrentCustomerKey(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(currentKey) > 0, nil
+}
+
+// CheckStoreEncryptionKeyChange returns an EncryptionKeyInUseError if the key differs from the current one while
+// backups exist in the store. An empty key removes encryption. Setting a key on a store without one returns an
+// UnencryptedBackupsExistError if it has backups, unless allowUnencryptedBackups is set.
+func CheckStoreEncryptionKeyChange(ctx context.Context, key []byte, allowUnencryptedBackups bool) error {
+	currentKey, bsl, err := getCurrentCustomerKey(ctx)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(currentKey, key) {
+		return nil
+	}
+	if len(currentKey) == 0 && (len(key) == 0 || allowUnencryptedBackups) {
+		return nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	backups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("velero.io/storage-location=%s", bsl.Name),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list backups")
+	}
+	if len(backups.Items) == 0 {
+		return nil
+	}
+	if len(currentKey) == 0 {
+		return UnencryptedBackupsExistError{BackupCount: len(backups.Items)}
+	}
+	return EncryptionKeyInUseError{BackupCount: len(backups.Items)}
+}
+
+// getCurrentCustomerKey returns the key the backup storage location is encrypted with, nil if it isn't
+func getCurrentCustomerKey(ctx context.Context) ([]byte, *velerov1.BackupStorageLocation, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if _, ok := bsl.Spec.Config[customerKeyConfigKey]; !ok {
+		return nil, bsl, nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	secret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, customerKeySecretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return nil, bsl, nil
+	} else if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get encryption key secret")
+	}
+
+	return secret.Data[customerKeySecretKey], bsl, nil
+}
+
+// DecodeStoreEncryptionKey decodes a base64 encoded AES-256 key
+func DecodeStoreEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("encryption key must be base64 encoded")
+	}
+	if len(key) != customerKeyLength {
+		return nil, errors.Errorf("encryption key must be %d bytes", customerKeyLength)
+	}
+	return key, nil
+}
+
+// SetStoreEncryptionKey configures the store to encrypt backup data with the key before it's stored.
+// An empty key disables encryption.
+func SetStoreEncryptionKey(ctx context.Context, key []byte) (*velerov1.BackupStorageLocation, error) {
+	return setStoreEncryptionKey(ctx, key, false)
+}
+
+// ReapplyStoreEncryptionKey points the backup storage location at the current key again, if there is one.
+// Updating the store replaces the location's config.
+func ReapplyStoreEncryptionKey(ctx context.Context) (*velerov1.BackupStorageLocation, error) {
+	return setStoreEncryptionKey(ctx, nil, true)
+}
+
+func setStoreEncryptionKey(ctx context.Context, key []byte, keep bool) (*velerov1.BackupStorageLocation, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	namespace := bsl.Namespace
+
+	if keep {
+		if bsl.Spec.Provider != customerKeyProviderName {
+			// the store was changed to one that doesn't support encryption
+			return bsl, nil
+		}
+		_, err := clientset.CoreV1().Secrets(namespace).Get(ctx, customerKeySecretName, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			return bsl, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to get encryption key secret")
+		}
+	}
+
+	enabled := keep || len(key) > 0
+	if enabled && bsl.Spec.Provider != customerKeyProviderName {
+		return nil, errors.New("encryption is only supported for S3 compatible stores")
+	}
+
+	if !keep {
+		if enabled {
+			if err := ensureCustomerKeySecret(ctx, clientset, namespace, key); err != nil {
+				return nil, errors.Wrap(err, "failed to ensure encryption key secret")
+			}
+		} else {
+			err := clientset.CoreV1().Secrets(namespace).Delete(ctx, customerKeySecretName, metav1.DeleteOptions{})
+			if err != nil && !kuberneteserrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "failed to delete encryption key secret")
+			}
+		}
+	}
+
+	if err := setVeleroCustomerKeyVolume(ctx, clientset, namespace, enabled); err != nil {
+		return nil, errors.Wrap(err, "failed to update velero deployment")
+	}
+
+	if bsl.Spec.Config == nil {
+		bsl.Spec.Config = map[string]string{}
+	}
+	if enabled {
+		bsl.Spec.Config[customerKeyConfigKey] = filepath.Join(customerKeyMountPath, customerKeySecretKey)
+	} else {
+		delete(bsl.Spec.Config, customerKeyConfigKey)
+	}
+
+	bsl, err = veleroClient.BackupStorageLocations(namespace).Update(ctx, bsl, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update backup storage location")
+	}
+
+	return bsl, nil
+}
+
+// getStoreEncryptionKey returns the base64 encoded key, or an empty string if backups are not encrypted
+func getStoreEncryptionKey(ctx context.Context, clientset kubernetes.Interface, bsl *velerov1.BackupStorageLocation) (string, error) {
+	if _, ok := bsl.Spec.Config[customerKeyConfigKey]; !ok {
+		return "", nil
+	}
+
+	secret, err := clientset.CoreV1().Secrets(bsl.Namespace).Get(ctx, customerKeySecretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", errors.Wrap(err, "failed to get encryption key secret")
+	}
+
+	return base64.StdEncoding.EncodeToString(secret.Data[customerKeySecretKey]), nil
+}
+
+func ensureCustomerKeySecret(ctx context.Context, clientset kubernetes.Interface, namespace string, key []byte) error {
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, customerKeySecretName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      customerKeySecretName,
+				Namespace: namespace,
+			},
+			Data: map[string][]byte{
+				customerKeySecretKey: key,
+			},
+		}
+		if _, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create secret")
+		}
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get secret")
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[customerKeySecretKey] = key
+	if _, err := clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update secret")
+	}
+
+	return nil
+}
+
+func setVeleroCustomerKeyVolume(ctx context.Context, clientset *kubernetes.Clientset, namespace string, enabled bool) error {
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		podSpec := &veleroDeployment.Spec.Template.Spec
+		if len(podSpec.Containers) == 0 {
+			continue
+		}
+		container := &podSpec.Containers[0]
+
+		volumes := []corev1.Volume{}
+		for _, volume := range podSpec.Volumes {
+			if volume.Name != customerKeyVolumeName {
+				volumes = append(volumes, volume)
+			}
+		}
+		volumeMounts := []corev1.VolumeMount{}
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.Name != customerKeyVolumeName {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+
+		if enabled {
+			volumes = append(volumes, corev1.Volume{
+				Name: customerKeyVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: customerKeySecretName,
+					},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      customerKeyVolumeName,
+				MountPath: customerKeyMountPath,
+				ReadOnly:  true,
+			})
+		}
+
+		podSpec.Volumes = volumes
+		container.VolumeMounts = volumeMounts
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(ctx, &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package snapshot
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDecodeStoreEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "aes-256 key",
+			encoded: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+		},
+		{
+			name:    "too short",
+			encoded: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 16))),
+			wantErr: true,
+		},
+		{
+			name:    "not base64",
+			encoded: "not a key!",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := DecodeStoreEncryptionKey(test.encoded)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got key %q", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(key) != 32 {
+				t.Errorf("Expected 32 byte key, got %d bytes", len(key))
+			}
+		})
+	}
+}
+
+func TestSupportsStoreEncryption(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.1.0", false},
+		{"v1.2.0", true},
+		{"v1.10.1", true},
+		{"", false},
+	}
+	for _, test := range tests {
+		got := SupportsStoreEncryption(test.version)
+		if got != test.want {
+			t.Errorf("Expected %v for %q, got %v", test.want, test.version, got)
+		}
+	}
+}
+
+func TestValidateStoreEncryption(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   types.Store
+		wantErr bool
+	}{
+		{
+			name:  "aws",
+			store: types.Store{AWS: &types.StoreAWS{Region: "us-east-1"}},
+		},
+		{
+			name:  "s3 compatible over https",
+			store: types.Store{Other: &types.StoreOther{Endpoint: "https://minio.example.com"}},
+		},
+		{
+			name:    "s3 compatible over http",
+			store:   types.Store{Other: &types.StoreOther{Endpoint: "http://minio.example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "internal",
+			store:   types.Store{Internal: &types.StoreInternal{Endpoint: "http://rook-ceph-rgw"}},
+			wantErr: true,
+		},
+		{
+			name:    "google",
+			store:   types.Store{Google: &types.StoreGoogle{}},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateStoreEncryption(&test.store)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateStoreEncryption() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetAWSPluginVersion(t *testing.T) {
+	initContainers := []corev1.Container{
+		{Name: "velero-plugin-for-gcp", Image: "velero/velero-plugin-for-gcp:v1.1.0"},
+		{Name: "velero-plugin-for-aws", Image: "docker.io/velero/velero-plugin-for-aws:v1.1.0"},
+	}
+	if got := getAWSPluginVersion(initContainers); got != "v1.1.0" {
+		t.Errorf("Expected v1.1.0, got %q", got)
+	}
+	if got := getAWSPluginVersion(initContainers[:1]); got != "" {
+		t.Errorf("Expected no version, got %q", got)
+	}
+}
@@ -0,0 +1,137 @@
+package snapshot
+
+import (
+	"context"
+	"math"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// backupEstimateSampleSize is how many of the most recent completed backups the estimate is based on
+const backupEstimateSampleSize = 10
+
+const (
+	BackupEstimateConfidenceNone   = "none"
+	BackupEstimateConfidenceLow    = "low"
+	BackupEstimateConfidenceMedium = "medium"
+	BackupEstimateConfidenceHigh   = "high"
+)
+
+// GetAppBackupEstimate estimates how long the next backup of the app will take from the durations of its
+// recent completed backups
+func GetAppBackupEstimate(ctx context.Context, appSlug string) (*types.BackupEstimate, error) {
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get app from slug")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroBackups, err := veleroClient.Backups(backendStorageLocation.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	history := []types.BackupHistoryEntry{}
+	for _, veleroBackup := range veleroBackups.Items {
+		entry, ok := getBackupHistoryEntry(veleroBackup, a.ID, a.Slug)
+		if !ok {
+			continue
+		}
+		history = append(history, *entry)
+	}
+
+	sortBackupHistory(history)
+
+	return estimateBackupDuration(history), nil
+}
+
+// estimateBackupDuration averages the durations of the most recent completed app backups in the history, which
+// must be sorted newest first. instance backups are left out since they also back up the other apps.
+func estimateBackupDuration(history []types.BackupHistoryEntry) *types.BackupEstimate {
+	durations := []time.Duration{}
+	for _, entry := range history {
+		if len(durations) == backupEstimateSampleSize {
+			break
+		}
+		if entry.IsInstance || entry.Status != "Completed" {
+			continue
+		}
+		if entry.StartedAt == nil || entry.FinishedAt == nil || entry.FinishedAt.Before(*entry.StartedAt) {
+			continue
+		}
+		durations = append(durations, entry.FinishedAt.Sub(*entry.StartedAt))
+	}
+
+	estimate := &types.BackupEstimate{
+		SampleSize: len(durations),
+		Confidence: BackupEstimateConfidenceNone,
+	}
+	if len(durations) == 0 {
+		return estimate
+	}
+
+	var total time.Duration
+	min, max := durations[0], durations[0]
+	for _, d := range durations {
+		total += d
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+	}
+	average := total / time.Duration(len(durations))
+
+	var variance float64
+	for _, d := range durations {
+		diff := float64(d - average)
+		variance += diff * diff
+	}
+	variance /= float64(len(durations))
+
+	estimate.EstimatedSeconds = int64(average.Round(time.Second).Seconds())
+	estimate.EstimatedHuman = units.HumanDuration(average)
+	estimate.MinSeconds = int64(min.Round(time.Second).Seconds())
+	estimate.MaxSeconds = int64(max.Round(time.Second).Seconds())
+	estimate.Confidence = backupEstimateConfidence(len(durations), average, math.Sqrt(variance))
+
+	return estimate
+}
+
+// backupEstimateConfidence is higher the more backups the estimate is based on and the less their durations vary
+func backupEstimateConfidence(sampleSize int, average time.Duration, stddev float64) string {
+	if sampleSize < 3 || average <= 0 {
+		return BackupEstimateConfidenceLow
+	}
+
+	variation := stddev / float64(average)
+	switch {
+	case variation > 0.5:
+		return BackupEstimateConfidenceLow
+	case variation > 0.25 || sampleSize < 5:
+		return BackupEstimateConfidenceMedium
+	}
+	return BackupEstimateConfidenceHigh
+}
@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestEstimateBackupDuration(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	completed := func(name string, d time.Duration) types.BackupHistoryEntry {
+		finished := start.Add(d)
+		return types.BackupHistoryEntry{Name: name, Status: "Completed", StartedAt: &start, FinishedAt: &finished}
+	}
+
+	tests := []struct {
+		name    string
+		history []types.BackupHistoryEntry
+		want    *types.BackupEstimate
+	}{
+		{
+			name:    "no backups",
+			history: []types.BackupHistoryEntry{},
+			want:    &types.BackupEstimate{Confidence: BackupEstimateConfidenceNone},
+		},
+		{
+			name: "only failed, in progress and instance backups",
+			history: []types.BackupHistoryEntry{
+				{Name: "in-progress", Status: "InProgress", StartedAt: &start},
+				func() types.BackupHistoryEntry {
+					e := completed("failed", time.Minute)
+					e.Status = "Failed"
+					return e
+				}(),
+				func() types.BackupHistoryEntry {
+					e := completed("instance", time.Hour)
+					e.IsInstance = true
+					return e
+				}(),
+			},
+			want: &types.BackupEstimate{Confidence: BackupEstimateConfidenceNone},
+		},
+		{
+			name: "one backup",
+			history: []types.BackupHistoryEntry{
+				completed("a", 12*time.Minute),
+			},
+			want: &types.BackupEstimate{
+				SampleSize:       1,
+				EstimatedSeconds: 720,
+				EstimatedHuman:   "12 minutes",
+				MinSeconds:       720,
+				MaxSeconds:       720,
+				Confidence:       BackupEstimateConfidenceLow,
+			},
+		},
+		{
+			name: "consistent backups",
+			history: []types.BackupHistoryEntry{
+				completed("a", 11*time.Minute),
+				completed("b", 12*time.Minute),
+				completed("c", 12*time.Minute),
+				completed("d", 12*time.Minute),
+				completed("e", 13*time.Minute),
+			},
+			want: &types.BackupEstimate{
+				SampleSize:       5,
+				EstimatedSeconds: 720,
+				EstimatedHuman:   "12 minutes",
+				MinSeconds:       660,
+				MaxSeconds:       780,
+				Confidence:       BackupEstimateConfidenceHigh,
+			},
+		},
+		{
+			name: "varying backups",
+			history: []types.BackupHistoryEntry{
+				completed("a", time.Minute),
+				completed("b", 10*time.Minute),
+				completed("c", time.Minute),
+			},
+			want: &types.BackupEstimate{
+				SampleSize:       3,
+				EstimatedSeconds: 240,
+				EstimatedHuman:   "4 minutes",
+				MinSeconds:       60,
+				MaxSeconds:       600,
+				Confidence:       BackupEstimateConfidenceLow,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := estimateBackupDuration(test.history)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("estimateBackupDuration() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestEstimateBackupDurationSampleSize(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	history := []types.BackupHistoryEntry{}
+	for i := 0; i < backupEstimateSampleSize+5; i++ {
+		finished := start.Add(time.Minute)
+		history = append(history, types.BackupHistoryEntry{Status: "Completed", StartedAt: &start, FinishedAt: &finished})
+	}
+
+	got := estimateBackupDuration(history)
+	if got.SampleSize != backupEstimateSampleSize {
+		t.Errorf("estimateBackupDuration() sample size = %d, want %d", got.SampleSize, backupEstimateSampleSize)
+	}
+}
@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// fanOutOfAnnotation names the scheduled backup a fan-out backup was copied from
+const fanOutOfAnnotation = "kots.io/snapshot-fan-out-of"
+
+// IsFanOutBackup returns true if the backup is a copy of a scheduled backup to another storage location
+func IsFanOutBackup(backup *velerov1.Backup) bool {
+	_, ok := backup.Annotations[fanOutOfAnnotation]
+	return ok
+}
+
+// ValidateFanOutLocations checks that each fan-out location is a velero backup storage location other than the
+// default one, which every backup already goes to
+func ValidateFanOutLocations(ctx context.Context, locations []string) error {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	seen := map[string]bool{}
+	for _, location := range locations {
+		if location == bsl.Name {
+			return errors.Errorf("%s is the default storage location", location)
+		}
+		if seen[location] {
+			return errors.Errorf("storage location %s is listed more than once", location)
+		}
+		seen[location] = true
+
+		fanOutBSL, err := veleroClient.BackupStorageLocations(bsl.Namespace).Get(ctx, location, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			return errors.Errorf("storage location %s not found in namespace %s", location, bsl.Namespace)
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get storage location %s", location)
+		}
+		if IsStorageLocationReadOnly(fanOutBSL) {
+			return errors.Errorf("storage location %s is read-only", location)
+		}
+	}
+
+	return nil
+}
+
+// createFanOutBackups creates a copy of the scheduled backup for each of the other storage locations, since velero
+// only writes a backup to one location. The scheduled backup has already been created, so failing to create a
+// copy is logged rather than failing the schedule. Locations made read-only since they were validated are skipped,
+// velero would fail the copy.
+func createFanOutBackups(ctx context.Context, veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup, locations []string) {
+	for _, location := range locations {
+		fanOutBSL, err := veleroClient.BackupStorageLocations(backup.Namespace).Get(ctx, location, metav1.GetOptions{})
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to get storage location %s for fan-out backup of %s", location, backup.Name))
+			continue
+		}
+		if IsStorageLocationReadOnly(fanOutBSL) {
+			logger.Infof("Skipping fan-out backup of %s to storage location %s because it is read-only", backup.Name, location)
+			continue
+		}
+
+		fanOutBackup, err := veleroClient.Backups(backup.Namespace).Create(ctx, newFanOutBackup(backup, location), metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to create fan-out backup of %s to storage location %s", backup.Name, location))
+			continue
+		}
+		logger.Infof("Created fan-out backup %s of %s to storage location %s", fanOutBackup.Name, backup.Name, location)
+	}
+}
+
+func newFanOutBackup(backup *velerov1.Backup, location string) *velerov1.Backup {
+	annotations := map[string]string{
+		"kots.io/snapshot-trigger":   backup.Annotations["kots.io/snapshot-trigger"],
+		"kots.io/snapshot-requested": backup.Annotations["kots.io/snapshot-requested"],
+		fanOutOfAnnotation:           backup.Name,
+	}
+	for _, key := range backupSourceAnnotations {
+		if value, ok := backup.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+
+	fanOutBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: backup.Name + "-",
+			Namespace:    backup.Namespace,
+			Annotations:  annotations,
+		},
+		Spec: *backup.Spec.DeepCopy(),
+	}
+	fanOutBackup.Spec.StorageLocation = location
+
+	return fanOutBackup
+}
+
+// GetBackupToUnquiesce returns the backup whose quiesced workloads can be scaled back now that the backup is done.
+// That's the backup itself, or the backup it was copied from, once it and all of its fan-out copies are done, since
+// the copies back up the same workloads. It returns nil if there is none yet.
+func GetBackupToUnquiesce(ctx context.Context, veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup) (*velerov1.Backup, error) {
+	primary := backup
+	if name, ok := backup.Annotations[fanOutOfAnnotation]; ok {
+		parent, err := veleroClient.Backups(backup.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get backup %s", name)
+		}
+		primary = parent
+	}
+
+	if !NeedsUnquiesce(primary) || !IsBackupDone(primary) {
+		return nil, nil
+	}
+
+	running, err := hasRunningFanOutCopies(ctx, veleroClient, primary)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check fan-out backups")
+	}
+	if running {
+		return nil, nil
+	}
+
+	return primary, nil
+}
+
+func hasRunningFanOutCopies(ctx context.Context, veleroClient *veleroclientv1.VeleroV1Client, backup *velerov1.Backup) (bool, error) {
+	backups, err := veleroClient.Backups(backup.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list backups")
+	}
+	return !fanOutCopiesDone(backups.Items, backup.Name), nil
+}
+
+// fanOutCopiesDone returns true if every fan-out copy of the backup is done
+func fanOutCopiesDone(backups []velerov1.Backup, name string) bool {
+	for _, backup := range backups {
+		if backup.Annotations[fanOutOfAnnotation] != name {
+			continue
+		}
+		if !IsBackupDone(&backup) {
+			return false
+		}
+	}
+	return true
+}
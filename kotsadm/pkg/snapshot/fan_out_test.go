@@ -0,0 +1,95 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewFanOutBackup(t *testing.T) {
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-app-abcde",
+			Namespace: "velero",
+			Annotations: map[string]string{
+				"kots.io/snapshot-trigger":   "schedule",
+				"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+				"kots.io/snapshot-schedule":  "0 0 * * *",
+				"kots.io/app-id":             "app-id",
+				"kots.io/app-sequence":       "3",
+				quiescedWorkloadsAnnotation:  "[]",
+			},
+		},
+		Spec: velerov1.BackupSpec{
+			IncludedNamespaces: []string{"default"},
+			TTL:                metav1.Duration{Duration: 72 * time.Hour},
+			StorageLocation:    "default",
+		},
+	}
+
+	got := newFanOutBackup(backup, "offsite")
+
+	if got.GenerateName != "my-app-abcde-" || got.Namespace != "velero" {
+		t.Errorf("Expected generate name my-app-abcde- in velero, got %s in %s", got.GenerateName, got.Namespace)
+	}
+	if got.Spec.StorageLocation != "offsite" {
+		t.Errorf("Expected storage location offsite, got %s", got.Spec.StorageLocation)
+	}
+	if backup.Spec.StorageLocation != "default" {
+		t.Errorf("Expected scheduled backup storage location to be unchanged, got %s", backup.Spec.StorageLocation)
+	}
+	if !reflect.DeepEqual(got.Spec.IncludedNamespaces, backup.Spec.IncludedNamespaces) || got.Spec.TTL != backup.Spec.TTL {
+		t.Errorf("Expected spec to be copied, got %+v", got.Spec)
+	}
+
+	wantAnnotations := map[string]string{
+		"kots.io/snapshot-trigger":   "schedule",
+		"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+		"kots.io/snapshot-schedule":  "0 0 * * *",
+		"kots.io/app-id":             "app-id",
+		"kots.io/app-sequence":       "3",
+		fanOutOfAnnotation:           "my-app-abcde",
+	}
+	if !reflect.DeepEqual(got.Annotations, wantAnnotations) {
+		t.Errorf("Expected annotations %v, got %v", wantAnnotations, got.Annotations)
+	}
+}
+
+func TestFanOutCopiesDone(t *testing.T) {
+	backup := func(name string, fanOutOf string, phase velerov1.BackupPhase) velerov1.Backup {
+		b := velerov1.Backup{}
+		b.Name = name
+		if fanOutOf != "" {
+			b.Annotations = map[string]string{fanOutOfAnnotation: fanOutOf}
+		}
+		b.Status.Phase = phase
+		return b
+	}
+
+	backups := []velerov1.Backup{
+		backup("primary-done", "", velerov1.BackupPhaseCompleted),
+		backup("primary-done-1", "primary-done", velerov1.BackupPhaseCompleted),
+		backup("primary-done-2", "primary-done", velerov1.BackupPhasePartiallyFailed),
+		backup("primary-running", "", velerov1.BackupPhaseCompleted),
+		backup("primary-running-1", "primary-running", velerov1.BackupPhaseCompleted),
+		backup("primary-running-2", "primary-running", velerov1.BackupPhaseInProgress),
+		backup("primary-new", "", velerov1.BackupPhaseCompleted),
+		backup("primary-new-1", "primary-new", ""),
+		backup("no-copies", "", velerov1.BackupPhaseCompleted),
+	}
+
+	tests := map[string]bool{
+		"primary-done":    true,
+		"primary-running": false,
+		"primary-new":     false,
+		"no-copies":       true,
+	}
+	for name, want := range tests {
+		if got := fanOutCopiesDone(backups, name); got != want {
+			t.Errorf("fanOutCopiesDone(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// awsFIPSRegions are the regions s3 serves a FIPS endpoint in, across the aws and aws-us-gov partitions
+var awsFIPSRegions = map[string]bool{
+	"us-east-1":     true,
+	"us-east-2":     true,
+	"us-west-1":     true,
+	"us-west-2":     true,
+	"ca-central-1":  true,
+	"ca-west-1":     true,
+	"us-gov-east-1": true,
+	"us-gov-west-1": true,
+}
+
+// ValidateAWSFIPSRegion checks that s3 has a FIPS endpoint in the region
+func ValidateAWSFIPSRegion(region string) error {
+	if !awsFIPSRegions[region] {
+		return errors.Errorf("region %q does not have a FIPS S3 endpoint", region)
+	}
+	return nil
+}
+
+// awsFIPSEndpoint returns the FIPS S3 endpoint of the region. Both partitions use the amazonaws.com domain.
+func awsFIPSEndpoint(region string) string {
+	return fmt.Sprintf("https://s3-fips.%s.amazonaws.com", region)
+}
+
+// isAWSFIPSEndpoint returns true if the backup storage location's s3 url is the FIPS endpoint of its region,
+// which makes it an aws store rather than an s3 compatible one
+func isAWSFIPSEndpoint(endpoint string, region string) bool {
+	return awsFIPSRegions[region] && endpoint == awsFIPSEndpoint(region)
+}
@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"testing"
+)
+
+func TestValidateAWSFIPSRegion(t *testing.T) {
+	tests := []struct {
+		region  string
+		wantErr bool
+	}{
+		{region: "us-gov-west-1", wantErr: false},
+		{region: "us-east-1", wantErr: false},
+		{region: "eu-west-1", wantErr: true},
+		{region: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.region, func(t *testing.T) {
+			err := ValidateAWSFIPSRegion(test.region)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateAWSFIPSRegion() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsAWSFIPSEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		region   string
+		want     bool
+	}{
+		{name: "fips endpoint", endpoint: "https://s3-fips.us-gov-west-1.amazonaws.com", region: "us-gov-west-1", want: true},
+		{name: "another region's fips endpoint", endpoint: "https://s3-fips.us-gov-east-1.amazonaws.com", region: "us-gov-west-1", want: false},
+		{name: "s3 compatible endpoint", endpoint: "http://minio:9000", region: "us-east-1", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isAWSFIPSEndpoint(test.endpoint, test.region); got != test.want {
+				t.Errorf("isAWSFIPSEndpoint() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
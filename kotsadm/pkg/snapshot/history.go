@@ -0,0 +1,147 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	units "github.com/docker/go-units"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// GetAppBackupHistory returns the app and instance backups that include the app, newest first, each with the
+// app version it was taken at so a restore point can be picked by version
+func GetAppBackupHistory(ctx context.Context, appSlug string) ([]types.BackupHistoryEntry, error) {
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get app from slug")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroBackups, err := veleroClient.Backups(backendStorageLocation.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	history := []types.BackupHistoryEntry{}
+	versionLabels := map[int64]string{}
+
+	for _, veleroBackup := range veleroBackups.Items {
+		entry, ok := getBackupHistoryEntry(veleroBackup, a.ID, a.Slug)
+		if !ok {
+			continue
+		}
+
+		if entry.Sequence >= 0 {
+			versionLabel, ok := versionLabels[entry.Sequence]
+			if !ok {
+				version, err := store.GetStore().GetAppVersion(a.ID, entry.Sequence)
+				if err != nil && !store.GetStore().IsNotFound(err) {
+					return nil, errors.Wrapf(err, "failed to get app version %d", entry.Sequence)
+				}
+				if version != nil && version.KOTSKinds != nil {
+					versionLabel = version.KOTSKinds.Installation.Spec.VersionLabel
+				}
+				versionLabels[entry.Sequence] = versionLabel
+			}
+			entry.VersionLabel = versionLabel
+		}
+
+		if _, ok := veleroBackup.Annotations["kots.io/snapshot-volume-bytes"]; !ok && isBackupFinished(entry.Status) {
+			volumeSummary, err := getSnapshotVolumeSummary(ctx, &veleroBackup)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get volume summary for backup %s", veleroBackup.Name)
+			}
+			entry.VolumeBytes = volumeSummary.VolumeBytes
+			entry.VolumeSizeHuman = volumeSummary.VolumeSizeHuman
+		}
+
+		history = append(history, *entry)
+	}
+
+	sortBackupHistory(history)
+
+	return history, nil
+}
+
+// getBackupHistoryEntry returns the history entry for a backup of the app, or false if the backup doesn't include the app
+func getBackupHistoryEntry(veleroBackup velerov1.Backup, appID string, appSlug string) (*types.BackupHistoryEntry, bool) {
+	entry := types.BackupHistoryEntry{
+		Name:     veleroBackup.Name,
+		Status:   string(veleroBackup.Status.Phase),
+		Trigger:  veleroBackup.Annotations["kots.io/snapshot-trigger"],
+		Sequence: -1,
+	}
+
+	if veleroBackup.Annotations["kots.io/instance"] == "true" {
+		appsSequences := map[string]int64{}
+		if err := json.Unmarshal([]byte(veleroBackup.Annotations["kots.io/apps-sequences"]), &appsSequences); err != nil {
+			return nil, false
+		}
+		sequence, ok := appsSequences[appSlug]
+		if !ok {
+			return nil, false
+		}
+		entry.IsInstance = true
+		entry.Sequence = sequence
+	} else if veleroBackup.Annotations["kots.io/app-id"] == appID {
+		if sequence, err := strconv.ParseInt(veleroBackup.Annotations["kots.io/app-sequence"], 10, 64); err == nil {
+			entry.Sequence = sequence
+		}
+	} else {
+		return nil, false
+	}
+
+	if entry.Status == "" {
+		entry.Status = "New"
+	}
+
+	if veleroBackup.Status.StartTimestamp != nil {
+		entry.StartedAt = &veleroBackup.Status.StartTimestamp.Time
+	}
+	if veleroBackup.Status.CompletionTimestamp != nil {
+		entry.FinishedAt = &veleroBackup.Status.CompletionTimestamp.Time
+	}
+
+	if volumeBytes, err := strconv.ParseInt(veleroBackup.Annotations["kots.io/snapshot-volume-bytes"], 10, 64); err == nil {
+		entry.VolumeBytes = volumeBytes
+		entry.VolumeSizeHuman = units.HumanSize(float64(volumeBytes))
+	}
+
+	return &entry, true
+}
+
+func isBackupFinished(status string) bool {
+	return status != "New" && status != "InProgress"
+}
+
+// sortBackupHistory orders the history newest first, backups that haven't started yet come first
+func sortBackupHistory(history []types.BackupHistoryEntry) {
+	sort.SliceStable(history, func(i, j int) bool {
+		if history[i].StartedAt == nil || history[j].StartedAt == nil {
+			return history[i].StartedAt == nil && history[j].StartedAt != nil
+		}
+		return history[i].StartedAt.After(*history[j].StartedAt)
+	})
+}
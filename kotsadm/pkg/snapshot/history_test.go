@@ -0,0 +1,149 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetBackupHistoryEntry(t *testing.T) {
+	started := metav1.NewTime(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		backup    velerov1.Backup
+		wantOK    bool
+		wantEntry *types.BackupHistoryEntry
+	}{
+		{
+			name: "app backup",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my-app-abcde",
+					Annotations: map[string]string{
+						"kots.io/app-id":                "app-id",
+						"kots.io/app-sequence":          "3",
+						"kots.io/snapshot-trigger":      "manual",
+						"kots.io/snapshot-volume-bytes": "1000",
+					},
+				},
+				Status: velerov1.BackupStatus{
+					Phase:          velerov1.BackupPhaseCompleted,
+					StartTimestamp: &started,
+				},
+			},
+			wantOK: true,
+			wantEntry: &types.BackupHistoryEntry{
+				Name:            "my-app-abcde",
+				Status:          "Completed",
+				Trigger:         "manual",
+				Sequence:        3,
+				StartedAt:       &started.Time,
+				VolumeBytes:     1000,
+				VolumeSizeHuman: "1kB",
+			},
+		},
+		{
+			name: "instance backup with the app",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "instance-abcde",
+					Annotations: map[string]string{
+						"kots.io/instance":       "true",
+						"kots.io/apps-sequences": `{"my-app": 5, "other-app": 1}`,
+					},
+				},
+			},
+			wantOK: true,
+			wantEntry: &types.BackupHistoryEntry{
+				Name:       "instance-abcde",
+				Status:     "New",
+				IsInstance: true,
+				Sequence:   5,
+			},
+		},
+		{
+			name: "instance backup without the app",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "instance-fghij",
+					Annotations: map[string]string{
+						"kots.io/instance":       "true",
+						"kots.io/apps-sequences": `{"other-app": 1}`,
+					},
+				},
+			},
+			wantOK: false,
+		},
+		{
+			name: "another app's backup",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "other-app-abcde",
+					Annotations: map[string]string{
+						"kots.io/app-id":       "other-app-id",
+						"kots.io/app-sequence": "1",
+					},
+				},
+			},
+			wantOK: false,
+		},
+		{
+			name: "app backup without a sequence",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "my-app-fghij",
+					Annotations: map[string]string{
+						"kots.io/app-id": "app-id",
+					},
+				},
+				Status: velerov1.BackupStatus{
+					Phase: velerov1.BackupPhaseInProgress,
+				},
+			},
+			wantOK: true,
+			wantEntry: &types.BackupHistoryEntry{
+				Name:     "my-app-fghij",
+				Status:   "InProgress",
+				Sequence: -1,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry, ok := getBackupHistoryEntry(test.backup, "app-id", "my-app")
+			if ok != test.wantOK {
+				t.Fatalf("getBackupHistoryEntry() ok = %v, want %v", ok, test.wantOK)
+			}
+			if !reflect.DeepEqual(entry, test.wantEntry) {
+				t.Errorf("getBackupHistoryEntry() = %+v, want %+v", entry, test.wantEntry)
+			}
+		})
+	}
+}
+
+func TestSortBackupHistory(t *testing.T) {
+	older := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+
+	history := []types.BackupHistoryEntry{
+		{Name: "older", StartedAt: &older},
+		{Name: "new"},
+		{Name: "newer", StartedAt: &newer},
+	}
+	sortBackupHistory(history)
+
+	got := []string{}
+	for _, entry := range history {
+		got = append(got, entry.Name)
+	}
+	want := []string{"new", "newer", "older"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortBackupHistory() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ValidateHookSettings checks the hook timeout is a positive duration and the error mode is one velero supports
+func ValidateHookSettings(settings *types.HookSettings) error {
+	if settings == nil {
+		return nil
+	}
+
+	if settings.Timeout != "" {
+		timeout, err := time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return errors.Wrapf(err, "invalid timeout %q", settings.Timeout)
+		}
+		if timeout <= 0 {
+			return errors.Errorf("timeout %q must be positive", settings.Timeout)
+		}
+	}
+
+	switch velerov1.HookErrorMode(settings.OnError) {
+	case "", velerov1.HookErrorModeContinue, velerov1.HookErrorModeFail:
+	default:
+		return errors.Errorf("invalid onError %q, must be %s or %s", settings.OnError, velerov1.HookErrorModeContinue, velerov1.HookErrorModeFail)
+	}
+
+	for hookName, container := range settings.Containers {
+		if hookName == "" {
+			return errors.Errorf("hook name is required for container %q", container)
+		}
+		if errs := validation.IsDNS1123Label(container); len(errs) > 0 {
+			return errors.Errorf("invalid container name %q for hook %s", container, hookName)
+		}
+	}
+
+	return nil
+}
+
+// applyHookSettings overrides the timeout and error mode of every exec hook in the backup spec with the
+// settings the operator configured for the app, and the container of the exec hooks in the named hook specs.
+// Without a container velero runs the hook in the pod's first container, which is wrong for sidecars.
+func applyHookSettings(hooks *velerov1.BackupHooks, settings *types.HookSettings) error {
+	if settings == nil {
+		return nil
+	}
+
+	var timeout *metav1.Duration
+	if settings.Timeout != "" {
+		d, err := time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse hook timeout")
+		}
+		timeout = &metav1.Duration{Duration: d}
+	}
+
+	apply := func(resourceHooks []velerov1.BackupResourceHook, container string) {
+		for i := range resourceHooks {
+			if resourceHooks[i].Exec == nil {
+				continue
+			}
+			if timeout != nil {
+				resourceHooks[i].Exec.Timeout = *timeout
+			}
+			if settings.OnError != "" {
+				resourceHooks[i].Exec.OnError = velerov1.HookErrorMode(settings.OnError)
+			}
+			if container != "" {
+				resourceHooks[i].Exec.Container = container
+			}
+		}
+	}
+
+	for i := range hooks.Resources {
+		container := settings.Containers[hooks.Resources[i].Name]
+		apply(hooks.Resources[i].PreHooks, container)
+		apply(hooks.Resources[i].PostHooks, container)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateHookSettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  *types.HookSettings
+		wantError bool
+	}{
+		{"nil", nil, false},
+		{"valid", &types.HookSettings{Timeout: "5m", OnError: "Continue"}, false},
+		{"fail", &types.HookSettings{OnError: "Fail"}, false},
+		{"bad timeout", &types.HookSettings{Timeout: "5 minutes"}, true},
+		{"zero timeout", &types.HookSettings{Timeout: "0s"}, true},
+		{"bad onError", &types.HookSettings{OnError: "ignore"}, true},
+		{"container", &types.HookSettings{Containers: map[string]string{"db": "postgres"}}, false},
+		{"bad container", &types.HookSettings{Containers: map[string]string{"db": "Postgres_1"}}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateHookSettings(test.settings)
+			if (err != nil) != test.wantError {
+				t.Errorf("Expected error %v, got %v", test.wantError, err)
+			}
+		})
+	}
+}
+
+func TestApplyHookSettings(t *testing.T) {
+	hooks := velerov1.BackupHooks{
+		Resources: []velerov1.BackupResourceHookSpec{
+			{
+				Name: "db",
+				PreHooks: []velerov1.BackupResourceHook{
+					{Exec: &velerov1.ExecHook{Command: []string{"pg_dump"}, OnError: velerov1.HookErrorModeFail}},
+				},
+				PostHooks: []velerov1.BackupResourceHook{
+					{Exec: &velerov1.ExecHook{Command: []string{"rm", "/tmp/dump"}, Timeout: metav1.Duration{Duration: time.Minute}}},
+				},
+			},
+			{
+				Name: "cache",
+				PreHooks: []velerov1.BackupResourceHook{
+					{Exec: &velerov1.ExecHook{Container: "redis", Command: []string{"redis-cli", "save"}}},
+				},
+			},
+		},
+	}
+
+	err := applyHookSettings(&hooks, &types.HookSettings{Timeout: "10m", OnError: "Continue", Containers: map[string]string{"db": "postgres"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if container := hooks.Resources[1].PreHooks[0].Exec.Container; container != "redis" {
+		t.Errorf("Expected hooks without a configured container to keep redis, got %q", container)
+	}
+
+	for _, hook := range append(hooks.Resources[0].PreHooks, hooks.Resources[0].PostHooks...) {
+		if hook.Exec.Timeout.Duration != 10*time.Minute {
+			t.Errorf("Expected timeout 10m, got %s", hook.Exec.Timeout.Duration)
+		}
+		if hook.Exec.OnError != velerov1.HookErrorModeContinue {
+			t.Errorf("Expected onError Continue, got %s", hook.Exec.OnError)
+		}
+		if hook.Exec.Container != "postgres" {
+			t.Errorf("Expected container postgres, got %q", hook.Exec.Container)
+		}
+	}
+}
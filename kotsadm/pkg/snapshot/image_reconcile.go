@@ -0,0 +1,284 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	"github.com/replicatedhq/kots/kotsadm/pkg/registry"
+	registrytypes "github.com/replicatedhq/kots/kotsadm/pkg/registry/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	kotsregistry "github.com/replicatedhq/kots/pkg/docker/registry"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// veleroImageReconcileIntervalSeconds is how often the velero deployment and restic daemonset are checked for drift
+	veleroImageReconcileIntervalSeconds = 60
+	// veleroRegistryPullSecretName matches the pull secret name kots uses for the local registry
+	veleroRegistryPullSecretName = "kotsadm-replicated-registry"
+)
+
+// IsVeleroImageReconcileEnabled returns true when kotsadm should keep the velero images pointed at the
+// registry kotsadm is configured to use if there is no velero image registry override
+func IsVeleroImageReconcileEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("ENABLE_VELERO_IMAGE_RECONCILE"))
+	return enabled
+}
+
+// StartVeleroReconciler periodically puts back what kots configured on the velero deployment and restic daemonset,
+// when ENABLE_VELERO_IMAGE_RECONCILE is set. A velero upgrade through helm or its own install command resets the
+// images to the public registries, which breaks snapshots in airgap installs, and removes the extra init containers.
+// Without the flag, the velero image registry and extra containers are only applied when they're saved.
+func StartVeleroReconciler() {
+	if !IsVeleroImageReconcileEnabled() {
+		return
+	}
+
+	go func() {
+		for {
+			if err := ReconcileVelero(context.TODO()); err != nil {
+				logger.Error(errors.Wrap(err, "failed to reconcile velero"))
+			}
+			time.Sleep(time.Second * veleroImageReconcileIntervalSeconds)
+		}
+	}()
+}
+
+// ReconcileVelero applies the extra init containers to the velero deployment and rewrites any velero, plugin or
+// restic image that isn't pulled from the velero registry, making sure the pods can pull from it. Images are left
+// alone when there is no velero registry.
+func ReconcileVelero(ctx context.Context) error {
+	registrySettings, err := getVeleroRegistry()
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero registry")
+	}
+
+	extra, err := GetVeleroExtraContainers()
+	if err != nil {
+		return errors.Wrap(err, "failed to get velero extra containers")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if namespace == "" {
+		return nil
+	}
+
+	if registrySettings != nil && registrySettings.Username != "" {
+		if err := ensureVeleroRegistryPullSecret(ctx, clientset, namespace, registrySettings); err != nil {
+			return errors.Wrap(err, "failed to ensure registry pull secret")
+		}
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+	for _, veleroDeployment := range veleroDeployments {
+		original := veleroDeployment.DeepCopy()
+
+		// the extra init containers are put back first so that their images are rewritten too
+		if err := applyVeleroExtraContainers(&veleroDeployment, extra); err != nil {
+			return errors.Wrapf(err, "failed to apply extra containers to velero deployment %s", veleroDeployment.Name)
+		}
+		if registrySettings != nil {
+			rewriteVeleroPodSpecImages(&veleroDeployment.Spec.Template.Spec, registrySettings)
+		}
+
+		if reflect.DeepEqual(original.Spec, veleroDeployment.Spec) && reflect.DeepEqual(original.Annotations, veleroDeployment.Annotations) {
+			continue
+		}
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(ctx, &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+		logger.Infof("Reconciled velero deployment %s", veleroDeployment.Name)
+	}
+
+	if registrySettings == nil {
+		return nil
+	}
+
+	resticDaemonsets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list restic daemonsets")
+	}
+	for _, resticDaemonset := range resticDaemonsets {
+		if !rewriteVeleroPodSpecImages(&resticDaemonset.Spec.Template.Spec, registrySettings) {
+			continue
+		}
+		if _, err := clientset.AppsV1().DaemonSets(namespace).Update(ctx, &resticDaemonset, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update restic daemonset %s", resticDaemonset.Name)
+		}
+		logger.Infof("Rewrote images of restic daemonset %s to registry %s", resticDaemonset.Name, registrySettings.Hostname)
+	}
+
+	return nil
+}
+
+// GetVeleroImageRegistry returns the registry override for velero images, the hostname is empty when there is none
+func GetVeleroImageRegistry() (*types.VeleroImageRegistry, error) {
+	veleroRegistry, err := store.GetStore().GetVeleroImageRegistry()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero image registry")
+	}
+	return veleroRegistry, nil
+}
+
+// ValidateVeleroImageRegistry checks that the hostname and namespace can be joined into image references
+func ValidateVeleroImageRegistry(veleroRegistry *types.VeleroImageRegistry) error {
+	if veleroRegistry.Hostname == "" {
+		if veleroRegistry.Namespace != "" {
+			return errors.New("a namespace requires a hostname")
+		}
+		return nil
+	}
+	if strings.Contains(veleroRegistry.Hostname, "://") || strings.Contains(veleroRegistry.Hostname, "/") {
+		return errors.Errorf("hostname %q must not include a scheme or path, use the namespace for the path", veleroRegistry.Hostname)
+	}
+	if strings.HasPrefix(veleroRegistry.Namespace, "/") || strings.HasSuffix(veleroRegistry.Namespace, "/") {
+		return errors.Errorf("namespace %q must not start or end with a slash", veleroRegistry.Namespace)
+	}
+	return nil
+}
+
+// SetVeleroImageRegistry saves the registry override for velero images and rewrites the images to it. An empty
+// hostname removes the override, images go back to the kotsadm registry only when image reconcile is enabled.
+func SetVeleroImageRegistry(ctx context.Context, veleroRegistry *types.VeleroImageRegistry) error {
+	if err := store.GetStore().SetVeleroImageRegistry(veleroRegistry); err != nil {
+		return errors.Wrap(err, "failed to save velero image registry")
+	}
+
+	if err := ReconcileVelero(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile velero")
+	}
+
+	return nil
+}
+
+// getVeleroRegistry returns the registry velero images are pulled from, nil if they shouldn't be rewritten
+func getVeleroRegistry() (*registrytypes.RegistrySettings, error) {
+	kotsadmRegistry, err := registry.GetKotsadmRegistry()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get kotsadm registry")
+	}
+
+	veleroRegistry, err := GetVeleroImageRegistry()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero image registry")
+	}
+
+	return veleroRegistrySettings(kotsadmRegistry, veleroRegistry, IsVeleroImageReconcileEnabled()), nil
+}
+
+// veleroRegistrySettings prefers the velero image registry override to the kotsadm registry. The override is used
+// even when image reconcile isn't enabled since it was set for velero explicitly. The kotsadm registry credentials
+// are only used with the override when it is on the same host.
+func veleroRegistrySettings(kotsadmRegistry *registrytypes.RegistrySettings, veleroRegistry *types.VeleroImageRegistry, reconcileEnabled bool) *registrytypes.RegistrySettings {
+	if veleroRegistry != nil && veleroRegistry.Hostname != "" {
+		registrySettings := &registrytypes.RegistrySettings{
+			Hostname:  veleroRegistry.Hostname,
+			Namespace: veleroRegistry.Namespace,
+		}
+		if kotsadmRegistry != nil && kotsadmRegistry.Hostname == veleroRegistry.Hostname {
+			registrySettings.Username = kotsadmRegistry.Username
+			registrySettings.Password = kotsadmRegistry.Password
+		}
+		return registrySettings
+	}
+
+	if !reconcileEnabled || kotsadmRegistry == nil || kotsadmRegistry.Hostname == "" {
+		return nil
+	}
+	return kotsadmRegistry
+}
+
+func ensureVeleroRegistryPullSecret(ctx context.Context, clientset *kubernetes.Clientset, namespace string, registrySettings *registrytypes.RegistrySettings) error {
+	pullSecret, err := kotsregistry.PullSecretForRegistries([]string{registrySettings.Hostname}, registrySettings.Username, registrySettings.Password, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to generate pull secret")
+	}
+	pullSecret.Name = veleroRegistryPullSecretName
+
+	existing, err := clientset.CoreV1().Secrets(namespace).Get(ctx, pullSecret.Name, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		if _, err := clientset.CoreV1().Secrets(namespace).Create(ctx, pullSecret, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create pull secret")
+		}
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to get pull secret")
+	}
+
+	key := corev1.DockerConfigJsonKey
+	if bytes.Equal(existing.Data[key], pullSecret.Data[key]) {
+		return nil
+	}
+	existing.Data = pullSecret.Data
+	if _, err := clientset.CoreV1().Secrets(namespace).Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update pull secret")
+	}
+
+	return nil
+}
+
+// rewriteVeleroPodSpecImages points every container image at the registry and adds the registry pull secret.
+// It returns true if the pod spec was changed.
+func rewriteVeleroPodSpecImages(podSpec *corev1.PodSpec, registrySettings *registrytypes.RegistrySettings) bool {
+	changed := false
+
+	// images under another namespace of the same registry, e.g. the kotsadm one, are moved too
+	prefix := registrySettings.Hostname + "/"
+	if registrySettings.Namespace != "" {
+		prefix += registrySettings.Namespace + "/"
+	}
+
+	rewrite := func(containers []corev1.Container) {
+		for i, container := range containers {
+			if strings.HasPrefix(container.Image, prefix) {
+				continue
+			}
+			containers[i].Image = registry.RewriteImage(registrySettings, container.Image)
+			changed = true
+		}
+	}
+	rewrite(podSpec.InitContainers)
+	rewrite(podSpec.Containers)
+
+	if registrySettings.Username == "" {
+		return changed
+	}
+	for _, pullSecret := range podSpec.ImagePullSecrets {
+		if pullSecret.Name == veleroRegistryPullSecretName {
+			return changed
+		}
+	}
+	podSpec.ImagePullSecrets = append(podSpec.ImagePullSecrets, corev1.LocalObjectReference{
+		Name: veleroRegistryPullSecretName,
+	})
+
+	return true
+}
@@ -0,0 +1,158 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	registrytypes "github.com/replicatedhq/kots/kotsadm/pkg/registry/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRewriteVeleroPodSpecImages(t *testing.T) {
+	registrySettings := &registrytypes.RegistrySettings{
+		Hostname:  "registry.example.com",
+		Namespace: "velero",
+		Username:  "user",
+		Password:  "pass",
+	}
+
+	tests := []struct {
+		name        string
+		podSpec     corev1.PodSpec
+		wantChanged bool
+		wantImages  []string
+	}{
+		{
+			name: "public images are rewritten",
+			podSpec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Image: "velero/velero-plugin-for-aws:v1.1.0"}},
+				Containers:     []corev1.Container{{Image: "velero/velero:v1.5.1"}},
+			},
+			wantChanged: true,
+			wantImages:  []string{"registry.example.com/velero/velero-plugin-for-aws:v1.1.0", "registry.example.com/velero/velero:v1.5.1"},
+		},
+		{
+			name: "missing pull secret is added",
+			podSpec: corev1.PodSpec{
+				Containers: []corev1.Container{{Image: "registry.example.com/velero/velero:v1.5.1"}},
+			},
+			wantChanged: true,
+			wantImages:  []string{"registry.example.com/velero/velero:v1.5.1"},
+		},
+		{
+			name: "other namespace of the registry is moved",
+			podSpec: corev1.PodSpec{
+				Containers:       []corev1.Container{{Image: "registry.example.com/kotsadm/velero:v1.5.1"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: veleroRegistryPullSecretName}},
+			},
+			wantChanged: true,
+			wantImages:  []string{"registry.example.com/velero/velero:v1.5.1"},
+		},
+		{
+			name: "already reconciled",
+			podSpec: corev1.PodSpec{
+				Containers:       []corev1.Container{{Image: "registry.example.com/velero/velero:v1.5.1"}},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: veleroRegistryPullSecretName}},
+			},
+			wantImages: []string{"registry.example.com/velero/velero:v1.5.1"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			podSpec := test.podSpec
+			changed := rewriteVeleroPodSpecImages(&podSpec, registrySettings)
+			if changed != test.wantChanged {
+				t.Errorf("Expected changed %v, got %v", test.wantChanged, changed)
+			}
+
+			images := []string{}
+			for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+				images = append(images, container.Image)
+			}
+			if len(images) != len(test.wantImages) {
+				t.Fatalf("Expected images %v, got %v", test.wantImages, images)
+			}
+			for i := range images {
+				if images[i] != test.wantImages[i] {
+					t.Errorf("Expected image %s, got %s", test.wantImages[i], images[i])
+				}
+			}
+
+			if len(podSpec.ImagePullSecrets) != 1 || podSpec.ImagePullSecrets[0].Name != veleroRegistryPullSecretName {
+				t.Errorf("Expected pull secret %s, got %v", veleroRegistryPullSecretName, podSpec.ImagePullSecrets)
+			}
+		})
+	}
+}
+
+func TestVeleroRegistrySettings(t *testing.T) {
+	kotsadmRegistry := &registrytypes.RegistrySettings{
+		Hostname:  "registry.example.com",
+		Namespace: "kotsadm",
+		Username:  "user",
+		Password:  "pass",
+	}
+
+	tests := []struct {
+		name             string
+		veleroRegistry   *types.VeleroImageRegistry
+		reconcileEnabled bool
+		want             *registrytypes.RegistrySettings
+	}{
+		{
+			name:             "kotsadm registry",
+			veleroRegistry:   &types.VeleroImageRegistry{},
+			reconcileEnabled: true,
+			want:             kotsadmRegistry,
+		},
+		{
+			name:           "reconcile not enabled",
+			veleroRegistry: &types.VeleroImageRegistry{},
+		},
+		{
+			name:           "override on the same host keeps the credentials",
+			veleroRegistry: &types.VeleroImageRegistry{Hostname: "registry.example.com", Namespace: "mirror/velero"},
+			want: &registrytypes.RegistrySettings{
+				Hostname:  "registry.example.com",
+				Namespace: "mirror/velero",
+				Username:  "user",
+				Password:  "pass",
+			},
+		},
+		{
+			name:             "override on another host",
+			veleroRegistry:   &types.VeleroImageRegistry{Hostname: "mirror.example.com", Namespace: "velero"},
+			reconcileEnabled: true,
+			want:             &registrytypes.RegistrySettings{Hostname: "mirror.example.com", Namespace: "velero"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := veleroRegistrySettings(kotsadmRegistry, test.veleroRegistry, test.reconcileEnabled)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %+v, got %+v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateVeleroImageRegistry(t *testing.T) {
+	tests := []struct {
+		veleroRegistry types.VeleroImageRegistry
+		wantErr        bool
+	}{
+		{types.VeleroImageRegistry{}, false},
+		{types.VeleroImageRegistry{Hostname: "mirror.example.com:5000", Namespace: "mirror/velero"}, false},
+		{types.VeleroImageRegistry{Hostname: "https://mirror.example.com"}, true},
+		{types.VeleroImageRegistry{Hostname: "mirror.example.com/velero"}, true},
+		{types.VeleroImageRegistry{Hostname: "mirror.example.com", Namespace: "/velero"}, true},
+		{types.VeleroImageRegistry{Namespace: "velero"}, true},
+	}
+	for _, test := range tests {
+		err := ValidateVeleroImageRegistry(&test.veleroRegistry)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Expected error %v for %+v, got %v", test.wantErr, test.veleroRegistry, err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// backupSyncMargin is how long past the sync period SyncBackups waits, for velero to list the store and create
+// the backups it found
+const backupSyncMargin = 30 * time.Second
+
+// BackupSyncTimeoutError is returned when velero didn't sync backups from the store in time
+type BackupSyncTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e BackupSyncTimeoutError) Error() string {
+	return fmt.Sprintf("velero did not sync backups from the store within %s", e.Timeout)
+}
+
+// IsBackupSyncTimeoutError returns true if the cause of the error is a BackupSyncTimeoutError
+func IsBackupSyncTimeoutError(err error) bool {
+	_, ok := errors.Cause(err).(BackupSyncTimeoutError)
+	return ok
+}
+
+// ImportExistingBackups waits for velero to sync backups from the store and points the app backups that were
+// taken for an installed app at it, so they show up with the app's snapshots. This is used after pointing a new
+// cluster at the store of a cluster that was lost, where the apps were installed again with new ids.
+func ImportExistingBackups(ctx context.Context, apps []*apptypes.App) error {
+	bsl, err := SyncBackups(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to sync backups")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroBackups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero backups")
+	}
+
+	appIDsByBackup := importedBackupAppIDs(veleroBackups.Items, apps)
+	for _, veleroBackup := range veleroBackups.Items {
+		appID, ok := appIDsByBackup[veleroBackup.Name]
+		if !ok {
+			continue
+		}
+
+		backup := veleroBackup.DeepCopy()
+		backup.Annotations["kots.io/app-id"] = appID
+		if _, err := veleroClient.Backups(backup.Namespace).Update(ctx, backup, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update backup %s", backup.Name)
+		}
+	}
+
+	return nil
+}
+
+// ListImportedBackups returns the kots backups velero has synced from the store, matched to the installed apps
+func ListImportedBackups(ctx context.Context, apps []*apptypes.App) ([]types.ImportedBackup, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroBackups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	return matchBackupsToApps(veleroBackups.Items, apps), nil
+}
+
+// SyncBackups waits for velero to sync backups from the backup storage location. Velero 1.5 can't be asked to
+// sync on demand, and status is a subresource it owns, so this waits for the next sync of the server's backup
+// sync period, plus a margin. It returns an error if the location wasn't synced by then.
+func SyncBackups(ctx context.Context) (*velerov1.BackupStorageLocation, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	syncPeriod, err := getVeleroBackupSyncPeriod(clientset, bsl.Namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup sync period")
+	}
+	timeout := syncPeriod + backupSyncMargin
+
+	// sync times are stored with second precision
+	requestedAt := time.Now().Truncate(time.Second)
+	previousSyncTime := bsl.Status.LastSyncedTime
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		bsl, err = veleroClient.BackupStorageLocations(bsl.Namespace).Get(ctx, bsl.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup storage location")
+		}
+
+		if isStatusTimeAfterRequest(bsl.Status.LastSyncedTime, previousSyncTime, requestedAt) {
+			return bsl, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "failed to wait for backup sync")
+		case <-timer.C:
+			return nil, BackupSyncTimeoutError{Timeout: timeout}
+		case <-ticker.C:
+		}
+	}
+}
+
+func matchBackupsToApps(veleroBackups []velerov1.Backup, apps []*apptypes.App) []types.ImportedBackup {
+	appIDsBySlug := map[string]string{}
+	appIDs := map[string]bool{}
+	for _, a := range apps {
+		appIDsBySlug[a.Slug] = a.ID
+		appIDs[a.ID] = true
+	}
+
+	importedBackups := []types.ImportedBackup{}
+	for _, veleroBackup := range veleroBackups {
+		annotations := veleroBackup.Annotations
+
+		importedBackup := types.ImportedBackup{
+			Name:     veleroBackup.Name,
+			Status:   string(veleroBackup.Status.Phase),
+			AppSlugs: []string{},
+			AppIDs:   []string{},
+		}
+		if importedBackup.Status == "" {
+			importedBackup.Status = "New"
+		}
+		if veleroBackup.Status.StartTimestamp != nil {
+			importedBackup.StartedAt = &veleroBackup.Status.StartTimestamp.Time
+		}
+		if veleroBackup.Status.CompletionTimestamp != nil {
+			importedBackup.FinishedAt = &veleroBackup.Status.CompletionTimestamp.Time
+		}
+
+		if annotations["kots.io/instance"] == "true" {
+			importedBackup.IsInstance = true
+
+			appsSequences := map[string]int64{}
+			if err := json.Unmarshal([]byte(annotations["kots.io/apps-sequences"]), &appsSequences); err != nil {
+				// still offer the backup, it can't be matched to apps
+				importedBackups = append(importedBackups, importedBackup)
+				continue
+			}
+			for slug := range appsSequences {
+				importedBackup.AppSlugs = append(importedBackup.AppSlugs, slug)
+			}
+			sort.Strings(importedBackup.AppSlugs)
+
+			for _, slug := range importedBackup.AppSlugs {
+				if appID, ok := appIDsBySlug[slug]; ok {
+					importedBackup.AppIDs = append(importedBackup.AppIDs, appID)
+				}
+			}
+		} else if slug := backupAppSlug(veleroBackup); slug != "" {
+			importedBackup.AppSlugs = append(importedBackup.AppSlugs, slug)
+
+			// app ids are only the same if the app database was restored too
+			if appID := annotations["kots.io/app-id"]; appIDs[appID] {
+				importedBackup.AppIDs = append(importedBackup.AppIDs, appID)
+			} else if appID, ok := appIDsBySlug[slug]; ok {
+				importedBackup.AppIDs = append(importedBackup.AppIDs, appID)
+			}
+		} else {
+			// not a kots backup
+			continue
+		}
+
+		importedBackups = append(importedBackups, importedBackup)
+	}
+
+	return importedBackups
+}
+
+// importedBackupAppIDs returns the id of the installed app each app backup was taken for, by backup name, for the
+// backups that don't already have it. Instance backups are matched to apps by slug and are left alone.
+func importedBackupAppIDs(veleroBackups []velerov1.Backup, apps []*apptypes.App) map[string]string {
+	appIDsBySlug := map[string]string{}
+	appIDs := map[string]bool{}
+	for _, a := range apps {
+		appIDsBySlug[a.Slug] = a.ID
+		appIDs[a.ID] = true
+	}
+
+	appIDsByBackup := map[string]string{}
+	for _, veleroBackup := range veleroBackups {
+		if veleroBackup.Annotations["kots.io/instance"] == "true" {
+			continue
+		}
+		slug := backupAppSlug(veleroBackup)
+		if slug == "" {
+			continue
+		}
+		if appIDs[veleroBackup.Annotations["kots.io/app-id"]] {
+			continue
+		}
+		if appID, ok := appIDsBySlug[slug]; ok {
+			appIDsByBackup[veleroBackup.Name] = appID
+		}
+	}
+
+	return appIDsByBackup
+}
+
+// backupAppSlug returns the slug of the app an app backup was taken for, from the label selector kots sets on it
+func backupAppSlug(veleroBackup velerov1.Backup) string {
+	if _, ok := veleroBackup.Annotations["kots.io/app-id"]; !ok {
+		return ""
+	}
+	if veleroBackup.Spec.LabelSelector == nil {
+		return ""
+	}
+	return veleroBackup.Spec.LabelSelector.MatchLabels["kots.io/app-slug"]
+}
@@ -0,0 +1,126 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchBackupsToApps(t *testing.T) {
+	backup := func(name string, phase velerov1.BackupPhase, slug string, annotations map[string]string) velerov1.Backup {
+		b := velerov1.Backup{}
+		b.Name = name
+		b.Annotations = annotations
+		if slug != "" {
+			b.Spec.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{"kots.io/app-slug": slug},
+			}
+		}
+		b.Status.Phase = phase
+		return b
+	}
+
+	apps := []*apptypes.App{
+		{ID: "app-a-id", Slug: "app-a"},
+		{ID: "app-b-id", Slug: "app-b"},
+	}
+
+	veleroBackups := []velerov1.Backup{
+		backup("instance-1", velerov1.BackupPhaseCompleted, "", map[string]string{
+			"kots.io/instance":       "true",
+			"kots.io/apps-sequences": `{"app-b": 2, "app-a": 1, "app-c": 4}`,
+		}),
+		backup("app-a-1", velerov1.BackupPhaseCompleted, "app-a", map[string]string{
+			"kots.io/app-id": "app-a-id",
+		}),
+		backup("app-b-1", velerov1.BackupPhasePartiallyFailed, "app-b", map[string]string{
+			"kots.io/app-id": "old-cluster-id",
+		}),
+		backup("app-c-1", "", "app-c", map[string]string{
+			"kots.io/app-id": "app-c-id",
+		}),
+		backup("other", velerov1.BackupPhaseCompleted, "", nil),
+	}
+
+	want := []types.ImportedBackup{
+		{
+			Name:       "instance-1",
+			Status:     "Completed",
+			IsInstance: true,
+			AppSlugs:   []string{"app-a", "app-b", "app-c"},
+			AppIDs:     []string{"app-a-id", "app-b-id"},
+		},
+		{
+			Name:     "app-a-1",
+			Status:   "Completed",
+			AppSlugs: []string{"app-a"},
+			AppIDs:   []string{"app-a-id"},
+		},
+		{
+			Name:     "app-b-1",
+			Status:   "PartiallyFailed",
+			AppSlugs: []string{"app-b"},
+			AppIDs:   []string{"app-b-id"},
+		},
+		{
+			Name:     "app-c-1",
+			Status:   "New",
+			AppSlugs: []string{"app-c"},
+			AppIDs:   []string{},
+		},
+	}
+
+	got := matchBackupsToApps(veleroBackups, apps)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+}
+
+func TestImportedBackupAppIDs(t *testing.T) {
+	backup := func(name string, slug string, annotations map[string]string) velerov1.Backup {
+		b := velerov1.Backup{}
+		b.Name = name
+		b.Annotations = annotations
+		if slug != "" {
+			b.Spec.LabelSelector = &metav1.LabelSelector{
+				MatchLabels: map[string]string{"kots.io/app-slug": slug},
+			}
+		}
+		return b
+	}
+
+	apps := []*apptypes.App{
+		{ID: "app-a-id", Slug: "app-a"},
+		{ID: "app-b-id", Slug: "app-b"},
+	}
+
+	veleroBackups := []velerov1.Backup{
+		backup("instance-1", "", map[string]string{
+			"kots.io/instance":       "true",
+			"kots.io/apps-sequences": `{"app-a": 1}`,
+		}),
+		backup("app-a-1", "app-a", map[string]string{
+			"kots.io/app-id": "app-a-id",
+		}),
+		backup("app-b-1", "app-b", map[string]string{
+			"kots.io/app-id": "old-cluster-id",
+		}),
+		backup("app-c-1", "app-c", map[string]string{
+			"kots.io/app-id": "app-c-id",
+		}),
+		backup("other", "app-b", nil),
+	}
+
+	want := map[string]string{
+		"app-b-1": "app-b-id",
+	}
+
+	got := importedBackupAppIDs(veleroBackups, apps)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
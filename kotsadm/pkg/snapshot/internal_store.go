@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/kurl"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NotInternalStoreError is returned when the internal store is refreshed but snapshots are stored somewhere else
+type NotInternalStoreError struct{}
+
+func (e NotInternalStoreError) Error() string {
+	return "snapshots are not configured to use the internal store"
+}
+
+// IsNotInternalStoreError returns true if the cause of the error is a NotInternalStoreError
+func IsNotInternalStoreError(err error) bool {
+	_, ok := errors.Cause(err).(NotInternalStoreError)
+	return ok
+}
+
+// SetStoreInternal points the store at the kurl object store described by the kurl s3 secret
+func SetStoreInternal(store *types.Store, s3Secret *corev1.Secret) {
+	store.AWS = nil
+	store.Google = nil
+	store.Azure = nil
+	store.Other = nil
+
+	store.Provider = "aws"
+	store.Bucket = string(s3Secret.Data["velero-local-bucket"])
+	store.Path = ""
+
+	store.Internal = &types.StoreInternal{
+		AccessKeyID:          string(s3Secret.Data["access-key-id"]),
+		SecretAccessKey:      string(s3Secret.Data["secret-access-key"]),
+		Endpoint:             string(s3Secret.Data["endpoint"]),
+		ObjectStoreClusterIP: string(s3Secret.Data["object-store-cluster-ip"]),
+		Region:               "us-east-1",
+	}
+}
+
+// RefreshInternalStore re-reads the kurl object store config and applies it to the backup storage location.
+// kurl upgrades can move the object store to a new service and rotate its credentials, which leaves velero
+// unable to reach the backups until the store is configured again.
+func RefreshInternalStore(ctx context.Context) (*velerov1.BackupStorageLocation, error) {
+	if !kurl.IsKurl() {
+		return nil, NotInternalStoreError{}
+	}
+
+	s3Secret, err := kurl.GetS3Secret()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get s3 secret")
+	}
+	if s3Secret == nil {
+		return nil, errors.New("s3 secret does not exist")
+	}
+
+	kotsadmVeleroBackendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if !isInternalStoreLocation(kotsadmVeleroBackendStorageLocation, s3Secret) {
+		return nil, NotInternalStoreError{}
+	}
+
+	store := &types.Store{}
+	SetStoreInternal(store, s3Secret)
+	store.Internal.InsecureSkipTLSVerify = kotsadmVeleroBackendStorageLocation.Spec.Config[insecureSkipTLSVerifyConfig] == "true"
+
+	if err := ValidateStore(store); err != nil {
+		return nil, errors.Wrap(err, "failed to validate store")
+	}
+
+	if _, err := UpdateGlobalStore(store); err != nil {
+		return nil, errors.Wrap(err, "failed to update global store")
+	}
+
+	updatedBackupStorageLocation, err := ReapplyStoreEncryptionKey(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reapply store encryption key")
+	}
+
+	// the restic repositories still point at the old endpoint
+	if err := ResetResticRepositories(); err != nil {
+		return nil, errors.Wrap(err, "failed to reset restic repositories")
+	}
+
+	if err := RestartVelero(); err != nil {
+		return nil, errors.Wrap(err, "failed to restart velero")
+	}
+
+	return updatedBackupStorageLocation, nil
+}
+
+// isInternalStoreLocation returns true if the storage location uses the kurl bucket. The endpoint isn't compared
+// since it is what changes when the object store moves.
+func isInternalStoreLocation(bsl *velerov1.BackupStorageLocation, s3Secret *corev1.Secret) bool {
+	if bsl.Spec.Provider != "aws" || bsl.Spec.ObjectStorage == nil {
+		return false
+	}
+	if _, isS3Compatible := bsl.Spec.Config["s3Url"]; !isS3Compatible {
+		return false
+	}
+	bucket := string(s3Secret.Data["velero-local-bucket"])
+	return bucket != "" && bsl.Spec.ObjectStorage.Bucket == bucket
+}
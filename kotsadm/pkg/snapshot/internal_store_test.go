@@ -0,0 +1,69 @@
+package snapshot
+
+import (
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestIsInternalStoreLocation(t *testing.T) {
+	s3Secret := &corev1.Secret{
+		Data: map[string][]byte{
+			"velero-local-bucket": []byte("velero"),
+			"endpoint":            []byte("http://10.96.2.15"),
+		},
+	}
+
+	location := func(provider string, bucket string, config map[string]string) *velerov1.BackupStorageLocation {
+		return &velerov1.BackupStorageLocation{
+			Spec: velerov1.BackupStorageLocationSpec{
+				Provider: provider,
+				StorageType: velerov1.StorageType{
+					ObjectStorage: &velerov1.ObjectStorageLocation{Bucket: bucket},
+				},
+				Config: config,
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		bsl  *velerov1.BackupStorageLocation
+		want bool
+	}{
+		{
+			name: "same endpoint",
+			bsl:  location("aws", "velero", map[string]string{"s3Url": "http://10.96.2.15"}),
+			want: true,
+		},
+		{
+			name: "endpoint moved by kurl upgrade",
+			bsl:  location("aws", "velero", map[string]string{"s3Url": "http://10.96.0.20"}),
+			want: true,
+		},
+		{
+			name: "other bucket",
+			bsl:  location("aws", "my-backups", map[string]string{"s3Url": "http://10.96.2.15"}),
+			want: false,
+		},
+		{
+			name: "aws",
+			bsl:  location("aws", "velero", map[string]string{"region": "us-east-1"}),
+			want: false,
+		},
+		{
+			name: "gcp",
+			bsl:  location("gcp", "velero", nil),
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isInternalStoreLocation(test.bsl, s3Secret); got != test.want {
+				t.Errorf("isInternalStoreLocation() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
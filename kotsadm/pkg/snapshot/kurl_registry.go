@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	kurlRegistryNamespace = "kube-system"
+	kurlRegistrySelector  = "app=registry"
+)
+
+// includeKurlRegistry adds the kurl registry's pods and pvcs to an instance backup, so that images pushed to the
+// registry come back with a full cluster restore. Instance backups only include resources with the backup label,
+// so the label is added before each backup since registry pods lose it when they're recreated. kurl installs the
+// registry deployment itself, only the data has to come from the backup.
+func includeKurlRegistry(ctx context.Context, veleroBackup *velerov1.Backup) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	pods, err := clientset.CoreV1().Pods(kurlRegistryNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: kurlRegistrySelector,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list registry pods")
+	}
+	if len(pods.Items) == 0 {
+		logger.Infof("No kurl registry pods found in namespace %s, registry data won't be included in the backup", kurlRegistryNamespace)
+		return nil
+	}
+
+	for _, pod := range pods.Items {
+		pvcNames := podPVCNames(pod)
+
+		if prepareKurlRegistryPod(&pod) {
+			if _, err := clientset.CoreV1().Pods(kurlRegistryNamespace).Update(ctx, &pod, metav1.UpdateOptions{}); err != nil {
+				return errors.Wrapf(err, "failed to update registry pod %s", pod.Name)
+			}
+		}
+
+		for _, pvcName := range pvcNames {
+			pvc, err := clientset.CoreV1().PersistentVolumeClaims(kurlRegistryNamespace).Get(ctx, pvcName, metav1.GetOptions{})
+			if err != nil {
+				return errors.Wrapf(err, "failed to get registry pvc %s", pvcName)
+			}
+			if pvc.Labels[kotsadmtypes.BackupLabel] == kotsadmtypes.BackupLabelValue {
+				continue
+			}
+			if pvc.Labels == nil {
+				pvc.Labels = map[string]string{}
+			}
+			pvc.Labels[kotsadmtypes.BackupLabel] = kotsadmtypes.BackupLabelValue
+			if _, err := clientset.CoreV1().PersistentVolumeClaims(kurlRegistryNamespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+				return errors.Wrapf(err, "failed to update registry pvc %s", pvcName)
+			}
+		}
+	}
+
+	for _, namespace := range veleroBackup.Spec.IncludedNamespaces {
+		if namespace == kurlRegistryNamespace {
+			return nil
+		}
+	}
+	veleroBackup.Spec.IncludedNamespaces = append(veleroBackup.Spec.IncludedNamespaces, kurlRegistryNamespace)
+
+	return nil
+}
+
+// prepareKurlRegistryPod adds the backup label to the registry pod and opts its data volumes in to restic.
+// Returns true if the pod was changed.
+func prepareKurlRegistryPod(pod *corev1.Pod) bool {
+	changed := false
+
+	if pod.Labels[kotsadmtypes.BackupLabel] != kotsadmtypes.BackupLabelValue {
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[kotsadmtypes.BackupLabel] = kotsadmtypes.BackupLabelValue
+		changed = true
+	}
+
+	volumes := podResticVolumes(*pod, nil)
+	if len(volumes) > 0 {
+		includes, volumesChanged := mergeVolumeNames(pod.Annotations[resticVolumesAnnotation], volumes)
+		if volumesChanged {
+			if pod.Annotations == nil {
+				pod.Annotations = map[string]string{}
+			}
+			pod.Annotations[resticVolumesAnnotation] = includes
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func podPVCNames(pod corev1.Pod) []string {
+	pvcNames := []string{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			pvcNames = append(pvcNames, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	return pvcNames
+}
@@ -0,0 +1,45 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrepareKurlRegistryPod(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "registry-7d9f8c6b5-abcde",
+			Namespace: "kube-system",
+			Labels:    map[string]string{"app": "registry"},
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "registry-data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "registry-pvc"}}},
+				{Name: "registry-htpasswd", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "registry-htpasswd"}}},
+				{Name: "registry-pki", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "registry-pki"}}},
+			},
+		},
+	}
+
+	if !prepareKurlRegistryPod(&pod) {
+		t.Fatal("Expected the registry pod to change")
+	}
+
+	if pod.Labels[kotsadmtypes.BackupLabel] != kotsadmtypes.BackupLabelValue {
+		t.Errorf("Expected the backup label, got labels %v", pod.Labels)
+	}
+	if got := pod.Annotations[resticVolumesAnnotation]; got != "registry-data" {
+		t.Errorf("Expected the registry volume to be backed up with restic, got %q", got)
+	}
+	if got := podPVCNames(pod); !reflect.DeepEqual(got, []string{"registry-pvc"}) {
+		t.Errorf("podPVCNames() = %v, want [registry-pvc]", got)
+	}
+
+	if prepareKurlRegistryPod(&pod) {
+		t.Error("Expected a prepared registry pod not to change again")
+	}
+}
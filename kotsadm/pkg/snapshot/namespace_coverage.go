@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/kurl"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// NoInstanceBackupError is returned when instance backups aren't taken, so there is no coverage to check
+type NoInstanceBackupError struct{}
+
+func (e NoInstanceBackupError) Error() string {
+	return "no instance backup has been taken or scheduled"
+}
+
+// IsNoInstanceBackupError returns true if the cause of the error is a NoInstanceBackupError
+func IsNoInstanceBackupError(err error) bool {
+	_, ok := errors.Cause(err).(NoInstanceBackupError)
+	return ok
+}
+
+// CheckInstanceBackupNamespaceCoverage returns the namespaces that installed apps have pods or volumes in but the
+// next instance backup won't include, e.g. when a new app deploys to a namespace that isn't one of its additional
+// namespaces. The namespaces are the ones CreateInstanceBackup would include now, not the ones of the last backup.
+func CheckInstanceBackupNamespaceCoverage(ctx context.Context) (*types.NamespaceCoverage, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroBackups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	isScheduled := len(clusters) > 0 && clusters[0].SnapshotSchedule != ""
+	if !isScheduled && latestInstanceBackup(veleroBackups.Items) == nil {
+		return nil, NoInstanceBackupError{}
+	}
+
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list installed apps")
+	}
+	installedSlugs := map[string]bool{}
+	for _, a := range apps {
+		installedSlugs[a.Slug] = true
+	}
+
+	backupApps, err := listInstanceBackupApps(apps)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list apps to back up")
+	}
+
+	kotsadmNamespace := os.Getenv("POD_NAMESPACE")
+	if os.Getenv("KOTSADM_TARGET_NAMESPACE") != "" {
+		kotsadmNamespace = os.Getenv("KOTSADM_TARGET_NAMESPACE")
+	}
+	includedNamespaces := instanceBackupNamespaces(kotsadmNamespace, backupApps, kurl.IsKurl())
+
+	appNamespaces, err := listAppNamespaces(ctx, clientset, installedSlugs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list app namespaces")
+	}
+
+	return &types.NamespaceCoverage{
+		UncoveredNamespaces: findUncoveredNamespaces(appNamespaces, includedNamespaces, nil),
+	}, nil
+}
+
+// listAppNamespaces returns the apps with pods or persistent volume claims in each namespace. Kots labels every
+// resource it deploys with the app slug.
+func listAppNamespaces(ctx context.Context, clientset kubernetes.Interface, installedSlugs map[string]bool) (map[string][]string, error) {
+	listOptions := metav1.ListOptions{LabelSelector: "kots.io/app-slug"}
+
+	appsByNamespace := map[string]map[string]bool{}
+	add := func(namespace string, labels map[string]string) {
+		slug := labels["kots.io/app-slug"]
+		if !installedSlugs[slug] {
+			return
+		}
+		if appsByNamespace[namespace] == nil {
+			appsByNamespace[namespace] = map[string]bool{}
+		}
+		appsByNamespace[namespace][slug] = true
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+	for _, pod := range pods.Items {
+		add(pod.Namespace, pod.Labels)
+	}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list persistent volume claims")
+	}
+	for _, pvc := range pvcs.Items {
+		add(pvc.Namespace, pvc.Labels)
+	}
+
+	appNamespaces := map[string][]string{}
+	for namespace, slugs := range appsByNamespace {
+		for slug := range slugs {
+			appNamespaces[namespace] = append(appNamespaces[namespace], slug)
+		}
+		sort.Strings(appNamespaces[namespace])
+	}
+
+	return appNamespaces, nil
+}
+
+func latestInstanceBackup(backups []velerov1.Backup) *velerov1.Backup {
+	var latest *velerov1.Backup
+	for i := range backups {
+		backup := &backups[i]
+		if backup.Annotations["kots.io/instance"] != "true" {
+			continue
+		}
+		if _, ok := backup.Annotations[fanOutOfAnnotation]; ok {
+			continue
+		}
+		if latest == nil || backup.CreationTimestamp.After(latest.CreationTimestamp.Time) {
+			latest = backup
+		}
+	}
+	return latest
+}
+
+// findUncoveredNamespaces returns the app namespaces that a backup with the included and excluded namespaces
+// leaves out. An empty included list or "*" includes every namespace, as in velero.
+func findUncoveredNamespaces(appNamespaces map[string][]string, included []string, excluded []string) []types.UncoveredNamespace {
+	includedSet := map[string]bool{}
+	includeAll := len(included) == 0
+	for _, namespace := range included {
+		if namespace == "*" {
+			includeAll = true
+		}
+		includedSet[namespace] = true
+	}
+	excludedSet := map[string]bool{}
+	for _, namespace := range excluded {
+		excludedSet[namespace] = true
+	}
+
+	uncovered := []types.UncoveredNamespace{}
+	for namespace, apps := range appNamespaces {
+		if !excludedSet[namespace] && (includeAll || includedSet[namespace]) {
+			continue
+		}
+		uncovered = append(uncovered, types.UncoveredNamespace{
+			Namespace: namespace,
+			Apps:      apps,
+		})
+	}
+
+	sort.Slice(uncovered, func(i, j int) bool {
+		return uncovered[i].Namespace < uncovered[j].Namespace
+	})
+
+	return uncovered
+}
@@ -0,0 +1,85 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindUncoveredNamespaces(t *testing.T) {
+	appNamespaces := map[string][]string{
+		"default":    {"app-a", "app-b"},
+		"monitoring": {"app-b"},
+		"data":       {"app-a"},
+	}
+
+	tests := []struct {
+		name     string
+		included []string
+		excluded []string
+		want     []types.UncoveredNamespace
+	}{
+		{
+			name:     "all covered",
+			included: []string{"default", "monitoring", "data", "kurl"},
+			want:     []types.UncoveredNamespace{},
+		},
+		{
+			name:     "namespace not included",
+			included: []string{"default", "data"},
+			want: []types.UncoveredNamespace{
+				{Namespace: "monitoring", Apps: []string{"app-b"}},
+			},
+		},
+		{
+			name:     "include all with an exclusion",
+			included: []string{"*"},
+			excluded: []string{"data"},
+			want: []types.UncoveredNamespace{
+				{Namespace: "data", Apps: []string{"app-a"}},
+			},
+		},
+		{
+			name:     "empty included list includes everything",
+			excluded: []string{"default"},
+			want: []types.UncoveredNamespace{
+				{Namespace: "default", Apps: []string{"app-a", "app-b"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := findUncoveredNamespaces(appNamespaces, test.included, test.excluded)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("findUncoveredNamespaces() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLatestInstanceBackup(t *testing.T) {
+	older := metav1.NewTime(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC))
+	newest := metav1.NewTime(time.Date(2020, 10, 3, 0, 0, 0, 0, time.UTC))
+
+	backups := []velerov1.Backup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "instance-older", CreationTimestamp: older, Annotations: map[string]string{"kots.io/instance": "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "instance-newer", CreationTimestamp: newer, Annotations: map[string]string{"kots.io/instance": "true"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "instance-newer-copy", CreationTimestamp: newest, Annotations: map[string]string{"kots.io/instance": "true", fanOutOfAnnotation: "instance-newer"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "my-app-abcde", CreationTimestamp: newest}},
+	}
+
+	latest := latestInstanceBackup(backups)
+	if latest == nil || latest.Name != "instance-newer" {
+		t.Errorf("latestInstanceBackup() = %v, want instance-newer", latest)
+	}
+
+	if latest := latestInstanceBackup(nil); latest != nil {
+		t.Errorf("latestInstanceBackup() = %v, want nil", latest.Name)
+	}
+}
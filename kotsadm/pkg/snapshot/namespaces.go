@@ -0,0 +1,77 @@
+package snapshot
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// ValidateAppSnapshotNamespaces checks that the included and excluded namespaces exist and don't overlap
+func ValidateAppSnapshotNamespaces(ctx context.Context, included []string, excluded []string) error {
+	if len(included) == 0 && len(excluded) == 0 {
+		return nil
+	}
+
+	excludedSet := map[string]bool{}
+	for _, namespace := range excluded {
+		excludedSet[namespace] = true
+	}
+	for _, namespace := range included {
+		if excludedSet[namespace] {
+			return errors.Errorf("namespace %q is both included and excluded", namespace)
+		}
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	for _, namespace := range append(append([]string{}, included...), excluded...) {
+		_, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			if kuberneteserrors.IsNotFound(err) {
+				return errors.Errorf("namespace %q not found", namespace)
+			}
+			return errors.Wrapf(err, "failed to get namespace %s", namespace)
+		}
+	}
+
+	return nil
+}
+
+// appBackupNamespaces returns the namespaces to include in and exclude from an app backup. Namespaces configured
+// for the app replace the defaults, and excluded namespaces are removed from the included list since velero
+// rejects backups that list a namespace in both.
+func appBackupNamespaces(defaults []string, included []string, excluded []string) ([]string, []string) {
+	namespaces := defaults
+	if len(included) > 0 {
+		namespaces = included
+	}
+
+	excludedSet := map[string]bool{}
+	for _, namespace := range excluded {
+		excludedSet[namespace] = true
+	}
+
+	includedNamespaces := []string{}
+	seen := map[string]bool{}
+	for _, namespace := range namespaces {
+		if excludedSet[namespace] || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		includedNamespaces = append(includedNamespaces, namespace)
+	}
+
+	return includedNamespaces, excluded
+}
@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppBackupNamespaces(t *testing.T) {
+	tests := []struct {
+		name         string
+		defaults     []string
+		included     []string
+		excluded     []string
+		wantIncluded []string
+		wantExcluded []string
+	}{
+		{
+			name:         "defaults",
+			defaults:     []string{"default", "app-extra"},
+			wantIncluded: []string{"default", "app-extra"},
+		},
+		{
+			name:         "included replaces defaults",
+			defaults:     []string{"default", "app-extra"},
+			included:     []string{"app-data"},
+			wantIncluded: []string{"app-data"},
+		},
+		{
+			name:         "excluded removed from defaults",
+			defaults:     []string{"default", "app-extra", "default"},
+			excluded:     []string{"app-extra"},
+			wantIncluded: []string{"default"},
+			wantExcluded: []string{"app-extra"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotIncluded, gotExcluded := appBackupNamespaces(test.defaults, test.included, test.excluded)
+			if !reflect.DeepEqual(gotIncluded, test.wantIncluded) {
+				t.Errorf("Expected included %v, got %v", test.wantIncluded, gotIncluded)
+			}
+			if !reflect.DeepEqual(gotExcluded, test.wantExcluded) {
+				t.Errorf("Expected excluded %v, got %v", test.wantExcluded, gotExcluded)
+			}
+		})
+	}
+}
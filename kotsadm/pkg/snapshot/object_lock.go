@@ -0,0 +1,193 @@
+package snapshot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+const (
+	// objectLockRetentionAnnotation records the bucket's object lock retention on the backup storage location,
+	// since the velero aws plugin rejects config keys it doesn't know about
+	objectLockRetentionAnnotation = "kots.io/object-lock-retention-days"
+
+	// defaultBackupTTL is the retention velero gives backups that don't set one
+	defaultBackupTTL = 720 * time.Hour
+)
+
+// ObjectLockRetentionDays returns the object lock retention configured for the store, 0 if it has none
+func ObjectLockRetentionDays(store *types.Store) int {
+	if store.AWS != nil {
+		return retentionDaysValue(store.AWS.ObjectLockRetentionDays)
+	}
+	if store.Other != nil {
+		return retentionDaysValue(store.Other.ObjectLockRetentionDays)
+	}
+	return 0
+}
+
+func retentionDaysValue(days *int) int {
+	if days == nil {
+		return 0
+	}
+	return *days
+}
+
+// GetObjectLockRetentionDays returns the object lock retention of the default backup storage location,
+// 0 if it has none or there is no storage location
+func GetObjectLockRetentionDays() (int, error) {
+	bsl, err := FindBackupStoreLocation()
+	if IsNoStoreConfiguredError(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	return getObjectLockRetentionDays(bsl), nil
+}
+
+func getObjectLockRetentionDays(bsl *velerov1.BackupStorageLocation) int {
+	days, _ := strconv.Atoi(bsl.Annotations[objectLockRetentionAnnotation])
+	return days
+}
+
+// storeObjectLockRetentionDays is the object lock retention of the location as the store reports it, nil if it has none
+func storeObjectLockRetentionDays(bsl *velerov1.BackupStorageLocation) *int {
+	days := getObjectLockRetentionDays(bsl)
+	if days <= 0 {
+		return nil
+	}
+	return &days
+}
+
+func setObjectLockRetentionDays(bsl *velerov1.BackupStorageLocation, days int) {
+	if days <= 0 {
+		delete(bsl.Annotations, objectLockRetentionAnnotation)
+		return
+	}
+	if bsl.Annotations == nil {
+		bsl.Annotations = map[string]string{}
+	}
+	bsl.Annotations[objectLockRetentionAnnotation] = strconv.Itoa(days)
+}
+
+// ValidateTTLForObjectLock returns an error if backups with the ttl would expire while the bucket still locks
+// them. Velero fails to delete them, and the backups are left in the bucket and in the cluster.
+// An empty ttl is velero's default.
+func ValidateTTLForObjectLock(ttl string, retentionDays int) error {
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	ttlDuration := defaultBackupTTL
+	if ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse ttl")
+		}
+		ttlDuration = d
+	}
+
+	if ttlDuration < objectLockRetention(retentionDays) {
+		return errors.Errorf("retention of %s is shorter than the %d day object lock retention of the bucket", ttlDuration, retentionDays)
+	}
+
+	return nil
+}
+
+// FindObjectLockTTLConflicts returns a description of each app or instance snapshot retention that is shorter
+// than the object lock retention
+func FindObjectLockTTLConflicts(retentionDays int) ([]string, error) {
+	conflicts := []string{}
+	if retentionDays <= 0 {
+		return conflicts, nil
+	}
+
+	defaults, err := GetSnapshotDefaults()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get snapshot defaults")
+	}
+
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list installed apps")
+	}
+	for _, a := range apps {
+		snapshotTTL := a.SnapshotTTL
+		if snapshotTTL == "" {
+			snapshotTTL = defaults.TTL
+		}
+		if err := ValidateTTLForObjectLock(snapshotTTL, retentionDays); err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("app %s: %s", a.Slug, err.Error()))
+		}
+		if a.SnapshotScheduleTTL == "" {
+			continue
+		}
+		if err := ValidateTTLForObjectLock(a.SnapshotScheduleTTL, retentionDays); err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("app %s scheduled snapshots: %s", a.Slug, err.Error()))
+		}
+	}
+
+	clusters, err := store.GetStore().ListClusters()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list clusters")
+	}
+	for _, c := range clusters {
+		if err := ValidateTTLForObjectLock(c.SnapshotTTL, retentionDays); err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("instance snapshots: %s", err.Error()))
+		}
+	}
+
+	return conflicts, nil
+}
+
+// validateBucketObjectLock checks that the bucket has object lock enabled, and that objects aren't locked
+// longer by default than the retention kots was told about
+func validateBucketObjectLock(s3Client *s3.S3, bucket string, retentionDays int) error {
+	if retentionDays <= 0 {
+		return nil
+	}
+
+	output, err := s3Client.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get bucket object lock configuration")
+	}
+
+	return checkBucketObjectLock(output.ObjectLockConfiguration, retentionDays)
+}
+
+func checkBucketObjectLock(lockConfig *s3.ObjectLockConfiguration, retentionDays int) error {
+	if lockConfig == nil || aws.StringValue(lockConfig.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		return errors.New("bucket does not have object lock enabled")
+	}
+
+	if lockConfig.Rule == nil || lockConfig.Rule.DefaultRetention == nil {
+		return nil
+	}
+
+	var bucketRetention time.Duration
+	if days := aws.Int64Value(lockConfig.Rule.DefaultRetention.Days); days > 0 {
+		bucketRetention = objectLockRetention(int(days))
+	} else if years := aws.Int64Value(lockConfig.Rule.DefaultRetention.Years); years > 0 {
+		bucketRetention = objectLockRetention(int(years) * 365)
+	}
+
+	if bucketRetention > objectLockRetention(retentionDays) {
+		return errors.Errorf("bucket locks objects for %s by default, longer than the %d day object lock retention", bucketRetention, retentionDays)
+	}
+
+	return nil
+}
+
+func objectLockRetention(days int) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
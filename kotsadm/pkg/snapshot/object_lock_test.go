@@ -0,0 +1,124 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func TestValidateTTLForObjectLock(t *testing.T) {
+	tests := []struct {
+		name          string
+		ttl           string
+		retentionDays int
+		wantErr       bool
+	}{
+		{
+			name:          "no object lock",
+			ttl:           "1h",
+			retentionDays: 0,
+			wantErr:       false,
+		},
+		{
+			name:          "ttl longer than lock",
+			ttl:           "2160h",
+			retentionDays: 30,
+			wantErr:       false,
+		},
+		{
+			name:          "ttl equal to lock",
+			ttl:           "720h",
+			retentionDays: 30,
+			wantErr:       false,
+		},
+		{
+			name:          "ttl shorter than lock",
+			ttl:           "168h",
+			retentionDays: 30,
+			wantErr:       true,
+		},
+		{
+			name:          "velero default ttl",
+			ttl:           "",
+			retentionDays: 30,
+			wantErr:       false,
+		},
+		{
+			name:          "velero default ttl shorter than lock",
+			ttl:           "",
+			retentionDays: 90,
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTTLForObjectLock(test.ttl, test.retentionDays)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckBucketObjectLock(t *testing.T) {
+	tests := []struct {
+		name          string
+		lockConfig    *s3.ObjectLockConfiguration
+		retentionDays int
+		wantErr       bool
+	}{
+		{
+			name:          "object lock not enabled",
+			lockConfig:    nil,
+			retentionDays: 30,
+			wantErr:       true,
+		},
+		{
+			name: "no default retention",
+			lockConfig: &s3.ObjectLockConfiguration{
+				ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+			},
+			retentionDays: 30,
+			wantErr:       false,
+		},
+		{
+			name: "default retention in days",
+			lockConfig: &s3.ObjectLockConfiguration{
+				ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+				Rule: &s3.ObjectLockRule{
+					DefaultRetention: &s3.DefaultRetention{
+						Mode: aws.String(s3.ObjectLockRetentionModeCompliance),
+						Days: aws.Int64(30),
+					},
+				},
+			},
+			retentionDays: 30,
+			wantErr:       false,
+		},
+		{
+			name: "default retention in years is longer",
+			lockConfig: &s3.ObjectLockConfiguration{
+				ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+				Rule: &s3.ObjectLockRule{
+					DefaultRetention: &s3.DefaultRetention{
+						Mode:  aws.String(s3.ObjectLockRetentionModeGovernance),
+						Years: aws.Int64(1),
+					},
+				},
+			},
+			retentionDays: 30,
+			wantErr:       true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkBucketObjectLock(test.lockConfig, test.retentionDays)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
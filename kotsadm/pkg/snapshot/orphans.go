@@ -0,0 +1,213 @@
+package snapshot
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// CleanupOrphanedObjects finds backup and restore directories in the store that no longer have a Backup or
+// Restore in the cluster, and deletes their objects unless dryRun is set. Backup directories that still have
+// velero's metadata file are never orphaned, velero syncs those back into the cluster as backups.
+func CleanupOrphanedObjects(ctx context.Context, dryRun bool) (*types.OrphanedObjectsCleanup, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	store, err := GetGlobalStore(bsl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get store")
+	}
+	if store.AWS == nil && store.Other == nil && store.Internal == nil {
+		return nil, errors.New("orphaned object cleanup is only supported for s3-compatible stores")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backups, err := veleroClient.Backups(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+	backupNames := []string{}
+	for _, backup := range backups.Items {
+		backupNames = append(backupNames, backup.Name)
+	}
+
+	restores, err := veleroClient.Restores(bsl.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list restores")
+	}
+	restoreNames := []string{}
+	for _, restore := range restores.Items {
+		restoreNames = append(restoreNames, restore.Name)
+	}
+
+	s3Client, err := newStoreS3Client(store)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create s3 client")
+	}
+
+	cleanup := &types.OrphanedObjectsCleanup{
+		DryRun:  dryRun,
+		Orphans: []types.OrphanedObjects{},
+	}
+
+	for _, kind := range []string{"backups", "restores"} {
+		existing := backupNames
+		if kind == "restores" {
+			existing = restoreNames
+		}
+
+		dirs, err := listStoreDirs(s3Client, store.Bucket, path.Join(store.Path, kind)+"/")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list %s", kind)
+		}
+
+		for _, name := range orphanedDirs(dirs, existing) {
+			prefix := path.Join(store.Path, kind, name) + "/"
+			keys, size, err := listStoreObjects(s3Client, store.Bucket, prefix)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to list objects in %s", prefix)
+			}
+
+			if kind == "backups" && hasBackupMetadata(prefix, keys) {
+				continue
+			}
+
+			cleanup.Orphans = append(cleanup.Orphans, types.OrphanedObjects{
+				Kind:        strings.TrimSuffix(kind, "s"),
+				Name:        name,
+				Prefix:      prefix,
+				ObjectCount: len(keys),
+				Bytes:       size,
+			})
+			cleanup.TotalBytes += size
+
+			if dryRun {
+				continue
+			}
+			if err := deleteStoreObjects(s3Client, store.Bucket, keys); err != nil {
+				return nil, errors.Wrapf(err, "failed to delete objects in %s", prefix)
+			}
+			cleanup.DeletedObjects += len(keys)
+		}
+	}
+
+	return cleanup, nil
+}
+
+// orphanedDirs returns the directory names that don't belong to an existing object
+func orphanedDirs(dirs []string, existing []string) []string {
+	existingSet := map[string]bool{}
+	for _, name := range existing {
+		existingSet[name] = true
+	}
+
+	orphans := []string{}
+	for _, dir := range dirs {
+		if !existingSet[dir] {
+			orphans = append(orphans, dir)
+		}
+	}
+	sort.Strings(orphans)
+
+	return orphans
+}
+
+func hasBackupMetadata(prefix string, keys []string) bool {
+	for _, key := range keys {
+		if key == prefix+"velero-backup.json" {
+			return true
+		}
+	}
+	return false
+}
+
+func listStoreDirs(s3Client *s3.S3, bucket string, prefix string) ([]string, error) {
+	dirs := []string{}
+	err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(strings.TrimPrefix(prefix, "/")),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, commonPrefix := range page.CommonPrefixes {
+			dir := strings.TrimSuffix(strings.TrimPrefix(aws.StringValue(commonPrefix.Prefix), strings.TrimPrefix(prefix, "/")), "/")
+			if dir != "" {
+				dirs = append(dirs, dir)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
+
+func listStoreObjects(s3Client *s3.S3, bucket string, prefix string) ([]string, int64, error) {
+	keys := []string{}
+	size := int64(0)
+	err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(strings.TrimPrefix(prefix, "/")),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			keys = append(keys, aws.StringValue(object.Key))
+			size += aws.Int64Value(object.Size)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return keys, size, nil
+}
+
+func deleteStoreObjects(s3Client *s3.S3, bucket string, keys []string) error {
+	// DeleteObjects takes at most 1000 keys per request
+	for start := 0; start < len(keys); start += 1000 {
+		end := start + 1000
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		objects := []*s3.ObjectIdentifier{}
+		for _, key := range keys[start:end] {
+			objects = append(objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		output, err := s3Client.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{
+				Objects: objects,
+				Quiet:   aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if len(output.Errors) > 0 {
+			return errors.Errorf("failed to delete %s: %s", aws.StringValue(output.Errors[0].Key), aws.StringValue(output.Errors[0].Message))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOrphanedDirs(t *testing.T) {
+	dirs := []string{"app-xyz12", "app-abc34", "instance-def56"}
+	existing := []string{"app-abc34", "instance-ghi78"}
+
+	want := []string{"app-xyz12", "instance-def56"}
+	got := orphanedDirs(dirs, existing)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestHasBackupMetadata(t *testing.T) {
+	prefix := "kotsadm/backups/app-xyz12/"
+	withMetadata := []string{prefix + "app-xyz12-logs.gz", prefix + "velero-backup.json"}
+	logsOnly := []string{prefix + "app-xyz12-logs.gz"}
+
+	if !hasBackupMetadata(prefix, withMetadata) {
+		t.Errorf("Expected metadata to be found in %v", withMetadata)
+	}
+	if hasBackupMetadata(prefix, logsOnly) {
+		t.Errorf("Expected no metadata in %v", logsOnly)
+	}
+}
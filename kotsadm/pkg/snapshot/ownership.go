@@ -0,0 +1,285 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/downstream"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// generatedNameSuffix matches the random suffix the api server appends to a generateName
+var generatedNameSuffix = regexp.MustCompile(`^[a-z0-9]{5}$`)
+
+// ownershipHints are what the annotations of backups that lost them are recovered from
+type ownershipHints struct {
+	// scheduledBackups are the names of the backups the kots scheduler created
+	scheduledBackups map[string]bool
+	// deployments are the versions deployed for each app, by app id
+	deployments      map[string][]appDeployment
+	kotsadmNamespace string
+}
+
+type appDeployment struct {
+	parentSequence int64
+	deployedAt     time.Time
+}
+
+// ReconcileBackupOwnership restores the annotations kots uses to recognize and list the backups it created, for
+// backups that lost them to changes made outside of kots. Backups are matched to their app by the label selector
+// kots set on the backup spec, or by the name kots generated for them. The trigger, request time and app sequences
+// are recovered from the scheduler's records, the backup's creation time and the app's deploy history. The kotsadm
+// image of instance backups can't be recovered and stays missing.
+// kots doesn't create velero schedules, backups created by a velero schedule for an app are recognized by its
+// schedule name label and reported as scheduled. The backups that were fixed are returned.
+func ReconcileBackupOwnership(ctx context.Context) ([]types.ReconciledBackup, error) {
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list installed apps")
+	}
+
+	hints, err := getOwnershipHints(apps)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get ownership hints")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroBackups, err := veleroClient.Backups(backendStorageLocation.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero backups")
+	}
+
+	reconciled := []types.ReconciledBackup{}
+	for _, veleroBackup := range veleroBackups.Items {
+		annotations := getMissingOwnershipAnnotations(veleroBackup, apps, hints)
+		if len(annotations) == 0 {
+			continue
+		}
+
+		backup := veleroBackup.DeepCopy()
+		if backup.Annotations == nil {
+			backup.Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			backup.Annotations[key] = value
+		}
+		if _, err := veleroClient.Backups(backup.Namespace).Update(ctx, backup, metav1.UpdateOptions{}); err != nil {
+			return nil, errors.Wrapf(err, "failed to update backup %s", backup.Name)
+		}
+
+		reconciled = append(reconciled, types.ReconciledBackup{
+			Name:        backup.Name,
+			Annotations: annotations,
+		})
+	}
+
+	return reconciled, nil
+}
+
+func getOwnershipHints(apps []*apptypes.App) (*ownershipHints, error) {
+	scheduledBackupNames, err := store.GetStore().ListScheduledSnapshotBackupNames()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list scheduled snapshot backup names")
+	}
+
+	hints := &ownershipHints{
+		scheduledBackups: map[string]bool{},
+		deployments:      map[string][]appDeployment{},
+		kotsadmNamespace: os.Getenv("POD_NAMESPACE"),
+	}
+	if os.Getenv("KOTSADM_TARGET_NAMESPACE") != "" {
+		hints.kotsadmNamespace = os.Getenv("KOTSADM_TARGET_NAMESPACE")
+	}
+	for _, backupName := range scheduledBackupNames {
+		hints.scheduledBackups[backupName] = true
+	}
+
+	for _, a := range apps {
+		downstreams, err := store.GetStore().ListDownstreamsForApp(a.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list downstreams for app %s", a.Slug)
+		}
+		if len(downstreams) == 0 {
+			continue
+		}
+
+		pastVersions, err := downstream.GetPastVersions(a.ID, downstreams[0].ClusterID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get past versions for app %s", a.Slug)
+		}
+		versions := pastVersions
+		currentVersion, err := downstream.GetCurrentVersion(a.ID, downstreams[0].ClusterID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get current version for app %s", a.Slug)
+		}
+		if currentVersion != nil {
+			versions = append(versions, *currentVersion)
+		}
+
+		for _, version := range versions {
+			if version.DeployedAt == nil {
+				continue
+			}
+			hints.deployments[a.ID] = append(hints.deployments[a.ID], appDeployment{
+				parentSequence: version.ParentSequence,
+				deployedAt:     *version.DeployedAt,
+			})
+		}
+	}
+
+	return hints, nil
+}
+
+// getMissingOwnershipAnnotations returns the annotations to add for kots to recognize and list the backup as one it
+// created, or nil if it already has them or the backup wasn't created by kots. Annotations the backup has are kept.
+func getMissingOwnershipAnnotations(veleroBackup velerov1.Backup, apps []*apptypes.App, hints *ownershipHints) map[string]string {
+	var annotations map[string]string
+	if isInstanceBackupOwnedByKots(veleroBackup) {
+		annotations = instanceBackupOwnershipAnnotations(veleroBackup, apps, hints)
+	} else if a := findBackupApp(veleroBackup, apps); a != nil {
+		annotations = appBackupOwnershipAnnotations(veleroBackup, a, hints)
+	} else {
+		return nil
+	}
+
+	missing := map[string]string{}
+	for key, value := range annotations {
+		if _, ok := veleroBackup.Annotations[key]; !ok {
+			missing[key] = value
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return missing
+}
+
+func isInstanceBackupOwnedByKots(veleroBackup velerov1.Backup) bool {
+	if veleroBackup.Annotations["kots.io/instance"] == "true" {
+		return true
+	}
+	if veleroBackup.Annotations["kots.io/app-id"] != "" {
+		return false
+	}
+
+	matchLabels := map[string]string{}
+	if veleroBackup.Spec.LabelSelector != nil {
+		matchLabels = veleroBackup.Spec.LabelSelector.MatchLabels
+	}
+	return isGeneratedName(veleroBackup.Name, "instance-") && matchLabels[kotsadmtypes.BackupLabel] == kotsadmtypes.BackupLabelValue
+}
+
+func findBackupApp(veleroBackup velerov1.Backup, apps []*apptypes.App) *apptypes.App {
+	appID := veleroBackup.Annotations["kots.io/app-id"]
+
+	matchLabels := map[string]string{}
+	if veleroBackup.Spec.LabelSelector != nil {
+		matchLabels = veleroBackup.Spec.LabelSelector.MatchLabels
+	}
+
+	for _, a := range apps {
+		if appID != "" {
+			if a.ID == appID {
+				return a
+			}
+			continue
+		}
+		if matchLabels["kots.io/app-slug"] == a.Slug || isGeneratedName(veleroBackup.Name, a.Slug+"-") {
+			return a
+		}
+	}
+
+	return nil
+}
+
+func appBackupOwnershipAnnotations(veleroBackup velerov1.Backup, a *apptypes.App, hints *ownershipHints) map[string]string {
+	annotations := backupRequestAnnotations(veleroBackup, hints)
+	annotations["kots.io/app-id"] = a.ID
+	if sequence, ok := deployedSequenceAt(hints.deployments[a.ID], veleroBackup.CreationTimestamp.Time); ok {
+		annotations["kots.io/app-sequence"] = strconv.FormatInt(sequence, 10)
+	}
+	return annotations
+}
+
+func instanceBackupOwnershipAnnotations(veleroBackup velerov1.Backup, apps []*apptypes.App, hints *ownershipHints) map[string]string {
+	annotations := backupRequestAnnotations(veleroBackup, hints)
+	annotations["kots.io/instance"] = "true"
+	if hints.kotsadmNamespace != "" {
+		annotations["kots.io/kotsadm-deploy-namespace"] = hints.kotsadmNamespace
+	}
+
+	appsSequences := map[string]int64{}
+	for _, a := range apps {
+		if sequence, ok := deployedSequenceAt(hints.deployments[a.ID], veleroBackup.CreationTimestamp.Time); ok {
+			appsSequences[a.Slug] = sequence
+		}
+	}
+	if b, err := json.Marshal(appsSequences); err == nil {
+		annotations["kots.io/apps-sequences"] = string(b)
+	}
+
+	return annotations
+}
+
+// backupRequestAnnotations are the trigger and request time. Backups are manual unless the kots scheduler
+// or a velero schedule created them.
+func backupRequestAnnotations(veleroBackup velerov1.Backup, hints *ownershipHints) map[string]string {
+	annotations := map[string]string{
+		"kots.io/snapshot-trigger": "manual",
+	}
+	if hints.scheduledBackups[veleroBackup.Name] || veleroBackup.Labels[velerov1.ScheduleNameLabel] != "" {
+		annotations["kots.io/snapshot-trigger"] = "schedule"
+	}
+	if !veleroBackup.CreationTimestamp.IsZero() {
+		annotations["kots.io/snapshot-requested"] = veleroBackup.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// deployedSequenceAt returns the parent sequence of the version deployed last before the time
+func deployedSequenceAt(deployments []appDeployment, at time.Time) (int64, bool) {
+	var found *appDeployment
+	for i, deployment := range deployments {
+		if deployment.deployedAt.After(at) {
+			continue
+		}
+		if found == nil || deployment.deployedAt.After(found.deployedAt) {
+			found = &deployments[i]
+		}
+	}
+	if found == nil {
+		return 0, false
+	}
+	return found.parentSequence, true
+}
+
+func isGeneratedName(name string, generateName string) bool {
+	return strings.HasPrefix(name, generateName) && generatedNameSuffix.MatchString(strings.TrimPrefix(name, generateName))
+}
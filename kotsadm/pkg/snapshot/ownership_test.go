@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	kotsadmtypes "github.com/replicatedhq/kots/pkg/kotsadm/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetMissingOwnershipAnnotations(t *testing.T) {
+	apps := []*apptypes.App{
+		{ID: "app-id", Slug: "my-app"},
+		{ID: "other-app-id", Slug: "my-app-2"},
+	}
+
+	created := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	hints := &ownershipHints{
+		scheduledBackups: map[string]bool{"my-app-2-fghij": true},
+		deployments: map[string][]appDeployment{
+			"app-id": {
+				{parentSequence: 1, deployedAt: created.Add(-48 * time.Hour)},
+				{parentSequence: 2, deployedAt: created.Add(-time.Hour)},
+				{parentSequence: 3, deployedAt: created.Add(time.Hour)},
+			},
+			"other-app-id": {
+				{parentSequence: 5, deployedAt: created.Add(-time.Hour)},
+			},
+		},
+		kotsadmNamespace: "default",
+	}
+
+	tests := []struct {
+		name   string
+		backup velerov1.Backup
+		want   map[string]string
+	}{
+		{
+			name: "app backup with its annotations",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "my-app-abcde",
+					CreationTimestamp: metav1.NewTime(created),
+					Annotations: map[string]string{
+						"kots.io/app-id":             "app-id",
+						"kots.io/app-sequence":       "2",
+						"kots.io/snapshot-trigger":   "manual",
+						"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "app backup matched by label selector",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "renamed", CreationTimestamp: metav1.NewTime(created)},
+				Spec: velerov1.BackupSpec{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"kots.io/app-slug": "my-app"},
+					},
+				},
+			},
+			want: map[string]string{
+				"kots.io/app-id":             "app-id",
+				"kots.io/app-sequence":       "2",
+				"kots.io/snapshot-trigger":   "manual",
+				"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+			},
+		},
+		{
+			name: "scheduled app backup matched by name",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-app-2-fghij", CreationTimestamp: metav1.NewTime(created)},
+			},
+			want: map[string]string{
+				"kots.io/app-id":             "other-app-id",
+				"kots.io/app-sequence":       "5",
+				"kots.io/snapshot-trigger":   "schedule",
+				"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+			},
+		},
+		{
+			name: "app backup that only lost its trigger",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "my-app-abcde",
+					CreationTimestamp: metav1.NewTime(created),
+					Annotations: map[string]string{
+						"kots.io/app-id":             "app-id",
+						"kots.io/app-sequence":       "1",
+						"kots.io/snapshot-requested": "2020-10-01T11:59:58Z",
+					},
+				},
+			},
+			want: map[string]string{"kots.io/snapshot-trigger": "manual"},
+		},
+		{
+			name: "app backup created by a velero schedule",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "nightly-20201001120000",
+					CreationTimestamp: metav1.NewTime(created),
+					Labels:            map[string]string{velerov1.ScheduleNameLabel: "nightly"},
+				},
+				Spec: velerov1.BackupSpec{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"kots.io/app-slug": "my-app-2"},
+					},
+				},
+			},
+			want: map[string]string{
+				"kots.io/app-id":             "other-app-id",
+				"kots.io/app-sequence":       "5",
+				"kots.io/snapshot-trigger":   "schedule",
+				"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+			},
+		},
+		{
+			name: "app backup taken before the app was deployed",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-app-2-klmno", CreationTimestamp: metav1.NewTime(created.Add(-24 * time.Hour))},
+			},
+			want: map[string]string{
+				"kots.io/app-id":             "other-app-id",
+				"kots.io/snapshot-trigger":   "manual",
+				"kots.io/snapshot-requested": "2020-09-30T12:00:00Z",
+			},
+		},
+		{
+			name: "instance backup",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "instance-abcde", CreationTimestamp: metav1.NewTime(created)},
+				Spec: velerov1.BackupSpec{
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{kotsadmtypes.BackupLabel: kotsadmtypes.BackupLabelValue},
+					},
+				},
+			},
+			want: map[string]string{
+				"kots.io/instance":                 "true",
+				"kots.io/apps-sequences":           `{"my-app":2,"my-app-2":5}`,
+				"kots.io/kotsadm-deploy-namespace": "default",
+				"kots.io/snapshot-trigger":         "manual",
+				"kots.io/snapshot-requested":       "2020-10-01T12:00:00Z",
+			},
+		},
+		{
+			name: "instance backup with its annotations",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "instance-abcde",
+					CreationTimestamp: metav1.NewTime(created),
+					Annotations: map[string]string{
+						"kots.io/instance":                 "true",
+						"kots.io/apps-sequences":           `{"my-app":2}`,
+						"kots.io/kotsadm-image":            "kotsadm/kotsadm:v1.25.0",
+						"kots.io/kotsadm-deploy-namespace": "default",
+						"kots.io/snapshot-trigger":         "schedule",
+						"kots.io/snapshot-requested":       "2020-10-01T12:00:00Z",
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "backup of an app that isn't installed",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "imported-abcde",
+					Annotations: map[string]string{"kots.io/app-id": "removed-app-id"},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "backup not created by kots",
+			backup: velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly-20201001"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getMissingOwnershipAnnotations(test.backup, apps, hints)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMissingOwnershipAnnotations() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// FindStorePrefixCollisions returns the other backup storage locations in the cluster that use the store's bucket
+// with a prefix that overlaps the store's path. Backups written by either location would show up in the other.
+func FindStorePrefixCollisions(ctx context.Context, store *types.Store) ([]types.StorePrefixCollision, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	backupStorageLocations, err := veleroClient.BackupStorageLocations("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backupstoragelocations")
+	}
+
+	collisions := []types.StorePrefixCollision{}
+	for _, backupStorageLocation := range backupStorageLocations.Items {
+		if backupStorageLocation.Name == "default" {
+			// this is the location the store is saved to
+			continue
+		}
+
+		objectStorage := backupStorageLocation.Spec.ObjectStorage
+		if objectStorage == nil || objectStorage.Bucket != store.Bucket {
+			continue
+		}
+		if backupStorageLocation.Spec.Provider != store.Provider && !strings.HasSuffix(backupStorageLocation.Spec.Provider, "/"+store.Provider) {
+			continue
+		}
+
+		if !prefixesOverlap(store.Path, objectStorage.Prefix) {
+			continue
+		}
+
+		collisions = append(collisions, types.StorePrefixCollision{
+			Location:  backupStorageLocation.Name,
+			Namespace: backupStorageLocation.Namespace,
+			Prefix:    objectStorage.Prefix,
+		})
+	}
+
+	return collisions, nil
+}
+
+// prefixesOverlap returns true if either prefix is the bucket root or one prefix is contained in the other.
+// Velero expects only its own directories at the root of a location, so an empty prefix always collides.
+func prefixesOverlap(a string, b string) bool {
+	a = strings.Trim(a, "/")
+	b = strings.Trim(b, "/")
+
+	if a == "" || b == "" || a == b {
+		return true
+	}
+
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
@@ -0,0 +1,28 @@
+package snapshot
+
+import (
+	"testing"
+)
+
+func TestPrefixesOverlap(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want bool
+	}{
+		{a: "", b: "app-b", want: true},
+		{a: "app-a", b: "", want: true},
+		{a: "app-a", b: "app-a/", want: true},
+		{a: "apps", b: "apps/app-a", want: true},
+		{a: "/apps/app-a", b: "apps", want: true},
+		{a: "app-a", b: "app-b", want: false},
+		{a: "app", b: "app-a", want: false},
+		{a: "apps/app-a", b: "apps/app-b", want: false},
+	}
+	for _, test := range tests {
+		got := prefixesOverlap(test.a, test.b)
+		if got != test.want {
+			t.Errorf("prefixesOverlap(%q, %q): expected %v, got %v", test.a, test.b, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const veleroImagePrePullName = "kotsadm-velero-image-prepull"
+
+// PrePullVeleroImages starts a daemonset in the velero namespace that pulls the velero, plugin and restic images
+// onto every node so the first backup on a node doesn't wait on image pulls. Progress is reported by
+// GetVeleroImagePrePullStatus, which also removes the daemonset once every node has the images.
+func PrePullVeleroImages(ctx context.Context) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if namespace == "" {
+		return errors.New("velero not found")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+	if len(veleroDeployments) == 0 {
+		return errors.New("velero deployment not found")
+	}
+
+	resticDaemonSets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list restic daemonsets")
+	}
+
+	images := listVeleroImages(veleroDeployments, resticDaemonSets)
+
+	err = clientset.AppsV1().DaemonSets(namespace).Delete(ctx, veleroImagePrePullName, metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to delete previous image pre-pull daemonset")
+	}
+
+	daemonSet := veleroImagePrePullDaemonSet(namespace, images, veleroDeployments[0].Spec.Template.Spec.ImagePullSecrets)
+	if _, err := clientset.AppsV1().DaemonSets(namespace).Create(ctx, daemonSet, metav1.CreateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to create image pre-pull daemonset")
+	}
+
+	return nil
+}
+
+// GetVeleroImagePrePullStatus reports which nodes have pulled the velero images
+func GetVeleroImagePrePullStatus(ctx context.Context) (*types.ImagePrePullStatus, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if namespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	daemonSet, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, veleroImagePrePullName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return &types.ImagePrePullStatus{Nodes: []types.NodeImagePrePullStatus{}}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get image pre-pull daemonset")
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s", veleroImagePrePullName),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list image pre-pull pods")
+	}
+
+	status := &types.ImagePrePullStatus{
+		InProgress: true,
+		Nodes:      []types.NodeImagePrePullStatus{},
+	}
+	for _, pod := range pods.Items {
+		status.Nodes = append(status.Nodes, types.NodeImagePrePullStatus{
+			Node:   pod.Spec.NodeName,
+			Pulled: isImagePrePullPodDone(pod),
+		})
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].Node < status.Nodes[j].Node
+	})
+
+	if int32(len(status.Nodes)) < daemonSet.Status.DesiredNumberScheduled {
+		return status, nil
+	}
+	for _, node := range status.Nodes {
+		if !node.Pulled {
+			return status, nil
+		}
+	}
+
+	status.InProgress = false
+	err = clientset.AppsV1().DaemonSets(namespace).Delete(ctx, veleroImagePrePullName, metav1.DeleteOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "failed to delete image pre-pull daemonset")
+	}
+
+	return status, nil
+}
+
+func listVeleroImages(veleroDeployments []appsv1.Deployment, resticDaemonSets []appsv1.DaemonSet) []string {
+	unique := map[string]bool{}
+	for _, deployment := range veleroDeployments {
+		for _, container := range deployment.Spec.Template.Spec.InitContainers {
+			unique[container.Image] = true
+		}
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			unique[container.Image] = true
+		}
+	}
+	for _, daemonSet := range resticDaemonSets {
+		for _, container := range daemonSet.Spec.Template.Spec.Containers {
+			unique[container.Image] = true
+		}
+	}
+
+	images := []string{}
+	for image := range unique {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+
+	return images
+}
+
+// veleroImagePrePullDaemonSet runs a container for each image so they're pulled in parallel. Plugin images don't all
+// ship a shell, so the containers may fail to start, but the image is on the node once the kubelet reports an image ID.
+func veleroImagePrePullDaemonSet(namespace string, images []string, imagePullSecrets []corev1.LocalObjectReference) *appsv1.DaemonSet {
+	labels := map[string]string{
+		"app": veleroImagePrePullName,
+	}
+
+	containers := []corev1.Container{}
+	for i, image := range images {
+		containers = append(containers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", "sleep 3600"},
+		})
+	}
+
+	return &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "apps/v1",
+			Kind:       "DaemonSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      veleroImagePrePullName,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ImagePullSecrets: imagePullSecrets,
+					Containers:       containers,
+					Tolerations: []corev1.Toleration{
+						{
+							Operator: corev1.TolerationOpExists,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func isImagePrePullPodDone(pod corev1.Pod) bool {
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.ImageID == "" {
+			return false
+		}
+	}
+	return true
+}
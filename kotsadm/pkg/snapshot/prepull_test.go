@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestListVeleroImages(t *testing.T) {
+	deployment := appsv1.Deployment{}
+	deployment.Spec.Template.Spec.InitContainers = []corev1.Container{
+		{Name: "velero-plugin-for-aws", Image: "velero/velero-plugin-for-aws:v1.1.0"},
+	}
+	deployment.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "velero", Image: "velero/velero:v1.5.1"},
+	}
+
+	daemonSet := appsv1.DaemonSet{}
+	daemonSet.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "restic", Image: "velero/velero:v1.5.1"},
+	}
+
+	got := listVeleroImages([]appsv1.Deployment{deployment}, []appsv1.DaemonSet{daemonSet})
+	want := []string{"velero/velero-plugin-for-aws:v1.1.0", "velero/velero:v1.5.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestIsImagePrePullPodDone(t *testing.T) {
+	pod := corev1.Pod{}
+	pod.Spec.Containers = []corev1.Container{{Name: "image-0"}, {Name: "image-1"}}
+
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "image-0", ImageID: "docker-pullable://velero/velero@sha256:abcd"}}
+	if isImagePrePullPodDone(pod) {
+		t.Errorf("Expected pod with a missing container status to not be done")
+	}
+
+	pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{Name: "image-1"})
+	if isImagePrePullPodDone(pod) {
+		t.Errorf("Expected pod with an image still pulling to not be done")
+	}
+
+	pod.Status.ContainerStatuses[1].ImageID = "docker-pullable://velero/velero-plugin-for-aws@sha256:abcd"
+	if !isImagePrePullPodDone(pod) {
+		t.Errorf("Expected pod with all images pulled to be done")
+	}
+}
@@ -0,0 +1,183 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	profilerAddressFlag = "--profiler-address"
+	// velero always serves pprof on localhost:6060, enabling the profiler makes it reachable from kotsadm
+	veleroProfilerPort = 6060
+)
+
+// VeleroProfiles are the pprof profiles that can be collected from velero
+var VeleroProfiles = map[string]bool{
+	"allocs":       true,
+	"block":        true,
+	"goroutine":    true,
+	"heap":         true,
+	"mutex":        true,
+	"profile":      true,
+	"threadcreate": true,
+	"trace":        true,
+}
+
+func isProfilerExposed(args []string) bool {
+	exposed := false
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, profilerAddressFlag+"=") {
+			continue
+		}
+		host, _, err := net.SplitHostPort(strings.TrimPrefix(arg, profilerAddressFlag+"="))
+		exposed = err == nil && host != "localhost" && host != "127.0.0.1"
+	}
+	return exposed
+}
+
+// SetVeleroProfilerEnabled exposes velero's pprof endpoint on the pod network so profiles can be collected with
+// GetVeleroProfile. The endpoint has no authentication and exposes velero internals, disabling the profiler
+// restores velero's default of listening on localhost only.
+func SetVeleroProfilerEnabled(enabled bool) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		if isProfilerExposed(container.Args) == enabled {
+			continue
+		}
+		if enabled {
+			container.Args = setFlagArg(container.Args, profilerAddressFlag, fmt.Sprintf(":%d", veleroProfilerPort))
+		} else {
+			container.Args = removeFlagArg(container.Args, profilerAddressFlag)
+		}
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+// GetVeleroProfile collects a pprof profile from a running velero pod and writes it to w. The profiler must have
+// been enabled with SetVeleroProfilerEnabled. Seconds is used by the cpu profile and trace.
+func GetVeleroProfile(ctx context.Context, profile string, seconds int, w io.Writer) error {
+	if !VeleroProfiles[profile] {
+		return errors.Errorf("unknown profile %q", profile)
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if namespace == "" {
+		return errors.New("velero not found")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	var podIP string
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		if !isProfilerExposed(veleroDeployment.Spec.Template.Spec.Containers[0].Args) {
+			continue
+		}
+
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(veleroDeployment.Spec.Selector.MatchLabels).String(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to list velero pods")
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+				podIP = pod.Status.PodIP
+				break
+			}
+		}
+		if podIP != "" {
+			break
+		}
+	}
+	if podIP == "" {
+		return errors.New("no running velero pod with the profiler enabled")
+	}
+
+	url := fmt.Sprintf("http://%s/debug/pprof/%s", net.JoinHostPort(podIP, fmt.Sprintf("%d", veleroProfilerPort)), profile)
+	if seconds > 0 {
+		url = fmt.Sprintf("%s?seconds=%d", url, seconds)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+
+	client := &http.Client{
+		Timeout: time.Duration(seconds)*time.Second + 30*time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to get profile")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return errors.Wrap(err, "failed to copy profile")
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package snapshot
+
+import (
+	"testing"
+)
+
+func TestIsProfilerExposed(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"server"}, false},
+		{[]string{"server", "--profiler-address=localhost:6060"}, false},
+		{[]string{"server", "--profiler-address=127.0.0.1:6060"}, false},
+		{[]string{"server", "--profiler-address=:6060"}, true},
+		{[]string{"server", "--profiler-address=0.0.0.0:6060"}, true},
+		{[]string{"server", "--profiler-address=:6060", "--profiler-address=localhost:6060"}, false},
+	}
+	for _, test := range tests {
+		got := isProfilerExposed(test.args)
+		if got != test.want {
+			t.Errorf("Expected %v for %v, got %v", test.want, test.args, got)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// WatchBackupProgress calls onProgress with the progress of the backup every time the backup or one of its restic
+// volume backups changes. It returns once the backup is done, the context is cancelled or onProgress returns an error.
+func WatchBackupProgress(ctx context.Context, backupName string, onProgress func(*types.BackupProgress) error) error {
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return errors.New("velero not found")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	volumesSelector := fmt.Sprintf("velero.io/backup-name=%s", velerolabel.GetValidName(backupName))
+
+	progressState := &backupProgressState{}
+	for {
+		backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get backup")
+		}
+
+		backupVolumes, err := veleroClient.PodVolumeBackups(veleroNamespace).List(ctx, metav1.ListOptions{
+			LabelSelector: volumesSelector,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to list volumes")
+		}
+
+		progressState.reset(backup, backupVolumes.Items)
+		if err := progressState.send(onProgress); err != nil {
+			return err
+		}
+		if IsBackupDone(progressState.backup) {
+			return nil
+		}
+
+		backupWatch, err := veleroClient.Backups(veleroNamespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", backupName).String(),
+			ResourceVersion: backup.ResourceVersion,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to watch backup")
+		}
+
+		volumesWatch, err := veleroClient.PodVolumeBackups(veleroNamespace).Watch(ctx, metav1.ListOptions{
+			LabelSelector:   volumesSelector,
+			ResourceVersion: backupVolumes.ResourceVersion,
+		})
+		if err != nil {
+			backupWatch.Stop()
+			return errors.Wrap(err, "failed to watch volumes")
+		}
+
+		done, err := watchBackupProgress(ctx, backupWatch, volumesWatch, progressState, onProgress)
+		backupWatch.Stop()
+		volumesWatch.Stop()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		// the api server closes watches after a while, start over from the current state
+	}
+}
+
+// watchBackupProgress returns true when the backup is done, and false when one of the watches was closed
+func watchBackupProgress(ctx context.Context, backupWatch watch.Interface, volumesWatch watch.Interface, progressState *backupProgressState, onProgress func(*types.BackupProgress) error) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+
+		case e, ok := <-backupWatch.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			backup, ok := e.Object.(*velerov1.Backup)
+			if !ok {
+				continue
+			}
+			if e.Type == watch.Deleted {
+				return false, errors.Errorf("backup %s was deleted", backup.Name)
+			}
+			progressState.backup = backup
+
+		case e, ok := <-volumesWatch.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			backupVolume, ok := e.Object.(*velerov1.PodVolumeBackup)
+			if !ok {
+				continue
+			}
+			progressState.updateVolume(e.Type, backupVolume)
+		}
+
+		if err := progressState.send(onProgress); err != nil {
+			return false, err
+		}
+		if IsBackupDone(progressState.backup) {
+			return true, nil
+		}
+	}
+}
+
+// backupProgressState holds the latest backup and volume backups seen, so progress is only sent when it changes
+type backupProgressState struct {
+	backup  *velerov1.Backup
+	volumes map[string]velerov1.PodVolumeBackup
+	last    *types.BackupProgress
+}
+
+func (s *backupProgressState) reset(backup *velerov1.Backup, backupVolumes []velerov1.PodVolumeBackup) {
+	s.backup = backup
+	s.volumes = map[string]velerov1.PodVolumeBackup{}
+	for _, backupVolume := range backupVolumes {
+		s.volumes[backupVolume.Name] = backupVolume
+	}
+}
+
+func (s *backupProgressState) updateVolume(eventType watch.EventType, backupVolume *velerov1.PodVolumeBackup) {
+	switch eventType {
+	case watch.Added, watch.Modified:
+		s.volumes[backupVolume.Name] = *backupVolume
+	case watch.Deleted:
+		delete(s.volumes, backupVolume.Name)
+	}
+}
+
+func (s *backupProgressState) send(onProgress func(*types.BackupProgress) error) error {
+	backupVolumes := []velerov1.PodVolumeBackup{}
+	for _, backupVolume := range s.volumes {
+		backupVolumes = append(backupVolumes, backupVolume)
+	}
+
+	progress := backupProgress(s.backup, backupVolumes)
+	if reflect.DeepEqual(progress, s.last) {
+		return nil
+	}
+	s.last = progress
+
+	return onProgress(progress)
+}
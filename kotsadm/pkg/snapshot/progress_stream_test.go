@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestBackupProgressStateSend(t *testing.T) {
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+		Status: velerov1.BackupStatus{
+			Phase: velerov1.BackupPhaseInProgress,
+		},
+	}
+	volume := func(name string, done int64) *velerov1.PodVolumeBackup {
+		v := &velerov1.PodVolumeBackup{}
+		v.Name = name
+		v.Status.Progress.TotalBytes = 100
+		v.Status.Progress.BytesDone = done
+		return v
+	}
+
+	sent := []*types.BackupProgress{}
+	onProgress := func(progress *types.BackupProgress) error {
+		sent = append(sent, progress)
+		return nil
+	}
+
+	state := &backupProgressState{}
+	state.reset(backup, []velerov1.PodVolumeBackup{*volume("a", 0)})
+	if err := state.send(onProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing changed
+	state.updateVolume(watch.Modified, volume("a", 0))
+	if err := state.send(onProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	state.updateVolume(watch.Added, volume("b", 50))
+	if err := state.send(onProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	state.updateVolume(watch.Modified, volume("a", 100))
+	if err := state.send(onProgress); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 3 {
+		t.Fatalf("Expected 3 progress events, got %d", len(sent))
+	}
+	if sent[1].VolumeTotalBytes != 200 || sent[1].VolumeBytesDone != 50 {
+		t.Errorf("Expected 50 of 200 bytes done, got %d of %d", sent[1].VolumeBytesDone, sent[1].VolumeTotalBytes)
+	}
+	if sent[2].VolumeBytesDone != 150 {
+		t.Errorf("Expected 150 bytes done, got %d", sent[2].VolumeBytesDone)
+	}
+
+	state.updateVolume(watch.Deleted, volume("b", 50))
+	completed := backup.DeepCopy()
+	completed.Status.Phase = velerov1.BackupPhaseCompleted
+	state.backup = completed
+	if err := state.send(onProgress); err != nil {
+		t.Fatal(err)
+	}
+	last := sent[len(sent)-1]
+	if last.Phase != string(velerov1.BackupPhaseCompleted) || last.CompletionPercent != 100 || last.VolumeTotalBytes != 100 {
+		t.Errorf("Expected completed progress for one volume, got %+v", last)
+	}
+}
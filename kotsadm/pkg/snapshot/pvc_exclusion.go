@@ -0,0 +1,239 @@
+package snapshot
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// resticVolumesExcludesAnnotation is the pod annotation restic skips volumes with when backing up all pod volumes
+	resticVolumesExcludesAnnotation = "backup.velero.io/backup-volumes-excludes"
+	// excludedVolumesAnnotation lists the volumes kots added to the pod's restic excludes
+	excludedVolumesAnnotation = "kots.io/restic-excluded-volumes"
+	// removedVolumesAnnotation lists the volumes kots removed from the pod's restic volumes
+	removedVolumesAnnotation = "kots.io/restic-removed-volumes"
+)
+
+// ValidateExcludedPVCs checks that each excluded pvc is a pvc name or a namespace/name pair
+func ValidateExcludedPVCs(excludedPVCs []string) error {
+	for _, excludedPVC := range excludedPVCs {
+		namespace, name := splitExcludedPVC(excludedPVC)
+		if namespace != "" {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return errors.Errorf("invalid namespace in %q: %s", excludedPVC, strings.Join(errs, ", "))
+			}
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return errors.Errorf("invalid pvc name %q: %s", excludedPVC, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// excludePVCsFromBackup annotates the pods mounting the excluded pvcs so restic skips their volumes, whether the
+// backup defaults all volumes to restic or only backs up the volumes pods opt in. Volumes of pvcs that are no longer
+// excluded get their annotations back the way they were. Pods that are recreated lose the annotations, so this runs
+// before each backup.
+func excludePVCsFromBackup(ctx context.Context, namespaces []string, excludedPVCs []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	for _, namespace := range namespaces {
+		if namespace == "" || namespace == "*" {
+			continue
+		}
+
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list pods in namespace %s", namespace)
+		}
+
+		for _, pod := range pods.Items {
+			volumes := podVolumesToExclude(pod, excludedPVCs)
+			annotations, changed := excludeVolumeAnnotations(pod.Annotations, volumes)
+			if !changed {
+				continue
+			}
+
+			pod.Annotations = annotations
+			if _, err := clientset.CoreV1().Pods(namespace).Update(ctx, &pod, metav1.UpdateOptions{}); err != nil {
+				return errors.Wrapf(err, "failed to update pod %s in namespace %s", pod.Name, namespace)
+			}
+			logger.Infof("Excluded volumes [%s] of pod %s in namespace %s from restic backup", strings.Join(volumes, ","), pod.Name, namespace)
+		}
+	}
+
+	return nil
+}
+
+// excludeVolumeAnnotations returns the pod annotations with the volumes added to the restic excludes and removed
+// from the restic volumes. Volumes kots excluded or removed before that aren't in the list anymore are put back.
+// It returns true if the annotations changed.
+func excludeVolumeAnnotations(annotations map[string]string, volumes []string) (map[string]string, bool) {
+	exclude := map[string]bool{}
+	for _, volume := range volumes {
+		exclude[volume] = true
+	}
+
+	updated := map[string]string{}
+	for k, v := range annotations {
+		updated[k] = v
+	}
+
+	// the excludes annotation may have volumes of its own, only the ones kots added are tracked and taken out
+	excludes := []string{}
+	excludedByKots := []string{}
+	previouslyExcluded := volumeNameSet(annotations[excludedVolumesAnnotation])
+	present := map[string]bool{}
+	for _, volume := range splitVolumeNames(annotations[resticVolumesExcludesAnnotation]) {
+		if previouslyExcluded[volume] && !exclude[volume] {
+			continue
+		}
+		if previouslyExcluded[volume] {
+			excludedByKots = append(excludedByKots, volume)
+		}
+		present[volume] = true
+		excludes = append(excludes, volume)
+	}
+	for _, volume := range volumes {
+		if !present[volume] {
+			excludes = append(excludes, volume)
+			excludedByKots = append(excludedByKots, volume)
+		}
+	}
+
+	includes := []string{}
+	removedByKots := []string{}
+	for _, volume := range splitVolumeNames(annotations[resticVolumesAnnotation]) {
+		if exclude[volume] {
+			removedByKots = append(removedByKots, volume)
+			continue
+		}
+		includes = append(includes, volume)
+	}
+	for _, volume := range splitVolumeNames(annotations[removedVolumesAnnotation]) {
+		if exclude[volume] {
+			if !containsString(removedByKots, volume) {
+				removedByKots = append(removedByKots, volume)
+			}
+			continue
+		}
+		if !containsString(includes, volume) {
+			includes = append(includes, volume)
+		}
+	}
+
+	setVolumeNames(updated, resticVolumesExcludesAnnotation, excludes)
+	setVolumeNames(updated, excludedVolumesAnnotation, excludedByKots)
+	setVolumeNames(updated, resticVolumesAnnotation, includes)
+	setVolumeNames(updated, removedVolumesAnnotation, removedByKots)
+
+	if len(updated) == 0 && len(annotations) == 0 {
+		return annotations, false
+	}
+	return updated, !reflect.DeepEqual(updated, annotations)
+}
+
+func setVolumeNames(annotations map[string]string, key string, volumes []string) {
+	if reflect.DeepEqual(splitVolumeNames(annotations[key]), volumes) {
+		// keep the value as it was written
+		return
+	}
+	if len(volumes) == 0 {
+		delete(annotations, key)
+		return
+	}
+	annotations[key] = strings.Join(volumes, ",")
+}
+
+func splitVolumeNames(value string) []string {
+	volumes := []string{}
+	for _, volume := range strings.Split(value, ",") {
+		volume = strings.TrimSpace(volume)
+		if volume != "" && !containsString(volumes, volume) {
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes
+}
+
+func volumeNameSet(value string) map[string]bool {
+	volumes := map[string]bool{}
+	for _, volume := range splitVolumeNames(value) {
+		volumes[volume] = true
+	}
+	return volumes
+}
+
+// podVolumesToExclude returns the names of the pod's volumes that mount an excluded pvc
+func podVolumesToExclude(pod corev1.Pod, excludedPVCs []string) []string {
+	volumes := []string{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim == nil {
+			continue
+		}
+		for _, excludedPVC := range excludedPVCs {
+			namespace, name := splitExcludedPVC(excludedPVC)
+			if namespace != "" && namespace != pod.Namespace {
+				continue
+			}
+			if name == volume.PersistentVolumeClaim.ClaimName {
+				volumes = append(volumes, volume.Name)
+				break
+			}
+		}
+	}
+	return volumes
+}
+
+// mergeVolumeNames adds the volumes to a comma separated volumes annotation value, keeping the volumes
+// that are already there. It returns true if the value was changed.
+func mergeVolumeNames(existing string, volumes []string) (string, bool) {
+	excludes := []string{}
+	seen := map[string]bool{}
+	for _, volume := range strings.Split(existing, ",") {
+		volume = strings.TrimSpace(volume)
+		if volume == "" || seen[volume] {
+			continue
+		}
+		seen[volume] = true
+		excludes = append(excludes, volume)
+	}
+
+	changed := false
+	for _, volume := range volumes {
+		if seen[volume] {
+			continue
+		}
+		seen[volume] = true
+		excludes = append(excludes, volume)
+		changed = true
+	}
+
+	return strings.Join(excludes, ","), changed
+}
+
+// splitExcludedPVC splits "namespace/name", a name without a namespace matches the pvc in any backed up namespace
+func splitExcludedPVC(excludedPVC string) (string, string) {
+	parts := strings.SplitN(excludedPVC, "/", 2)
+	if len(parts) == 1 {
+		return "", parts[0]
+	}
+	return parts[0], parts[1]
+}
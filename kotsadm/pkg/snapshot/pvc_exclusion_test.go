@@ -0,0 +1,220 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodVolumesToExclude(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-0",
+			Namespace: "default",
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-app-0"},
+					},
+				},
+				{
+					Name: "cache",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache-app-0"},
+					},
+				},
+				{
+					Name: "scratch",
+					VolumeSource: corev1.VolumeSource{
+						EmptyDir: &corev1.EmptyDirVolumeSource{},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		excludedPVCs []string
+		want         []string
+	}{
+		{
+			name:         "by name",
+			excludedPVCs: []string{"cache-app-0"},
+			want:         []string{"cache"},
+		},
+		{
+			name:         "by namespace and name",
+			excludedPVCs: []string{"default/cache-app-0", "default/data-app-0"},
+			want:         []string{"data", "cache"},
+		},
+		{
+			name:         "other namespace",
+			excludedPVCs: []string{"other/cache-app-0"},
+			want:         []string{},
+		},
+		{
+			name:         "not a pvc",
+			excludedPVCs: []string{"scratch"},
+			want:         []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := podVolumesToExclude(pod, test.excludedPVCs)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeVolumeNames(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		volumes     []string
+		want        string
+		wantChanged bool
+	}{
+		{
+			name:        "no existing annotation",
+			existing:    "",
+			volumes:     []string{"cache"},
+			want:        "cache",
+			wantChanged: true,
+		},
+		{
+			name:        "keeps existing excludes",
+			existing:    "tmp, logs",
+			volumes:     []string{"cache"},
+			want:        "tmp,logs,cache",
+			wantChanged: true,
+		},
+		{
+			name:        "already excluded",
+			existing:    "cache,tmp",
+			volumes:     []string{"cache"},
+			want:        "cache,tmp",
+			wantChanged: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, changed := mergeVolumeNames(test.existing, test.volumes)
+			if got != test.want || changed != test.wantChanged {
+				t.Errorf("Expected %q %v, got %q %v", test.want, test.wantChanged, got, changed)
+			}
+		})
+	}
+}
+
+func TestExcludeVolumeAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		volumes     []string
+		want        map[string]string
+		wantChanged bool
+	}{
+		{
+			name:        "nothing to exclude",
+			annotations: nil,
+			volumes:     []string{},
+			want:        nil,
+			wantChanged: false,
+		},
+		{
+			name:        "default volumes to restic",
+			annotations: map[string]string{"backup.velero.io/backup-volumes-excludes": "tmp"},
+			volumes:     []string{"cache"},
+			want: map[string]string{
+				"backup.velero.io/backup-volumes-excludes": "tmp,cache",
+				"kots.io/restic-excluded-volumes":          "cache",
+			},
+			wantChanged: true,
+		},
+		{
+			name:        "opted in volumes",
+			annotations: map[string]string{"backup.velero.io/backup-volumes": "data,cache"},
+			volumes:     []string{"cache"},
+			want: map[string]string{
+				"backup.velero.io/backup-volumes":          "data",
+				"kots.io/restic-removed-volumes":           "cache",
+				"backup.velero.io/backup-volumes-excludes": "cache",
+				"kots.io/restic-excluded-volumes":          "cache",
+			},
+			wantChanged: true,
+		},
+		{
+			name: "already excluded",
+			annotations: map[string]string{
+				"backup.velero.io/backup-volumes":          "data",
+				"kots.io/restic-removed-volumes":           "cache",
+				"backup.velero.io/backup-volumes-excludes": "cache",
+				"kots.io/restic-excluded-volumes":          "cache",
+			},
+			volumes: []string{"cache"},
+			want: map[string]string{
+				"backup.velero.io/backup-volumes":          "data",
+				"kots.io/restic-removed-volumes":           "cache",
+				"backup.velero.io/backup-volumes-excludes": "cache",
+				"kots.io/restic-excluded-volumes":          "cache",
+			},
+			wantChanged: false,
+		},
+		{
+			name: "no longer excluded",
+			annotations: map[string]string{
+				"backup.velero.io/backup-volumes":          "data",
+				"kots.io/restic-removed-volumes":           "cache",
+				"backup.velero.io/backup-volumes-excludes": "tmp,cache",
+				"kots.io/restic-excluded-volumes":          "cache",
+			},
+			volumes: []string{},
+			want: map[string]string{
+				"backup.velero.io/backup-volumes":          "data,cache",
+				"backup.velero.io/backup-volumes-excludes": "tmp",
+			},
+			wantChanged: true,
+		},
+		{
+			name:        "excluded by the app",
+			annotations: map[string]string{"backup.velero.io/backup-volumes-excludes": "cache"},
+			volumes:     []string{},
+			want:        map[string]string{"backup.velero.io/backup-volumes-excludes": "cache"},
+			wantChanged: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, changed := excludeVolumeAnnotations(test.annotations, test.volumes)
+			if changed != test.wantChanged {
+				t.Errorf("Expected changed %v, got %v", test.wantChanged, changed)
+			}
+			if changed && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateExcludedPVCs(t *testing.T) {
+	if err := ValidateExcludedPVCs([]string{"cache", "default/data-app-0"}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := ValidateExcludedPVCs([]string{"Cache"}); err == nil {
+		t.Error("Expected an error for an invalid pvc name")
+	}
+	if err := ValidateExcludedPVCs([]string{"my_namespace/cache"}); err == nil {
+		t.Error("Expected an error for an invalid namespace")
+	}
+}
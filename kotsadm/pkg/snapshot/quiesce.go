@@ -0,0 +1,545 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	"go.uber.org/zap"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	quiescedWorkloadsAnnotation = "kots.io/quiesced-workloads"
+	quiesceRestoredAnnotation   = "kots.io/quiesce-restored"
+	quiesceTimeout              = 5 * time.Minute
+
+	// quiescedReplicasAnnotation and quiescedForBackupAnnotation are set on a workload while it's quiesced, so that
+	// it can be scaled back if kotsadm restarts before the backup finishes
+	quiescedReplicasAnnotation  = "kots.io/quiesced-replicas"
+	quiescedForBackupAnnotation = "kots.io/quiesced-for-backup"
+)
+
+var (
+	// quiescingBackups counts the backups of each app whose workloads are being quiesced, they don't exist yet but
+	// count as running backups
+	quiescingBackups   = map[string]int{}
+	quiescingBackupsMu sync.Mutex
+)
+
+// ValidateQuiesceActions checks that every action references a deployment or statefulset that exists
+func ValidateQuiesceActions(ctx context.Context, actions []types.QuiesceAction) error {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	for _, action := range actions {
+		if action.Kind != "Deployment" && action.Kind != "StatefulSet" {
+			return errors.Errorf("unsupported kind %q, must be Deployment or StatefulSet", action.Kind)
+		}
+		if errs := validation.IsDNS1123Subdomain(action.Name); len(errs) > 0 {
+			return errors.Errorf("invalid %s name %q", action.Kind, action.Name)
+		}
+		if action.Replicas < 0 {
+			return errors.Errorf("replicas for %s %s must not be negative", action.Kind, action.Name)
+		}
+		if _, err := getScale(ctx, clientset, quiesceNamespace(action, defaultAppNamespace()), action.Kind, action.Name); err != nil {
+			return errors.Wrapf(err, "failed to find %s %s", action.Kind, action.Name)
+		}
+	}
+
+	return nil
+}
+
+// quiesceWorkloads scales the workloads to the replicas configured for the backup and waits for them to scale.
+// The original replica counts are recorded on each workload before it's scaled, and returned so they can be
+// scaled back after the backup.
+func quiesceWorkloads(ctx context.Context, defaultNamespace string, backupName string, actions []types.QuiesceAction) ([]types.QuiescedWorkload, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	quiesced := []types.QuiescedWorkload{}
+	for _, action := range actions {
+		namespace := quiesceNamespace(action, defaultNamespace)
+
+		replicas, err := getOriginalReplicas(ctx, clientset, namespace, action.Kind, action.Name)
+		if err != nil {
+			unquiesceWorkloads(ctx, clientset, backupName, quiesced)
+			return nil, errors.Wrapf(err, "failed to get replicas of %s %s", action.Kind, action.Name)
+		}
+
+		workload := types.QuiescedWorkload{
+			Kind:      action.Kind,
+			Namespace: namespace,
+			Name:      action.Name,
+			Replicas:  replicas,
+		}
+
+		if err := setQuiescedAnnotations(ctx, clientset, workload, backupName); err != nil {
+			unquiesceWorkloads(ctx, clientset, backupName, quiesced)
+			return nil, errors.Wrapf(err, "failed to record replicas of %s %s", action.Kind, action.Name)
+		}
+
+		logger.Debug("quiescing workload for backup",
+			zap.String("kind", action.Kind),
+			zap.String("name", action.Name),
+			zap.Int32("replicas", action.Replicas))
+
+		if err := scaleAndWait(ctx, clientset, namespace, action.Kind, action.Name, action.Replicas); err != nil {
+			unquiesceWorkloads(ctx, clientset, backupName, append(quiesced, workload))
+			return nil, errors.Wrapf(err, "failed to scale %s %s", action.Kind, action.Name)
+		}
+
+		quiesced = append(quiesced, workload)
+	}
+
+	return quiesced, nil
+}
+
+// quiesceAndCreateBackup quiesces the app's workloads and then creates the backup. The backup counts as running
+// while the workloads scale down. The workloads are scaled back if the backup can't be created, or by the informer
+// once it's done.
+func quiesceAndCreateBackup(veleroClient *veleroclientv1.VeleroV1Client, appID string, defaultNamespace string, veleroBackup *velerov1.Backup, actions []types.QuiesceAction) (*velerov1.Backup, error) {
+	startQuiescingBackup(appID)
+	defer finishQuiescingBackup(appID)
+
+	// the workloads have to be scaled back even if the request that started the backup goes away
+	ctx := context.Background()
+
+	quiesced, err := quiesceWorkloads(ctx, defaultNamespace, veleroBackup.Name, actions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to quiesce workloads")
+	}
+
+	// the informer scales the workloads back up once the backup is done
+	if err := setQuiescedWorkloads(veleroBackup, quiesced); err != nil {
+		if err := UnquiesceBackup(ctx, veleroBackup); err != nil {
+			logger.Error(errors.Wrap(err, "failed to unquiesce workloads"))
+		}
+		return nil, errors.Wrap(err, "failed to record quiesced workloads")
+	}
+
+	backup, err := veleroClient.Backups(veleroBackup.Namespace).Create(ctx, veleroBackup, metav1.CreateOptions{})
+	if err != nil {
+		if err := UnquiesceBackup(ctx, veleroBackup); err != nil {
+			logger.Error(errors.Wrap(err, "failed to unquiesce workloads"))
+		}
+		return nil, errors.Wrap(err, "failed to create velero backup")
+	}
+
+	return backup, nil
+}
+
+func startQuiescingBackup(appID string) {
+	quiescingBackupsMu.Lock()
+	defer quiescingBackupsMu.Unlock()
+	quiescingBackups[appID]++
+}
+
+func finishQuiescingBackup(appID string) {
+	quiescingBackupsMu.Lock()
+	defer quiescingBackupsMu.Unlock()
+	quiescingBackups[appID]--
+	if quiescingBackups[appID] <= 0 {
+		delete(quiescingBackups, appID)
+	}
+}
+
+func countQuiescingBackups(appID string) int {
+	quiescingBackupsMu.Lock()
+	defer quiescingBackupsMu.Unlock()
+	return quiescingBackups[appID]
+}
+
+// ReconcileQuiescedWorkloads scales back the workloads that are still quiesced for a backup that finished or was
+// never created, which happens when kotsadm restarts while quiescing or before the informer sees the backup finish.
+func ReconcileQuiescedWorkloads(ctx context.Context) error {
+	apps, err := store.GetStore().ListInstalledApps()
+	if err != nil {
+		return errors.Wrap(err, "failed to list installed apps")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	for _, a := range apps {
+		if countQuiescingBackups(a.ID) > 0 {
+			continue
+		}
+		for _, action := range a.SnapshotQuiesceActions {
+			namespace := quiesceNamespace(action, defaultAppNamespace())
+			annotations, err := getWorkloadAnnotations(ctx, clientset, namespace, action.Kind, action.Name)
+			if kuberneteserrors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				logger.Error(errors.Wrapf(err, "failed to get %s %s", action.Kind, action.Name))
+				continue
+			}
+
+			backupName, ok := annotations[quiescedForBackupAnnotation]
+			if !ok {
+				continue
+			}
+
+			if veleroNamespace != "" {
+				backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+				if err != nil && !kuberneteserrors.IsNotFound(err) {
+					logger.Error(errors.Wrapf(err, "failed to get backup %s", backupName))
+					continue
+				}
+				if err == nil && !IsBackupDone(backup) {
+					// the informer scales it back once the backup is done
+					continue
+				}
+				if err == nil {
+					running, err := hasRunningFanOutCopies(ctx, veleroClient, backup)
+					if err != nil {
+						logger.Error(errors.Wrapf(err, "failed to check fan-out backups of %s", backupName))
+						continue
+					}
+					if running {
+						// or once its fan-out copies are done
+						continue
+					}
+				}
+			}
+
+			replicas, err := parseQuiescedReplicas(annotations)
+			if err != nil {
+				logger.Error(errors.Wrapf(err, "failed to get original replicas of %s %s", action.Kind, action.Name))
+				continue
+			}
+
+			workload := types.QuiescedWorkload{Kind: action.Kind, Namespace: namespace, Name: action.Name, Replicas: replicas}
+			logger.Infof("Scaling %s %s back to %d replicas after backup %s", action.Kind, action.Name, replicas, backupName)
+			unquiesceWorkloads(ctx, clientset, backupName, []types.QuiescedWorkload{workload})
+		}
+	}
+
+	return nil
+}
+
+// UnquiesceRestoredWorkloads scales the workloads that were quiesced when the backup was taken back to their
+// original replicas once they're restored. The backup captured them scaled down, and redeploying the app after the
+// restore doesn't change the replicas since the restored workloads already match the app's manifests.
+func UnquiesceRestoredWorkloads(ctx context.Context, backup *velerov1.Backup, restore *velerov1.Restore) error {
+	quiesced, err := getQuiescedWorkloads(backup)
+	if err != nil {
+		return errors.Wrap(err, "failed to get quiesced workloads")
+	}
+	if len(quiesced) == 0 {
+		return nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	restored := []types.QuiescedWorkload{}
+	for _, workload := range quiesced {
+		if namespace, ok := restore.Spec.NamespaceMapping[workload.Namespace]; ok {
+			workload.Namespace = namespace
+		}
+		restored = append(restored, workload)
+	}
+
+	if err := unquiesceWorkloads(ctx, clientset, backup.Name, restored); err != nil {
+		return errors.Wrap(err, "failed to unquiesce workloads")
+	}
+
+	return nil
+}
+
+// UnquiesceBackup scales the workloads that were quiesced for a backup back to their original replicas
+func UnquiesceBackup(ctx context.Context, backup *velerov1.Backup) error {
+	quiesced, err := getQuiescedWorkloads(backup)
+	if err != nil {
+		return errors.Wrap(err, "failed to get quiesced workloads")
+	}
+	if len(quiesced) == 0 {
+		return nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	if err := unquiesceWorkloads(ctx, clientset, backup.Name, quiesced); err != nil {
+		return errors.Wrap(err, "failed to unquiesce workloads")
+	}
+
+	return nil
+}
+
+// NeedsUnquiesce returns true if workloads were quiesced for the backup and have not been scaled back yet
+func NeedsUnquiesce(backup *velerov1.Backup) bool {
+	if _, ok := backup.Annotations[quiescedWorkloadsAnnotation]; !ok {
+		return false
+	}
+	_, ok := backup.Annotations[quiesceRestoredAnnotation]
+	return !ok
+}
+
+// MarkUnquiesced records on the backup that its quiesced workloads have been scaled back
+func MarkUnquiesced(backup *velerov1.Backup) {
+	if backup.Annotations == nil {
+		backup.Annotations = map[string]string{}
+	}
+	backup.Annotations[quiesceRestoredAnnotation] = time.Now().UTC().Format(time.RFC3339)
+}
+
+func setQuiescedWorkloads(backup *velerov1.Backup, quiesced []types.QuiescedWorkload) error {
+	b, err := json.Marshal(quiesced)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal quiesced workloads")
+	}
+	if backup.Annotations == nil {
+		backup.Annotations = map[string]string{}
+	}
+	backup.Annotations[quiescedWorkloadsAnnotation] = string(b)
+	return nil
+}
+
+func getQuiescedWorkloads(backup *velerov1.Backup) ([]types.QuiescedWorkload, error) {
+	value, ok := backup.Annotations[quiescedWorkloadsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	quiesced := []types.QuiescedWorkload{}
+	if err := json.Unmarshal([]byte(value), &quiesced); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal quiesced workloads")
+	}
+	return quiesced, nil
+}
+
+// unquiesceWorkloads scales the workloads that are still quiesced for the backup back to their original replicas.
+// Workloads that were already scaled back, or were quiesced again for another backup since, are left alone.
+func unquiesceWorkloads(ctx context.Context, clientset *kubernetes.Clientset, backupName string, quiesced []types.QuiescedWorkload) error {
+	var lastErr error
+	for _, workload := range quiesced {
+		annotations, err := getWorkloadAnnotations(ctx, clientset, workload.Namespace, workload.Kind, workload.Name)
+		if kuberneteserrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			lastErr = errors.Wrapf(err, "failed to get %s %s", workload.Kind, workload.Name)
+			logger.Error(lastErr)
+			continue
+		}
+		if annotations[quiescedForBackupAnnotation] != backupName {
+			continue
+		}
+
+		// do not wait for the workload to come back up, the backup is already done
+		if err := setScale(ctx, clientset, workload.Namespace, workload.Kind, workload.Name, workload.Replicas); err != nil {
+			lastErr = errors.Wrapf(err, "failed to scale %s %s", workload.Kind, workload.Name)
+			logger.Error(lastErr)
+			continue
+		}
+		if err := clearQuiescedAnnotations(ctx, clientset, workload); err != nil {
+			lastErr = errors.Wrapf(err, "failed to clear quiesced annotations of %s %s", workload.Kind, workload.Name)
+			logger.Error(lastErr)
+		}
+	}
+	return lastErr
+}
+
+// getOriginalReplicas returns the replicas to scale the workload back to. A workload that is still quiesced for
+// an earlier backup is scaled back to the replicas it had before that one.
+func getOriginalReplicas(ctx context.Context, clientset *kubernetes.Clientset, namespace string, kind string, name string) (int32, error) {
+	annotations, err := getWorkloadAnnotations(ctx, clientset, namespace, kind, name)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get annotations")
+	}
+	if _, ok := annotations[quiescedReplicasAnnotation]; ok {
+		return parseQuiescedReplicas(annotations)
+	}
+
+	scale, err := getScale(ctx, clientset, namespace, kind, name)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get scale")
+	}
+	return scale.Spec.Replicas, nil
+}
+
+func parseQuiescedReplicas(annotations map[string]string) (int32, error) {
+	replicas, err := strconv.ParseInt(annotations[quiescedReplicasAnnotation], 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to parse %s annotation", quiescedReplicasAnnotation)
+	}
+	return int32(replicas), nil
+}
+
+func setQuiescedAnnotations(ctx context.Context, clientset *kubernetes.Clientset, workload types.QuiescedWorkload, backupName string) error {
+	return patchWorkloadAnnotations(ctx, clientset, workload, map[string]interface{}{
+		quiescedReplicasAnnotation:  strconv.FormatInt(int64(workload.Replicas), 10),
+		quiescedForBackupAnnotation: backupName,
+	})
+}
+
+func clearQuiescedAnnotations(ctx context.Context, clientset *kubernetes.Clientset, workload types.QuiescedWorkload) error {
+	return patchWorkloadAnnotations(ctx, clientset, workload, map[string]interface{}{
+		quiescedReplicasAnnotation:  nil,
+		quiescedForBackupAnnotation: nil,
+	})
+}
+
+func patchWorkloadAnnotations(ctx context.Context, clientset *kubernetes.Clientset, workload types.QuiescedWorkload, annotations map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal patch")
+	}
+
+	switch workload.Kind {
+	case "Deployment":
+		_, err = clientset.AppsV1().Deployments(workload.Namespace).Patch(ctx, workload.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = clientset.AppsV1().StatefulSets(workload.Namespace).Patch(ctx, workload.Name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+	default:
+		return errors.Errorf("unsupported kind %q", workload.Kind)
+	}
+	return err
+}
+
+func getWorkloadAnnotations(ctx context.Context, clientset *kubernetes.Clientset, namespace string, kind string, name string) (map[string]string, error) {
+	switch kind {
+	case "Deployment":
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return deployment.Annotations, nil
+	case "StatefulSet":
+		statefulSet, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return statefulSet.Annotations, nil
+	}
+	return nil, errors.Errorf("unsupported kind %q", kind)
+}
+
+func defaultAppNamespace() string {
+	if os.Getenv("KOTSADM_TARGET_NAMESPACE") != "" {
+		return os.Getenv("KOTSADM_TARGET_NAMESPACE")
+	}
+	return os.Getenv("POD_NAMESPACE")
+}
+
+func quiesceNamespace(action types.QuiesceAction, defaultNamespace string) string {
+	if action.Namespace != "" {
+		return action.Namespace
+	}
+	return defaultNamespace
+}
+
+func scaleAndWait(ctx context.Context, clientset *kubernetes.Clientset, namespace string, kind string, name string, replicas int32) error {
+	if err := setScale(ctx, clientset, namespace, kind, name, replicas); err != nil {
+		return errors.Wrap(err, "failed to set scale")
+	}
+
+	start := time.Now()
+	for {
+		scale, err := getScale(ctx, clientset, namespace, kind, name)
+		if err != nil {
+			return errors.Wrap(err, "failed to get scale")
+		}
+		if scale.Status.Replicas == replicas {
+			return nil
+		}
+		if time.Now().Sub(start) > quiesceTimeout {
+			return errors.Errorf("timed out waiting for %d replicas", replicas)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func getScale(ctx context.Context, clientset *kubernetes.Clientset, namespace string, kind string, name string) (*autoscalingv1.Scale, error) {
+	switch kind {
+	case "Deployment":
+		return clientset.AppsV1().Deployments(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	case "StatefulSet":
+		return clientset.AppsV1().StatefulSets(namespace).GetScale(ctx, name, metav1.GetOptions{})
+	}
+	return nil, errors.Errorf("unsupported kind %q", kind)
+}
+
+func setScale(ctx context.Context, clientset *kubernetes.Clientset, namespace string, kind string, name string, replicas int32) error {
+	scale, err := getScale(ctx, clientset, namespace, kind, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get scale")
+	}
+	scale.Spec.Replicas = replicas
+
+	switch kind {
+	case "Deployment":
+		_, err = clientset.AppsV1().Deployments(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	case "StatefulSet":
+		_, err = clientset.AppsV1().StatefulSets(namespace).UpdateScale(ctx, name, scale, metav1.UpdateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+func TestQuiescedWorkloadsAnnotation(t *testing.T) {
+	backup := &velerov1.Backup{}
+	if NeedsUnquiesce(backup) {
+		t.Error("Expected backup without quiesced workloads to not need unquiesce")
+	}
+
+	quiesced := []types.QuiescedWorkload{
+		{Kind: "Deployment", Namespace: "default", Name: "api", Replicas: 3},
+		{Kind: "StatefulSet", Namespace: "default", Name: "postgres", Replicas: 1},
+	}
+	if err := setQuiescedWorkloads(backup, quiesced); err != nil {
+		t.Fatal(err)
+	}
+	if !NeedsUnquiesce(backup) {
+		t.Error("Expected backup with quiesced workloads to need unquiesce")
+	}
+
+	got, err := getQuiescedWorkloads(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, quiesced) {
+		t.Errorf("Expected %#v, got %#v", quiesced, got)
+	}
+
+	MarkUnquiesced(backup)
+	if NeedsUnquiesce(backup) {
+		t.Error("Expected backup to not need unquiesce after being marked")
+	}
+}
+
+func TestParseQuiescedReplicas(t *testing.T) {
+	replicas, err := parseQuiescedReplicas(map[string]string{quiescedReplicasAnnotation: "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas != 3 {
+		t.Errorf("Expected 3 replicas, got %d", replicas)
+	}
+
+	if _, err := parseQuiescedReplicas(map[string]string{}); err == nil {
+		t.Error("Expected error without the annotation")
+	}
+}
+
+func TestQuiescingBackups(t *testing.T) {
+	startQuiescingBackup("app-id")
+	startQuiescingBackup("app-id")
+	if got := countQuiescingBackups("app-id"); got != 2 {
+		t.Errorf("Expected 2 quiescing backups, got %d", got)
+	}
+
+	finishQuiescingBackup("app-id")
+	finishQuiescingBackup("app-id")
+	if got := countQuiescingBackups("app-id"); got != 0 {
+		t.Errorf("Expected no quiescing backups, got %d", got)
+	}
+}
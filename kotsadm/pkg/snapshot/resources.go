@@ -0,0 +1,248 @@
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// NamespacedResourceError is returned when a cluster resource filter names a namespaced resource. Velero applies
+// resource filters to namespaced and cluster scoped resources alike, so it would filter the namespaced one too.
+type NamespacedResourceError struct {
+	Name string
+}
+
+func (e NamespacedResourceError) Error() string {
+	return fmt.Sprintf("%s is a namespaced resource", e.Name)
+}
+
+// IsNamespacedResourceError returns true if the cause of the error is a NamespacedResourceError
+func IsNamespacedResourceError(err error) bool {
+	_, ok := errors.Cause(err).(NamespacedResourceError)
+	return ok
+}
+
+// apiResource is a resource type served by the cluster
+type apiResource struct {
+	Name       string
+	Group      string
+	Namespaced bool
+}
+
+func (r apiResource) qualifiedName() string {
+	if r.Group == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s.%s", r.Name, r.Group)
+}
+
+func (r apiResource) matches(name string) bool {
+	return name == r.Name || name == r.qualifiedName()
+}
+
+// ValidateClusterResourceNames checks that each name is a resource name velero can filter on,
+// e.g. "clusterroles", "clusterroles.rbac.authorization.k8s.io" or "*"
+func ValidateClusterResourceNames(names []string) error {
+	for _, name := range names {
+		if name == "*" {
+			continue
+		}
+		if strings.TrimSpace(name) != name {
+			return errors.Errorf("resource name %q must not contain leading or trailing whitespace", name)
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return errors.Errorf("invalid resource name %q: %s", name, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateClusterScopedResources checks that none of the names match a namespaced resource served by the cluster
+func ValidateClusterScopedResources(included []string, excluded []string) error {
+	if len(included) == 0 && len(excluded) == 0 {
+		return nil
+	}
+
+	resources, err := listAPIResources()
+	if err != nil {
+		return errors.Wrap(err, "failed to list api resources")
+	}
+
+	_, err = clusterResourceExclusions(resources, included, excluded)
+	return err
+}
+
+// applyClusterResourceFilters limits the cluster scoped resources in the backup to the included ones, all of them if
+// none are listed, minus the excluded ones. Velero has no filters that only apply to cluster scoped resources, so
+// both lists are turned into exclusions of cluster scoped resource types, which leaves namespaced resources alone.
+func applyClusterResourceFilters(veleroBackup *velerov1.Backup, included []string, excluded []string) error {
+	if len(included) == 0 && len(excluded) == 0 {
+		return nil
+	}
+
+	resources, err := listAPIResources()
+	if err != nil {
+		return errors.Wrap(err, "failed to list api resources")
+	}
+
+	exclusions, err := clusterResourceExclusions(resources, included, excluded)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster resource exclusions")
+	}
+
+	if len(included) > 0 {
+		includeClusterResources := true
+		veleroBackup.Spec.IncludeClusterResources = &includeClusterResources
+	}
+	veleroBackup.Spec.ExcludedResources = append(veleroBackup.Spec.ExcludedResources, exclusions...)
+
+	return nil
+}
+
+// clusterResourceExclusions returns the cluster scoped resource types to exclude so that only the included ones,
+// minus the excluded ones, are backed up. "*" includes or excludes all of them. Names that aren't served by the
+// cluster are excluded as is, in case their CRD is installed later.
+func clusterResourceExclusions(resources []apiResource, included []string, excluded []string) ([]string, error) {
+	for _, name := range append(append([]string{}, included...), excluded...) {
+		for _, resource := range resources {
+			if resource.Namespaced && resource.matches(name) {
+				return nil, NamespacedResourceError{Name: name}
+			}
+		}
+	}
+
+	exclusions := []string{}
+	added := map[string]bool{}
+	exclude := func(name string) {
+		if !added[name] {
+			exclusions = append(exclusions, name)
+			added[name] = true
+		}
+	}
+
+	includeAll := len(included) == 0 || containsString(included, "*")
+	excludeAll := containsString(excluded, "*")
+	for _, resource := range resources {
+		if resource.Namespaced {
+			continue
+		}
+		if excludeAll || (!includeAll && !matchesAnyName(resource, included)) {
+			exclude(resource.qualifiedName())
+		}
+	}
+	for _, name := range excluded {
+		if name != "*" {
+			exclude(name)
+		}
+	}
+
+	return exclusions, nil
+}
+
+func matchesAnyName(resource apiResource, names []string) bool {
+	for _, name := range names {
+		if resource.matches(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func listAPIResources() ([]apiResource, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	// groups that fail discovery, e.g. an unavailable aggregated api, are left out and the rest are still returned
+	resourceLists, err := clientset.Discovery().ServerPreferredResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, errors.Wrap(err, "failed to get server preferred resources")
+	}
+
+	resources := []apiResource{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse group version %s", resourceList.GroupVersion)
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				// subresource
+				continue
+			}
+			resources = append(resources, apiResource{
+				Name:       resource.Name,
+				Group:      gv.Group,
+				Namespaced: resource.Namespaced,
+			})
+		}
+	}
+
+	return resources, nil
+}
+
+// GetBackupResourceFilter returns the resources every backup includes and excludes
+func GetBackupResourceFilter() (*types.BackupResourceFilter, error) {
+	filter, err := store.GetStore().GetBackupResourceFilter()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup resource filter")
+	}
+	return filter, nil
+}
+
+// applyBackupResourceFilter merges the instance resource filter into the backup. The excluded resources are
+// always added, velero gives exclusions precedence. The included resources only apply to backups that
+// don't list their own, so an app's Backup spec can still narrow it down.
+func applyBackupResourceFilter(veleroBackup *velerov1.Backup, filter *types.BackupResourceFilter) {
+	if filter == nil {
+		return
+	}
+
+	if len(filter.IncludedResources) > 0 && !hasIncludedResources(veleroBackup.Spec.IncludedResources) {
+		veleroBackup.Spec.IncludedResources = append([]string{}, filter.IncludedResources...)
+	}
+
+	excluded := map[string]bool{}
+	for _, resource := range veleroBackup.Spec.ExcludedResources {
+		excluded[resource] = true
+	}
+	for _, resource := range filter.ExcludedResources {
+		if !excluded[resource] {
+			veleroBackup.Spec.ExcludedResources = append(veleroBackup.Spec.ExcludedResources, resource)
+			excluded[resource] = true
+		}
+	}
+}
+
+func hasIncludedResources(includedResources []string) bool {
+	for _, resource := range includedResources {
+		if resource != "" && resource != "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+func TestValidateClusterResourceNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"wildcard", []string{"*"}, false},
+		{"plain", []string{"clusterroles", "persistentvolumes"}, false},
+		{"qualified", []string{"clusterroles.rbac.authorization.k8s.io"}, false},
+		{"uppercase", []string{"ClusterRoles"}, true},
+		{"whitespace", []string{" clusterroles"}, true},
+		{"empty name", []string{""}, true},
+		{"comma", []string{"clusterroles,crds"}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateClusterResourceNames(test.names)
+			if test.wantErr && err == nil {
+				t.Error("Expected error")
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestApplyBackupResourceFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         velerov1.BackupSpec
+		filter       *types.BackupResourceFilter
+		wantIncluded []string
+		wantExcluded []string
+	}{
+		{
+			name:         "no filter",
+			spec:         velerov1.BackupSpec{ExcludedResources: []string{"events"}},
+			filter:       nil,
+			wantExcluded: []string{"events"},
+		},
+		{
+			name:         "exclusions are merged",
+			spec:         velerov1.BackupSpec{ExcludedResources: []string{"events"}},
+			filter:       &types.BackupResourceFilter{ExcludedResources: []string{"events", "podmetrics.metrics.k8s.io"}},
+			wantExcluded: []string{"events", "podmetrics.metrics.k8s.io"},
+		},
+		{
+			name:         "inclusions apply to backups without their own",
+			spec:         velerov1.BackupSpec{IncludedResources: []string{"*"}},
+			filter:       &types.BackupResourceFilter{IncludedResources: []string{"deployments", "secrets"}},
+			wantIncluded: []string{"deployments", "secrets"},
+		},
+		{
+			name:         "backup inclusions are kept",
+			spec:         velerov1.BackupSpec{IncludedResources: []string{"configmaps"}},
+			filter:       &types.BackupResourceFilter{IncludedResources: []string{"deployments"}},
+			wantIncluded: []string{"configmaps"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			veleroBackup := &velerov1.Backup{Spec: test.spec}
+			applyBackupResourceFilter(veleroBackup, test.filter)
+
+			if !reflect.DeepEqual(veleroBackup.Spec.IncludedResources, test.wantIncluded) {
+				t.Errorf("IncludedResources = %v, want %v", veleroBackup.Spec.IncludedResources, test.wantIncluded)
+			}
+			if !reflect.DeepEqual(veleroBackup.Spec.ExcludedResources, test.wantExcluded) {
+				t.Errorf("ExcludedResources = %v, want %v", veleroBackup.Spec.ExcludedResources, test.wantExcluded)
+			}
+		})
+	}
+}
+
+func TestClusterResourceExclusions(t *testing.T) {
+	resources := []apiResource{
+		{Name: "pods", Namespaced: true},
+		{Name: "persistentvolumes"},
+		{Name: "clusterroles", Group: "rbac.authorization.k8s.io"},
+		{Name: "roles", Group: "rbac.authorization.k8s.io", Namespaced: true},
+		{Name: "customresourcedefinitions", Group: "apiextensions.k8s.io"},
+	}
+
+	tests := []struct {
+		name     string
+		included []string
+		excluded []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "no filters",
+			want: []string{},
+		},
+		{
+			name:     "include some",
+			included: []string{"clusterroles"},
+			want:     []string{"persistentvolumes", "customresourcedefinitions.apiextensions.k8s.io"},
+		},
+		{
+			name:     "include some and exclude others",
+			included: []string{"clusterroles.rbac.authorization.k8s.io", "persistentvolumes"},
+			excluded: []string{"persistentvolumes"},
+			want:     []string{"customresourcedefinitions.apiextensions.k8s.io", "persistentvolumes"},
+		},
+		{
+			name:     "exclude only",
+			excluded: []string{"clusterroles", "widgets.example.com"},
+			want:     []string{"clusterroles", "widgets.example.com"},
+		},
+		{
+			name:     "exclude all",
+			excluded: []string{"*"},
+			want:     []string{"persistentvolumes", "clusterroles.rbac.authorization.k8s.io", "customresourcedefinitions.apiextensions.k8s.io"},
+		},
+		{
+			name:     "include namespaced",
+			included: []string{"roles"},
+			wantErr:  true,
+		},
+		{
+			name:     "exclude namespaced",
+			excluded: []string{"pods"},
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := clusterResourceExclusions(resources, test.included, test.excluded)
+			if test.wantErr {
+				if !IsNamespacedResourceError(err) {
+					t.Errorf("Expected namespaced resource error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
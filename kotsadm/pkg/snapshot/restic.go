@@ -0,0 +1,282 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/kurl"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// ResticPasswordMismatchError is returned when restic repositories in the store were created with a different
+// password than the one in velero's repo credentials, usually by another installation sharing the same store
+type ResticPasswordMismatchError struct {
+	Repositories []string
+}
+
+func (e ResticPasswordMismatchError) Error() string {
+	return fmt.Sprintf("restic repository password mismatch for %s", strings.Join(e.Repositories, ", "))
+}
+
+// ValidateResticRepositories checks that velero could open the restic repositories of the default store
+// with the current repo credentials
+func ValidateResticRepositories(ctx context.Context) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	storageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	repos, err := veleroClient.ResticRepositories(storageLocation.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "velero.io/storage-location=default",
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list resticrepositories")
+	}
+
+	mismatched := []string{}
+	for _, repo := range repos.Items {
+		if repo.Status.Phase != velerov1.ResticRepositoryPhaseNotReady {
+			continue
+		}
+		if isResticPasswordMismatch(repo.Status.Message) {
+			mismatched = append(mismatched, repo.Spec.VolumeNamespace)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return ResticPasswordMismatchError{Repositories: mismatched}
+	}
+
+	return nil
+}
+
+func isResticPasswordMismatch(message string) bool {
+	return strings.Contains(message, "wrong password or no key found")
+}
+
+// CheckResticLocks returns the restic repositories that velero could not use because a stale lock was left
+// behind, usually by a restic process on a node that crashed mid-backup
+func CheckResticLocks(ctx context.Context) ([]types.ResticRepositoryLock, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	repos, err := veleroClient.ResticRepositories(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list resticrepositories")
+	}
+
+	locks := []types.ResticRepositoryLock{}
+	for _, repo := range repos.Items {
+		if !isResticRepositoryLocked(repo.Status.Message) {
+			continue
+		}
+		locks = append(locks, types.ResticRepositoryLock{
+			Name:             repo.Name,
+			VolumeNamespace:  repo.Spec.VolumeNamespace,
+			ResticIdentifier: repo.Spec.ResticIdentifier,
+			Message:          repo.Status.Message,
+		})
+	}
+
+	return locks, nil
+}
+
+func isResticRepositoryLocked(message string) bool {
+	return strings.Contains(message, "repository is already locked")
+}
+
+const (
+	// resticCredentialsSecret is the secret velero keeps the restic repository password in, the file velero mounts
+	// it from changed between releases so it's read from the secret instead
+	resticCredentialsSecret      = "velero-restic-credentials"
+	resticCredentialsPasswordKey = "repository-password"
+)
+
+// UnlockResticRepository removes stale locks from the restic repository by running restic unlock in the velero
+// pod, which already has the store credentials. The repository password is read from velero's restic credentials.
+func UnlockResticRepository(ctx context.Context, name string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return errors.New("velero not found")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	repo, err := veleroClient.ResticRepositories(veleroNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get resticrepository")
+	}
+
+	veleroPod, err := findRunningVeleroPod(ctx, clientset, veleroNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to find velero pod")
+	}
+
+	password, err := getResticRepositoryPassword(ctx, clientset, veleroNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to get restic repository password")
+	}
+
+	exitCode, _, stderr, err := kurl.SyncExec(clientset.CoreV1(), cfg, veleroNamespace, veleroPod.Name, "velero",
+		"env", fmt.Sprintf("RESTIC_PASSWORD=%s", password), "restic", "unlock", fmt.Sprintf("--repo=%s", repo.Spec.ResticIdentifier))
+	if err != nil {
+		return errors.Wrap(err, "failed to exec restic unlock")
+	}
+	if exitCode != 0 {
+		return errors.Errorf("restic unlock exited with code %d: %s", exitCode, stderr)
+	}
+
+	return nil
+}
+
+func getResticRepositoryPassword(ctx context.Context, clientset *kubernetes.Clientset, veleroNamespace string) (string, error) {
+	secret, err := clientset.CoreV1().Secrets(veleroNamespace).Get(ctx, resticCredentialsSecret, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get secret %s", resticCredentialsSecret)
+	}
+
+	password, ok := secret.Data[resticCredentialsPasswordKey]
+	if !ok || len(password) == 0 {
+		return "", errors.Errorf("secret %s has no %s", resticCredentialsSecret, resticCredentialsPasswordKey)
+	}
+
+	return string(password), nil
+}
+
+func findRunningVeleroPod(ctx context.Context, clientset *kubernetes.Clientset, namespace string) (*corev1.Pod, error) {
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(veleroDeployment.Spec.Selector.MatchLabels).String(),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list pods in velero deployment")
+		}
+
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == corev1.PodRunning {
+				return &pod, nil
+			}
+		}
+	}
+
+	return nil, errors.New("no running velero pod")
+}
+
+// GetSnapshotDiagnostics runs checks against the snapshot setup that explain failures velero only reports as errors deep in its logs
+func GetSnapshotDiagnostics(ctx context.Context) []types.SnapshotDiagnostic {
+	diagnostics := []types.SnapshotDiagnostic{}
+
+	resticRepositories := types.SnapshotDiagnostic{
+		Name:   "resticRepositories",
+		Title:  "Restic repositories can be opened",
+		Passed: true,
+	}
+	if err := ValidateResticRepositories(ctx); err != nil {
+		resticRepositories.Passed = false
+		if _, ok := errors.Cause(err).(ResticPasswordMismatchError); ok {
+			resticRepositories.Message = fmt.Sprintf("%s. The repositories were likely created by another installation using the same store.", err.Error())
+		} else {
+			resticRepositories.Message = err.Error()
+		}
+	}
+	diagnostics = append(diagnostics, resticRepositories)
+
+	resticLocks := types.SnapshotDiagnostic{
+		Name:   "resticLocks",
+		Title:  "Restic repositories are not locked",
+		Passed: true,
+	}
+	locks, err := CheckResticLocks(ctx)
+	if err != nil {
+		resticLocks.Passed = false
+		resticLocks.Message = err.Error()
+	} else if len(locks) > 0 {
+		lockedNamespaces := []string{}
+		for _, lock := range locks {
+			lockedNamespaces = append(lockedNamespaces, lock.VolumeNamespace)
+		}
+		resticLocks.Passed = false
+		resticLocks.Message = fmt.Sprintf("restic repositories for %s are locked", strings.Join(lockedNamespaces, ", "))
+	}
+	diagnostics = append(diagnostics, resticLocks)
+
+	namespaceCoverage := types.SnapshotDiagnostic{
+		Name:   "instanceNamespaceCoverage",
+		Title:  "Instance backups include every app namespace",
+		Passed: true,
+	}
+	coverage, err := CheckInstanceBackupNamespaceCoverage(ctx)
+	if IsNoInstanceBackupError(err) {
+		namespaceCoverage.Message = "not applicable, instance backups are not taken"
+	} else if err != nil {
+		namespaceCoverage.Passed = false
+		namespaceCoverage.Message = err.Error()
+	} else if len(coverage.UncoveredNamespaces) > 0 {
+		uncovered := []string{}
+		for _, namespace := range coverage.UncoveredNamespaces {
+			uncovered = append(uncovered, fmt.Sprintf("%s (%s)", namespace.Namespace, strings.Join(namespace.Apps, ", ")))
+		}
+		namespaceCoverage.Passed = false
+		namespaceCoverage.Message = fmt.Sprintf("the next instance backup will not include %s", strings.Join(uncovered, "; "))
+	}
+	diagnostics = append(diagnostics, namespaceCoverage)
+
+	return diagnostics
+}
@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// resticHostPodsVolumeName is the restic daemonset volume velero mounts the kubelet pods directory with
+	resticHostPodsVolumeName = "host-pods"
+	// DefaultResticHostPodsPath is where kubelet keeps pod volumes unless its --root-dir is changed.
+	// OpenShift uses the default path too, but needs restic to run privileged to read it.
+	DefaultResticHostPodsPath = "/var/lib/kubelet/pods"
+)
+
+// ValidateResticHostPodsPath checks that the kubelet pods directory is a clean absolute path
+func ValidateResticHostPodsPath(path string) error {
+	if !filepath.IsAbs(path) {
+		return errors.Errorf("host pods path %q must be absolute", path)
+	}
+	if filepath.Clean(path) != path {
+		return errors.Errorf("host pods path %q must be a clean path", path)
+	}
+	if path == "/" {
+		return errors.New("host pods path must not be the root directory")
+	}
+	return nil
+}
+
+// SetResticHostPodsPath points the restic daemonset's pods volume at the kubelet pods directory on the nodes.
+// Restic can't find pod volumes on clusters where kubelet runs with a different --root-dir, and the volumes are
+// silently left out of backups. An empty path restores the default.
+func SetResticHostPodsPath(path string) error {
+	if path == "" {
+		path = DefaultResticHostPodsPath
+	}
+	if err := ValidateResticHostPodsPath(path); err != nil {
+		return err
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	resticDaemonsets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list restic daemonsets")
+	}
+
+	isOpenShift := k8sutil.IsOpenShift(clientset)
+
+	for _, resticDaemonset := range resticDaemonsets {
+		podSpec := &resticDaemonset.Spec.Template.Spec
+		changed, err := setResticHostPodsPath(podSpec, path)
+		if err != nil {
+			return errors.Wrapf(err, "failed to set host pods path of restic daemonset %s", resticDaemonset.Name)
+		}
+		if isOpenShift && setResticPrivileged(podSpec) {
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+
+		if _, err := clientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), &resticDaemonset, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update restic daemonset %s", resticDaemonset.Name)
+		}
+	}
+
+	return nil
+}
+
+func getResticHostPodsPath(podSpec corev1.PodSpec) string {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == resticHostPodsVolumeName && volume.HostPath != nil {
+			return volume.HostPath.Path
+		}
+	}
+	return ""
+}
+
+// setResticHostPodsPath returns true if the pod spec was changed
+func setResticHostPodsPath(podSpec *corev1.PodSpec, path string) (bool, error) {
+	for i, volume := range podSpec.Volumes {
+		if volume.Name != resticHostPodsVolumeName {
+			continue
+		}
+		if volume.HostPath == nil {
+			return false, errors.Errorf("volume %s is not a host path", resticHostPodsVolumeName)
+		}
+		if volume.HostPath.Path == path {
+			return false, nil
+		}
+		podSpec.Volumes[i].HostPath.Path = path
+		return true, nil
+	}
+	return false, errors.Errorf("volume %s not found", resticHostPodsVolumeName)
+}
+
+// setResticPrivileged lets restic read other pods' volumes under the selinux labels openshift uses.
+// It returns true if the pod spec was changed.
+func setResticPrivileged(podSpec *corev1.PodSpec) bool {
+	if len(podSpec.Containers) == 0 {
+		return false
+	}
+	container := &podSpec.Containers[0]
+	if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+		return false
+	}
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	privileged := true
+	container.SecurityContext.Privileged = &privileged
+	return true
+}
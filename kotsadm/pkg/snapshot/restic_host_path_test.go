@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestValidateResticHostPodsPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantErr bool
+	}{
+		{"/var/lib/kubelet/pods", false},
+		{"/var/lib/k0s/kubelet/pods", false},
+		{"var/lib/kubelet/pods", true},
+		{"/var/lib/kubelet/pods/", true},
+		{"/var/lib/../kubelet/pods", true},
+		{"/", true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			err := ValidateResticHostPodsPath(test.path)
+			if (err != nil) != test.wantErr {
+				t.Errorf("Expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSetResticHostPodsPath(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			{Name: resticHostPodsVolumeName, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: DefaultResticHostPodsPath}}},
+		},
+	}
+
+	changed, err := setResticHostPodsPath(&podSpec, DefaultResticHostPodsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Errorf("Expected no change for the same path")
+	}
+
+	changed, err = setResticHostPodsPath(&podSpec, "/var/lib/k0s/kubelet/pods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Errorf("Expected a change for a new path")
+	}
+	if got := getResticHostPodsPath(podSpec); got != "/var/lib/k0s/kubelet/pods" {
+		t.Errorf("Expected /var/lib/k0s/kubelet/pods, got %s", got)
+	}
+
+	if _, err := setResticHostPodsPath(&corev1.PodSpec{}, DefaultResticHostPodsPath); err == nil {
+		t.Errorf("Expected an error without the host pods volume")
+	}
+}
@@ -0,0 +1,169 @@
+package snapshot
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	// resticVolumesAnnotation is the pod annotation restic backs up volumes from when backups don't default all
+	// pod volumes to restic
+	resticVolumesAnnotation = "backup.velero.io/backup-volumes"
+	// includedVolumesAnnotation lists the volumes kots added to the pod's restic volumes for the restic pod selector
+	includedVolumesAnnotation = "kots.io/restic-included-volumes"
+)
+
+// ValidateResticPodSelector checks that the selector is a valid label selector, e.g. "tier=data"
+func ValidateResticPodSelector(selector string) error {
+	if selector == "" {
+		return nil
+	}
+	if _, err := labels.Parse(selector); err != nil {
+		return errors.Wrap(err, "invalid label selector")
+	}
+	return nil
+}
+
+// applyResticPodSelector limits restic to the volumes of the pods matching the selector. Velero can only select
+// pods for restic by annotation, so the backup stops defaulting all volumes to restic and the matching pods'
+// volumes are opted in instead. Pods that are recreated lose the annotation, so it's added again before each backup.
+// The volumes of pods that stop matching, or of all pods once the selector is cleared, are taken out again.
+func applyResticPodSelector(ctx context.Context, veleroBackup *velerov1.Backup, selector string, excludedPVCs []string) error {
+	podSelector := labels.Nothing()
+	if selector != "" {
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse restic pod selector")
+		}
+		podSelector = parsed
+
+		falseVal := false
+		veleroBackup.Spec.DefaultVolumesToRestic = &falseVal
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	for _, namespace := range veleroBackup.Spec.IncludedNamespaces {
+		if namespace == "" || namespace == "*" {
+			continue
+		}
+
+		// all pods are listed so that the ones that stopped matching can be reverted
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to list pods in namespace %s", namespace)
+		}
+
+		for _, pod := range pods.Items {
+			volumes := []string{}
+			if podSelector.Matches(labels.Set(pod.Labels)) {
+				volumes = podResticVolumes(pod, excludedPVCs)
+			}
+
+			annotations, changed := includeVolumeAnnotations(pod.Annotations, volumes)
+			if !changed {
+				continue
+			}
+
+			pod.Annotations = annotations
+			if _, err := clientset.CoreV1().Pods(namespace).Update(ctx, &pod, metav1.UpdateOptions{}); err != nil {
+				return errors.Wrapf(err, "failed to update pod %s in namespace %s", pod.Name, namespace)
+			}
+			logger.Infof("Included volumes [%s] of pod %s in namespace %s in restic backup", strings.Join(volumes, ","), pod.Name, namespace)
+		}
+	}
+
+	return nil
+}
+
+// includeVolumeAnnotations returns the pod annotations with the volumes added to the restic volumes. Volumes kots
+// included before that aren't in the list anymore are taken out, volumes that were already there are left alone.
+// It returns true if the annotations changed.
+func includeVolumeAnnotations(annotations map[string]string, volumes []string) (map[string]string, bool) {
+	include := map[string]bool{}
+	for _, volume := range volumes {
+		include[volume] = true
+	}
+
+	updated := map[string]string{}
+	for k, v := range annotations {
+		updated[k] = v
+	}
+
+	includes := []string{}
+	includedByKots := []string{}
+	previouslyIncluded := volumeNameSet(annotations[includedVolumesAnnotation])
+	present := map[string]bool{}
+	for _, volume := range splitVolumeNames(annotations[resticVolumesAnnotation]) {
+		if previouslyIncluded[volume] && !include[volume] {
+			continue
+		}
+		if previouslyIncluded[volume] {
+			includedByKots = append(includedByKots, volume)
+		}
+		present[volume] = true
+		includes = append(includes, volume)
+	}
+	for _, volume := range volumes {
+		if !present[volume] {
+			includes = append(includes, volume)
+			includedByKots = append(includedByKots, volume)
+			present[volume] = true
+		}
+	}
+	// volumes of excluded pvcs are taken out by the pvc exclusion and put back once they're no longer excluded,
+	// they stay tracked so that they can still be taken out then
+	removedByExclusion := volumeNameSet(annotations[removedVolumesAnnotation])
+	for _, volume := range splitVolumeNames(annotations[includedVolumesAnnotation]) {
+		if !present[volume] && removedByExclusion[volume] {
+			includedByKots = append(includedByKots, volume)
+		}
+	}
+
+	setVolumeNames(updated, resticVolumesAnnotation, includes)
+	setVolumeNames(updated, includedVolumesAnnotation, includedByKots)
+
+	if len(updated) == 0 && len(annotations) == 0 {
+		return annotations, false
+	}
+	return updated, !reflect.DeepEqual(updated, annotations)
+}
+
+// podResticVolumes returns the names of the pod's volumes restic would back up by default, the same volumes velero
+// picks when defaulting to restic, without the excluded pvcs
+func podResticVolumes(pod corev1.Pod, excludedPVCs []string) []string {
+	excluded := map[string]bool{}
+	for _, volume := range podVolumesToExclude(pod, excludedPVCs) {
+		excluded[volume] = true
+	}
+
+	volumes := []string{}
+	for _, volume := range pod.Spec.Volumes {
+		if excluded[volume.Name] {
+			continue
+		}
+		if volume.HostPath != nil || volume.Secret != nil || volume.ConfigMap != nil || volume.Projected != nil || volume.DownwardAPI != nil {
+			continue
+		}
+		volumes = append(volumes, volume.Name)
+	}
+	return volumes
+}
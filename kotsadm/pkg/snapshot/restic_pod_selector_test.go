@@ -0,0 +1,176 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodResticVolumes(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "postgres-0",
+			Namespace: "app",
+		},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-postgres-0"}}},
+				{Name: "cache", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache-postgres-0"}}},
+				{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+				{Name: "creds", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "creds"}}},
+				{Name: "token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{}}},
+				{Name: "labels", VolumeSource: corev1.VolumeSource{DownwardAPI: &corev1.DownwardAPIVolumeSource{}}},
+				{Name: "logs", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		excludedPVCs []string
+		want         []string
+	}{
+		{
+			name: "no exclusions",
+			want: []string{"data", "cache", "scratch"},
+		},
+		{
+			name:         "excluded pvc",
+			excludedPVCs: []string{"app/cache-postgres-0"},
+			want:         []string{"data", "scratch"},
+		},
+		{
+			name:         "excluded pvc in another namespace",
+			excludedPVCs: []string{"other/cache-postgres-0"},
+			want:         []string{"data", "cache", "scratch"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := podResticVolumes(pod, test.excludedPVCs); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("podResticVolumes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateResticPodSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		wantErr  bool
+	}{
+		{selector: ""},
+		{selector: "tier=data"},
+		{selector: "app in (postgres,redis),tier!=frontend"},
+		{selector: "tier in (data", wantErr: true},
+		{selector: "bad key=data", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.selector, func(t *testing.T) {
+			err := ValidateResticPodSelector(test.selector)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateResticPodSelector(%q) error = %v, wantErr %v", test.selector, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestIncludeVolumeAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		volumes     []string
+		want        map[string]string
+		wantChanged bool
+	}{
+		{
+			name:        "no annotations",
+			annotations: nil,
+			volumes:     []string{"data"},
+			want: map[string]string{
+				resticVolumesAnnotation:   "data",
+				includedVolumesAnnotation: "data",
+			},
+			wantChanged: true,
+		},
+		{
+			name:        "keeps volumes that were already included",
+			annotations: map[string]string{resticVolumesAnnotation: "registry"},
+			volumes:     []string{"registry", "data"},
+			want: map[string]string{
+				resticVolumesAnnotation:   "registry,data",
+				includedVolumesAnnotation: "data",
+			},
+			wantChanged: true,
+		},
+		{
+			name: "unchanged",
+			annotations: map[string]string{
+				resticVolumesAnnotation:   "registry,data",
+				includedVolumesAnnotation: "data",
+			},
+			volumes:     []string{"registry", "data"},
+			wantChanged: false,
+		},
+		{
+			name: "pod stopped matching",
+			annotations: map[string]string{
+				resticVolumesAnnotation:   "registry,data",
+				includedVolumesAnnotation: "data",
+				"other":                   "value",
+			},
+			volumes: []string{},
+			want: map[string]string{
+				resticVolumesAnnotation: "registry",
+				"other":                 "value",
+			},
+			wantChanged: true,
+		},
+		{
+			name: "selector cleared",
+			annotations: map[string]string{
+				resticVolumesAnnotation:   "data,cache",
+				includedVolumesAnnotation: "data,cache",
+			},
+			volumes:     nil,
+			want:        map[string]string{},
+			wantChanged: true,
+		},
+		{
+			name: "volume removed by a pvc exclusion",
+			annotations: map[string]string{
+				resticVolumesAnnotation:   "data",
+				includedVolumesAnnotation: "data,cache",
+				removedVolumesAnnotation:  "cache",
+			},
+			volumes:     []string{"data"},
+			wantChanged: false,
+		},
+		{
+			name:        "never matched",
+			annotations: nil,
+			volumes:     nil,
+			wantChanged: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, changed := includeVolumeAnnotations(test.annotations, test.volumes)
+			if changed != test.wantChanged {
+				t.Errorf("includeVolumeAnnotations() changed = %v, want %v", changed, test.wantChanged)
+			}
+			if !changed {
+				return
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("includeVolumeAnnotations() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
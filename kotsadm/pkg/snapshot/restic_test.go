@@ -0,0 +1,37 @@
+package snapshot
+
+import "testing"
+
+func TestIsResticPasswordMismatch(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"", false},
+		{"error running command=restic snapshots --repo=s3:http://minio/velero/restic/default --password-file=/tmp/credentials/velero/velero-restic-credentials-repository-password --cache-dir=/scratch/.cache/restic --last, stdout=, stderr=Fatal: wrong password or no key found\n: exit status 1", true},
+		{"error running command=restic init, stderr=Fatal: create repository at s3:http://minio/velero failed: config file already exists", false},
+	}
+	for _, test := range tests {
+		got := isResticPasswordMismatch(test.message)
+		if got != test.want {
+			t.Errorf("Expected %v for %q, got %v", test.want, test.message, got)
+		}
+	}
+}
+
+func TestIsResticRepositoryLocked(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"", false},
+		{"error running command=restic prune --repo=s3:http://minio/velero/restic/default, stdout=, stderr=unable to create lock in backend: repository is already locked exclusively by PID 1041 on velero-7d8f9c6b5-x2x7v by root (UID 0, GID 0)\nlock was created at 2020-10-01 02:00:04 (3h12m7s ago)\n: exit status 1", true},
+		{"error running command=restic snapshots, stderr=Fatal: wrong password or no key found\n: exit status 1", false},
+	}
+	for _, test := range tests {
+		got := isResticRepositoryLocked(test.message)
+		if got != test.want {
+			t.Errorf("Expected %v for %q, got %v", test.want, test.message, got)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// CanResticBackupVolume returns true if restic can back up the persistent volume, and the reason when it can't.
+// Restic reads volumes through the kubelet's pod volume directories, which hostPath and raw block volumes aren't
+// mounted under.
+func CanResticBackupVolume(pv *corev1.PersistentVolume) (bool, string) {
+	if pv.Spec.HostPath != nil {
+		return false, "hostPath volumes are not supported by restic"
+	}
+	if pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == corev1.PersistentVolumeBlock {
+		return false, "block volumes are not supported by restic"
+	}
+	return true, ""
+}
+
+// resticVolumeSettings are the app and velero settings that decide which pod volumes restic backs up
+type resticVolumeSettings struct {
+	defaultVolumesToRestic bool
+	// podSelector opts in the volumes of matching pods, it's nil when the app doesn't select pods
+	podSelector  labels.Selector
+	excludedPVCs []string
+}
+
+// CheckAppResticVolumes reports which of the app's persistent volume claims restic will back up and why the
+// others are skipped
+func CheckAppResticVolumes(ctx context.Context, appSlug string, veleroDefaultVolumesToRestic bool) ([]types.ResticVolume, error) {
+	a, err := store.GetStore().GetAppFromSlug(appSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get app from slug")
+	}
+
+	settings := resticVolumeSettings{
+		defaultVolumesToRestic: veleroDefaultVolumesToRestic,
+		excludedPVCs:           a.SnapshotExcludedPVCs,
+	}
+	if a.SnapshotDefaultVolumesToRestic != nil {
+		settings.defaultVolumesToRestic = *a.SnapshotDefaultVolumesToRestic
+	}
+	if a.SnapshotResticPodSelector != "" {
+		podSelector, err := labels.Parse(a.SnapshotResticPodSelector)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse restic pod selector")
+		}
+		settings.defaultVolumesToRestic = false
+		settings.podSelector = podSelector
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	// app backups only include resources labeled with the app slug, so restic only sees those pods
+	listOptions := metav1.ListOptions{LabelSelector: fmt.Sprintf("kots.io/app-slug=%s", a.Slug)}
+
+	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list persistent volume claims")
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, listOptions)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pods")
+	}
+
+	pvs, err := clientset.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list persistent volumes")
+	}
+	pvsByName := map[string]corev1.PersistentVolume{}
+	for _, pv := range pvs.Items {
+		pvsByName[pv.Name] = pv
+	}
+
+	return getResticVolumes(pvcs.Items, pods.Items, pvsByName, settings), nil
+}
+
+func getResticVolumes(pvcs []corev1.PersistentVolumeClaim, pods []corev1.Pod, pvs map[string]corev1.PersistentVolume, settings resticVolumeSettings) []types.ResticVolume {
+	resticVolumes := []types.ResticVolume{}
+	for _, pvc := range pvcs {
+		resticVolume := types.ResticVolume{
+			Namespace: pvc.Namespace,
+			PVC:       pvc.Name,
+			Volume:    pvc.Spec.VolumeName,
+		}
+		resticVolume.Included, resticVolume.Reason = canResticBackupPVC(pvc, pods, pvs, settings)
+		resticVolumes = append(resticVolumes, resticVolume)
+	}
+
+	sort.Slice(resticVolumes, func(i, j int) bool {
+		if resticVolumes[i].Namespace != resticVolumes[j].Namespace {
+			return resticVolumes[i].Namespace < resticVolumes[j].Namespace
+		}
+		return resticVolumes[i].PVC < resticVolumes[j].PVC
+	})
+
+	return resticVolumes
+}
+
+// canResticBackupPVC returns true if restic backs up the pvc's volume through at least one running pod mounting it,
+// and the reason when it doesn't
+func canResticBackupPVC(pvc corev1.PersistentVolumeClaim, pods []corev1.Pod, pvs map[string]corev1.PersistentVolume, settings resticVolumeSettings) (bool, string) {
+	if pvc.Spec.VolumeName == "" {
+		return false, "the claim is not bound to a volume"
+	}
+	pv, ok := pvs[pvc.Spec.VolumeName]
+	if !ok {
+		return false, fmt.Sprintf("volume %s was not found", pvc.Spec.VolumeName)
+	}
+	if ok, reason := CanResticBackupVolume(&pv); !ok {
+		return false, reason
+	}
+
+	for _, excludedPVC := range settings.excludedPVCs {
+		namespace, name := splitExcludedPVC(excludedPVC)
+		if (namespace == "" || namespace == pvc.Namespace) && name == pvc.Name {
+			return false, "the claim is excluded in the app's snapshot settings"
+		}
+	}
+
+	reason := "the claim is not mounted by a running pod, restic only backs up the volumes of running pods"
+	for _, pod := range pods {
+		if pod.Namespace != pvc.Namespace || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != pvc.Name {
+				continue
+			}
+			if annotationListsVolume(pod.Annotations[resticVolumesExcludesAnnotation], volume.Name) {
+				reason = fmt.Sprintf("volume %s of pod %s is excluded by the %s annotation", volume.Name, pod.Name, resticVolumesExcludesAnnotation)
+				continue
+			}
+			if settings.defaultVolumesToRestic || annotationListsVolume(pod.Annotations[resticVolumesAnnotation], volume.Name) {
+				return true, ""
+			}
+			if settings.podSelector != nil && settings.podSelector.Matches(labels.Set(pod.Labels)) {
+				return true, ""
+			}
+			reason = fmt.Sprintf("volume %s of pod %s is not opted in to restic with the %s annotation", volume.Name, pod.Name, resticVolumesAnnotation)
+		}
+	}
+
+	return false, reason
+}
+
+// annotationListsVolume returns true if the comma separated volumes annotation value contains the volume
+func annotationListsVolume(value string, volume string) bool {
+	for _, v := range strings.Split(value, ",") {
+		if strings.TrimSpace(v) == volume {
+			return true
+		}
+	}
+	return false
+}
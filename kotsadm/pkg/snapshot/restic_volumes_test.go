@@ -0,0 +1,203 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestCanResticBackupVolume(t *testing.T) {
+	block := corev1.PersistentVolumeBlock
+	filesystem := corev1.PersistentVolumeFilesystem
+
+	tests := []struct {
+		name   string
+		pv     corev1.PersistentVolume
+		wantOK bool
+	}{
+		{
+			name: "csi filesystem volume",
+			pv: corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{
+					VolumeMode: &filesystem,
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com"},
+					},
+				},
+			},
+			wantOK: true,
+		},
+		{
+			name: "host path volume",
+			pv: corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/data"},
+					},
+				},
+			},
+			wantOK: false,
+		},
+		{
+			name: "block volume",
+			pv: corev1.PersistentVolume{
+				Spec: corev1.PersistentVolumeSpec{
+					VolumeMode: &block,
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com"},
+					},
+				},
+			},
+			wantOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ok, reason := CanResticBackupVolume(&test.pv)
+			if ok != test.wantOK {
+				t.Errorf("CanResticBackupVolume() = %v, want %v", ok, test.wantOK)
+			}
+			if !ok && reason == "" {
+				t.Errorf("CanResticBackupVolume() returned no reason")
+			}
+		})
+	}
+}
+
+func TestGetResticVolumes(t *testing.T) {
+	pvc := func(name string, volumeName string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+	}
+	pod := func(name string, claimName string, podLabels map[string]string, annotations map[string]string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: podLabels, Annotations: annotations},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{
+					{
+						Name: "data",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	pvs := map[string]corev1.PersistentVolume{
+		"pv-db":       {ObjectMeta: metav1.ObjectMeta{Name: "pv-db"}},
+		"pv-cache":    {ObjectMeta: metav1.ObjectMeta{Name: "pv-cache"}},
+		"pv-unused":   {ObjectMeta: metav1.ObjectMeta{Name: "pv-unused"}},
+		"pv-excluded": {ObjectMeta: metav1.ObjectMeta{Name: "pv-excluded"}},
+		"pv-host": {
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-host"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/data"},
+				},
+			},
+		},
+	}
+
+	pvcs := []corev1.PersistentVolumeClaim{
+		pvc("db", "pv-db"),
+		pvc("cache", "pv-cache"),
+		pvc("host", "pv-host"),
+		pvc("pending", ""),
+		pvc("unused", "pv-unused"),
+		pvc("excluded", "pv-excluded"),
+	}
+
+	pods := []corev1.Pod{
+		pod("db-0", "db", map[string]string{"tier": "data"}, nil),
+		pod("cache-0", "cache", map[string]string{"tier": "cache"}, map[string]string{resticVolumesExcludesAnnotation: "data"}),
+		pod("host-0", "host", nil, nil),
+		pod("excluded-0", "excluded", map[string]string{"tier": "data"}, nil),
+	}
+
+	tests := []struct {
+		name     string
+		settings resticVolumeSettings
+		want     map[string]bool
+	}{
+		{
+			name:     "default volumes to restic",
+			settings: resticVolumeSettings{defaultVolumesToRestic: true, excludedPVCs: []string{"default/excluded"}},
+			want: map[string]bool{
+				"db":       true,
+				"cache":    false,
+				"host":     false,
+				"pending":  false,
+				"unused":   false,
+				"excluded": false,
+			},
+		},
+		{
+			name:     "opt in",
+			settings: resticVolumeSettings{},
+			want: map[string]bool{
+				"db":       false,
+				"cache":    false,
+				"host":     false,
+				"pending":  false,
+				"unused":   false,
+				"excluded": false,
+			},
+		},
+		{
+			name:     "pod selector",
+			settings: resticVolumeSettings{podSelector: labels.SelectorFromSet(labels.Set{"tier": "data"})},
+			want: map[string]bool{
+				"db":       true,
+				"cache":    false,
+				"host":     false,
+				"pending":  false,
+				"unused":   false,
+				"excluded": true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := map[string]bool{}
+			for _, volume := range getResticVolumes(pvcs, pods, pvs, test.settings) {
+				got[volume.PVC] = volume.Included
+				if !volume.Included && volume.Reason == "" {
+					t.Errorf("pvc %s is skipped without a reason", volume.PVC)
+				}
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getResticVolumes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetResticVolumesSorted(t *testing.T) {
+	pvcs := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "ns-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "ns-1"}},
+	}
+
+	want := []types.ResticVolume{
+		{Namespace: "ns-1", PVC: "a", Reason: "the claim is not bound to a volume"},
+		{Namespace: "ns-1", PVC: "b", Reason: "the claim is not bound to a volume"},
+		{Namespace: "ns-2", PVC: "a", Reason: "the claim is not bound to a volume"},
+	}
+
+	got := getResticVolumes(pvcs, nil, nil, resticVolumeSettings{})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getResticVolumes() = %+v, want %+v", got, want)
+	}
+}
@@ -82,7 +82,7 @@ func CreateApplicationRestore(snapshotName string, appSlug string) error {
 	restore := &velerov1.Restore{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: veleroNamespace,
-			Name:      snapshotName, // restore name same as snapshot name
+			Name:      applicationRestoreName(backup, appSlug),
 		},
 		Spec: velerov1.RestoreSpec{
 			BackupName:              snapshotName,
@@ -93,7 +93,6 @@ func CreateApplicationRestore(snapshotName string, appSlug string) error {
 
 	if backup.Annotations["kots.io/instance"] == "true" {
 		// only restore app-specific objects
-		restore.ObjectMeta.Name = fmt.Sprintf("%s.%s", snapshotName, appSlug)
 		restore.ObjectMeta.Annotations = map[string]string{
 			"kots.io/instance": "true",
 		}
@@ -104,6 +103,11 @@ func CreateApplicationRestore(snapshotName string, appSlug string) error {
 		}
 	}
 
+	// another restore's helper image override would apply to this one too, it's retried once that one is done
+	if err := CheckRestoreHelperOverride(context.TODO(), restore.Name); err != nil {
+		return errors.Wrap(err, "failed to check restore helper override")
+	}
+
 	_, err = veleroClient.Restores(veleroNamespace).Create(context.TODO(), restore, metav1.CreateOptions{})
 	if err != nil {
 		return errors.Wrap(err, "failed to create restore")
@@ -112,6 +116,24 @@ func CreateApplicationRestore(snapshotName string, appSlug string) error {
 	return nil
 }
 
+// GetApplicationRestoreName returns the name of the restore CreateApplicationRestore creates for the app
+func GetApplicationRestoreName(snapshotName string, appSlug string) (string, error) {
+	backup, err := GetBackup(snapshotName)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get backup")
+	}
+	return applicationRestoreName(backup, appSlug), nil
+}
+
+// applicationRestoreName is the name of the backup, with the app slug appended for instance backups since each
+// app is restored from them separately
+func applicationRestoreName(backup *velerov1.Backup, appSlug string) string {
+	if backup.Annotations["kots.io/instance"] == "true" {
+		return fmt.Sprintf("%s.%s", backup.Name, appSlug)
+	}
+	return backup.Name
+}
+
 func DeleteRestore(snapshotName string) error {
 	bsl, err := FindBackupStoreLocation()
 	if err != nil {
@@ -230,3 +252,98 @@ func listRestoreVolumes(restoreVolumes []velerov1.PodVolumeRestore) []types.Rest
 	}
 	return volumes
 }
+
+const (
+	// used when there are no completed volume restores to measure throughput from
+	defaultRestoreBytesPerSecond = 10 * 1024 * 1024
+	// rough time it takes velero to restore a single kubernetes resource
+	restoreSecondsPerResource = 0.05
+)
+
+// EstimateRestoreTime returns a coarse estimate of how long restoring the given backup will take,
+// based on the backup's volume sizes and the restic throughput of previous volume restores
+func EstimateRestoreTime(ctx context.Context, backupName string) (*types.RestoreEstimate, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backendStorageLocation, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	veleroNamespace := backendStorageLocation.Namespace
+
+	backup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup")
+	}
+
+	backupVolumes, err := veleroClient.PodVolumeBackups(veleroNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("velero.io/backup-name=%s", velerolabel.GetValidName(backupName)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	restoreVolumes, err := veleroClient.PodVolumeRestores(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list restore volumes")
+	}
+
+	estimate := &types.RestoreEstimate{
+		BackupName:     backup.Name,
+		VolumeCount:    len(backupVolumes.Items),
+		BytesPerSecond: defaultRestoreBytesPerSecond,
+	}
+	if backup.Status.Progress != nil {
+		estimate.ResourceCount = backup.Status.Progress.ItemsBackedUp
+	}
+
+	for _, backupVolume := range backupVolumes.Items {
+		estimate.VolumeSizeBytes += backupVolume.Status.Progress.TotalBytes
+	}
+	estimate.VolumeSizeHuman = units.HumanSize(float64(estimate.VolumeSizeBytes))
+
+	bytesPerSecond := restoreThroughput(restoreVolumes.Items)
+	if bytesPerSecond > 0 {
+		estimate.BytesPerSecond = bytesPerSecond
+		estimate.FromHistory = true
+	}
+
+	seconds := float64(estimate.VolumeSizeBytes)/float64(estimate.BytesPerSecond) + float64(estimate.ResourceCount)*restoreSecondsPerResource
+	estimate.EstimatedSeconds = int(math.Ceil(seconds))
+
+	return estimate, nil
+}
+
+// restoreThroughput returns the average bytes per second of all completed volume restores, or 0 if there are none
+func restoreThroughput(restoreVolumes []velerov1.PodVolumeRestore) int64 {
+	totalBytes := int64(0)
+	totalSeconds := float64(0)
+	for _, restoreVolume := range restoreVolumes {
+		if restoreVolume.Status.Phase != velerov1.PodVolumeRestorePhaseCompleted {
+			continue
+		}
+		if restoreVolume.Status.StartTimestamp == nil || restoreVolume.Status.CompletionTimestamp == nil {
+			continue
+		}
+		duration := restoreVolume.Status.CompletionTimestamp.Sub(restoreVolume.Status.StartTimestamp.Time).Seconds()
+		if duration <= 0 {
+			continue
+		}
+		totalBytes += restoreVolume.Status.Progress.TotalBytes
+		totalSeconds += duration
+	}
+
+	if totalSeconds == 0 {
+		return 0
+	}
+	return int64(float64(totalBytes) / totalSeconds)
+}
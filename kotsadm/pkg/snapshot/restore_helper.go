@@ -0,0 +1,244 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/registry"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	resticRestoreActionConfigMapName = "restic-restore-action-config"
+	// restoreHelperOverrideAnnotation holds the restore the restore helper image was overridden for
+	restoreHelperOverrideAnnotation = "kots.io/restore-helper-override"
+	// restoreHelperOriginalAnnotation holds the image to revert to, or is missing if the image wasn't set
+	restoreHelperOriginalAnnotation = "kots.io/restore-helper-original-image"
+	// restoreHelperCreatedAnnotation marks a config map kots created for the override and deletes on revert
+	restoreHelperCreatedAnnotation = "kots.io/restore-helper-created"
+)
+
+// RestoreHelperOverrideInUseError is returned when the restore helper image is overridden for another restore that
+// hasn't finished. Velero reads the image from a single plugin config, so only one restore can override it at a time.
+type RestoreHelperOverrideInUseError struct {
+	RestoreName string
+}
+
+func (e RestoreHelperOverrideInUseError) Error() string {
+	return fmt.Sprintf("restore helper image is overridden for restore %s", e.RestoreName)
+}
+
+// IsRestoreHelperOverrideInUseError returns true if the cause of the error is a RestoreHelperOverrideInUseError
+func IsRestoreHelperOverrideInUseError(err error) bool {
+	_, ok := errors.Cause(err).(RestoreHelperOverrideInUseError)
+	return ok
+}
+
+// OverrideRestoreHelperImage sets the restic restore helper image in velero's plugin config for a restore,
+// rewritten to the registry kotsadm uses. RevertRestoreHelperImage puts back the original image once the restore
+// is done. A RestoreHelperOverrideInUseError is returned if another restore still has it overridden.
+func OverrideRestoreHelperImage(ctx context.Context, restoreName string, image string) error {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return errors.Wrapf(err, "invalid image %q", image)
+	}
+
+	registrySettings, err := registry.GetKotsadmRegistry()
+	if err != nil {
+		return errors.Wrap(err, "failed to get kotsadm registry")
+	}
+	image = registry.RewriteImage(registrySettings, image)
+
+	clientset, veleroNamespace, err := getRestoreHelperClient()
+	if err != nil {
+		return err
+	}
+
+	configMap, err := findResticRestoreActionConfigMap(ctx, clientset, veleroNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to find restic restore action config")
+	}
+
+	if err := checkRestoreHelperOverride(ctx, veleroNamespace, configMap, restoreName); err != nil {
+		return err
+	}
+
+	if configMap == nil {
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      resticRestoreActionConfigMapName,
+				Namespace: veleroNamespace,
+				Labels: map[string]string{
+					"velero.io/plugin-config": "",
+					"velero.io/restic":        "RestoreItemAction",
+				},
+				Annotations: map[string]string{
+					restoreHelperOverrideAnnotation: restoreName,
+					restoreHelperCreatedAnnotation:  "true",
+				},
+			},
+			Data: map[string]string{
+				"image": image,
+			},
+		}
+		if _, err := clientset.CoreV1().ConfigMaps(veleroNamespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(err, "failed to create restic restore action config")
+		}
+		return nil
+	}
+
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	if _, ok := configMap.Annotations[restoreHelperOverrideAnnotation]; !ok {
+		// keep the original from the first override if one is already in place
+		if original, ok := configMap.Data["image"]; ok {
+			configMap.Annotations[restoreHelperOriginalAnnotation] = original
+		}
+	}
+	configMap.Annotations[restoreHelperOverrideAnnotation] = restoreName
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data["image"] = image
+
+	if _, err := clientset.CoreV1().ConfigMaps(veleroNamespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update restic restore action config")
+	}
+
+	return nil
+}
+
+// CheckRestoreHelperOverride returns a RestoreHelperOverrideInUseError if the restore helper image is overridden
+// for a restore other than this one that hasn't finished, since this restore would run with that image
+func CheckRestoreHelperOverride(ctx context.Context, restoreName string) error {
+	clientset, veleroNamespace, err := getRestoreHelperClient()
+	if err != nil {
+		return err
+	}
+
+	configMap, err := findResticRestoreActionConfigMap(ctx, clientset, veleroNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to find restic restore action config")
+	}
+
+	return checkRestoreHelperOverride(ctx, veleroNamespace, configMap, restoreName)
+}
+
+func checkRestoreHelperOverride(ctx context.Context, veleroNamespace string, configMap *corev1.ConfigMap, restoreName string) error {
+	if configMap == nil {
+		return nil
+	}
+	owner := configMap.Annotations[restoreHelperOverrideAnnotation]
+	if owner == "" || owner == restoreName {
+		return nil
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	// a restore that wasn't created yet still needs the override, so only a finished one gives it up
+	restore, err := veleroClient.Restores(veleroNamespace).Get(ctx, owner, metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get restore %s", owner)
+	}
+	if err == nil && isRestoreDone(restore) {
+		return nil
+	}
+
+	return RestoreHelperOverrideInUseError{RestoreName: owner}
+}
+
+func isRestoreDone(restore *velerov1.Restore) bool {
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseCompleted, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailed, velerov1.RestorePhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// RevertRestoreHelperImage puts back the restore helper image if it was overridden for the restore
+func RevertRestoreHelperImage(ctx context.Context, restoreName string) error {
+	clientset, veleroNamespace, err := getRestoreHelperClient()
+	if err != nil {
+		return err
+	}
+
+	configMap, err := findResticRestoreActionConfigMap(ctx, clientset, veleroNamespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to find restic restore action config")
+	}
+	if configMap == nil || configMap.Annotations[restoreHelperOverrideAnnotation] != restoreName {
+		return nil
+	}
+
+	if configMap.Annotations[restoreHelperCreatedAnnotation] == "true" {
+		if err := clientset.CoreV1().ConfigMaps(veleroNamespace).Delete(ctx, configMap.Name, metav1.DeleteOptions{}); err != nil {
+			return errors.Wrap(err, "failed to delete restic restore action config")
+		}
+		return nil
+	}
+
+	if original, ok := configMap.Annotations[restoreHelperOriginalAnnotation]; ok {
+		configMap.Data["image"] = original
+	} else {
+		delete(configMap.Data, "image")
+	}
+	delete(configMap.Annotations, restoreHelperOverrideAnnotation)
+	delete(configMap.Annotations, restoreHelperOriginalAnnotation)
+
+	if _, err := clientset.CoreV1().ConfigMaps(veleroNamespace).Update(ctx, configMap, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrap(err, "failed to update restic restore action config")
+	}
+
+	return nil
+}
+
+func getRestoreHelperClient() (*kubernetes.Clientset, string, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return nil, "", errors.New("velero not found")
+	}
+
+	return clientset, veleroNamespace, nil
+}
+
+func findResticRestoreActionConfigMap(ctx context.Context, clientset *kubernetes.Clientset, namespace string) (*corev1.ConfigMap, error) {
+	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "velero.io/plugin-config,velero.io/restic=RestoreItemAction",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list restic plugin config")
+	}
+	if len(configMaps.Items) == 0 {
+		return nil, nil
+	}
+	return &configMaps.Items[0], nil
+}
@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRestoreThroughput(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	restoreVolume := func(phase velerov1.PodVolumeRestorePhase, totalBytes int64, seconds int) velerov1.PodVolumeRestore {
+		v := velerov1.PodVolumeRestore{}
+		v.Status.Phase = phase
+		v.Status.Progress.TotalBytes = totalBytes
+		v.Status.StartTimestamp = &metav1.Time{Time: start}
+		v.Status.CompletionTimestamp = &metav1.Time{Time: start.Add(time.Duration(seconds) * time.Second)}
+		return v
+	}
+
+	tests := []struct {
+		name           string
+		restoreVolumes []velerov1.PodVolumeRestore
+		want           int64
+	}{
+		{
+			name: "no history",
+			want: 0,
+		},
+		{
+			name: "single completed",
+			restoreVolumes: []velerov1.PodVolumeRestore{
+				restoreVolume(velerov1.PodVolumeRestorePhaseCompleted, 1000, 10),
+			},
+			want: 100,
+		},
+		{
+			name: "skips failed and in progress",
+			restoreVolumes: []velerov1.PodVolumeRestore{
+				restoreVolume(velerov1.PodVolumeRestorePhaseCompleted, 1000, 10),
+				restoreVolume(velerov1.PodVolumeRestorePhaseCompleted, 3000, 10),
+				restoreVolume(velerov1.PodVolumeRestorePhaseFailed, 5000, 1),
+				restoreVolume(velerov1.PodVolumeRestorePhaseInProgress, 5000, 1),
+			},
+			want: 200,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := restoreThroughput(test.restoreVolumes)
+			if got != test.want {
+				t.Errorf("Expected %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestApplicationRestoreName(t *testing.T) {
+	backup := &velerov1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "my-app-abcde"}}
+	if got := applicationRestoreName(backup, "my-app"); got != "my-app-abcde" {
+		t.Errorf("Expected my-app-abcde, got %q", got)
+	}
+
+	instanceBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "instance-abcde",
+			Annotations: map[string]string{"kots.io/instance": "true"},
+		},
+	}
+	if got := applicationRestoreName(instanceBackup, "my-app"); got != "instance-abcde.my-app" {
+		t.Errorf("Expected instance-abcde.my-app, got %q", got)
+	}
+}
@@ -0,0 +1,139 @@
+package snapshot
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	pkgrestore "github.com/vmware-tanzu/velero/pkg/restore"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// GetRestoreWarnings downloads the results of a finished restore of the app and returns its warnings by category.
+// Restores that complete with warnings are reported as successful by velero but often leave resources
+// behind that weren't restored. A not found error is returned for restores of other apps.
+func GetRestoreWarnings(ctx context.Context, appID string, appSlug string, restoreName string) ([]types.RestoreWarning, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	restore, err := veleroClient.Restores(veleroNamespace).Get(ctx, restoreName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get restore")
+	}
+
+	belongs, err := restoreBelongsToApp(ctx, veleroClient, restore, appID, appSlug)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check restore app")
+	}
+	if !belongs {
+		return nil, kuberneteserrors.NewNotFound(velerov1.Resource("restore"), restoreName)
+	}
+
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseCompleted, velerov1.RestorePhasePartiallyFailed, velerov1.RestorePhaseFailed:
+	default:
+		// results are only uploaded once the restore is done
+		return []types.RestoreWarning{}, nil
+	}
+
+	if restore.Status.Warnings == 0 {
+		return []types.RestoreWarning{}, nil
+	}
+
+	resultMap, err := downloadRestoreResultMap(veleroNamespace, restore.Name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download restore results")
+	}
+
+	return categorizeRestoreWarnings(resultMap["warnings"]), nil
+}
+
+// restoreBelongsToApp checks that the restore was created for the app. Restores of instance backups and cross-app
+// restores name the app they restore, others belong to the app that took the backup.
+func restoreBelongsToApp(ctx context.Context, veleroClient veleroclientv1.VeleroV1Interface, restore *velerov1.Restore, appID string, appSlug string) (bool, error) {
+	if target, ok := restore.Annotations[crossAppRestoreTargetAnnotation]; ok {
+		return target == appSlug, nil
+	}
+	if restore.Spec.LabelSelector != nil {
+		if slug, ok := restore.Spec.LabelSelector.MatchLabels["kots.io/app-slug"]; ok {
+			return slug == appSlug, nil
+		}
+	}
+
+	backup, err := veleroClient.Backups(restore.Namespace).Get(ctx, restore.Spec.BackupName, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get backup")
+	}
+
+	return backup.Annotations["kots.io/app-id"] == appID, nil
+}
+
+func categorizeRestoreWarnings(result pkgrestore.Result) []types.RestoreWarning {
+	warnings := []types.RestoreWarning{}
+
+	for _, message := range result.Velero {
+		warnings = append(warnings, newRestoreWarning("", message))
+	}
+	for _, message := range result.Cluster {
+		warnings = append(warnings, newRestoreWarning("", message))
+	}
+
+	namespaces := []string{}
+	for ns := range result.Namespaces {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	for _, ns := range namespaces {
+		for _, message := range result.Namespaces[ns] {
+			warnings = append(warnings, newRestoreWarning(ns, message))
+		}
+	}
+
+	return warnings
+}
+
+func newRestoreWarning(namespace string, message string) types.RestoreWarning {
+	return types.RestoreWarning{
+		Category:  restoreWarningCategory(message),
+		Namespace: namespace,
+		Message:   message,
+	}
+}
+
+func restoreWarningCategory(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "already exists"):
+		// velero also reports when the existing resource differs from the backed-up one, it is still skipped
+		return types.RestoreWarningAlreadyExists
+	case strings.Contains(lower, "version") && (strings.Contains(lower, "mismatch") || strings.Contains(lower, "not available") || strings.Contains(lower, "not supported")):
+		return types.RestoreWarningVersionMismatch
+	case strings.Contains(lower, "unable to resolve"), strings.Contains(lower, "not supported"), strings.Contains(lower, "no matches for kind"), strings.Contains(lower, "could not find the requested resource"):
+		return types.RestoreWarningUnsupportedResource
+	}
+	return types.RestoreWarningOther
+}
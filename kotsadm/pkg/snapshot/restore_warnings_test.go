@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	pkgrestore "github.com/vmware-tanzu/velero/pkg/restore"
+)
+
+func TestCategorizeRestoreWarnings(t *testing.T) {
+	result := pkgrestore.Result{
+		Velero: []string{
+			"could not restore, apiVersion batch/v2alpha1 not available on the cluster",
+		},
+		Cluster: []string{
+			"could not restore, CustomResourceDefinition \"widgets.example.com\" already exists. Warning: the in-cluster version is different than the backed-up version.",
+		},
+		Namespaces: map[string][]string{
+			"web": {
+				"error restoring certificates.cert-manager.io/web/tls: the server could not find the requested resource",
+			},
+			"app": {
+				"could not restore, Secret \"registry\" already exists. Warning: the in-cluster version is different than the backed-up version.",
+				"unexpected annotation on pod",
+			},
+		},
+	}
+
+	want := []types.RestoreWarning{
+		{
+			Category: types.RestoreWarningVersionMismatch,
+			Message:  "could not restore, apiVersion batch/v2alpha1 not available on the cluster",
+		},
+		{
+			Category: types.RestoreWarningAlreadyExists,
+			Message:  "could not restore, CustomResourceDefinition \"widgets.example.com\" already exists. Warning: the in-cluster version is different than the backed-up version.",
+		},
+		{
+			Category:  types.RestoreWarningAlreadyExists,
+			Namespace: "app",
+			Message:   "could not restore, Secret \"registry\" already exists. Warning: the in-cluster version is different than the backed-up version.",
+		},
+		{
+			Category:  types.RestoreWarningOther,
+			Namespace: "app",
+			Message:   "unexpected annotation on pod",
+		},
+		{
+			Category:  types.RestoreWarningUnsupportedResource,
+			Namespace: "web",
+			Message:   "error restoring certificates.cert-manager.io/web/tls: the server could not find the requested resource",
+		},
+	}
+
+	got := categorizeRestoreWarnings(result)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %#v, got %#v", want, got)
+	}
+}
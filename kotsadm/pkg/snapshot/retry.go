@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// backupSourceAnnotations are the annotations describing what a backup is of. Annotations recording what
+// happened while a backup ran, like quiesced workloads or checksums, are not carried over to backups copied from it.
+var backupSourceAnnotations = []string{
+	"kots.io/app-id",
+	"kots.io/app-sequence",
+	"kots.io/snapshot-schedule",
+	"kots.io/instance",
+	"kots.io/kotsadm-image",
+	"kots.io/kotsadm-deploy-namespace",
+	"kots.io/apps-sequences",
+}
+
+// BackupNotRetryableError is returned when retrying a backup that didn't fail
+type BackupNotRetryableError struct {
+	Name  string
+	Phase velerov1.BackupPhase
+}
+
+func (e BackupNotRetryableError) Error() string {
+	return fmt.Sprintf("backup %s is %s, only failed backups can be retried", e.Name, e.Phase)
+}
+
+// IsBackupNotRetryableError returns true if the cause of the error is a BackupNotRetryableError
+func IsBackupNotRetryableError(err error) bool {
+	_, ok := errors.Cause(err).(BackupNotRetryableError)
+	return ok
+}
+
+// RetryBackup creates a new backup with the same spec as a failed or partially failed backup
+func RetryBackup(ctx context.Context, backupName string) (*velerov1.Backup, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero namespace")
+	}
+
+	veleroNamespace := bsl.Namespace
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	failedBackup, err := veleroClient.Backups(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup")
+	}
+
+	switch failedBackup.Status.Phase {
+	case velerov1.BackupPhaseFailed, velerov1.BackupPhasePartiallyFailed:
+	default:
+		return nil, BackupNotRetryableError{Name: backupName, Phase: failedBackup.Status.Phase}
+	}
+
+	backup, err := veleroClient.Backups(veleroNamespace).Create(ctx, newRetryBackup(failedBackup, time.Now()), metav1.CreateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero backup")
+	}
+
+	return backup, nil
+}
+
+func newRetryBackup(failedBackup *velerov1.Backup, now time.Time) *velerov1.Backup {
+	generateName := failedBackup.GenerateName
+	if generateName == "" {
+		generateName = failedBackup.Name + "-"
+	}
+
+	labels := map[string]string{}
+	for key, value := range failedBackup.Labels {
+		// velero labels the backup itself once it's been created
+		if strings.HasPrefix(key, "velero.io/") {
+			continue
+		}
+		labels[key] = value
+	}
+
+	annotations := map[string]string{
+		"kots.io/snapshot-trigger":   "manual",
+		"kots.io/snapshot-requested": now.UTC().Format(time.RFC3339),
+		"kots.io/snapshot-retry-of":  failedBackup.Name,
+	}
+	for _, key := range backupSourceAnnotations {
+		if value, ok := failedBackup.Annotations[key]; ok {
+			annotations[key] = value
+		}
+	}
+
+	return &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    failedBackup.Namespace,
+			Labels:       labels,
+			Annotations:  annotations,
+		},
+		Spec: *failedBackup.Spec.DeepCopy(),
+	}
+}
@@ -0,0 +1,100 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewRetryBackup(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	failedBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         "my-app-abcde",
+			GenerateName: "my-app-",
+			Namespace:    "velero",
+			Labels: map[string]string{
+				"velero.io/storage-location": "default",
+				"team":                       "ops",
+			},
+			Annotations: map[string]string{
+				"kots.io/snapshot-trigger":   "schedule",
+				"kots.io/snapshot-requested": "2020-09-30T12:00:00Z",
+				"kots.io/snapshot-schedule":  "0 0 * * *",
+				"kots.io/app-id":             "app-id",
+				"kots.io/app-sequence":       "3",
+				"kots.io/quiesced-workloads": "[]",
+			},
+		},
+		Spec: velerov1.BackupSpec{
+			IncludedNamespaces: []string{"default"},
+			ExcludedNamespaces: []string{"kube-system"},
+			TTL:                metav1.Duration{Duration: 72 * time.Hour},
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"kots.io/app-slug": "my-app"},
+			},
+			StorageLocation: "default",
+		},
+		Status: velerov1.BackupStatus{
+			Phase: velerov1.BackupPhaseFailed,
+		},
+	}
+
+	got := newRetryBackup(failedBackup, now)
+
+	if got.Name != "" || got.GenerateName != "my-app-" {
+		t.Errorf("Expected generate name my-app-, got name %q generate name %q", got.Name, got.GenerateName)
+	}
+	if got.Namespace != "velero" {
+		t.Errorf("Expected namespace velero, got %s", got.Namespace)
+	}
+	wantLabels := map[string]string{"team": "ops"}
+	if !reflect.DeepEqual(got.Labels, wantLabels) {
+		t.Errorf("Expected labels %v, got %v", wantLabels, got.Labels)
+	}
+	wantAnnotations := map[string]string{
+		"kots.io/snapshot-trigger":   "manual",
+		"kots.io/snapshot-requested": "2020-10-01T12:00:00Z",
+		"kots.io/snapshot-retry-of":  "my-app-abcde",
+		"kots.io/snapshot-schedule":  "0 0 * * *",
+		"kots.io/app-id":             "app-id",
+		"kots.io/app-sequence":       "3",
+	}
+	if !reflect.DeepEqual(got.Annotations, wantAnnotations) {
+		t.Errorf("Expected annotations %v, got %v", wantAnnotations, got.Annotations)
+	}
+	if !reflect.DeepEqual(got.Spec, failedBackup.Spec) {
+		t.Errorf("Expected spec %+v, got %+v", failedBackup.Spec, got.Spec)
+	}
+	if got.Status.Phase != "" {
+		t.Errorf("Expected no phase, got %s", got.Status.Phase)
+	}
+
+	// the retry doesn't share the failed backup's selectors
+	got.Spec.LabelSelector.MatchLabels["kots.io/app-slug"] = "other"
+	if slug := failedBackup.Spec.LabelSelector.MatchLabels["kots.io/app-slug"]; slug != "my-app" {
+		t.Errorf("Expected failed backup selector to be unchanged, got %s", slug)
+	}
+}
+
+func TestNewRetryBackupWithoutGenerateName(t *testing.T) {
+	failedBackup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nightly",
+			Namespace: "velero",
+		},
+	}
+
+	got := newRetryBackup(failedBackup, time.Now())
+
+	if got.GenerateName != "nightly-" {
+		t.Errorf("Expected generate name nightly-, got %s", got.GenerateName)
+	}
+	if got.Annotations["kots.io/snapshot-retry-of"] != "nightly" {
+		t.Errorf("Expected retry of nightly, got %s", got.Annotations["kots.io/snapshot-retry-of"])
+	}
+}
@@ -0,0 +1,132 @@
+package snapshot
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	cron "github.com/robfig/cron/v3"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+// scheduleCoverageRuns is how many upcoming runs are checked for the longest interval, enough to cover a
+// schedule that only runs on some days of the week
+const scheduleCoverageRuns = 14
+
+// ValidateScheduleJitter checks the jitter window, in minutes, that scheduled backups are spread across. The window
+// has to be shorter than the time between runs of the schedule, or a run could start after the next one is due.
+func ValidateScheduleJitter(cronExpression string, jitterMinutes int, now time.Time) error {
+	if jitterMinutes < 0 {
+		return errors.New("jitter can't be negative")
+	}
+	if jitterMinutes == 0 {
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse cron expression")
+	}
+
+	shortestInterval := shortestScheduleInterval(schedule, now)
+	if window := time.Duration(jitterMinutes) * time.Minute; window >= shortestInterval {
+		return errors.Errorf("jitter must be less than the %s between scheduled runs", shortestInterval)
+	}
+	return nil
+}
+
+// ApplyScheduleJitter offsets a scheduled time by a random amount within the jitter window, so apps sharing a
+// schedule don't all start their backups at the same moment
+func ApplyScheduleJitter(scheduled time.Time, jitterMinutes int) time.Time {
+	if jitterMinutes <= 0 {
+		return scheduled
+	}
+	window := time.Duration(jitterMinutes) * time.Minute
+	return scheduled.Add(time.Duration(rand.Int63n(int64(window))))
+}
+
+// CheckBackupGapRisk returns the details of the gap when every completed backup expires before the next
+// scheduled backup runs, or nil if there's no gap
+func CheckBackupGapRisk(backups []*types.Backup, nextScheduled time.Time) *types.BackupGapRisk {
+	var latest *types.Backup
+	for _, backup := range backups {
+		if backup.Status != string(velerov1.BackupPhaseCompleted) || backup.ExpiresAt == nil {
+			continue
+		}
+		if latest == nil || backup.ExpiresAt.After(*latest.ExpiresAt) {
+			latest = backup
+		}
+	}
+
+	if latest == nil {
+		return &types.BackupGapRisk{
+			NextScheduledBackupAt: nextScheduled,
+			Message:               "There are no completed backups to restore from until the next scheduled backup runs",
+		}
+	}
+
+	if !latest.ExpiresAt.Before(nextScheduled) {
+		return nil
+	}
+
+	return &types.BackupGapRisk{
+		LatestBackupName:      latest.Name,
+		LatestBackupExpiresAt: latest.ExpiresAt,
+		NextScheduledBackupAt: nextScheduled,
+		Message:               fmt.Sprintf("Backup %s expires %s before the next scheduled backup runs", latest.Name, nextScheduled.Sub(*latest.ExpiresAt).Round(time.Minute)),
+	}
+}
+
+// CheckScheduleRetentionGap returns a warning when backups taken on the schedule expire before the next one
+// runs, which leaves periods with no backup to restore from. The ttl is a duration like "72h". An empty string
+// is returned when the retention covers the longest interval between runs.
+func CheckScheduleRetentionGap(cronExpression string, ttl string, jitterMinutes int, now time.Time) (string, error) {
+	schedule, err := cron.ParseStandard(cronExpression)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse cron expression")
+	}
+
+	retention, err := time.ParseDuration(ttl)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse ttl")
+	}
+
+	longestInterval := longestScheduleInterval(schedule, now)
+	// a backup can start up to the jitter window late
+	longestInterval += time.Duration(jitterMinutes) * time.Minute
+
+	if retention >= longestInterval {
+		return "", nil
+	}
+
+	return fmt.Sprintf("Backups are kept for %s but can be up to %s apart, so for %s before each scheduled backup there will be no backup to restore from. Increase the retention or run the schedule more often.",
+		retention, longestInterval, longestInterval-retention), nil
+}
+
+func longestScheduleInterval(schedule cron.Schedule, now time.Time) time.Duration {
+	var longest time.Duration
+	previous := schedule.Next(now)
+	for i := 0; i < scheduleCoverageRuns; i++ {
+		next := schedule.Next(previous)
+		if interval := next.Sub(previous); interval > longest {
+			longest = interval
+		}
+		previous = next
+	}
+	return longest
+}
+
+func shortestScheduleInterval(schedule cron.Schedule, now time.Time) time.Duration {
+	var shortest time.Duration
+	previous := schedule.Next(now)
+	for i := 0; i < scheduleCoverageRuns; i++ {
+		next := schedule.Next(previous)
+		if interval := next.Sub(previous); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+		previous = next
+	}
+	return shortest
+}
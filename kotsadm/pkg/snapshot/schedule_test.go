@@ -0,0 +1,140 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestApplyScheduleJitter(t *testing.T) {
+	scheduled := time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)
+
+	if got := ApplyScheduleJitter(scheduled, 0); !got.Equal(scheduled) {
+		t.Errorf("Expected no jitter to keep %s, got %s", scheduled, got)
+	}
+
+	for i := 0; i < 100; i++ {
+		got := ApplyScheduleJitter(scheduled, 30)
+		if got.Before(scheduled) || !got.Before(scheduled.Add(30*time.Minute)) {
+			t.Fatalf("Expected %s to be within 30 minutes after %s", got, scheduled)
+		}
+	}
+}
+
+func TestValidateScheduleJitter(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		schedule  string
+		jitter    int
+		wantError bool
+	}{
+		{"0 2 * * *", 0, false},
+		{"0 2 * * *", 30, false},
+		{"0 2 * * *", 1439, false},
+		{"0 2 * * *", 1440, true},
+		{"0 2 * * *", -1, true},
+		{"0 * * * *", 59, false},
+		{"0 * * * *", 60, true},
+		{"*/15 * * * *", 15, true},
+		{"0 2 * * 1", 1440, false},
+		{"0 2 * * 1,2", 1440, true},
+		{"not a schedule", 30, true},
+		{"not a schedule", 0, false},
+	}
+	for _, test := range tests {
+		err := ValidateScheduleJitter(test.schedule, test.jitter, now)
+		if (err != nil) != test.wantError {
+			t.Errorf("Expected error %v for %d on %q, got %v", test.wantError, test.jitter, test.schedule, err)
+		}
+	}
+}
+
+func TestCheckBackupGapRisk(t *testing.T) {
+	nextScheduled := time.Date(2020, 10, 8, 2, 0, 0, 0, time.UTC)
+	expiresAt := func(day int) *time.Time {
+		t := time.Date(2020, 10, day, 2, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name       string
+		backups    []*types.Backup
+		wantRisk   bool
+		wantBackup string
+	}{
+		{
+			name:     "no backups",
+			backups:  []*types.Backup{},
+			wantRisk: true,
+		},
+		{
+			name: "latest backup outlives the next run",
+			backups: []*types.Backup{
+				{Name: "a", Status: "Completed", ExpiresAt: expiresAt(7)},
+				{Name: "b", Status: "Completed", ExpiresAt: expiresAt(9)},
+			},
+			wantRisk: false,
+		},
+		{
+			name: "latest backup expires first",
+			backups: []*types.Backup{
+				{Name: "a", Status: "Completed", ExpiresAt: expiresAt(6)},
+				{Name: "b", Status: "Completed", ExpiresAt: expiresAt(7)},
+			},
+			wantRisk:   true,
+			wantBackup: "b",
+		},
+		{
+			name: "failed backups don't count",
+			backups: []*types.Backup{
+				{Name: "a", Status: "Completed", ExpiresAt: expiresAt(7)},
+				{Name: "b", Status: "PartiallyFailed", ExpiresAt: expiresAt(9)},
+			},
+			wantRisk:   true,
+			wantBackup: "a",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := CheckBackupGapRisk(test.backups, nextScheduled)
+			if (got != nil) != test.wantRisk {
+				t.Fatalf("Expected risk %v, got %+v", test.wantRisk, got)
+			}
+			if got != nil && got.LatestBackupName != test.wantBackup {
+				t.Errorf("Expected latest backup %q, got %q", test.wantBackup, got.LatestBackupName)
+			}
+		})
+	}
+}
+
+func TestCheckScheduleRetentionGap(t *testing.T) {
+	now := time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		schedule    string
+		ttl         string
+		jitter      int
+		wantWarning bool
+	}{
+		{"weekly kept for a month", "0 0 * * MON", "720h", 0, false},
+		{"weekly kept for 3 days", "0 0 * * MON", "72h", 0, true},
+		{"daily kept for exactly a day", "0 2 * * *", "24h", 0, false},
+		{"daily kept for a day with jitter", "0 2 * * *", "24h", 30, true},
+		{"twice a week uses the longest interval", "0 0 * * MON,THU", "72h", 0, true},
+		{"hourly descriptor", "@hourly", "2h", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warning, err := CheckScheduleRetentionGap(test.schedule, test.ttl, test.jitter, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (warning != "") != test.wantWarning {
+				t.Errorf("Expected warning %v, got %q", test.wantWarning, warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+// StorageLocationNotFoundError is returned when there is no velero backup storage location with the name
+type StorageLocationNotFoundError struct {
+	Name string
+}
+
+func (e StorageLocationNotFoundError) Error() string {
+	return fmt.Sprintf("storage location %s not found", e.Name)
+}
+
+// IsStorageLocationNotFoundError returns true if the cause of the error is a StorageLocationNotFoundError
+func IsStorageLocationNotFoundError(err error) bool {
+	_, ok := errors.Cause(err).(StorageLocationNotFoundError)
+	return ok
+}
+
+// SetStorageLocationReadOnly sets the access mode of a velero backup storage location. Velero still syncs and
+// restores backups from a read-only location but won't write new backups or delete expired ones there, which
+// keeps an old store restorable while migrating to a new one.
+func SetStorageLocationReadOnly(ctx context.Context, name string, readOnly bool) (*velerov1.BackupStorageLocation, error) {
+	defaultBSL, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	bsl, err := veleroClient.BackupStorageLocations(defaultBSL.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if kuberneteserrors.IsNotFound(err) {
+		return nil, StorageLocationNotFoundError{Name: name}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get storage location %s", name)
+	}
+
+	accessMode := velerov1.BackupStorageLocationAccessModeReadWrite
+	if readOnly {
+		accessMode = velerov1.BackupStorageLocationAccessModeReadOnly
+	}
+	if bsl.Spec.AccessMode == accessMode {
+		return bsl, nil
+	}
+	bsl.Spec.AccessMode = accessMode
+
+	updated, err := veleroClient.BackupStorageLocations(bsl.Namespace).Update(ctx, bsl, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update storage location %s", name)
+	}
+
+	return updated, nil
+}
+
+// IsStorageLocationReadOnly returns true if velero won't write new backups to the location
+func IsStorageLocationReadOnly(bsl *velerov1.BackupStorageLocation) bool {
+	return bsl.Spec.AccessMode == velerov1.BackupStorageLocationAccessModeReadOnly
+}
@@ -1,10 +1,11 @@
 package snapshot
 
 import (
-	"bufio"
-	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
 	"time"
 
@@ -30,13 +31,38 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/api/option"
 	"gopkg.in/ini.v1"
-	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
+const gkeWorkloadIdentityAnnotation = "iam.gke.io/gcp-service-account"
+
+// insecureSkipTLSVerifyConfig is the backup storage location config key the velero aws plugin reads to skip
+// verifying the store's certificate
+const insecureSkipTLSVerifyConfig = "insecureSkipTLSVerify"
+
+// volumeSnapshotCredentialsProfile is the aws credentials profile used by the volume snapshot location
+// when it has credentials separate from the backup storage location
+const volumeSnapshotCredentialsProfile = "volumesnapshot"
+
+// NoStoreConfiguredError is returned when velero is installed but doesn't have a default backup storage
+// location with object storage for kotsadm to store snapshots in
+type NoStoreConfiguredError struct{}
+
+func (e NoStoreConfiguredError) Error() string {
+	return "no snapshot store configured"
+}
+
+// IsNoStoreConfiguredError returns true if the cause of the error is a NoStoreConfiguredError
+func IsNoStoreConfiguredError(err error) bool {
+	_, ok := errors.Cause(err).(NoStoreConfiguredError)
+	return ok
+}
+
+var gcpServiceAccountEmailRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*@([a-z0-9-]+\.iam|developer)\.gserviceaccount\.com$`)
+
 // UpdateGlobalStore will update the in-cluster storage with exactly what's in the store param
 func UpdateGlobalStore(store *types.Store) (*velerov1.BackupStorageLocation, error) {
 	cfg, err := config.GetConfig()
@@ -68,10 +94,7 @@ func UpdateGlobalStore(store *types.Store) (*velerov1.BackupStorageLocation, err
 	kotsadmVeleroBackendStorageLocation.Spec.ObjectStorage.Bucket = store.Bucket
 	kotsadmVeleroBackendStorageLocation.Spec.ObjectStorage.Prefix = store.Path
 
-	currentSecret, currentSecretErr := clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Get(context.TODO(), "cloud-credentials", metav1.GetOptions{})
-	if currentSecretErr != nil && !kuberneteserrors.IsNotFound(currentSecretErr) {
-		return nil, errors.Wrap(currentSecretErr, "failed to read aws secret")
-	}
+	setObjectLockRetentionDays(kotsadmVeleroBackendStorageLocation, ObjectLockRetentionDays(store))
 
 	if store.AWS != nil {
 		logger.Debug("updating aws config in global snapshot storage",
@@ -82,73 +105,12 @@ func UpdateGlobalStore(store *types.Store) (*velerov1.BackupStorageLocation, err
 		kotsadmVeleroBackendStorageLocation.Spec.Config = map[string]string{
 			"region": store.AWS.Region,
 		}
+		if store.AWS.UseFIPSEndpoint {
+			kotsadmVeleroBackendStorageLocation.Spec.Config["s3Url"] = awsFIPSEndpoint(store.AWS.Region)
+		}
 
-		if store.AWS.UseInstanceRole {
-			// delete the secret
-			if currentSecretErr == nil {
-				err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Delete(context.TODO(), "cloud-credentials", metav1.DeleteOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to delete aws secret")
-				}
-			}
-		} else {
-			awsCfg := ini.Empty()
-			section, err := awsCfg.NewSection("default")
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create default section in aws creds")
-			}
-			_, err = section.NewKey("aws_access_key_id", store.AWS.AccessKeyID)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create access key")
-			}
-
-			_, err = section.NewKey("aws_secret_access_key", store.AWS.SecretAccessKey)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create secret access key")
-			}
-
-			var awsCredentials bytes.Buffer
-			writer := bufio.NewWriter(&awsCredentials)
-			_, err = awsCfg.WriteTo(writer)
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to write ini")
-			}
-			if err := writer.Flush(); err != nil {
-				return nil, errors.Wrap(err, "failed to flush buffer")
-			}
-
-			// create or update the secret
-			if kuberneteserrors.IsNotFound(currentSecretErr) {
-				// create
-				toCreate := corev1.Secret{
-					TypeMeta: metav1.TypeMeta{
-						APIVersion: "v1",
-						Kind:       "Secret",
-					},
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "cloud-credentials",
-						Namespace: kotsadmVeleroBackendStorageLocation.Namespace,
-					},
-					Data: map[string][]byte{
-						"cloud": awsCredentials.Bytes(),
-					},
-				}
-				_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to create aws secret")
-				}
-			} else {
-				// update
-				if currentSecret.Data == nil {
-					currentSecret.Data = map[string][]byte{}
-				}
-
-				currentSecret.Data["cloud"] = awsCredentials.Bytes()
-				_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to update aws secret")
-				}
-			}
+		if err := updateAWSVolumeSnapshotLocation(veleroClient, kotsadmVeleroBackendStorageLocation.Namespace, store.AWS); err != nil {
+			return nil, errors.Wrap(err, "failed to update volume snapshot location")
 		}
 	} else if store.Other != nil {
 		kotsadmVeleroBackendStorageLocation.Spec.Config = map[string]string{
@@ -156,63 +118,17 @@ func UpdateGlobalStore(store *types.Store) (*velerov1.BackupStorageLocation, err
 			"s3Url":            store.Other.Endpoint,
 			"s3ForcePathStyle": "true",
 		}
-
-		otherCfg := ini.Empty()
-		section, err := otherCfg.NewSection("default")
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create default section in other creds")
-		}
-		_, err = section.NewKey("aws_access_key_id", store.Other.AccessKeyID)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create other access key id")
+		if store.Other.SignatureVersion != "" {
+			kotsadmVeleroBackendStorageLocation.Spec.Config["signatureVersion"] = store.Other.SignatureVersion
 		}
 
-		_, err = section.NewKey("aws_secret_access_key", store.Other.SecretAccessKey)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create other secret access key")
-		}
-
-		var otherCredentials bytes.Buffer
-		writer := bufio.NewWriter(&otherCredentials)
-		_, err = otherCfg.WriteTo(writer)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to write ini")
-		}
-		if err := writer.Flush(); err != nil {
-			return nil, errors.Wrap(err, "failed to flush buffer")
-		}
-
-		// create or update the secret
-		if kuberneteserrors.IsNotFound(currentSecretErr) {
-			// create
-			toCreate := corev1.Secret{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Secret",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "cloud-credentials",
-					Namespace: kotsadmVeleroBackendStorageLocation.Namespace,
-				},
-				Data: map[string][]byte{
-					"cloud": otherCredentials.Bytes(),
-				},
-			}
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create other secret")
+		if store.Other.Preset != "" {
+			if kotsadmVeleroBackendStorageLocation.Annotations == nil {
+				kotsadmVeleroBackendStorageLocation.Annotations = map[string]string{}
 			}
+			kotsadmVeleroBackendStorageLocation.Annotations[storePresetAnnotation] = store.Other.Preset
 		} else {
-			// update
-			if currentSecret.Data == nil {
-				currentSecret.Data = map[string][]byte{}
-			}
-
-			currentSecret.Data["cloud"] = otherCredentials.Bytes()
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to update other secret")
-			}
+			delete(kotsadmVeleroBackendStorageLocation.Annotations, storePresetAnnotation)
 		}
 	} else if store.Internal != nil {
 		kotsadmVeleroBackendStorageLocation.Spec.Config = map[string]string{
@@ -221,156 +137,37 @@ func UpdateGlobalStore(store *types.Store) (*velerov1.BackupStorageLocation, err
 			"publicUrl":        fmt.Sprintf("http://%s", store.Internal.ObjectStoreClusterIP),
 			"s3ForcePathStyle": "true",
 		}
-
-		internalCfg := ini.Empty()
-		section, err := internalCfg.NewSection("default")
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create default section in internal creds")
-		}
-		_, err = section.NewKey("aws_access_key_id", store.Internal.AccessKeyID)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create internal access key id")
-		}
-
-		_, err = section.NewKey("aws_secret_access_key", store.Internal.SecretAccessKey)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to create internal secret access key")
-		}
-
-		var internalCredentials bytes.Buffer
-		writer := bufio.NewWriter(&internalCredentials)
-		_, err = internalCfg.WriteTo(writer)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to write ini")
-		}
-		if err := writer.Flush(); err != nil {
-			return nil, errors.Wrap(err, "failed to flush buffer")
-		}
-
-		// create or update the secret
-		if kuberneteserrors.IsNotFound(currentSecretErr) {
-			// create
-			toCreate := corev1.Secret{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Secret",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "cloud-credentials",
-					Namespace: kotsadmVeleroBackendStorageLocation.Namespace,
-				},
-				Data: map[string][]byte{
-					"cloud": internalCredentials.Bytes(),
-				},
-			}
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create internal secret")
-			}
-		} else {
-			// update
-			if currentSecret.Data == nil {
-				currentSecret.Data = map[string][]byte{}
-			}
-
-			currentSecret.Data["cloud"] = internalCredentials.Bytes()
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to update internal secret")
-			}
+		if store.Internal.InsecureSkipTLSVerify {
+			kotsadmVeleroBackendStorageLocation.Spec.Config[insecureSkipTLSVerifyConfig] = "true"
 		}
 	} else if store.Google != nil {
 		if store.Google.UseInstanceRole {
 			kotsadmVeleroBackendStorageLocation.Spec.Config["serviceAccount"] = store.Google.ServiceAccount
 
-			// delete the secret
-			if currentSecretErr == nil {
-				err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Delete(context.TODO(), "cloud-credentials", metav1.DeleteOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to delete google secret")
-				}
+			// without a static key, velero authenticates through gke workload identity
+			if err := setVeleroServiceAccountAnnotation(clientset, kotsadmVeleroBackendStorageLocation.Namespace, gkeWorkloadIdentityAnnotation, store.Google.ServiceAccount); err != nil {
+				return nil, errors.Wrap(err, "failed to annotate velero service account")
 			}
 		} else {
 			delete(kotsadmVeleroBackendStorageLocation.Spec.Config, "serviceAccount")
 
-			// create or update the secret
-			if kuberneteserrors.IsNotFound(currentSecretErr) {
-				// create
-				toCreate := corev1.Secret{
-					TypeMeta: metav1.TypeMeta{
-						APIVersion: "v1",
-						Kind:       "Secret",
-					},
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "cloud-credentials",
-						Namespace: kotsadmVeleroBackendStorageLocation.Namespace,
-					},
-					Data: map[string][]byte{
-						"cloud": []byte(store.Google.JSONFile),
-					},
-				}
-				_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to create google secret")
-				}
-			} else {
-				// update
-				if currentSecret.Data == nil {
-					currentSecret.Data = map[string][]byte{}
-				}
-
-				currentSecret.Data["cloud"] = []byte(store.Google.JSONFile)
-				_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
-				if err != nil {
-					return nil, errors.Wrap(err, "failed to update google secret")
-				}
+			if err := setVeleroServiceAccountAnnotation(clientset, kotsadmVeleroBackendStorageLocation.Namespace, gkeWorkloadIdentityAnnotation, ""); err != nil {
+				return nil, errors.Wrap(err, "failed to remove annotation from velero service account")
 			}
 		}
 	} else if store.Azure != nil {
 		kotsadmVeleroBackendStorageLocation.Spec.Config["resourceGroup"] = store.Azure.ResourceGroup
 		kotsadmVeleroBackendStorageLocation.Spec.Config["storageAccount"] = store.Azure.StorageAccount
 		kotsadmVeleroBackendStorageLocation.Spec.Config["subscriptionId"] = store.Azure.SubscriptionID
+	}
 
-		config := providers.Azure{
-			SubscriptionID: store.Azure.SubscriptionID,
-			TenantID:       store.Azure.TenantID,
-			ClientID:       store.Azure.ClientID,
-			ClientSecret:   store.Azure.ClientSecret,
-			ResourceGroup:  store.Azure.ResourceGroup,
-			CloudName:      store.Azure.CloudName,
-		}
-
-		// create or update the secret
-		if kuberneteserrors.IsNotFound(currentSecretErr) {
-			// create
-			toCreate := corev1.Secret{
-				TypeMeta: metav1.TypeMeta{
-					APIVersion: "v1",
-					Kind:       "Secret",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "cloud-credentials",
-					Namespace: kotsadmVeleroBackendStorageLocation.Namespace,
-				},
-				Data: map[string][]byte{
-					"cloud": providers.RenderAzureConfig(config),
-				},
-			}
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Create(context.TODO(), &toCreate, metav1.CreateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to create azure secret")
-			}
-		} else {
-			// update
-			if currentSecret.Data == nil {
-				currentSecret.Data = map[string][]byte{}
-			}
-
-			currentSecret.Data["cloud"] = providers.RenderAzureConfig(config)
-			_, err = clientset.CoreV1().Secrets(kotsadmVeleroBackendStorageLocation.Namespace).Update(context.TODO(), currentSecret, metav1.UpdateOptions{})
-			if err != nil {
-				return nil, errors.Wrap(err, "failed to update azure secret")
-			}
+	if store.AWS != nil || store.Other != nil || store.Internal != nil || store.Google != nil || store.Azure != nil {
+		credentialsProvider, err := NewCredentialsProvider(store)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get credentials provider")
+		}
+		if err := applyCloudCredentials(clientset, kotsadmVeleroBackendStorageLocation.Namespace, credentialsProvider); err != nil {
+			return nil, errors.Wrap(err, "failed to apply cloud credentials")
 		}
 	}
 
@@ -414,8 +211,8 @@ func GetGlobalStore(kotsadmVeleroBackendStorageLocation *velerov1.BackupStorageL
 		}
 	}
 
-	if kotsadmVeleroBackendStorageLocation.Spec.ObjectStorage == nil {
-		return nil, nil
+	if kotsadmVeleroBackendStorageLocation == nil || kotsadmVeleroBackendStorageLocation.Spec.ObjectStorage == nil {
+		return nil, NoStoreConfiguredError{}
 	}
 
 	prefix := kotsadmVeleroBackendStorageLocation.Spec.ObjectStorage.Prefix
@@ -434,26 +231,37 @@ func GetGlobalStore(kotsadmVeleroBackendStorageLocation *velerov1.BackupStorageL
 	switch store.Provider {
 	case "aws":
 		endpoint, isS3Compatible := kotsadmVeleroBackendStorageLocation.Spec.Config["s3Url"]
-		if isS3Compatible {
+		useFIPSEndpoint := isS3Compatible && isAWSFIPSEndpoint(endpoint, kotsadmVeleroBackendStorageLocation.Spec.Config["region"])
+		if isS3Compatible && !useFIPSEndpoint {
 			s3Secret, err := kurl.GetS3Secret()
 			if err != nil {
 				return nil, errors.Wrap(err, "failed to get s3 secret")
 			}
 			if s3Secret != nil && string(s3Secret.Data["endpoint"]) == endpoint {
 				store.Internal = &types.StoreInternal{
-					Region:               kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
-					Endpoint:             endpoint,
-					ObjectStoreClusterIP: string(s3Secret.Data["object-store-cluster-ip"]),
+					Region:                kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
+					Endpoint:              endpoint,
+					ObjectStoreClusterIP:  string(s3Secret.Data["object-store-cluster-ip"]),
+					InsecureSkipTLSVerify: kotsadmVeleroBackendStorageLocation.Spec.Config[insecureSkipTLSVerifyConfig] == "true",
 				}
 			} else {
 				store.Other = &types.StoreOther{
-					Region:   kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
-					Endpoint: endpoint,
+					Region:           kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
+					Endpoint:         endpoint,
+					Preset:           kotsadmVeleroBackendStorageLocation.Annotations[storePresetAnnotation],
+					SignatureVersion: kotsadmVeleroBackendStorageLocation.Spec.Config["signatureVersion"],
+
+					ObjectLockRetentionDays: storeObjectLockRetentionDays(kotsadmVeleroBackendStorageLocation),
+				}
+				if store.Other.Preset == StorePresetOracle {
+					store.Other.Namespace = oracleNamespaceFromEndpoint(endpoint)
 				}
 			}
 		} else {
 			store.AWS = &types.StoreAWS{
-				Region: kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
+				Region:                  kotsadmVeleroBackendStorageLocation.Spec.Config["region"],
+				ObjectLockRetentionDays: storeObjectLockRetentionDays(kotsadmVeleroBackendStorageLocation),
+				UseFIPSEndpoint:         useFIPSEndpoint,
 			}
 		}
 
@@ -463,7 +271,7 @@ func GetGlobalStore(kotsadmVeleroBackendStorageLocation *velerov1.BackupStorageL
 		}
 
 		if kuberneteserrors.IsNotFound(err) {
-			if !isS3Compatible {
+			if store.AWS != nil {
 				store.AWS.UseInstanceRole = true
 			}
 		} else if err == nil {
@@ -473,6 +281,13 @@ func GetGlobalStore(kotsadmVeleroBackendStorageLocation *velerov1.BackupStorageL
 			}
 
 			for _, section := range awsCfg.Sections() {
+				if section.Name() == volumeSnapshotCredentialsProfile && store.AWS != nil {
+					store.AWS.VolumeSnapshot = &types.StoreAWSVolumeSnapshot{
+						Region:          getAWSVolumeSnapshotRegion(kotsadmVeleroBackendStorageLocation.Namespace),
+						AccessKeyID:     section.Key("aws_access_key_id").Value(),
+						SecretAccessKey: section.Key("aws_secret_access_key").Value(),
+					}
+				}
 				if section.Name() == "default" {
 					if store.Internal != nil {
 						store.Internal.AccessKeyID = section.Key("aws_access_key_id").Value()
@@ -540,9 +355,96 @@ func GetGlobalStore(kotsadmVeleroBackendStorageLocation *velerov1.BackupStorageL
 		break
 	}
 
+	encryptionKey, err := getStoreEncryptionKey(context.TODO(), clientset, kotsadmVeleroBackendStorageLocation)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get encryption key")
+	}
+	store.EncryptionKey = encryptionKey
+
 	return &store, nil
 }
 
+// updateAWSVolumeSnapshotLocation points the default volume snapshot location at the separate credentials profile
+// when volume snapshot credentials are configured, and back at the default profile otherwise
+func updateAWSVolumeSnapshotLocation(veleroClient *veleroclientv1.VeleroV1Client, namespace string, storeAWS *types.StoreAWS) error {
+	vsl, err := veleroClient.VolumeSnapshotLocations(namespace).Get(context.TODO(), "default", metav1.GetOptions{})
+	if err != nil && !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get volume snapshot location")
+	}
+
+	if kuberneteserrors.IsNotFound(err) {
+		if storeAWS.VolumeSnapshot == nil {
+			return nil
+		}
+
+		vsl = &velerov1.VolumeSnapshotLocation{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "velero.io/v1",
+				Kind:       "VolumeSnapshotLocation",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default",
+				Namespace: namespace,
+			},
+			Spec: velerov1.VolumeSnapshotLocationSpec{
+				Provider: "aws",
+				Config: map[string]string{
+					"region":  storeAWS.VolumeSnapshot.Region,
+					"profile": volumeSnapshotCredentialsProfile,
+				},
+			},
+		}
+		_, err = veleroClient.VolumeSnapshotLocations(namespace).Create(context.TODO(), vsl, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to create volume snapshot location")
+		}
+		return nil
+	}
+
+	if vsl.Spec.Provider != "aws" {
+		return nil
+	}
+
+	if vsl.Spec.Config == nil {
+		vsl.Spec.Config = map[string]string{}
+	}
+	if storeAWS.VolumeSnapshot != nil {
+		vsl.Spec.Config["region"] = storeAWS.VolumeSnapshot.Region
+		vsl.Spec.Config["profile"] = volumeSnapshotCredentialsProfile
+	} else {
+		if vsl.Spec.Config["profile"] != volumeSnapshotCredentialsProfile {
+			return nil
+		}
+		delete(vsl.Spec.Config, "profile")
+	}
+
+	_, err = veleroClient.VolumeSnapshotLocations(namespace).Update(context.TODO(), vsl, metav1.UpdateOptions{})
+	if err != nil {
+		return errors.Wrap(err, "failed to update volume snapshot location")
+	}
+
+	return nil
+}
+
+func getAWSVolumeSnapshotRegion(namespace string) string {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return ""
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return ""
+	}
+
+	vsl, err := veleroClient.VolumeSnapshotLocations(namespace).Get(context.TODO(), "default", metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+
+	return vsl.Spec.Config["region"]
+}
+
 func FindBackupStoreLocation() (*velerov1.BackupStorageLocation, error) {
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -565,7 +467,152 @@ func FindBackupStoreLocation() (*velerov1.BackupStorageLocation, error) {
 		}
 	}
 
-	return nil, errors.New("global config not found")
+	return nil, NoStoreConfiguredError{}
+}
+
+// revalidateStoreFrequency is the validation frequency the location is given while a revalidation is requested,
+// short enough that velero considers a validation due as soon as it sees the change
+const revalidateStoreFrequency = time.Second
+
+// StoreValidationTimeoutError is returned when velero didn't validate the backup storage location in time
+type StoreValidationTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e StoreValidationTimeoutError) Error() string {
+	return fmt.Sprintf("velero did not validate the backup storage location within %s", e.Timeout)
+}
+
+// IsStoreValidationTimeoutError returns true if the cause of the error is a StoreValidationTimeoutError
+func IsStoreValidationTimeoutError(err error) bool {
+	_, ok := errors.Cause(err).(StoreValidationTimeoutError)
+	return ok
+}
+
+// RevalidateStore forces velero to validate the backup storage location again instead of waiting for the next
+// validation cycle, and waits up to the given timeout for the validation to complete. Status is a subresource
+// velero owns, so the validation is requested by briefly shortening the location's validation frequency.
+func RevalidateStore(ctx context.Context, timeout time.Duration) (*velerov1.BackupStorageLocation, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	// validation times are stored with second precision
+	requestedAt := time.Now().Truncate(time.Second)
+	previousValidationTime := bsl.Status.LastValidationTime
+	originalFrequency := bsl.Spec.ValidationFrequency
+	// client-go returns an empty object on errors, so the location is always referred to by these
+	namespace, name := bsl.Namespace, bsl.Name
+
+	bsl.Spec.ValidationFrequency = &metav1.Duration{Duration: revalidateStoreFrequency}
+	if _, err := veleroClient.BackupStorageLocations(namespace).Update(ctx, bsl, metav1.UpdateOptions{}); err != nil {
+		return nil, errors.Wrap(err, "failed to request validation")
+	}
+	defer func() {
+		// restore the frequency even when the request was cancelled
+		restoreCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := setValidationFrequency(restoreCtx, veleroClient, namespace, name, originalFrequency); err != nil {
+			logger.Error(errors.Wrap(err, "failed to restore backup storage location validation frequency"))
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		current, err := veleroClient.BackupStorageLocations(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get backup storage location")
+		}
+
+		if isStatusTimeAfterRequest(current.Status.LastValidationTime, previousValidationTime, requestedAt) {
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "failed to wait for validation")
+		case <-timer.C:
+			return nil, StoreValidationTimeoutError{Timeout: timeout}
+		case <-ticker.C:
+		}
+	}
+}
+
+// isStatusTimeAfterRequest returns true if a location status time, e.g. of its last validation or sync, was set
+// after the request and is newer than the previous one
+func isStatusTimeAfterRequest(current *metav1.Time, previous *metav1.Time, requestedAt time.Time) bool {
+	if current == nil || current.Time.Before(requestedAt) {
+		return false
+	}
+	return previous == nil || current.Time.After(previous.Time)
+}
+
+// setValidationFrequency sets the validation frequency of the location, retrying once if it was updated
+// concurrently, e.g. by velero
+func setValidationFrequency(ctx context.Context, veleroClient *veleroclientv1.VeleroV1Client, namespace string, name string, frequency *metav1.Duration) error {
+	var err error
+	for i := 0; i < 2; i++ {
+		var bsl *velerov1.BackupStorageLocation
+		bsl, err = veleroClient.BackupStorageLocations(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get backup storage location")
+		}
+		bsl.Spec.ValidationFrequency = frequency
+		_, err = veleroClient.BackupStorageLocations(namespace).Update(ctx, bsl, metav1.UpdateOptions{})
+		if !kuberneteserrors.IsConflict(err) {
+			break
+		}
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to update backup storage location")
+	}
+	return nil
+}
+
+// SetStoreValidationFrequency sets how often velero checks that the backup storage location is reachable.
+// A frequency of 0 disables validation.
+func SetStoreValidationFrequency(ctx context.Context, frequency time.Duration) (*velerov1.BackupStorageLocation, error) {
+	if frequency < 0 {
+		return nil, errors.New("validation frequency cannot be negative")
+	}
+
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	bsl.Spec.ValidationFrequency = &metav1.Duration{Duration: frequency}
+	bsl, err = veleroClient.BackupStorageLocations(bsl.Namespace).Update(ctx, bsl, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update backup storage location")
+	}
+
+	return bsl, nil
 }
 
 func ValidateStore(store *types.Store) error {
@@ -608,11 +655,47 @@ func ValidateStore(store *types.Store) error {
 }
 
 func validateAWS(storeAWS *types.StoreAWS, bucket string) error {
+	s3Client := newAWSS3Client(storeAWS)
+
+	_, err := s3Client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if isS3BucketNotFoundError(err) {
+		return BucketNotFoundError{Bucket: bucket}
+	} else if err != nil {
+		return errors.Wrap(err, "failed to head bucket")
+	}
+
+	if err := validateBucketObjectLock(s3Client, bucket, retentionDaysValue(storeAWS.ObjectLockRetentionDays)); err != nil {
+		return errors.Wrap(err, "failed to validate object lock")
+	}
+
+	return nil
+}
+
+// newStoreS3Client returns the s3 client of an aws, s3 compatible or internal store, configured the same way
+// the store is validated with
+func newStoreS3Client(store *types.Store) (*s3.S3, error) {
+	switch {
+	case store.AWS != nil:
+		return newAWSS3Client(store.AWS), nil
+	case store.Other != nil:
+		return newOtherS3Client(store.Other), nil
+	case store.Internal != nil:
+		return newInternalS3Client(store.Internal), nil
+	}
+	return nil, errors.New("store is not s3 compatible")
+}
+
+func newAWSS3Client(storeAWS *types.StoreAWS) *s3.S3 {
 	s3Config := &aws.Config{
 		Region:           aws.String(storeAWS.Region),
 		DisableSSL:       aws.Bool(false),
 		S3ForcePathStyle: aws.Bool(false), // TODO: this may need to be configurable
 	}
+	if storeAWS.UseFIPSEndpoint {
+		s3Config.Endpoint = aws.String(awsFIPSEndpoint(storeAWS.Region))
+	}
 
 	if storeAWS.UseInstanceRole {
 		s3Config.Credentials = credentials.NewChainCredentials([]credentials.Provider{
@@ -626,35 +709,43 @@ func validateAWS(storeAWS *types.StoreAWS, bucket string) error {
 	}
 
 	newSession := session.New(s3Config)
-	s3Client := s3.New(newSession)
+	return s3.New(newSession)
+}
 
-	_, err := s3Client.HeadBucket(&s3.HeadBucketInput{
-		Bucket: aws.String(bucket),
-	})
+func validateAzure(storeAzure *types.StoreAzure, bucket string) error {
+	container, err := getAzureContainer(storeAzure, bucket)
+	if err != nil {
+		return errors.Wrap(err, "failed to get container")
+	}
 
+	exists, err := container.Exists()
 	if err != nil {
-		return errors.Wrap(err, "bucket does not exist")
+		return errors.Wrap(err, "failed to check container existence")
+	}
+
+	if !exists {
+		return BucketNotFoundError{Bucket: bucket}
 	}
 
 	return nil
 }
 
-func validateAzure(storeAzure *types.StoreAzure, bucket string) error {
+func getAzureContainer(storeAzure *types.StoreAzure, bucket string) (*storage.Container, error) {
 	// Mostly copied from Velero Azure plugin
 
 	env, err := azure.EnvironmentFromName(storeAzure.CloudName)
 	if err != nil {
-		return errors.Wrap(err, "failed to find azure env")
+		return nil, errors.Wrap(err, "failed to find azure env")
 	}
 
 	oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, storeAzure.TenantID)
 	if err != nil {
-		return errors.Wrap(err, "failed to get OAuthConfig")
+		return nil, errors.Wrap(err, "failed to get OAuthConfig")
 	}
 
 	spt, err := adal.NewServicePrincipalToken(*oauthConfig, storeAzure.ClientID, storeAzure.ClientSecret, env.ResourceManagerEndpoint)
 	if err != nil {
-		return errors.Wrap(err, "failed to get service principal token")
+		return nil, errors.Wrap(err, "failed to get service principal token")
 	}
 
 	storageAccountsClient := storagemgmt.NewAccountsClientWithBaseURI(env.ResourceManagerEndpoint, storeAzure.SubscriptionID)
@@ -662,10 +753,10 @@ func validateAzure(storeAzure *types.StoreAzure, bucket string) error {
 
 	res, err := storageAccountsClient.ListKeys(context.TODO(), storeAzure.ResourceGroup, storeAzure.StorageAccount)
 	if err != nil {
-		return errors.Wrap(err, "failed to list account keys")
+		return nil, errors.Wrap(err, "failed to list account keys")
 	}
 	if res.Keys == nil || len(*res.Keys) == 0 {
-		return errors.New("No storage keys found")
+		return nil, errors.New("No storage keys found")
 	}
 
 	var storageKey string
@@ -679,29 +770,29 @@ func validateAzure(storeAzure *types.StoreAzure, bucket string) error {
 	}
 
 	if storageKey == "" {
-		return errors.New("No storage key with Full permissions found")
+		return nil, errors.New("No storage key with Full permissions found")
 	}
 
 	storageClient, err := storage.NewBasicClientOnSovereignCloud(storeAzure.StorageAccount, storageKey, env)
 	if err != nil {
-		return errors.Wrap(err, "failed to get storage client")
+		return nil, errors.Wrap(err, "failed to get storage client")
 	}
 
 	blobClient := storageClient.GetBlobService()
 	container := blobClient.GetContainerReference(bucket)
 	if container == nil {
-		return errors.Errorf("unable to get container reference for bucket %s", bucket)
+		return nil, errors.Errorf("unable to get container reference for bucket %s", bucket)
 	}
 
-	exists, err := container.Exists()
-	if err != nil {
-		return errors.Wrap(err, "failed to check container existence")
-	}
+	return container, nil
+}
 
-	if !exists {
-		return errors.New("container does not exist")
+// ValidateGCPServiceAccount checks that the service account is a google service account email that can be
+// used for workload identity
+func ValidateGCPServiceAccount(serviceAccount string) error {
+	if !gcpServiceAccountEmailRegex.MatchString(serviceAccount) {
+		return errors.Errorf("%q is not a valid google service account email", serviceAccount)
 	}
-
 	return nil
 }
 
@@ -721,6 +812,9 @@ func validateGCP(storeGoogle *types.StoreGoogle, bucket string) error {
 			if strings.Contains(err.Error(), "no more items in iterator") {
 				return nil
 			}
+			if err == gcpstorage.ErrBucketNotExist {
+				return BucketNotFoundError{Bucket: bucket}
+			}
 
 			return errors.Wrap(err, "failed to get bucket attributes")
 		}
@@ -730,6 +824,25 @@ func validateGCP(storeGoogle *types.StoreGoogle, bucket string) error {
 }
 
 func validateOther(storeOther *types.StoreOther, bucket string) error {
+	s3Client := newOtherS3Client(storeOther)
+
+	_, err := s3Client.HeadBucket(&s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
+	})
+	if isS3BucketNotFoundError(err) {
+		return BucketNotFoundError{Bucket: bucket}
+	} else if err != nil {
+		return errors.Wrap(err, "failed to head bucket")
+	}
+
+	if err := validateBucketObjectLock(s3Client, bucket, retentionDaysValue(storeOther.ObjectLockRetentionDays)); err != nil {
+		return errors.Wrap(err, "failed to validate object lock")
+	}
+
+	return nil
+}
+
+func newOtherS3Client(storeOther *types.StoreOther) *s3.S3 {
 	s3Config := &aws.Config{
 		Region:           aws.String(storeOther.Region),
 		Endpoint:         aws.String(storeOther.Endpoint),
@@ -742,20 +855,25 @@ func validateOther(storeOther *types.StoreOther, bucket string) error {
 	}
 
 	newSession := session.New(s3Config)
-	s3Client := s3.New(newSession)
+	return s3.New(newSession)
+}
+
+func validateInternal(storeInternal *types.StoreInternal, bucket string) error {
+	s3Client := newInternalS3Client(storeInternal)
 
 	_, err := s3Client.HeadBucket(&s3.HeadBucketInput{
 		Bucket: aws.String(bucket),
 	})
-
-	if err != nil {
-		return errors.Wrap(err, "bucket does not exist")
+	if isS3BucketNotFoundError(err) {
+		return BucketNotFoundError{Bucket: bucket}
+	} else if err != nil {
+		return errors.Wrap(err, "failed to head bucket")
 	}
 
 	return nil
 }
 
-func validateInternal(storeInternal *types.StoreInternal, bucket string) error {
+func newInternalS3Client(storeInternal *types.StoreInternal) *s3.S3 {
 	s3Config := &aws.Config{
 		Region:           aws.String(storeInternal.Region),
 		Endpoint:         aws.String(storeInternal.Endpoint),
@@ -767,18 +885,16 @@ func validateInternal(storeInternal *types.StoreInternal, bucket string) error {
 		s3Config.Credentials = credentials.NewStaticCredentials(storeInternal.AccessKeyID, storeInternal.SecretAccessKey, "")
 	}
 
-	newSession := session.New(s3Config)
-	s3Client := s3.New(newSession)
-
-	_, err := s3Client.HeadBucket(&s3.HeadBucketInput{
-		Bucket: aws.String(bucket),
-	})
-
-	if err != nil {
-		return errors.Wrap(err, "bucket does not exist")
+	if storeInternal.InsecureSkipTLSVerify {
+		s3Config.HTTPClient = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		}
 	}
 
-	return nil
+	newSession := session.New(s3Config)
+	return s3.New(newSession)
 }
 
 func Redact(store *types.Store) error {
@@ -790,6 +906,9 @@ func Redact(store *types.Store) error {
 		if store.AWS.SecretAccessKey != "" {
 			store.AWS.SecretAccessKey = "--- REDACTED ---"
 		}
+		if store.AWS.VolumeSnapshot != nil && store.AWS.VolumeSnapshot.SecretAccessKey != "" {
+			store.AWS.VolumeSnapshot.SecretAccessKey = "--- REDACTED ---"
+		}
 	}
 
 	if store.Google != nil {
@@ -816,6 +935,10 @@ func Redact(store *types.Store) error {
 		}
 	}
 
+	if store.EncryptionKey != "" {
+		store.EncryptionKey = "--- REDACTED ---"
+	}
+
 	return nil
 }
 
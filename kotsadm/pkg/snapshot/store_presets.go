@@ -0,0 +1,63 @@
+package snapshot
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+const (
+	StorePresetOracle = "oracle"
+	StorePresetIBM    = "ibm"
+
+	// storePresetAnnotation records the preset on the backup storage location, since the velero aws plugin
+	// rejects config keys it doesn't know about
+	storePresetAnnotation = "kots.io/store-preset"
+)
+
+var oracleEndpointRegex = regexp.MustCompile(`^https://([^.]+)\.compat\.objectstorage\.[^.]+\.oraclecloud\.com$`)
+
+// ApplyStorePreset fills in the endpoint and signature version that the preset's cloud needs. Values that were
+// set explicitly are kept, so operators can still override the preset defaults.
+func ApplyStorePreset(storeOther *types.StoreOther) error {
+	switch storeOther.Preset {
+	case "":
+		return nil
+
+	case StorePresetOracle:
+		if storeOther.Endpoint == "" {
+			if storeOther.Namespace == "" || storeOther.Region == "" {
+				return errors.New("namespace and region are required for oracle cloud storage")
+			}
+			storeOther.Endpoint = fmt.Sprintf("https://%s.compat.objectstorage.%s.oraclecloud.com", storeOther.Namespace, storeOther.Region)
+		}
+
+	case StorePresetIBM:
+		if storeOther.Endpoint == "" {
+			if storeOther.Region == "" {
+				return errors.New("region is required for ibm cloud object storage")
+			}
+			storeOther.Endpoint = fmt.Sprintf("https://s3.%s.cloud-object-storage.appdomain.cloud", storeOther.Region)
+		}
+
+	default:
+		return errors.Errorf("unknown store preset %q", storeOther.Preset)
+	}
+
+	// both clouds only accept v4 signatures
+	if storeOther.SignatureVersion == "" {
+		storeOther.SignatureVersion = "4"
+	}
+
+	return nil
+}
+
+func oracleNamespaceFromEndpoint(endpoint string) string {
+	matches := oracleEndpointRegex.FindStringSubmatch(endpoint)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
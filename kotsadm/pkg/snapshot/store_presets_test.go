@@ -0,0 +1,80 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+func TestApplyStorePreset(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     types.StoreOther
+		want      types.StoreOther
+		wantError bool
+	}{
+		{
+			name:  "no preset",
+			store: types.StoreOther{Region: "us-east-1", Endpoint: "http://minio:9000"},
+			want:  types.StoreOther{Region: "us-east-1", Endpoint: "http://minio:9000"},
+		},
+		{
+			name:  "oracle",
+			store: types.StoreOther{Preset: "oracle", Region: "us-ashburn-1", Namespace: "axaxnpcrorw5"},
+			want: types.StoreOther{
+				Preset:           "oracle",
+				Region:           "us-ashburn-1",
+				Namespace:        "axaxnpcrorw5",
+				Endpoint:         "https://axaxnpcrorw5.compat.objectstorage.us-ashburn-1.oraclecloud.com",
+				SignatureVersion: "4",
+			},
+		},
+		{
+			name:      "oracle without namespace",
+			store:     types.StoreOther{Preset: "oracle", Region: "us-ashburn-1"},
+			wantError: true,
+		},
+		{
+			name:  "ibm with overrides",
+			store: types.StoreOther{Preset: "ibm", Region: "us-south", Endpoint: "https://s3.private.us-south.cloud-object-storage.appdomain.cloud", SignatureVersion: "1"},
+			want:  types.StoreOther{Preset: "ibm", Region: "us-south", Endpoint: "https://s3.private.us-south.cloud-object-storage.appdomain.cloud", SignatureVersion: "1"},
+		},
+		{
+			name:  "ibm",
+			store: types.StoreOther{Preset: "ibm", Region: "eu-de"},
+			want:  types.StoreOther{Preset: "ibm", Region: "eu-de", Endpoint: "https://s3.eu-de.cloud-object-storage.appdomain.cloud", SignatureVersion: "4"},
+		},
+		{
+			name:      "unknown",
+			store:     types.StoreOther{Preset: "wasabi"},
+			wantError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := test.store
+			err := ApplyStorePreset(&store)
+			if test.wantError {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if store != test.want {
+				t.Errorf("Expected %+v, got %+v", test.want, store)
+			}
+		})
+	}
+}
+
+func TestOracleNamespaceFromEndpoint(t *testing.T) {
+	if got := oracleNamespaceFromEndpoint("https://axaxnpcrorw5.compat.objectstorage.us-ashburn-1.oraclecloud.com"); got != "axaxnpcrorw5" {
+		t.Errorf("Expected namespace axaxnpcrorw5, got %q", got)
+	}
+	if got := oracleNamespaceFromEndpoint("http://minio:9000"); got != "" {
+		t.Errorf("Expected no namespace, got %q", got)
+	}
+}
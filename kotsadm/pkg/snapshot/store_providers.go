@@ -0,0 +1,147 @@
+package snapshot
+
+import (
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+)
+
+// ListStoreProviders returns the snapshot store providers supported by UpdateGlobalStore along with the fields
+// each one takes. Field names match the json fields of the provider in the update global snapshot settings request.
+func ListStoreProviders(isKurl bool) []types.StoreProvider {
+	bucketFields := []types.StoreProviderField{
+		{Name: "bucket", Title: "Bucket", Required: true},
+		{Name: "path", Title: "Path", Required: false},
+	}
+
+	return []types.StoreProvider{
+		{
+			Name:      "aws",
+			Title:     "Amazon S3",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "accessKey",
+					Title: "Access key",
+					Fields: []types.StoreProviderField{
+						{Name: "region", Title: "Region", Required: true},
+						{Name: "accessKeyID", Title: "Access key ID", Required: true},
+						{Name: "secretAccessKey", Title: "Secret access key", Required: true, Secret: true},
+					},
+				},
+				{
+					Name:   "useInstanceRole",
+					Title:  "Instance role",
+					Fields: []types.StoreProviderField{},
+				},
+			},
+		},
+		{
+			Name:      "gcp",
+			Title:     "Google Cloud Storage",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "jsonFile",
+					Title: "Service account JSON key",
+					Fields: []types.StoreProviderField{
+						{Name: "jsonFile", Title: "JSON file", Required: true, Secret: true},
+					},
+				},
+				{
+					Name:  "useInstanceRole",
+					Title: "Workload identity",
+					Fields: []types.StoreProviderField{
+						{Name: "serviceAccount", Title: "Service account", Required: true},
+					},
+				},
+			},
+		},
+		{
+			Name:      "azure",
+			Title:     "Azure Blob Storage",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "servicePrincipal",
+					Title: "Service principal",
+					Fields: []types.StoreProviderField{
+						{Name: "resourceGroup", Title: "Resource group", Required: true},
+						{Name: "storageAccount", Title: "Storage account", Required: true},
+						{Name: "subscriptionId", Title: "Subscription ID", Required: true},
+						{Name: "tenantId", Title: "Tenant ID", Required: true},
+						{Name: "clientId", Title: "Client ID", Required: true},
+						{Name: "clientSecret", Title: "Client secret", Required: true, Secret: true},
+						{Name: "cloudName", Title: "Cloud name", Required: false},
+					},
+				},
+			},
+		},
+		{
+			Name:      "other",
+			Title:     "Other S3-compatible storage",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "accessKey",
+					Title: "Access key",
+					Fields: []types.StoreProviderField{
+						{Name: "region", Title: "Region", Required: true},
+						{Name: "endpoint", Title: "Endpoint", Required: true},
+						{Name: "accessKeyID", Title: "Access key ID", Required: true},
+						{Name: "secretAccessKey", Title: "Secret access key", Required: true, Secret: true},
+						{Name: "signatureVersion", Title: "Signature version", Required: false},
+					},
+				},
+			},
+		},
+		{
+			// oracle and ibm are s3-compatible, and are sent as "other" with a preset
+			Name:      StorePresetOracle,
+			Title:     "Oracle Cloud Object Storage",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "accessKey",
+					Title: "Customer secret key",
+					Fields: []types.StoreProviderField{
+						{Name: "region", Title: "Region", Required: true},
+						{Name: "namespace", Title: "Object storage namespace", Required: true},
+						{Name: "accessKeyID", Title: "Access key", Required: true},
+						{Name: "secretAccessKey", Title: "Secret key", Required: true, Secret: true},
+						{Name: "endpoint", Title: "Endpoint", Required: false},
+					},
+				},
+			},
+		},
+		{
+			Name:      StorePresetIBM,
+			Title:     "IBM Cloud Object Storage",
+			Available: true,
+			Fields:    bucketFields,
+			AuthModes: []types.StoreProviderAuth{
+				{
+					Name:  "accessKey",
+					Title: "HMAC credentials",
+					Fields: []types.StoreProviderField{
+						{Name: "region", Title: "Region", Required: true},
+						{Name: "accessKeyID", Title: "Access key ID", Required: true},
+						{Name: "secretAccessKey", Title: "Secret access key", Required: true, Secret: true},
+						{Name: "endpoint", Title: "Endpoint", Required: false},
+					},
+				},
+			},
+		},
+		{
+			// the internal store is configured from the kurl object store, so it takes no fields
+			Name:      "internal",
+			Title:     "Internal storage",
+			Available: isKurl,
+			Fields:    []types.StoreProviderField{},
+			AuthModes: []types.StoreProviderAuth{},
+		},
+	}
+}
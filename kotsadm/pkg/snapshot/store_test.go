@@ -0,0 +1,62 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateGCPServiceAccount(t *testing.T) {
+	tests := []struct {
+		serviceAccount string
+		wantErr        bool
+	}{
+		{"velero@my-project.iam.gserviceaccount.com", false},
+		{"123456789-compute@developer.gserviceaccount.com", false},
+		{"", true},
+		{"velero", true},
+		{"velero@my-project.iam.gserviceaccount.com.evil.com", true},
+		{"Velero@my-project.iam.gserviceaccount.com", true},
+	}
+	for _, test := range tests {
+		t.Run(test.serviceAccount, func(t *testing.T) {
+			err := ValidateGCPServiceAccount(test.serviceAccount)
+			if test.wantErr && err == nil {
+				t.Error("Expected error")
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsStatusTimeAfterRequest(t *testing.T) {
+	requestedAt := time.Date(2020, 10, 1, 12, 0, 10, 0, time.UTC)
+	at := func(seconds int) *metav1.Time {
+		t := metav1.NewTime(time.Date(2020, 10, 1, 12, 0, seconds, 0, time.UTC))
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		validated *metav1.Time
+		previous  *metav1.Time
+		want      bool
+	}{
+		{name: "never validated", validated: nil, previous: nil, want: false},
+		{name: "first validation", validated: at(12), previous: nil, want: true},
+		{name: "not validated again", validated: at(5), previous: at(5), want: false},
+		{name: "validated again", validated: at(11), previous: at(5), want: true},
+		{name: "validated in the request's second", validated: at(10), previous: at(5), want: true},
+		{name: "previous validation in the request's second", validated: at(10), previous: at(10), want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isStatusTimeAfterRequest(test.validated, test.previous, requestedAt); got != test.want {
+				t.Errorf("isStatusTimeAfterRequest() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
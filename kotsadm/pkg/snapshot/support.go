@@ -0,0 +1,272 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// supportDataMaxCRs is the number of most recent backups and restores included in the support data
+	supportDataMaxCRs = 20
+	// supportDataLogTailLines limits how much of each pod log is included
+	supportDataLogTailLines = int64(10000)
+)
+
+// WriteVeleroSupportData writes a gzipped tarball with the velero and restic pod logs, the detected velero
+// install, the redacted store config, restic readiness per node, the backup and volume snapshot locations
+// and the most recent backups and restores. Failing to collect one item doesn't fail the whole archive,
+// the error is written to the archive in its place.
+func WriteVeleroSupportData(ctx context.Context, w io.Writer) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create velero clientset")
+	}
+
+	veleroNamespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if veleroNamespace == "" {
+		return errors.New("velero not found")
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	defer gzipWriter.Close()
+
+	tarWriter := tar.NewWriter(gzipWriter)
+	defer tarWriter.Close()
+
+	files := map[string][]byte{}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, veleroNamespace)
+	if err != nil {
+		files["velero/error.txt"] = []byte(err.Error())
+	}
+	for _, deployment := range veleroDeployments {
+		selector := labels.SelectorFromSet(deployment.Spec.Selector.MatchLabels).String()
+		collectPodLogs(ctx, clientset, veleroNamespace, selector, "velero", files)
+	}
+
+	resticDaemonsets, err := listPossibleResticDaemonsets(clientset, veleroNamespace)
+	if err != nil {
+		files["restic/error.txt"] = []byte(err.Error())
+	}
+	resticPods := []corev1.Pod{}
+	for _, daemonset := range resticDaemonsets {
+		selector := labels.SelectorFromSet(daemonset.Spec.Selector.MatchLabels).String()
+		collectPodLogs(ctx, clientset, veleroNamespace, selector, "restic", files)
+
+		pods, err := clientset.CoreV1().Pods(veleroNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err == nil {
+			resticPods = append(resticPods, pods.Items...)
+		}
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err == nil {
+		addSupportDataYAML(files, "restic-nodes.yaml", getResticNodeStatuses(nodes.Items, resticPods), nil)
+	} else {
+		addSupportDataYAML(files, "restic-nodes.yaml", nil, err)
+	}
+
+	veleroStatus, err := DetectVelero()
+	addSupportDataYAML(files, "velero.yaml", veleroStatus, err)
+
+	store, err := GetGlobalStore(nil)
+	if err == nil {
+		err = Redact(store)
+	}
+	addSupportDataYAML(files, "store.yaml", store, err)
+
+	addSupportDataYAML(files, "diagnostics.yaml", GetSnapshotDiagnostics(ctx), nil)
+
+	bsls, err := veleroClient.BackupStorageLocations(veleroNamespace).List(ctx, metav1.ListOptions{})
+	addSupportDataYAML(files, "backupstoragelocations.yaml", bsls, err)
+
+	vsls, err := veleroClient.VolumeSnapshotLocations(veleroNamespace).List(ctx, metav1.ListOptions{})
+	addSupportDataYAML(files, "volumesnapshotlocations.yaml", vsls, err)
+
+	backups, err := veleroClient.Backups(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		sort.Slice(backups.Items, func(i, j int) bool {
+			return backups.Items[j].CreationTimestamp.Before(&backups.Items[i].CreationTimestamp)
+		})
+		if len(backups.Items) > supportDataMaxCRs {
+			backups.Items = backups.Items[:supportDataMaxCRs]
+		}
+	}
+	addSupportDataYAML(files, "backups.yaml", backups, err)
+
+	restores, err := veleroClient.Restores(veleroNamespace).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		sort.Slice(restores.Items, func(i, j int) bool {
+			return restores.Items[j].CreationTimestamp.Before(&restores.Items[i].CreationTimestamp)
+		})
+		if len(restores.Items) > supportDataMaxCRs {
+			restores.Items = restores.Items[:supportDataMaxCRs]
+		}
+	}
+	addSupportDataYAML(files, "restores.yaml", restores, err)
+
+	filenames := []string{}
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	now := time.Now()
+	for _, filename := range filenames {
+		contents := files[filename]
+		header := &tar.Header{
+			Name:    fmt.Sprintf("velero-support/%s", filename),
+			Mode:    0644,
+			Size:    int64(len(contents)),
+			ModTime: now,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Wrapf(err, "failed to write tar header for %s", filename)
+		}
+		if _, err := tarWriter.Write(contents); err != nil {
+			return errors.Wrapf(err, "failed to write %s", filename)
+		}
+	}
+
+	return nil
+}
+
+// resticNodeStatus is whether restic can back up pod volumes on a node
+type resticNodeStatus struct {
+	Node  string `json:"node"`
+	Pod   string `json:"pod,omitempty"`
+	Ready bool   `json:"ready"`
+	// Message explains why restic isn't ready on the node
+	Message string `json:"message,omitempty"`
+}
+
+// getResticNodeStatuses matches the restic pods to the nodes. Pods on nodes restic isn't scheduled to, e.g.
+// because of taints, can't have their volumes backed up with restic.
+func getResticNodeStatuses(nodes []corev1.Node, resticPods []corev1.Pod) []resticNodeStatus {
+	podsByNode := map[string]corev1.Pod{}
+	for _, pod := range resticPods {
+		if pod.Spec.NodeName != "" {
+			podsByNode[pod.Spec.NodeName] = pod
+		}
+	}
+
+	statuses := []resticNodeStatus{}
+	for _, node := range nodes {
+		status := resticNodeStatus{
+			Node: node.Name,
+		}
+
+		pod, ok := podsByNode[node.Name]
+		if !ok {
+			status.Message = "no restic pod is scheduled on the node"
+			statuses = append(statuses, status)
+			continue
+		}
+
+		status.Pod = pod.Name
+		status.Ready = isPodReady(pod)
+		if !status.Ready {
+			status.Message = fmt.Sprintf("restic pod is %s and not ready", pod.Status.Phase)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func collectPodLogs(ctx context.Context, clientset *kubernetes.Clientset, namespace string, selector string, dir string, files map[string][]byte) {
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		files[fmt.Sprintf("%s/error.txt", dir)] = []byte(err.Error())
+		return
+	}
+
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			filename := fmt.Sprintf("%s/%s-%s.log", dir, pod.Name, container.Name)
+			logs, err := getPodLogs(ctx, clientset, pod, container.Name)
+			if err != nil {
+				files[filename] = []byte(err.Error())
+				continue
+			}
+			files[filename] = logs
+		}
+	}
+}
+
+func getPodLogs(ctx context.Context, clientset *kubernetes.Clientset, pod corev1.Pod, container string) ([]byte, error) {
+	tailLines := supportDataLogTailLines
+	req := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+	})
+	podLogs, err := req.Stream(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get log stream")
+	}
+	defer podLogs.Close()
+
+	buf := new(bytes.Buffer)
+	if _, err := io.Copy(buf, podLogs); err != nil {
+		return nil, errors.Wrap(err, "failed to copy log")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func addSupportDataYAML(files map[string][]byte, filename string, obj interface{}, err error) {
+	if err != nil {
+		files[filename] = []byte(err.Error())
+		return
+	}
+
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		files[filename] = []byte(errors.Wrap(err, "failed to marshal").Error())
+		return
+	}
+
+	files[filename] = b
+}
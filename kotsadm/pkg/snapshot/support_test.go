@@ -0,0 +1,48 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetResticNodeStatuses(t *testing.T) {
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	}
+
+	resticPods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "restic-abcde"},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "restic-fghij"},
+			Spec:       corev1.PodSpec{NodeName: "node-2"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+			},
+		},
+	}
+
+	want := []resticNodeStatus{
+		{Node: "node-1", Pod: "restic-abcde", Ready: true},
+		{Node: "node-2", Pod: "restic-fghij", Ready: false, Message: "restic pod is Pending and not ready"},
+		{Node: "node-3", Ready: false, Message: "no restic pod is scheduled on the node"},
+	}
+
+	got := getResticNodeStatuses(nodes, resticPods)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getResticNodeStatuses() = %+v, want %+v", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
@@ -91,3 +92,38 @@ func FormatTTL(quantity string, unit string) (string, error) {
 
 	return "", fmt.Errorf("Invalid snapshot TTL: %d %s", n, unit)
 }
+
+// ConvertTTL returns the ttl expressed in each unit the UI offers, so switching units doesn't round the value
+func ConvertTTL(s string) ([]snapshottypes.TTLConversion, error) {
+	if !ttlMatch.MatchString(s) {
+		return nil, errors.Errorf("invalid ttl %q", s)
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse ttl as duration")
+	}
+
+	units := []struct {
+		name  string
+		hours int64
+	}{
+		{"hours", 1},
+		{"days", 24},
+		{"weeks", 168},
+		{"months", 720},
+		{"years", 8766},
+	}
+
+	conversions := []snapshottypes.TTLConversion{}
+	for _, unit := range units {
+		unitDuration := time.Duration(unit.hours) * time.Hour
+		conversions = append(conversions, snapshottypes.TTLConversion{
+			Quantity: float64(duration) / float64(unitDuration),
+			Unit:     unit.name,
+			Exact:    duration%unitDuration == 0,
+		})
+	}
+
+	return conversions, nil
+}
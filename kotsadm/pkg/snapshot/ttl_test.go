@@ -66,3 +66,38 @@ func TestParseTTL(t *testing.T) {
 		t.Errorf("Expected error, got %v", parsed)
 	}
 }
+
+func TestConvertTTL(t *testing.T) {
+	conversions, err := ConvertTTL("720h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		quantity float64
+		exact    bool
+	}{
+		"hours":  {720, true},
+		"days":   {30, true},
+		"weeks":  {720.0 / 168, false},
+		"months": {1, true},
+		"years":  {720.0 / 8766, false},
+	}
+	if len(conversions) != len(want) {
+		t.Fatalf("Expected %d conversions, got %d", len(want), len(conversions))
+	}
+	for _, conversion := range conversions {
+		expected, ok := want[conversion.Unit]
+		if !ok {
+			t.Errorf("Unexpected unit %q", conversion.Unit)
+			continue
+		}
+		if conversion.Quantity != expected.quantity || conversion.Exact != expected.exact {
+			t.Errorf("Expected %v %s (exact %v), got %v (exact %v)", expected.quantity, conversion.Unit, expected.exact, conversion.Quantity, conversion.Exact)
+		}
+	}
+
+	if _, err := ConvertTTL("30d"); err == nil {
+		t.Error("Expected error")
+	}
+}
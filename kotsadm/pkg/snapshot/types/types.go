@@ -1,12 +1,32 @@
 package types
 
-import "time"
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
 
 type StoreAWS struct {
 	Region          string `json:"region"`
 	AccessKeyID     string `json:"accessKeyID"`
 	SecretAccessKey string `json:"secretAccessKey"` // added for unmarshaling, redacted on marshaling
 	UseInstanceRole bool   `json:"useInstanceRole"`
+
+	// VolumeSnapshot holds credentials for the volume snapshot location when they differ from the bucket's
+	VolumeSnapshot *StoreAWSVolumeSnapshot `json:"volumeSnapshot,omitempty"`
+
+	// ObjectLockRetentionDays is the default retention of an object lock enabled bucket. Velero can't delete
+	// backups before it expires, so snapshot retention can't be shorter. 0 removes it, nil leaves it unchanged on update.
+	ObjectLockRetentionDays *int `json:"objectLockRetentionDays,omitempty"`
+
+	// UseFIPSEndpoint sends requests to the region's FIPS S3 endpoint, e.g. s3-fips.us-gov-west-1.amazonaws.com
+	UseFIPSEndpoint bool `json:"useFIPSEndpoint,omitempty"`
+}
+
+type StoreAWSVolumeSnapshot struct {
+	Region          string `json:"region"`
+	AccessKeyID     string `json:"accessKeyID"`
+	SecretAccessKey string `json:"secretAccessKey"` // added for unmarshaling, redacted on marshaling
 }
 
 type StoreGoogle struct {
@@ -30,6 +50,14 @@ type StoreOther struct {
 	AccessKeyID     string `json:"accessKeyID"`
 	SecretAccessKey string `json:"secretAccessKey"` // added for unmarshaling, redacted on marshaling
 	Endpoint        string `json:"endpoint"`
+
+	// Preset fills in endpoint and signature defaults for clouds that need them, "oracle" or "ibm"
+	Preset           string `json:"preset,omitempty"`
+	Namespace        string `json:"namespace,omitempty"` // oracle object storage namespace
+	SignatureVersion string `json:"signatureVersion,omitempty"`
+
+	// ObjectLockRetentionDays is the default retention of an object lock enabled bucket, see StoreAWS
+	ObjectLockRetentionDays *int `json:"objectLockRetentionDays,omitempty"`
 }
 
 type StoreInternal struct {
@@ -38,6 +66,9 @@ type StoreInternal struct {
 	SecretAccessKey      string `json:"secretAccessKey"` // added for unmarshaling, redacted on marshaling
 	Endpoint             string `json:"endpoint"`
 	ObjectStoreClusterIP string `json:"objectStoreClusterIP"`
+	// InsecureSkipTLSVerify skips verifying the certificate of the kurl object store, e.g. when it's self-signed.
+	// It only applies to the internal store, external stores are always verified.
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
 }
 
 type Store struct {
@@ -49,6 +80,210 @@ type Store struct {
 	Google   *StoreGoogle   `json:"gcp,omitempty"`
 	Other    *StoreOther    `json:"other,omitempty"`
 	Internal *StoreInternal `json:"internal,omitempty"`
+	// EncryptionKey is the base64 encoded key backup data is encrypted with before it's stored
+	EncryptionKey string `json:"encryptionKey,omitempty"`
+}
+
+// StorePrefixCollision is another backup storage location in the same bucket whose prefix overlaps the store's
+type StorePrefixCollision struct {
+	Location  string `json:"location"`
+	Namespace string `json:"namespace"`
+	Prefix    string `json:"prefix"`
+}
+
+type VeleroImages struct {
+	Velero                       string   `json:"velero"`
+	Plugins                      []string `json:"plugins"`
+	Restic                       string   `json:"restic"`
+	ResticRestoreHelper          string   `json:"resticRestoreHelper"`
+	ResticRestoreHelperIsDefault bool     `json:"resticRestoreHelperIsDefault"`
+}
+
+type ImagePrePullStatus struct {
+	InProgress bool                     `json:"inProgress"`
+	Nodes      []NodeImagePrePullStatus `json:"nodes"`
+}
+
+type NodeImagePrePullStatus struct {
+	Node   string `json:"node"`
+	Pulled bool   `json:"pulled"`
+}
+
+type ResticRepositoryLock struct {
+	Name             string `json:"name"`
+	VolumeNamespace  string `json:"volumeNamespace"`
+	ResticIdentifier string `json:"resticIdentifier"`
+	Message          string `json:"message"`
+}
+
+type SnapshotDiagnostic struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// NamespaceCoverage lists the namespaces installed apps have resources in that the next instance backup won't include
+type NamespaceCoverage struct {
+	UncoveredNamespaces []UncoveredNamespace `json:"uncoveredNamespaces"`
+}
+
+type UncoveredNamespace struct {
+	Namespace string   `json:"namespace"`
+	Apps      []string `json:"apps"`
+}
+
+type QuiesceAction struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Replicas is the number of replicas to run while the backup is taken, usually 0
+	Replicas int32 `json:"replicas"`
+}
+
+type QuiescedWorkload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Replicas is the number of replicas to scale back to after the backup
+	Replicas int32 `json:"replicas"`
+}
+
+// ChecksumTarget is a set of files to checksum in the first running pod matching the selector
+type ChecksumTarget struct {
+	Namespace   string   `json:"namespace,omitempty"`
+	PodSelector string   `json:"podSelector"`
+	Container   string   `json:"container,omitempty"`
+	Paths       []string `json:"paths,omitempty"`
+}
+
+type ChecksumEntry struct {
+	Namespace   string `json:"namespace"`
+	PodSelector string `json:"podSelector"`
+	Container   string `json:"container,omitempty"`
+	Path        string `json:"path"`
+	SHA256      string `json:"sha256"`
+}
+
+type ChecksumMismatch struct {
+	Namespace string `json:"namespace"`
+	Path      string `json:"path"`
+	Expected  string `json:"expected"`
+	// Actual is empty if the file no longer exists
+	Actual string `json:"actual"`
+}
+
+const (
+	RestoreWarningAlreadyExists       = "AlreadyExists"
+	RestoreWarningUnsupportedResource = "UnsupportedResource"
+	RestoreWarningVersionMismatch     = "VersionMismatch"
+	RestoreWarningOther               = "Other"
+)
+
+type RestoreWarning struct {
+	Category string `json:"category"`
+	// Namespace is empty for velero and cluster scoped warnings
+	Namespace string `json:"namespace"`
+	Message   string `json:"message"`
+}
+
+// HookSettings override the timeout, error mode and container of the exec hooks in the app's backup spec
+type HookSettings struct {
+	Timeout string `json:"timeout,omitempty"`
+	// OnError is Continue or Fail
+	OnError string `json:"onError,omitempty"`
+	// Containers maps the name of a hook resource spec to the container its exec hooks run in
+	Containers map[string]string `json:"containers,omitempty"`
+}
+
+type InstanceSnapshotsUnsupportedReason string
+
+const (
+	InstanceSnapshotsNoCluster            InstanceSnapshotsUnsupportedReason = "NoClusterRegistered"
+	InstanceSnapshotsVeleroNotInstalled   InstanceSnapshotsUnsupportedReason = "VeleroNotInstalled"
+	InstanceSnapshotsRequiresVeleroAccess InstanceSnapshotsUnsupportedReason = "RequiresVeleroAccess"
+	InstanceSnapshotsNoStore              InstanceSnapshotsUnsupportedReason = "NoStoreConfigured"
+)
+
+type InstanceSnapshotCapability struct {
+	Supported bool                               `json:"supported"`
+	Reason    InstanceSnapshotsUnsupportedReason `json:"reason,omitempty"`
+	Message   string                             `json:"message,omitempty"`
+	// VeleroNamespace is set when kotsadm needs access to velero in this namespace
+	VeleroNamespace string `json:"veleroNamespace,omitempty"`
+}
+
+type OrphanedObjectsCleanup struct {
+	DryRun         bool              `json:"dryRun"`
+	Orphans        []OrphanedObjects `json:"orphans"`
+	TotalBytes     int64             `json:"totalBytes"`
+	DeletedObjects int               `json:"deletedObjects"`
+}
+
+// OrphanedObjects are the objects in the store for a backup or restore that no longer exists
+type OrphanedObjects struct {
+	// Kind is backup or restore
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Prefix      string `json:"prefix"`
+	ObjectCount int    `json:"objectCount"`
+	Bytes       int64  `json:"bytes"`
+}
+
+type BackupWebhook struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+	// ConfiguredAt is when the url was last changed. Backups that finished before then are not sent to it.
+	ConfiguredAt *time.Time `json:"configuredAt,omitempty"`
+}
+
+// SnapshotDefaults are the instance wide retention and schedule for apps that haven't set their own
+type SnapshotDefaults struct {
+	TTL      string `json:"ttl"`
+	Schedule string `json:"schedule"`
+}
+
+// BackupResourceFilter is merged into every backup kotsadm creates, for resource types that should never be backed up
+type BackupResourceFilter struct {
+	IncludedResources []string `json:"includedResources"`
+	ExcludedResources []string `json:"excludedResources"`
+}
+
+// VeleroExtraContainers are added to the velero deployment next to what velero was installed with, e.g. an init
+// container that bundles a custom CA into a volume the velero container mounts over its trust store
+type VeleroExtraContainers struct {
+	InitContainers []corev1.Container `json:"initContainers"`
+	Volumes        []corev1.Volume    `json:"volumes"`
+	// VolumeMounts are added to the velero container
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts"`
+}
+
+// VeleroImageRegistry is where velero, plugin and restic images are pulled from when they are mirrored under a
+// different registry or namespace than the kotsadm images
+type VeleroImageRegistry struct {
+	Hostname  string `json:"hostname"`
+	Namespace string `json:"namespace"`
+}
+
+type StoreProvider struct {
+	Name      string               `json:"name"`
+	Title     string               `json:"title"`
+	Available bool                 `json:"available"`
+	AuthModes []StoreProviderAuth  `json:"authModes"`
+	Fields    []StoreProviderField `json:"fields"`
+}
+
+type StoreProviderAuth struct {
+	Name   string               `json:"name"`
+	Title  string               `json:"title"`
+	Fields []StoreProviderField `json:"fields"`
+}
+
+type StoreProviderField struct {
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Required bool   `json:"required"`
+	Secret   bool   `json:"secret,omitempty"`
 }
 
 type Backup struct {
@@ -65,6 +300,74 @@ type Backup struct {
 	VolumeBytes        int64      `json:"volumeBytes"`
 	VolumeSizeHuman    string     `json:"volumeSizeHuman"`
 	SupportBundleID    string     `json:"supportBundleId,omitempty"`
+	// FanOutOf is the backup this one was copied from to another storage location
+	FanOutOf string `json:"fanOutOf,omitempty"`
+}
+
+// BackupHistoryEntry is a backup that includes an app, with the app version it was taken at
+type BackupHistoryEntry struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Trigger    string `json:"trigger,omitempty"`
+	IsInstance bool   `json:"isInstance"`
+	// Sequence is the app version the backup restores, -1 if it wasn't recorded
+	Sequence        int64      `json:"sequence"`
+	VersionLabel    string     `json:"versionLabel,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+	VolumeBytes     int64      `json:"volumeBytes"`
+	VolumeSizeHuman string     `json:"volumeSizeHuman"`
+}
+
+// BackupEstimate is how long the next backup of an app is expected to take, based on its recent backups
+type BackupEstimate struct {
+	// SampleSize is how many completed backups the estimate is based on, there is no estimate when it's 0
+	SampleSize       int    `json:"sampleSize"`
+	EstimatedSeconds int64  `json:"estimatedSeconds,omitempty"`
+	EstimatedHuman   string `json:"estimatedHuman,omitempty"`
+	MinSeconds       int64  `json:"minSeconds,omitempty"`
+	MaxSeconds       int64  `json:"maxSeconds,omitempty"`
+	// Confidence is one of none, low, medium or high
+	Confidence string `json:"confidence"`
+}
+
+// ResticVolume is whether restic will back up an app's persistent volume claim
+type ResticVolume struct {
+	Namespace string `json:"namespace"`
+	PVC       string `json:"pvc"`
+	Volume    string `json:"volume,omitempty"`
+	Included  bool   `json:"included"`
+	// Reason is why the volume is skipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// BackupDeletionStatus is how far velero has gotten deleting a backup and removing its data from the store
+type BackupDeletionStatus struct {
+	// Phase is the phase of the velero delete backup request, New, InProgress or Processed
+	Phase string `json:"phase"`
+	Done  bool   `json:"done"`
+	// Errors are why the backup or its data could not be removed, once the deletion is done
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ReconciledBackup is a backup kots created that had lost the annotations kots recognizes it by
+type ReconciledBackup struct {
+	Name string `json:"name"`
+	// Annotations are the annotations that were restored
+	Annotations map[string]string `json:"annotations"`
+}
+
+// ImportedBackup is a backup found in the store, possibly written by kotsadm on another cluster
+type ImportedBackup struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	IsInstance bool       `json:"isInstance"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// AppSlugs are the apps included in the backup, as recorded when it was taken
+	AppSlugs []string `json:"appSlugs"`
+	// AppIDs are the IDs of the installed apps the backup matched, by ID or by slug
+	AppIDs []string `json:"appIds"`
 }
 
 type BackupDetail struct {
@@ -78,6 +381,42 @@ type BackupDetail struct {
 	Warnings        []SnapshotError  `json:"warnings"`
 }
 
+type BackupProgress struct {
+	Name              string `json:"name"`
+	Phase             string `json:"phase"`
+	CompletionPercent int    `json:"completionPercent"`
+	TotalItems        int    `json:"totalItems"`
+	ItemsBackedUp     int    `json:"itemsBackedUp"`
+	VolumeTotalBytes  int64  `json:"volumeTotalBytes"`
+	VolumeBytesDone   int64  `json:"volumeBytesDone"`
+}
+
+// BackupComparison lists the resources that differ between two backups. Restoring the older backup reverts them.
+type BackupComparison struct {
+	FromBackup string           `json:"fromBackup"`
+	ToBackup   string           `json:"toBackup"`
+	Added      []BackupResource `json:"added"`
+	Removed    []BackupResource `json:"removed"`
+	Changed    []BackupResource `json:"changed"`
+}
+
+type BackupResource struct {
+	GroupResource string `json:"groupResource"`
+	Namespace     string `json:"namespace,omitempty"`
+	Name          string `json:"name"`
+}
+
+type RestoreEstimate struct {
+	BackupName       string `json:"backupName"`
+	VolumeCount      int    `json:"volumeCount"`
+	ResourceCount    int    `json:"resourceCount"`
+	VolumeSizeBytes  int64  `json:"volumeSizeBytes"`
+	VolumeSizeHuman  string `json:"volumeSizeHuman"`
+	BytesPerSecond   int64  `json:"bytesPerSecond"`
+	FromHistory      bool   `json:"fromHistory"` // false when no previous restores were found and the default throughput was used
+	EstimatedSeconds int    `json:"estimatedSeconds"`
+}
+
 type RestoreDetail struct {
 	Name     string          `json:"name"`
 	Phase    string          `json:"phase"`
@@ -143,6 +482,13 @@ type SnapshotSchedule struct {
 	Schedule string `json:"schedule"`
 }
 
+type BackupGapRisk struct {
+	LatestBackupName      string     `json:"latestBackupName,omitempty"`
+	LatestBackupExpiresAt *time.Time `json:"latestBackupExpiresAt,omitempty"`
+	NextScheduledBackupAt time.Time  `json:"nextScheduledBackupAt"`
+	Message               string     `json:"message"`
+}
+
 type SnapshotTTL struct {
 	InputValue    string `json:"inputValue"`
 	InputTimeUnit string `json:"inputTimeUnit"`
@@ -154,10 +500,18 @@ type ParsedTTL struct {
 	Unit     string `json:"unit"`
 }
 
+type TTLConversion struct {
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Exact    bool    `json:"exact"`
+}
+
 type ScheduledSnapshot struct {
 	ID                 string    `json:"id"`
 	AppID              string    `json:"appId"`
 	ScheduledTimestamp time.Time `json:"scheduledTimestamp"`
+	// set when the snapshot was due but held back by the max concurrent scheduled snapshots limit
+	QueuedAt *time.Time `json:"queuedAt,omitempty"`
 	// name of Backup CR will be set once scheduled
 	BackupName string `json:"backupName,omitempty"`
 }
@@ -169,3 +523,29 @@ type ScheduledInstanceSnapshot struct {
 	// name of Backup CR will be set once scheduled
 	BackupName string `json:"backupName,omitempty"`
 }
+
+// SnapshotAuditEvent records a change someone made to the snapshot settings
+type SnapshotAuditEvent struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Actor is the user id of the session that made the change, "kots-cli" for the kots CLI
+	Actor   string                `json:"actor"`
+	Action  string                `json:"action"`
+	AppID   string                `json:"appId,omitempty"`
+	Changes []SnapshotAuditChange `json:"changes"`
+}
+
+// SnapshotAuditChange is a single changed setting. Credentials are recorded as changed but their values are redacted.
+type SnapshotAuditChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+const (
+	SnapshotAuditActionUpdateGlobalSettings = "update-global-settings"
+	SnapshotAuditActionSaveAppConfig        = "save-app-config"
+	SnapshotAuditActionSaveInstanceConfig   = "save-instance-config"
+	SnapshotAuditActionRefreshInternalStore = "refresh-internal-store"
+	SnapshotAuditActionSetLocationReadOnly  = "set-location-read-only"
+)
@@ -2,20 +2,47 @@ package snapshot
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/replicatedhq/kots/kotsadm/pkg/k8s"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
 	"github.com/replicatedhq/kots/pkg/k8sutil"
 	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	kuberneteserrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
+const (
+	defaultVolumesToResticFlag = "--default-volumes-to-restic"
+	metricsAddressFlag         = "--metrics-address"
+	defaultVeleroMetricsPort   = 8085
+
+	storeValidationFrequencyFlag    = "--store-validation-frequency"
+	defaultStoreValidationFrequency = time.Minute
+
+	restoreResourcePrioritiesFlag = "--restore-resource-priorities"
+
+	terminatingResourceTimeoutFlag    = "--terminating-resource-timeout"
+	defaultTerminatingResourceTimeout = 10 * time.Minute
+
+	itemOperationTimeoutFlag    = "--item-operation-timeout"
+	defaultItemOperationTimeout = 4 * time.Hour
+
+	backupSyncPeriodFlag    = "--backup-sync-period"
+	defaultBackupSyncPeriod = time.Minute
+)
+
 var (
 	dockerImageNameRegex = regexp.MustCompile("(?:([^\\/]+)\\/)?(?:([^\\/]+)\\/)?([^@:\\/]+)(?:[@:](.+))")
 )
@@ -27,6 +54,29 @@ type VeleroStatus struct {
 
 	ResticVersion string
 	ResticStatus  string
+
+	// DefaultVolumesToRestic is true when the velero server backs up every pod volume with restic unless
+	// pods opt out with the backup.velero.io/backup-volumes-excludes annotation
+	DefaultVolumesToRestic bool
+	MetricsPort            int
+	PriorityClassName      string
+	// ServiceAccountName is the service account the velero pods run under
+	ServiceAccountName string
+	// StoreValidationFrequency is how often velero validates backup storage locations that don't set their own frequency
+	StoreValidationFrequency time.Duration
+	// RestoreResourcePriorities is the order resources are restored in, empty if velero's default order is used
+	RestoreResourcePriorities []string
+	// ProfilerEnabled is true when velero's pprof endpoint is reachable from outside the velero pod
+	ProfilerEnabled bool
+	// TerminatingResourceTimeout is how long restores wait for terminating resources to be deleted before giving up
+	TerminatingResourceTimeout time.Duration
+	// ItemOperationTimeout is how long velero waits for asynchronous item operations, e.g. data movement, before
+	// cancelling them. Only velero 1.11 and later have the flag.
+	ItemOperationTimeout time.Duration
+	// ResticHostPodsPath is the kubelet pods directory on the nodes restic reads pod volumes from
+	ResticHostPodsPath string
+	// AWSPluginVersion is the image tag of the velero aws plugin, empty if it isn't installed
+	AWSPluginVersion string
 }
 
 func CheckKotsadmVeleroAccess() (requiresAccess bool, veleroNamespace string, finalErr error) {
@@ -129,6 +179,9 @@ func DetectVelero() (*VeleroStatus, error) {
 			// the default installation is to name these like "velero-plugin-for-aws"
 			veleroStatus.Plugins = append(veleroStatus.Plugins, initContainer.Name)
 		}
+		if version := getAWSPluginVersion(deployment.Spec.Template.Spec.InitContainers); version != "" {
+			veleroStatus.AWSPluginVersion = version
+		}
 
 		matches := dockerImageNameRegex.FindStringSubmatch(deployment.Spec.Template.Spec.Containers[0].Image)
 		if len(matches) == 5 {
@@ -140,6 +193,15 @@ func DetectVelero() (*VeleroStatus, error) {
 
 			veleroStatus.Version = matches[4]
 			veleroStatus.Status = status
+			veleroStatus.DefaultVolumesToRestic = hasDefaultVolumesToResticArg(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.MetricsPort = getMetricsPortArg(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.PriorityClassName = deployment.Spec.Template.Spec.PriorityClassName
+			veleroStatus.ServiceAccountName = deployment.Spec.Template.Spec.ServiceAccountName
+			veleroStatus.StoreValidationFrequency = getStoreValidationFrequencyArg(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.RestoreResourcePriorities = getRestoreResourcePrioritiesArg(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.ProfilerEnabled = isProfilerExposed(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.TerminatingResourceTimeout = getTerminatingResourceTimeoutArg(deployment.Spec.Template.Spec.Containers[0].Args)
+			veleroStatus.ItemOperationTimeout = getItemOperationTimeoutArg(deployment.Spec.Template.Spec.Containers[0].Args)
 
 			goto DeploymentFound
 		}
@@ -163,6 +225,7 @@ DeploymentFound:
 
 			veleroStatus.ResticVersion = matches[4]
 			veleroStatus.ResticStatus = status
+			veleroStatus.ResticHostPodsPath = getResticHostPodsPath(daemonset.Spec.Template.Spec)
 
 			goto ResticFound
 		}
@@ -273,3 +336,797 @@ func RestartVelero() error {
 
 	return nil
 }
+
+// setVeleroServiceAccountAnnotation sets (or removes when value is empty) an annotation on the service accounts
+// used by the velero deployments in the namespace
+func setVeleroServiceAccountAnnotation(clientset *kubernetes.Clientset, namespace string, key string, value string) error {
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	serviceAccountNames := map[string]bool{}
+	for _, veleroDeployment := range veleroDeployments {
+		serviceAccountName := veleroDeployment.Spec.Template.Spec.ServiceAccountName
+		if serviceAccountName == "" {
+			serviceAccountName = "default"
+		}
+		serviceAccountNames[serviceAccountName] = true
+	}
+
+	for serviceAccountName := range serviceAccountNames {
+		serviceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), serviceAccountName, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get service account %s", serviceAccountName)
+		}
+
+		if serviceAccount.Annotations[key] == value {
+			continue
+		}
+		if value == "" {
+			delete(serviceAccount.Annotations, key)
+		} else {
+			if serviceAccount.Annotations == nil {
+				serviceAccount.Annotations = map[string]string{}
+			}
+			serviceAccount.Annotations[key] = value
+		}
+
+		if _, err := clientset.CoreV1().ServiceAccounts(namespace).Update(context.TODO(), serviceAccount, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update service account %s", serviceAccountName)
+		}
+	}
+
+	return nil
+}
+
+// SetVeleroDefaultVolumesToRestic sets the velero server flag that decides whether pod volumes are backed up with
+// restic by default. Per-pod annotations still override the default in either direction: pods can opt out with
+// backup.velero.io/backup-volumes-excludes, or opt in with backup.velero.io/backup-volumes.
+func SetVeleroDefaultVolumesToRestic(enabled bool) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		if hasDefaultVolumesToResticArg(container.Args) == enabled {
+			continue
+		}
+		container.Args = setFlagArg(container.Args, defaultVolumesToResticFlag, strconv.FormatBool(enabled))
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+func hasDefaultVolumesToResticArg(args []string) bool {
+	enabled := false
+	for _, arg := range args {
+		if arg == defaultVolumesToResticFlag {
+			enabled = true
+		} else if strings.HasPrefix(arg, defaultVolumesToResticFlag+"=") {
+			value, err := strconv.ParseBool(strings.TrimPrefix(arg, defaultVolumesToResticFlag+"="))
+			enabled = err == nil && value
+		}
+	}
+	return enabled
+}
+
+// setFlagArg replaces any occurrence of the flag in the server args with flag=value
+func setFlagArg(args []string, flag string, value string) []string {
+	return append(removeFlagArg(args, flag), fmt.Sprintf("%s=%s", flag, value))
+}
+
+func removeFlagArg(args []string, flag string) []string {
+	updated := []string{}
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			continue
+		}
+		updated = append(updated, arg)
+	}
+	return updated
+}
+
+// SetVeleroMetricsPort moves the velero server metrics endpoint to the port and adds the prometheus scrape
+// annotations to the velero pods so it is discovered by annotation-based scrape configs
+func SetVeleroMetricsPort(port int) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		container.Args = setFlagArg(container.Args, metricsAddressFlag, fmt.Sprintf(":%d", port))
+
+		hasMetricsPort := false
+		for i := range container.Ports {
+			if container.Ports[i].Name == "metrics" {
+				container.Ports[i].ContainerPort = int32(port)
+				hasMetricsPort = true
+			}
+		}
+		if !hasMetricsPort {
+			container.Ports = append(container.Ports, corev1.ContainerPort{
+				Name:          "metrics",
+				ContainerPort: int32(port),
+			})
+		}
+
+		if veleroDeployment.Spec.Template.Annotations == nil {
+			veleroDeployment.Spec.Template.Annotations = map[string]string{}
+		}
+		veleroDeployment.Spec.Template.Annotations["prometheus.io/scrape"] = "true"
+		veleroDeployment.Spec.Template.Annotations["prometheus.io/port"] = strconv.Itoa(port)
+		veleroDeployment.Spec.Template.Annotations["prometheus.io/path"] = "/metrics"
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+func getMetricsPortArg(args []string) int {
+	port := defaultVeleroMetricsPort
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, metricsAddressFlag+"=") {
+			continue
+		}
+		address := strings.TrimPrefix(arg, metricsAddressFlag+"=")
+		parsed, err := strconv.Atoi(address[strings.LastIndex(address, ":")+1:])
+		if err == nil {
+			port = parsed
+		}
+	}
+	return port
+}
+
+func getStoreValidationFrequencyArg(args []string) time.Duration {
+	frequency := defaultStoreValidationFrequency
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, storeValidationFrequencyFlag+"=") {
+			continue
+		}
+		parsed, err := time.ParseDuration(strings.TrimPrefix(arg, storeValidationFrequencyFlag+"="))
+		if err == nil {
+			frequency = parsed
+		}
+	}
+	return frequency
+}
+
+// SetVeleroStoreValidationFrequency sets the default frequency at which the velero server validates backup
+// storage locations. A frequency of 0 disables validation.
+func SetVeleroStoreValidationFrequency(frequency time.Duration) error {
+	if frequency < 0 {
+		return errors.New("store validation frequency cannot be negative")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		container.Args = setFlagArg(container.Args, storeValidationFrequencyFlag, frequency.String())
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+func getTerminatingResourceTimeoutArg(args []string) time.Duration {
+	timeout := defaultTerminatingResourceTimeout
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, terminatingResourceTimeoutFlag+"=") {
+			continue
+		}
+		parsed, err := time.ParseDuration(strings.TrimPrefix(arg, terminatingResourceTimeoutFlag+"="))
+		if err == nil {
+			timeout = parsed
+		}
+	}
+	return timeout
+}
+
+func getBackupSyncPeriodArg(args []string) time.Duration {
+	period := defaultBackupSyncPeriod
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, backupSyncPeriodFlag+"=") {
+			continue
+		}
+		parsed, err := time.ParseDuration(strings.TrimPrefix(arg, backupSyncPeriodFlag+"="))
+		if err == nil {
+			period = parsed
+		}
+	}
+	return period
+}
+
+// getVeleroBackupSyncPeriod returns how often the velero server syncs backups from the backup storage locations
+func getVeleroBackupSyncPeriod(clientset *kubernetes.Clientset, namespace string) (time.Duration, error) {
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list velero deployments")
+	}
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		return getBackupSyncPeriodArg(veleroDeployment.Spec.Template.Spec.Containers[0].Args), nil
+	}
+	return defaultBackupSyncPeriod, nil
+}
+
+// SetVeleroTerminatingResourceTimeout sets how long the velero server waits during restores for namespaces and
+// persistent volumes that are still terminating from a previous install to be deleted
+func SetVeleroTerminatingResourceTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return errors.New("terminating resource timeout must be positive")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		container.Args = setFlagArg(container.Args, terminatingResourceTimeoutFlag, timeout.String())
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+// getAWSPluginVersion returns the tag of the velero aws plugin image among the velero init containers
+func getAWSPluginVersion(initContainers []corev1.Container) string {
+	for _, initContainer := range initContainers {
+		matches := dockerImageNameRegex.FindStringSubmatch(initContainer.Image)
+		if len(matches) == 5 && matches[3] == "velero-plugin-for-aws" {
+			return matches[4]
+		}
+	}
+	return ""
+}
+
+// SupportsItemOperationTimeout returns true if the velero server version has the item operation timeout flag,
+// older servers fail to start with it
+func SupportsItemOperationTimeout(veleroVersion string) bool {
+	major, minor, ok := parseVeleroVersion(veleroVersion)
+	if !ok {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= 11)
+}
+
+func getItemOperationTimeoutArg(args []string) time.Duration {
+	timeout := defaultItemOperationTimeout
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, itemOperationTimeoutFlag+"=") {
+			continue
+		}
+		parsed, err := time.ParseDuration(strings.TrimPrefix(arg, itemOperationTimeoutFlag+"="))
+		if err == nil {
+			timeout = parsed
+		}
+	}
+	return timeout
+}
+
+// SetVeleroItemOperationTimeout sets how long the velero server lets asynchronous item operations, e.g. data
+// movement and csi snapshots, run before cancelling them. Updating the deployment restarts velero.
+func SetVeleroItemOperationTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return errors.New("item operation timeout must be positive")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		container.Args = setFlagArg(container.Args, itemOperationTimeoutFlag, timeout.String())
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+func getRestoreResourcePrioritiesArg(args []string) []string {
+	priorities := []string{}
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, restoreResourcePrioritiesFlag+"=") {
+			continue
+		}
+		priorities = []string{}
+		for _, resource := range strings.Split(strings.TrimPrefix(arg, restoreResourcePrioritiesFlag+"="), ",") {
+			if resource = strings.TrimSpace(resource); resource != "" {
+				priorities = append(priorities, resource)
+			}
+		}
+	}
+	return priorities
+}
+
+// ValidateRestoreResourcePriorities checks that the priorities are resource names, optionally qualified with
+// their api group (e.g. "customresourcedefinitions" or "widgets.example.com"), and that none are repeated
+func ValidateRestoreResourcePriorities(priorities []string) error {
+	seen := map[string]bool{}
+	for _, resource := range priorities {
+		if errs := validation.IsDNS1123Subdomain(resource); len(errs) > 0 {
+			return errors.Errorf("invalid resource %q: %s", resource, strings.Join(errs, ", "))
+		}
+		if seen[resource] {
+			return errors.Errorf("resource %q is listed more than once", resource)
+		}
+		seen[resource] = true
+	}
+	return nil
+}
+
+// SetVeleroRestoreResourcePriorities sets the order the velero server restores resources in, so resources
+// are restored before the resources that depend on them. Empty priorities restore velero's default order.
+func SetVeleroRestoreResourcePriorities(priorities []string) error {
+	if err := ValidateRestoreResourcePriorities(priorities); err != nil {
+		return err
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if len(veleroDeployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &veleroDeployment.Spec.Template.Spec.Containers[0]
+		if len(priorities) == 0 {
+			container.Args = removeFlagArg(container.Args, restoreResourcePrioritiesFlag)
+		} else {
+			container.Args = setFlagArg(container.Args, restoreResourcePrioritiesFlag, strings.Join(priorities, ","))
+		}
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateVeleroPriorityClass checks that the priority class exists, velero pods would otherwise be rejected
+// by the priority admission plugin
+func ValidateVeleroPriorityClass(ctx context.Context, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	clientset, err := k8s.Clientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	_, err = clientset.SchedulingV1().PriorityClasses().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return errors.Errorf("priority class %q not found", name)
+		}
+		return errors.Wrap(err, "failed to get priority class")
+	}
+
+	return nil
+}
+
+// SetVeleroPriorityClass sets the priority class of the velero deployment and restic daemonset pods so they
+// aren't the first to be evicted under node pressure. An empty name removes the priority class.
+func SetVeleroPriorityClass(name string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if veleroDeployment.Spec.Template.Spec.PriorityClassName == name {
+			continue
+		}
+		veleroDeployment.Spec.Template.Spec.PriorityClassName = name
+		// the admission plugin resolves the priority from the class name
+		veleroDeployment.Spec.Template.Spec.Priority = nil
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(context.TODO(), &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	resticDaemonsets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list restic daemonsets")
+	}
+
+	for _, resticDaemonset := range resticDaemonsets {
+		if resticDaemonset.Spec.Template.Spec.PriorityClassName == name {
+			continue
+		}
+		resticDaemonset.Spec.Template.Spec.PriorityClassName = name
+		resticDaemonset.Spec.Template.Spec.Priority = nil
+
+		if _, err := clientset.AppsV1().DaemonSets(namespace).Update(context.TODO(), &resticDaemonset, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update restic daemonset %s", resticDaemonset.Name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateVeleroServiceAccount checks that the service account exists in the velero namespace, velero pods
+// would otherwise fail to be created
+func ValidateVeleroServiceAccount(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("service account name is required")
+	}
+
+	clientset, err := k8s.Clientset()
+	if err != nil {
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	_, err = clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if kuberneteserrors.IsNotFound(err) {
+			return errors.Errorf("service account %q not found in namespace %s", name, namespace)
+		}
+		return errors.Wrap(err, "failed to get service account")
+	}
+
+	return nil
+}
+
+// SetVeleroServiceAccount runs the velero deployment and restic daemonset pods under an existing service account,
+// e.g. one bound to the cluster's policies. The image pull secrets of the service accounts the pods used before,
+// and the kots registry pull secret if there is one, are added to it so that the images can still be pulled.
+func SetVeleroServiceAccount(ctx context.Context, name string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return errors.Wrap(err, "failed to detect velero namespace")
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list velero deployments")
+	}
+	resticDaemonsets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return errors.Wrap(err, "failed to list restic daemonsets")
+	}
+
+	previousServiceAccountNames := []string{}
+	for _, veleroDeployment := range veleroDeployments {
+		previousServiceAccountNames = append(previousServiceAccountNames, veleroDeployment.Spec.Template.Spec.ServiceAccountName)
+	}
+	for _, resticDaemonset := range resticDaemonsets {
+		previousServiceAccountNames = append(previousServiceAccountNames, resticDaemonset.Spec.Template.Spec.ServiceAccountName)
+	}
+
+	if err := wireVeleroServiceAccountPullSecrets(ctx, clientset, namespace, name, previousServiceAccountNames); err != nil {
+		return errors.Wrap(err, "failed to add image pull secrets to service account")
+	}
+
+	for _, veleroDeployment := range veleroDeployments {
+		if veleroDeployment.Spec.Template.Spec.ServiceAccountName == name {
+			continue
+		}
+		veleroDeployment.Spec.Template.Spec.ServiceAccountName = name
+		// the deprecated field would otherwise win if it still names the old service account
+		veleroDeployment.Spec.Template.Spec.DeprecatedServiceAccount = ""
+
+		if _, err := clientset.AppsV1().Deployments(namespace).Update(ctx, &veleroDeployment, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update velero deployment %s", veleroDeployment.Name)
+		}
+	}
+
+	for _, resticDaemonset := range resticDaemonsets {
+		if resticDaemonset.Spec.Template.Spec.ServiceAccountName == name {
+			continue
+		}
+		resticDaemonset.Spec.Template.Spec.ServiceAccountName = name
+		resticDaemonset.Spec.Template.Spec.DeprecatedServiceAccount = ""
+
+		if _, err := clientset.AppsV1().DaemonSets(namespace).Update(ctx, &resticDaemonset, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to update restic daemonset %s", resticDaemonset.Name)
+		}
+	}
+
+	return nil
+}
+
+func wireVeleroServiceAccountPullSecrets(ctx context.Context, clientset *kubernetes.Clientset, namespace string, name string, previousServiceAccountNames []string) error {
+	pullSecrets := []corev1.LocalObjectReference{}
+	for _, previousServiceAccountName := range previousServiceAccountNames {
+		if previousServiceAccountName == "" || previousServiceAccountName == name {
+			continue
+		}
+		previousServiceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, previousServiceAccountName, metav1.GetOptions{})
+		if kuberneteserrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get service account %s", previousServiceAccountName)
+		}
+		pullSecrets = append(pullSecrets, previousServiceAccount.ImagePullSecrets...)
+	}
+
+	_, err := clientset.CoreV1().Secrets(namespace).Get(ctx, veleroRegistryPullSecretName, metav1.GetOptions{})
+	if err == nil {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: veleroRegistryPullSecretName})
+	} else if !kuberneteserrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed to get registry pull secret")
+	}
+
+	serviceAccount, err := clientset.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get service account %s", name)
+	}
+
+	if !addImagePullSecrets(serviceAccount, pullSecrets) {
+		return nil
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts(namespace).Update(ctx, serviceAccount, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update service account %s", name)
+	}
+
+	return nil
+}
+
+// addImagePullSecrets adds the pull secrets the service account doesn't list yet. It returns true if any were added.
+func addImagePullSecrets(serviceAccount *corev1.ServiceAccount, pullSecrets []corev1.LocalObjectReference) bool {
+	added := false
+	for _, pullSecret := range pullSecrets {
+		found := false
+		for _, existing := range serviceAccount.ImagePullSecrets {
+			if existing.Name == pullSecret.Name {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		serviceAccount.ImagePullSecrets = append(serviceAccount.ImagePullSecrets, pullSecret)
+		added = true
+	}
+	return added
+}
+
+// GetVeleroImages returns the image references velero is running with, including the restic restore helper
+// that velero injects into restored pods. When no plugin config overrides the helper image, velero pulls it
+// from docker hub with the same version as the server, which is a common cause of ImagePullBackOff in airgap.
+func GetVeleroImages(ctx context.Context) (*types.VeleroImages, error) {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	namespace, err := DetectVeleroNamespace()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect velero namespace")
+	}
+	if namespace == "" {
+		return nil, errors.New("velero not found")
+	}
+
+	veleroImages := &types.VeleroImages{
+		Plugins: []string{},
+	}
+
+	veleroDeployments, err := listPossibleVeleroDeployments(clientset, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list velero deployments")
+	}
+	for _, deployment := range veleroDeployments {
+		if len(deployment.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		veleroImages.Velero = deployment.Spec.Template.Spec.Containers[0].Image
+		for _, initContainer := range deployment.Spec.Template.Spec.InitContainers {
+			veleroImages.Plugins = append(veleroImages.Plugins, initContainer.Image)
+		}
+		break
+	}
+
+	resticDaemonSets, err := listPossibleResticDaemonsets(clientset, namespace)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list restic daemonsets")
+	}
+	for _, daemonSet := range resticDaemonSets {
+		if len(daemonSet.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		veleroImages.Restic = daemonSet.Spec.Template.Spec.Containers[0].Image
+		break
+	}
+
+	// velero reads the helper image from the restic restore item action plugin config
+	configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "velero.io/plugin-config,velero.io/restic=RestoreItemAction",
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list restic plugin config")
+	}
+	for _, configMap := range configMaps.Items {
+		if image := configMap.Data["image"]; image != "" {
+			veleroImages.ResticRestoreHelper = image
+			break
+		}
+	}
+
+	if veleroImages.ResticRestoreHelper == "" {
+		veleroImages.ResticRestoreHelperIsDefault = true
+		matches := dockerImageNameRegex.FindStringSubmatch(veleroImages.Velero)
+		if len(matches) == 5 {
+			veleroImages.ResticRestoreHelper = fmt.Sprintf("velero/velero-restic-restore-helper:%s", matches[4])
+		}
+	}
+
+	return veleroImages, nil
+}
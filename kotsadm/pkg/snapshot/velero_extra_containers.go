@@ -0,0 +1,180 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	"github.com/replicatedhq/kots/kotsadm/pkg/store"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// veleroExtraContainersAnnotation records the names of the init containers, volumes and volume mounts kots added
+// to the velero deployment, so they can be replaced without touching the ones velero was installed with
+const veleroExtraContainersAnnotation = "kots.io/velero-extra-containers"
+
+type veleroExtraContainerNames struct {
+	InitContainers []string `json:"initContainers,omitempty"`
+	Volumes        []string `json:"volumes,omitempty"`
+	VolumeMounts   []string `json:"volumeMounts,omitempty"`
+}
+
+// ValidateVeleroExtraContainers checks the init containers, volumes and volume mounts to add to the velero deployment.
+// Mounts can only use the extra volumes, the volumes velero is installed with differ between installers.
+func ValidateVeleroExtraContainers(extra *types.VeleroExtraContainers) error {
+	volumeNames := map[string]bool{}
+	for _, volume := range extra.Volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return errors.Errorf("invalid volume name %q: %s", volume.Name, strings.Join(errs, ", "))
+		}
+		if volumeNames[volume.Name] {
+			return errors.Errorf("volume %q is listed more than once", volume.Name)
+		}
+		volumeNames[volume.Name] = true
+	}
+
+	validateVolumeMounts := func(volumeMounts []corev1.VolumeMount) error {
+		for _, volumeMount := range volumeMounts {
+			if !volumeNames[volumeMount.Name] {
+				return errors.Errorf("volume mount %q does not match an extra volume", volumeMount.Name)
+			}
+			if !path.IsAbs(volumeMount.MountPath) {
+				return errors.Errorf("mount path %q of volume %q is not absolute", volumeMount.MountPath, volumeMount.Name)
+			}
+		}
+		return nil
+	}
+
+	containerNames := map[string]bool{}
+	for _, container := range extra.InitContainers {
+		if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+			return errors.Errorf("invalid init container name %q: %s", container.Name, strings.Join(errs, ", "))
+		}
+		if containerNames[container.Name] {
+			return errors.Errorf("init container %q is listed more than once", container.Name)
+		}
+		containerNames[container.Name] = true
+
+		if container.Image == "" {
+			return errors.Errorf("init container %q has no image", container.Name)
+		}
+		if err := validateVolumeMounts(container.VolumeMounts); err != nil {
+			return errors.Wrapf(err, "init container %q", container.Name)
+		}
+	}
+
+	if err := validateVolumeMounts(extra.VolumeMounts); err != nil {
+		return errors.Wrap(err, "velero container")
+	}
+
+	return nil
+}
+
+// GetVeleroExtraContainers returns the init containers, volumes and volume mounts kots keeps on the velero deployment
+func GetVeleroExtraContainers() (*types.VeleroExtraContainers, error) {
+	extra, err := store.GetStore().GetVeleroExtraContainers()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get velero extra containers")
+	}
+	return extra, nil
+}
+
+// SetVeleroExtraContainers saves the extra init containers, volumes and volume mounts and applies them to the velero
+// deployment. They are put back by the velero reconciler when an upgrade of velero removes them.
+func SetVeleroExtraContainers(ctx context.Context, extra *types.VeleroExtraContainers) error {
+	if err := store.GetStore().SetVeleroExtraContainers(extra); err != nil {
+		return errors.Wrap(err, "failed to save velero extra containers")
+	}
+
+	if err := ReconcileVelero(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile velero")
+	}
+
+	return nil
+}
+
+// applyVeleroExtraContainers replaces what kots added to the velero deployment before with the extra init containers,
+// volumes and volume mounts. The extra init containers run after the plugin init containers.
+func applyVeleroExtraContainers(deployment *appsv1.Deployment, extra *types.VeleroExtraContainers) error {
+	podSpec := &deployment.Spec.Template.Spec
+	if len(podSpec.Containers) == 0 {
+		return errors.New("velero deployment has no containers")
+	}
+
+	previous := veleroExtraContainerNames{}
+	if value := deployment.Annotations[veleroExtraContainersAnnotation]; value != "" {
+		if err := json.Unmarshal([]byte(value), &previous); err != nil {
+			return errors.Wrapf(err, "failed to parse %s annotation", veleroExtraContainersAnnotation)
+		}
+	}
+
+	var initContainers []corev1.Container
+	for _, container := range podSpec.InitContainers {
+		if !containsString(previous.InitContainers, container.Name) {
+			initContainers = append(initContainers, container)
+		}
+	}
+	var volumes []corev1.Volume
+	for _, volume := range podSpec.Volumes {
+		if !containsString(previous.Volumes, volume.Name) {
+			volumes = append(volumes, volume)
+		}
+	}
+	var volumeMounts []corev1.VolumeMount
+	for _, volumeMount := range podSpec.Containers[0].VolumeMounts {
+		if !containsString(previous.VolumeMounts, volumeMount.Name) {
+			volumeMounts = append(volumeMounts, volumeMount)
+		}
+	}
+
+	applied := veleroExtraContainerNames{}
+	if extra != nil {
+		for _, container := range extra.InitContainers {
+			for _, existing := range initContainers {
+				if existing.Name == container.Name {
+					return errors.Errorf("velero deployment already has an init container named %q", container.Name)
+				}
+			}
+			initContainers = append(initContainers, container)
+			applied.InitContainers = append(applied.InitContainers, container.Name)
+		}
+		for _, volume := range extra.Volumes {
+			for _, existing := range volumes {
+				if existing.Name == volume.Name {
+					return errors.Errorf("velero deployment already has a volume named %q", volume.Name)
+				}
+			}
+			volumes = append(volumes, volume)
+			applied.Volumes = append(applied.Volumes, volume.Name)
+		}
+		for _, volumeMount := range extra.VolumeMounts {
+			volumeMounts = append(volumeMounts, volumeMount)
+			applied.VolumeMounts = append(applied.VolumeMounts, volumeMount.Name)
+		}
+	}
+
+	podSpec.InitContainers = initContainers
+	podSpec.Volumes = volumes
+	podSpec.Containers[0].VolumeMounts = volumeMounts
+
+	if len(applied.InitContainers) == 0 && len(applied.Volumes) == 0 && len(applied.VolumeMounts) == 0 {
+		delete(deployment.Annotations, veleroExtraContainersAnnotation)
+		return nil
+	}
+
+	value, err := json.Marshal(applied)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal applied names")
+	}
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[veleroExtraContainersAnnotation] = string(value)
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateVeleroExtraContainers(t *testing.T) {
+	caVolume := corev1.Volume{Name: "ca-bundle"}
+
+	tests := []struct {
+		name    string
+		extra   types.VeleroExtraContainers
+		wantErr bool
+	}{
+		{
+			name: "ca bundle",
+			extra: types.VeleroExtraContainers{
+				InitContainers: []corev1.Container{{
+					Name:         "ca-bundle",
+					Image:        "corp/ca-bundle:1",
+					VolumeMounts: []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/out"}},
+				}},
+				Volumes:      []corev1.Volume{caVolume},
+				VolumeMounts: []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "/etc/ssl/certs"}},
+			},
+		},
+		{
+			name: "no image",
+			extra: types.VeleroExtraContainers{
+				InitContainers: []corev1.Container{{Name: "ca-bundle"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name",
+			extra: types.VeleroExtraContainers{
+				InitContainers: []corev1.Container{{Name: "CA_Bundle", Image: "corp/ca-bundle:1"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mount of a volume that isn't added",
+			extra: types.VeleroExtraContainers{
+				VolumeMounts: []corev1.VolumeMount{{Name: "plugins", MountPath: "/plugins"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative mount path",
+			extra: types.VeleroExtraContainers{
+				Volumes:      []corev1.Volume{caVolume},
+				VolumeMounts: []corev1.VolumeMount{{Name: "ca-bundle", MountPath: "certs"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate volume",
+			extra: types.VeleroExtraContainers{
+				Volumes: []corev1.Volume{caVolume, caVolume},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateVeleroExtraContainers(&test.extra)
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateVeleroExtraContainers() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyVeleroExtraContainers(t *testing.T) {
+	pluginContainer := corev1.Container{Name: "velero-plugin-for-aws", Image: "velero/velero-plugin-for-aws:v1.1.0"}
+	pluginsVolume := corev1.Volume{Name: "plugins"}
+	pluginsMount := corev1.VolumeMount{Name: "plugins", MountPath: "/plugins"}
+
+	caContainer := corev1.Container{Name: "ca-bundle", Image: "corp/ca-bundle:1"}
+	caVolume := corev1.Volume{Name: "ca-bundle"}
+	caMount := corev1.VolumeMount{Name: "ca-bundle", MountPath: "/etc/ssl/certs"}
+	caExtra := &types.VeleroExtraContainers{
+		InitContainers: []corev1.Container{caContainer},
+		Volumes:        []corev1.Volume{caVolume},
+		VolumeMounts:   []corev1.VolumeMount{caMount},
+	}
+	caAnnotation := `{"initContainers":["ca-bundle"],"volumes":["ca-bundle"],"volumeMounts":["ca-bundle"]}`
+
+	deployment := func(annotations map[string]string, initContainers []corev1.Container, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) appsv1.Deployment {
+		return appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						InitContainers: initContainers,
+						Volumes:        volumes,
+						Containers:     []corev1.Container{{Name: "velero", VolumeMounts: volumeMounts}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		deployment appsv1.Deployment
+		extra      *types.VeleroExtraContainers
+		want       appsv1.Deployment
+		wantErr    bool
+	}{
+		{
+			name:       "added after the plugins",
+			deployment: deployment(nil, []corev1.Container{pluginContainer}, []corev1.Volume{pluginsVolume}, []corev1.VolumeMount{pluginsMount}),
+			extra:      caExtra,
+			want: deployment(map[string]string{veleroExtraContainersAnnotation: caAnnotation},
+				[]corev1.Container{pluginContainer, caContainer},
+				[]corev1.Volume{pluginsVolume, caVolume},
+				[]corev1.VolumeMount{pluginsMount, caMount}),
+		},
+		{
+			name: "already applied",
+			deployment: deployment(map[string]string{veleroExtraContainersAnnotation: caAnnotation},
+				[]corev1.Container{pluginContainer, caContainer},
+				[]corev1.Volume{pluginsVolume, caVolume},
+				[]corev1.VolumeMount{pluginsMount, caMount}),
+			extra: caExtra,
+			want: deployment(map[string]string{veleroExtraContainersAnnotation: caAnnotation},
+				[]corev1.Container{pluginContainer, caContainer},
+				[]corev1.Volume{pluginsVolume, caVolume},
+				[]corev1.VolumeMount{pluginsMount, caMount}),
+		},
+		{
+			name: "removed",
+			deployment: deployment(map[string]string{veleroExtraContainersAnnotation: caAnnotation},
+				[]corev1.Container{pluginContainer, caContainer},
+				[]corev1.Volume{pluginsVolume, caVolume},
+				[]corev1.VolumeMount{pluginsMount, caMount}),
+			extra: &types.VeleroExtraContainers{},
+			want:  deployment(map[string]string{}, []corev1.Container{pluginContainer}, []corev1.Volume{pluginsVolume}, []corev1.VolumeMount{pluginsMount}),
+		},
+		{
+			name:       "name taken by the install",
+			deployment: deployment(nil, []corev1.Container{pluginContainer}, nil, nil),
+			extra: &types.VeleroExtraContainers{
+				InitContainers: []corev1.Container{{Name: "velero-plugin-for-aws", Image: "corp/ca-bundle:1"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.deployment
+			err := applyVeleroExtraContainers(&got, test.extra)
+			if test.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %#v, got %#v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHasDefaultVolumesToResticArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"server"}, false},
+		{[]string{"server", "--default-volumes-to-restic"}, true},
+		{[]string{"server", "--default-volumes-to-restic=true"}, true},
+		{[]string{"server", "--default-volumes-to-restic=false"}, false},
+		{[]string{"server", "--default-volumes-to-restic", "--default-volumes-to-restic=false"}, false},
+	}
+	for _, test := range tests {
+		got := hasDefaultVolumesToResticArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %v for %v, got %v", test.want, test.args, got)
+		}
+	}
+}
+
+func TestSetFlagArg(t *testing.T) {
+	tests := []struct {
+		args  []string
+		flag  string
+		value string
+		want  []string
+	}{
+		{[]string{"server"}, "--default-volumes-to-restic", "true", []string{"server", "--default-volumes-to-restic=true"}},
+		{[]string{"server", "--default-volumes-to-restic", "--log-level=debug"}, "--default-volumes-to-restic", "false", []string{"server", "--log-level=debug", "--default-volumes-to-restic=false"}},
+		{[]string{"server", "--metrics-address=:8085"}, "--metrics-address", ":9090", []string{"server", "--metrics-address=:9090"}},
+	}
+	for _, test := range tests {
+		got := setFlagArg(test.args, test.flag, test.value)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Expected %v, got %v", test.want, got)
+		}
+	}
+}
+
+func TestGetMetricsPortArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"server"}, 8085},
+		{[]string{"server", "--metrics-address=:9090"}, 9090},
+		{[]string{"server", "--metrics-address=0.0.0.0:8086"}, 8086},
+	}
+	for _, test := range tests {
+		got := getMetricsPortArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %d for %v, got %d", test.want, test.args, got)
+		}
+	}
+}
+
+func TestGetStoreValidationFrequencyArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"server"}, time.Minute},
+		{[]string{"server", "--store-validation-frequency=10s"}, 10 * time.Second},
+		{[]string{"server", "--store-validation-frequency=0s"}, 0},
+		{[]string{"server", "--store-validation-frequency=bad"}, time.Minute},
+	}
+	for _, test := range tests {
+		got := getStoreValidationFrequencyArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %s for %v, got %s", test.want, test.args, got)
+		}
+	}
+}
+
+func TestGetTerminatingResourceTimeoutArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"server"}, 10 * time.Minute},
+		{[]string{"server", "--terminating-resource-timeout=30m"}, 30 * time.Minute},
+		{[]string{"server", "--terminating-resource-timeout=bad"}, 10 * time.Minute},
+	}
+	for _, test := range tests {
+		got := getTerminatingResourceTimeoutArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %s for %v, got %s", test.want, test.args, got)
+		}
+	}
+}
+
+func TestGetBackupSyncPeriodArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"server"}, time.Minute},
+		{[]string{"server", "--backup-sync-period=30s"}, 30 * time.Second},
+		{[]string{"server", "--backup-sync-period=bad"}, time.Minute},
+	}
+	for _, test := range tests {
+		got := getBackupSyncPeriodArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %s for %v, got %s", test.want, test.args, got)
+		}
+	}
+}
+
+func TestGetItemOperationTimeoutArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want time.Duration
+	}{
+		{[]string{"server"}, 4 * time.Hour},
+		{[]string{"server", "--item-operation-timeout=12h"}, 12 * time.Hour},
+		{[]string{"server", "--item-operation-timeout=bad"}, 4 * time.Hour},
+	}
+	for _, test := range tests {
+		got := getItemOperationTimeoutArg(test.args)
+		if got != test.want {
+			t.Errorf("Expected %s for %v, got %s", test.want, test.args, got)
+		}
+	}
+}
+
+func TestSupportsItemOperationTimeout(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.5.1", false},
+		{"v1.10.3", false},
+		{"v1.11.0", true},
+		{"v2.0.0", true},
+		{"latest", false},
+	}
+	for _, test := range tests {
+		got := SupportsItemOperationTimeout(test.version)
+		if got != test.want {
+			t.Errorf("Expected %v for %s, got %v", test.want, test.version, got)
+		}
+	}
+}
+
+func TestGetRestoreResourcePrioritiesArg(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"server"}, []string{}},
+		{[]string{"server", "--restore-resource-priorities=namespaces,customresourcedefinitions"}, []string{"namespaces", "customresourcedefinitions"}},
+		{[]string{"server", "--restore-resource-priorities=namespaces,,secrets"}, []string{"namespaces", "secrets"}},
+	}
+	for _, test := range tests {
+		got := getRestoreResourcePrioritiesArg(test.args)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Expected %v for %v, got %v", test.want, test.args, got)
+		}
+	}
+}
+
+func TestValidateRestoreResourcePriorities(t *testing.T) {
+	tests := []struct {
+		priorities []string
+		wantErr    bool
+	}{
+		{[]string{}, false},
+		{[]string{"customresourcedefinitions", "namespaces", "widgets.example.com"}, false},
+		{[]string{"Namespaces"}, true},
+		{[]string{"namespaces", "namespaces"}, true},
+		{[]string{""}, true},
+	}
+	for _, test := range tests {
+		err := ValidateRestoreResourcePriorities(test.priorities)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Expected error %v for %v, got %v", test.wantErr, test.priorities, err)
+		}
+	}
+}
+
+func TestAddImagePullSecrets(t *testing.T) {
+	serviceAccount := &corev1.ServiceAccount{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "corp-registry"}},
+	}
+
+	added := addImagePullSecrets(serviceAccount, []corev1.LocalObjectReference{{Name: "corp-registry"}, {Name: veleroRegistryPullSecretName}})
+	if !added {
+		t.Error("Expected the registry pull secret to be added")
+	}
+	want := []corev1.LocalObjectReference{{Name: "corp-registry"}, {Name: veleroRegistryPullSecretName}}
+	if !reflect.DeepEqual(serviceAccount.ImagePullSecrets, want) {
+		t.Errorf("Expected %v, got %v", want, serviceAccount.ImagePullSecrets)
+	}
+
+	if addImagePullSecrets(serviceAccount, []corev1.LocalObjectReference{{Name: veleroRegistryPullSecretName}}) {
+		t.Error("Expected nothing to be added")
+	}
+}
@@ -0,0 +1,293 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	gcpstorage "cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	veleroclientv1 "github.com/vmware-tanzu/velero/pkg/generated/clientset/versioned/typed/velero/v1"
+	velerolabel "github.com/vmware-tanzu/velero/pkg/label"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+type objectExistsFunc func(key string) (bool, error)
+
+// objectListFunc returns the keys of the objects under the prefix
+type objectListFunc func(prefix string) ([]string, error)
+
+// resticShortIDLength is the length of the snapshot ids restic reports, the start of the id the snapshot is
+// stored under
+const resticShortIDLength = 8
+
+// VerifyBackupObjects checks the store for the objects velero and restic should have uploaded for the backup,
+// and returns the keys of any that are missing
+func VerifyBackupObjects(ctx context.Context, backupName string) ([]string, error) {
+	bsl, err := FindBackupStoreLocation()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find backupstoragelocations")
+	}
+	if bsl == nil {
+		return nil, errors.New("no backup store location found")
+	}
+
+	store, err := GetGlobalStore(bsl)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get store")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cluster config")
+	}
+
+	veleroClient, err := veleroclientv1.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create clientset")
+	}
+
+	backup, err := veleroClient.Backups(bsl.Namespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get backup")
+	}
+	if backup.Status.Phase != velerov1.BackupPhaseCompleted && backup.Status.Phase != velerov1.BackupPhasePartiallyFailed {
+		return nil, errors.Errorf("backup is in phase %s", backup.Status.Phase)
+	}
+
+	backupVolumes, err := veleroClient.PodVolumeBackups(bsl.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("velero.io/backup-name=%s", velerolabel.GetValidName(backupName)),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list volumes")
+	}
+
+	objectExists, listObjects, err := getStoreObjectFuncs(ctx, store)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create store client")
+	}
+
+	missing := []string{}
+	for _, key := range expectedBackupObjects(store.Path, backupName, backupVolumes.Items) {
+		exists, err := objectExists(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to check object %s", key)
+		}
+		if !exists {
+			missing = append(missing, key)
+		}
+	}
+
+	resticSnapshots := expectedResticSnapshots(store.Path, backupVolumes.Items)
+	snapshotDirs := []string{}
+	for snapshotsDir := range resticSnapshots {
+		snapshotDirs = append(snapshotDirs, snapshotsDir)
+	}
+	sort.Strings(snapshotDirs)
+
+	for _, snapshotsDir := range snapshotDirs {
+		keys, err := listObjects(snapshotsDir + "/")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list objects in %s", snapshotsDir)
+		}
+		missing = append(missing, findMissingResticSnapshots(snapshotsDir, resticSnapshots[snapshotsDir], keys)...)
+	}
+
+	return missing, nil
+}
+
+// expectedBackupObjects returns the keys velero writes for a backup under the store prefix, followed by the
+// config of every restic repository the backup's volumes were written to
+func expectedBackupObjects(prefix string, backupName string, backupVolumes []velerov1.PodVolumeBackup) []string {
+	backupDir := path.Join(prefix, "backups", backupName)
+	keys := []string{
+		path.Join(backupDir, "velero-backup.json"),
+		path.Join(backupDir, backupName+".tar.gz"),
+		path.Join(backupDir, backupName+"-logs.gz"),
+	}
+
+	repoConfigs := []string{}
+	for _, repoDir := range backupResticRepos(prefix, backupVolumes) {
+		repoConfigs = append(repoConfigs, path.Join(repoDir, "config"))
+	}
+	sort.Strings(repoConfigs)
+
+	return append(keys, repoConfigs...)
+}
+
+// expectedResticSnapshots returns the snapshot ids of the backup's volumes by the snapshots directory of their
+// restic repository. Pod volume backups report restic's short id, the snapshot is stored under its full id.
+func expectedResticSnapshots(prefix string, backupVolumes []velerov1.PodVolumeBackup) map[string][]string {
+	snapshots := map[string][]string{}
+	for _, backupVolume := range backupVolumes {
+		if !isCompletedResticVolume(backupVolume) {
+			continue
+		}
+		snapshotsDir := path.Join(prefix, "restic", backupVolume.Spec.Pod.Namespace, "snapshots")
+		snapshots[snapshotsDir] = append(snapshots[snapshotsDir], backupVolume.Status.SnapshotID)
+	}
+	for snapshotsDir := range snapshots {
+		sort.Strings(snapshots[snapshotsDir])
+	}
+	return snapshots
+}
+
+// findMissingResticSnapshots returns the snapshots that no key in the snapshots directory starts with, as the
+// key they would have if the id was the full one
+func findMissingResticSnapshots(snapshotsDir string, snapshotIDs []string, keys []string) []string {
+	missing := []string{}
+	for _, snapshotID := range snapshotIDs {
+		found := false
+		for _, key := range keys {
+			if path.Dir(key) != snapshotsDir {
+				continue
+			}
+			name := path.Base(key)
+			if name == snapshotID || (len(snapshotID) >= resticShortIDLength && strings.HasPrefix(name, snapshotID)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, path.Join(snapshotsDir, snapshotID))
+		}
+	}
+	return missing
+}
+
+func backupResticRepos(prefix string, backupVolumes []velerov1.PodVolumeBackup) []string {
+	repos := map[string]bool{}
+	repoDirs := []string{}
+	for _, backupVolume := range backupVolumes {
+		if !isCompletedResticVolume(backupVolume) {
+			continue
+		}
+		repoDir := path.Join(prefix, "restic", backupVolume.Spec.Pod.Namespace)
+		if !repos[repoDir] {
+			repos[repoDir] = true
+			repoDirs = append(repoDirs, repoDir)
+		}
+	}
+	return repoDirs
+}
+
+func isCompletedResticVolume(backupVolume velerov1.PodVolumeBackup) bool {
+	return backupVolume.Status.Phase == velerov1.PodVolumeBackupPhaseCompleted && backupVolume.Status.SnapshotID != ""
+}
+
+// getStoreObjectFuncs returns functions checking for an object and listing objects in the store's bucket
+func getStoreObjectFuncs(ctx context.Context, store *types.Store) (objectExistsFunc, objectListFunc, error) {
+	if store.AWS != nil || store.Other != nil || store.Internal != nil {
+		s3Client, err := newStoreS3Client(store)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create s3 client")
+		}
+		objectExists := func(key string) (bool, error) {
+			_, err := s3Client.HeadObject(&s3.HeadObjectInput{
+				Bucket: aws.String(store.Bucket),
+				Key:    aws.String(key),
+			})
+			if err != nil {
+				if aerr, ok := err.(awserr.RequestFailure); ok && aerr.StatusCode() == 404 {
+					return false, nil
+				}
+				return false, err
+			}
+			return true, nil
+		}
+		listObjects := func(prefix string) ([]string, error) {
+			keys := []string{}
+			err := s3Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+				Bucket: aws.String(store.Bucket),
+				Prefix: aws.String(prefix),
+			}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+				for _, object := range page.Contents {
+					keys = append(keys, aws.StringValue(object.Key))
+				}
+				return true
+			})
+			return keys, err
+		}
+		return objectExists, listObjects, nil
+	}
+
+	if store.Google != nil {
+		opts := []option.ClientOption{}
+		if !store.Google.UseInstanceRole {
+			opts = append(opts, option.WithCredentialsJSON([]byte(store.Google.JSONFile)))
+		}
+		client, err := gcpstorage.NewClient(ctx, opts...)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create storage client")
+		}
+		bucket := client.Bucket(store.Bucket)
+		objectExists := func(key string) (bool, error) {
+			_, err := bucket.Object(key).Attrs(ctx)
+			if err == gcpstorage.ErrObjectNotExist {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+		listObjects := func(prefix string) ([]string, error) {
+			keys := []string{}
+			objects := bucket.Objects(ctx, &gcpstorage.Query{Prefix: prefix})
+			for {
+				attrs, err := objects.Next()
+				if err == iterator.Done {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, attrs.Name)
+			}
+			return keys, nil
+		}
+		return objectExists, listObjects, nil
+	}
+
+	if store.Azure != nil {
+		container, err := getAzureContainer(store.Azure, store.Bucket)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to get container")
+		}
+		objectExists := func(key string) (bool, error) {
+			return container.GetBlobReference(key).Exists()
+		}
+		listObjects := func(prefix string) ([]string, error) {
+			keys := []string{}
+			params := storage.ListBlobsParameters{Prefix: prefix}
+			for {
+				blobs, err := container.ListBlobs(params)
+				if err != nil {
+					return nil, err
+				}
+				for _, blob := range blobs.Blobs {
+					keys = append(keys, blob.Name)
+				}
+				if blobs.NextMarker == "" {
+					break
+				}
+				params.Marker = blobs.NextMarker
+			}
+			return keys, nil
+		}
+		return objectExists, listObjects, nil
+	}
+
+	return nil, nil, errors.New("no valid configuration found")
+}
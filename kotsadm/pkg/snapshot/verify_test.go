@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"reflect"
+	"testing"
+
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+func TestExpectedBackupObjects(t *testing.T) {
+	volume := func(namespace string, phase velerov1.PodVolumeBackupPhase, snapshotID string) velerov1.PodVolumeBackup {
+		v := velerov1.PodVolumeBackup{}
+		v.Spec.Pod.Namespace = namespace
+		v.Status.Phase = phase
+		v.Status.SnapshotID = snapshotID
+		return v
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		volumes []velerov1.PodVolumeBackup
+		want    []string
+	}{
+		{
+			name:   "no volumes",
+			prefix: "",
+			want: []string{
+				"backups/instance-abcd/velero-backup.json",
+				"backups/instance-abcd/instance-abcd.tar.gz",
+				"backups/instance-abcd/instance-abcd-logs.gz",
+			},
+		},
+		{
+			name:   "restic volumes with prefix",
+			prefix: "kotsadm",
+			volumes: []velerov1.PodVolumeBackup{
+				volume("default", velerov1.PodVolumeBackupPhaseCompleted, "bbbbbbbb"),
+				volume("default", velerov1.PodVolumeBackupPhaseCompleted, "aaaaaaaa"),
+				volume("app", velerov1.PodVolumeBackupPhaseCompleted, "cccccccc"),
+				volume("app", velerov1.PodVolumeBackupPhaseFailed, ""),
+			},
+			want: []string{
+				"kotsadm/backups/instance-abcd/velero-backup.json",
+				"kotsadm/backups/instance-abcd/instance-abcd.tar.gz",
+				"kotsadm/backups/instance-abcd/instance-abcd-logs.gz",
+				"kotsadm/restic/app/config",
+				"kotsadm/restic/default/config",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := expectedBackupObjects(test.prefix, "instance-abcd", test.volumes)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestExpectedResticSnapshots(t *testing.T) {
+	volume := func(namespace string, phase velerov1.PodVolumeBackupPhase, snapshotID string) velerov1.PodVolumeBackup {
+		v := velerov1.PodVolumeBackup{}
+		v.Spec.Pod.Namespace = namespace
+		v.Status.Phase = phase
+		v.Status.SnapshotID = snapshotID
+		return v
+	}
+
+	volumes := []velerov1.PodVolumeBackup{
+		volume("default", velerov1.PodVolumeBackupPhaseCompleted, "bbbbbbbb"),
+		volume("default", velerov1.PodVolumeBackupPhaseCompleted, "aaaaaaaa"),
+		volume("app", velerov1.PodVolumeBackupPhaseCompleted, "cccccccc"),
+		volume("app", velerov1.PodVolumeBackupPhaseFailed, ""),
+	}
+
+	want := map[string][]string{
+		"kotsadm/restic/app/snapshots":     {"cccccccc"},
+		"kotsadm/restic/default/snapshots": {"aaaaaaaa", "bbbbbbbb"},
+	}
+
+	got := expectedResticSnapshots("kotsadm", volumes)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestFindMissingResticSnapshots(t *testing.T) {
+	snapshotsDir := "kotsadm/restic/default/snapshots"
+	keys := []string{
+		"kotsadm/restic/default/snapshots/4f1a9c3be7d2085a6b1c0f9e3d7a2b5c8e6f1d0a9b3c7e2f5a8d1b4c6e9f0a3b",
+		"kotsadm/restic/default/snapshots/d81e5b07c2a94f36e8b1d5c0a7f3e92b6c4d8a1f0e5b7c3d9a2f6e8b1c4d7a0e",
+		"kotsadm/restic/default/snapshots/nested/aaaaaaaa",
+	}
+
+	tests := []struct {
+		name        string
+		snapshotIDs []string
+		want        []string
+	}{
+		{
+			name:        "short ids of stored snapshots",
+			snapshotIDs: []string{"4f1a9c3b", "d81e5b07"},
+			want:        []string{},
+		},
+		{
+			name:        "full id of a stored snapshot",
+			snapshotIDs: []string{"4f1a9c3be7d2085a6b1c0f9e3d7a2b5c8e6f1d0a9b3c7e2f5a8d1b4c6e9f0a3b"},
+			want:        []string{},
+		},
+		{
+			name:        "missing snapshot",
+			snapshotIDs: []string{"4f1a9c3b", "aaaaaaaa"},
+			want:        []string{"kotsadm/restic/default/snapshots/aaaaaaaa"},
+		},
+		{
+			name:        "id too short to match by prefix",
+			snapshotIDs: []string{"4f1a"},
+			want:        []string{"kotsadm/restic/default/snapshots/4f1a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := findMissingResticSnapshots(snapshotsDir, test.snapshotIDs, keys)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+)
+
+const BackupWebhookSignatureHeader = "X-Kots-Signature"
+
+type BackupWebhookPayload struct {
+	BackupName  string     `json:"backupName"`
+	Phase       string     `json:"phase"`
+	AppID       string     `json:"appId,omitempty"`
+	Instance    bool       `json:"instance"`
+	Errors      []string   `json:"errors,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// IsBackupDone returns true when velero will no longer update the phase of the backup
+func IsBackupDone(backup *velerov1.Backup) bool {
+	switch backup.Status.Phase {
+	case velerov1.BackupPhaseCompleted, velerov1.BackupPhasePartiallyFailed, velerov1.BackupPhaseFailed, velerov1.BackupPhaseFailedValidation:
+		return true
+	}
+	return false
+}
+
+// ShouldNotifyBackupWebhook returns true if the backup finished after the webhook was configured and it hasn't been
+// sent to it yet. The informer sees every existing backup when it starts, older backups are never sent.
+func ShouldNotifyBackupWebhook(webhook *types.BackupWebhook, backup *velerov1.Backup) bool {
+	if webhook.URL == "" || webhook.ConfiguredAt == nil {
+		return false
+	}
+	if _, ok := backup.Annotations["kots.io/webhook-notified"]; ok {
+		return false
+	}
+	if !IsBackupDone(backup) {
+		return false
+	}
+
+	// backups that fail validation are never completed
+	finishedAt := backup.CreationTimestamp.Time
+	if backup.Status.CompletionTimestamp != nil {
+		finishedAt = backup.Status.CompletionTimestamp.Time
+	}
+	return finishedAt.After(*webhook.ConfiguredAt)
+}
+
+// NotifyBackupWebhook posts the result of a finished backup to the configured webhook, retrying with backoff.
+// When a secret is configured, the body is signed with HMAC-SHA256 and the signature sent in the X-Kots-Signature header.
+func NotifyBackupWebhook(webhook *types.BackupWebhook, backup *velerov1.Backup) error {
+	payload := BackupWebhookPayload{
+		BackupName: backup.Name,
+		Phase:      string(backup.Status.Phase),
+		AppID:      backup.Annotations["kots.io/app-id"],
+		Instance:   backup.Annotations["kots.io/instance"] == "true",
+		Errors:     backup.Status.ValidationErrors,
+	}
+	if backup.Status.FailureReason != "" {
+		payload.Errors = append(payload.Errors, backup.Status.FailureReason)
+	}
+	if backup.Status.CompletionTimestamp != nil {
+		payload.CompletedAt = &backup.Status.CompletionTimestamp.Time
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal payload")
+	}
+
+	var lastErr error
+	backoff := time.Second
+	for attempt := 0; attempt < 5; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		lastErr = postBackupWebhook(webhook, body)
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return errors.Wrap(lastErr, "failed to post to webhook")
+}
+
+func postBackupWebhook(webhook *types.BackupWebhook, body []byte) error {
+	req, err := http.NewRequest("POST", webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhook.Secret != "" {
+		req.Header.Set(BackupWebhookSignatureHeader, SignBackupWebhookPayload(webhook.Secret, body))
+	}
+
+	client := http.Client{
+		Timeout: 10 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// SignBackupWebhookPayload returns the signature receivers can use to verify that a payload was sent by kotsadm
+func SignBackupWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return fmt.Sprintf("sha256=%s", hex.EncodeToString(mac.Sum(nil)))
+}
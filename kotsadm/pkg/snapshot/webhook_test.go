@@ -0,0 +1,156 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	velerov1 "github.com/vmware-tanzu/velero/pkg/apis/velero/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNotifyBackupWebhook(t *testing.T) {
+	var gotBody []byte
+	var gotSignature string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			// fail the first attempt to exercise the retry
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotSignature = r.Header.Get(BackupWebhookSignatureHeader)
+	}))
+	defer server.Close()
+
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "instance-abcd",
+			Annotations: map[string]string{"kots.io/instance": "true"},
+		},
+	}
+	backup.Status.Phase = velerov1.BackupPhaseCompleted
+
+	webhook := &types.BackupWebhook{URL: server.URL, Secret: "shh"}
+	if err := NotifyBackupWebhook(webhook, backup); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+	if want := SignBackupWebhookPayload("shh", gotBody); gotSignature != want {
+		t.Errorf("Expected signature %q, got %q", want, gotSignature)
+	}
+}
+
+func TestShouldNotifyBackupWebhook(t *testing.T) {
+	configuredAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	completedBackup := func(completedAt time.Time) *velerov1.Backup {
+		backup := &velerov1.Backup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "instance-abcd",
+				CreationTimestamp: metav1.NewTime(completedAt.Add(-time.Minute)),
+			},
+		}
+		backup.Status.Phase = velerov1.BackupPhaseCompleted
+		backup.Status.CompletionTimestamp = &metav1.Time{Time: completedAt}
+		return backup
+	}
+
+	tests := []struct {
+		name    string
+		webhook *types.BackupWebhook
+		backup  *velerov1.Backup
+		want    bool
+	}{
+		{
+			name:    "completed after the webhook was configured",
+			webhook: &types.BackupWebhook{URL: "http://example.com", ConfiguredAt: &configuredAt},
+			backup:  completedBackup(configuredAt.Add(time.Hour)),
+			want:    true,
+		},
+		{
+			name:    "completed before the webhook was configured",
+			webhook: &types.BackupWebhook{URL: "http://example.com", ConfiguredAt: &configuredAt},
+			backup:  completedBackup(configuredAt.Add(-24 * time.Hour)),
+			want:    false,
+		},
+		{
+			name:    "webhook without a configured time",
+			webhook: &types.BackupWebhook{URL: "http://example.com"},
+			backup:  completedBackup(configuredAt.Add(time.Hour)),
+			want:    false,
+		},
+		{
+			name:    "no webhook",
+			webhook: &types.BackupWebhook{},
+			backup:  completedBackup(configuredAt.Add(time.Hour)),
+			want:    false,
+		},
+		{
+			name:    "still in progress",
+			webhook: &types.BackupWebhook{URL: "http://example.com", ConfiguredAt: &configuredAt},
+			backup: &velerov1.Backup{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(configuredAt.Add(time.Hour))},
+				Status:     velerov1.BackupStatus{Phase: velerov1.BackupPhaseInProgress},
+			},
+			want: false,
+		},
+		{
+			name:    "already notified",
+			webhook: &types.BackupWebhook{URL: "http://example.com", ConfiguredAt: &configuredAt},
+			backup: func() *velerov1.Backup {
+				backup := completedBackup(configuredAt.Add(time.Hour))
+				backup.Annotations = map[string]string{"kots.io/webhook-notified": "true"}
+				return backup
+			}(),
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ShouldNotifyBackupWebhook(test.webhook, test.backup); got != test.want {
+				t.Errorf("Expected %v, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestOldBackupDoesNotPostToWebhook(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	configuredAt := time.Now().UTC()
+	webhook := &types.BackupWebhook{URL: server.URL, ConfiguredAt: &configuredAt}
+
+	// an Added event from the informer for a backup that completed a day before the webhook was saved
+	backup := &velerov1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "instance-old",
+			CreationTimestamp: metav1.NewTime(configuredAt.Add(-25 * time.Hour)),
+		},
+	}
+	backup.Status.Phase = velerov1.BackupPhaseCompleted
+	backup.Status.CompletionTimestamp = &metav1.Time{Time: configuredAt.Add(-24 * time.Hour)}
+
+	if ShouldNotifyBackupWebhook(webhook, backup) {
+		if err := NotifyBackupWebhook(webhook, backup); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests, got %d", requests)
+	}
+}
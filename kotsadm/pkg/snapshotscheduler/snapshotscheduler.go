@@ -2,6 +2,7 @@ package snapshotscheduler
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,11 +18,14 @@ import (
 	cron "github.com/robfig/cron/v3"
 )
 
+// scheduleLoopIntervalSeconds is how often the scheduler checks for due snapshots
+const scheduleLoopIntervalSeconds = 60
+
 func Start() error {
 	logger.Debug("starting snapshot scheduler")
 
-	startLoop(appScheduleLoop, 60)
-	startLoop(instanceScheduleLoop, 60)
+	startLoop(appScheduleLoop, scheduleLoopIntervalSeconds)
+	startLoop(instanceScheduleLoop, scheduleLoopIntervalSeconds)
 
 	return nil
 }
@@ -35,6 +39,12 @@ func startLoop(fn func(), intervalInSeconds time.Duration) {
 	}()
 }
 
+// appSchedule is an app with its pending scheduled snapshots
+type appSchedule struct {
+	app     *apptypes.App
+	pending []snapshottypes.ScheduledSnapshot
+}
+
 func appScheduleLoop() {
 	appsList, err := store.GetStore().ListInstalledApps()
 	if err != nil {
@@ -42,14 +52,53 @@ func appScheduleLoop() {
 		return
 	}
 
+	schedules := []appSchedule{}
 	for _, a := range appsList {
-		if a.RestoreInProgressName != "" {
+		if a.RestoreInProgressName != "" || a.SnapshotSchedule == "" {
 			continue
 		}
-		if err := handleApp(a); err != nil {
-			logger.Error(errors.Wrapf(err, "failed to handle scheduled snapshots for app %s", a.ID))
+		pending, err := store.GetStore().ListPendingScheduledSnapshots(a.ID)
+		if err != nil {
+			logger.Error(errors.Wrapf(err, "failed to list pending scheduled snapshots for app %s", a.ID))
+			continue
+		}
+		schedules = append(schedules, appSchedule{app: a, pending: pending})
+	}
+
+	// apps held back by the max concurrent scheduled snapshots limit get the slots that free up first, in the
+	// order they were queued
+	sortAppSchedulesByQueuedAt(schedules)
+
+	for _, s := range schedules {
+		if err := handleApp(s.app, s.pending); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to handle scheduled snapshots for app %s", s.app.ID))
+		}
+	}
+}
+
+// sortAppSchedulesByQueuedAt moves the apps with a queued snapshot to the front, oldest first. The order of the
+// other apps is kept.
+func sortAppSchedulesByQueuedAt(schedules []appSchedule) {
+	sort.SliceStable(schedules, func(i, j int) bool {
+		iQueuedAt, jQueuedAt := earliestQueuedAt(schedules[i].pending), earliestQueuedAt(schedules[j].pending)
+		if iQueuedAt == nil {
+			return false
+		}
+		if jQueuedAt == nil {
+			return true
+		}
+		return iQueuedAt.Before(*jQueuedAt)
+	})
+}
+
+func earliestQueuedAt(pending []snapshottypes.ScheduledSnapshot) *time.Time {
+	var earliest *time.Time
+	for _, p := range pending {
+		if p.QueuedAt != nil && (earliest == nil || p.QueuedAt.Before(*earliest)) {
+			earliest = p.QueuedAt
 		}
 	}
+	return earliest
 }
 
 func instanceScheduleLoop() {
@@ -67,7 +116,7 @@ func instanceScheduleLoop() {
 }
 
 /* App Level Scheduled Snapshots */
-func handleApp(a *apptypes.App) error {
+func handleApp(a *apptypes.App, pending []snapshottypes.ScheduledSnapshot) error {
 	if a.SnapshotSchedule == "" {
 		return nil
 	}
@@ -81,20 +130,17 @@ func handleApp(a *apptypes.App) error {
 	* 0 or 2+ pending snapshots this routine will fix it up so there's exactly 1 when it finishes.)
 	*
 	* Before taking a snapshot, first check that it's not scheduled for a time in the future, then
-	* check that there is not already another snapshot in progress for the app. If both of those
-	* checks pass, then create the Backup CR for velero, save the Backup name to the row to
-	* mark that it has been handled, then schedule the next snapshot from the app's cron schedule
-	* expression.
+	* check that there is not already another snapshot in progress for the app. If a global limit on
+	* concurrent scheduled snapshots is set and has been reached, the due snapshot is marked queued and
+	* left pending until a later pass finds a free slot. Apps are handled in the order they were queued,
+	* so the earliest queued snapshot gets a free slot first. If all of those checks pass, then create the
+	* Backup CR for velero, save the Backup name to the row to mark that it has been handled, then
+	* schedule the next snapshot from the app's cron schedule expression.
 	 */
 
-	pending, err := store.GetStore().ListPendingScheduledSnapshots(a.ID)
-	if err != nil {
-		return errors.Wrap(err, "failed to list pending scheduled snapshots")
-	}
-
 	if len(pending) == 0 {
 		logger.Infof("No pending snapshots scheduled for app %s with schedule %s. Queueing one.", a.ID, a.SnapshotSchedule)
-		queued, err := nextScheduledApplicationSnapshot(a.ID, a.SnapshotSchedule)
+		queued, err := nextScheduledApplicationSnapshot(a.ID, a.SnapshotSchedule, a.SnapshotScheduleJitterMinutes)
 		if err != nil {
 			return errors.Wrap(err, "failed to get next schedule")
 		}
@@ -110,17 +156,37 @@ func handleApp(a *apptypes.App) error {
 		return nil
 	}
 
-	hasUnfinished, err := snapshot.HasUnfinishedApplicationBackup(a.ID)
+	if err := snapshot.CheckAppBackupConcurrency(a); snapshot.IsBackupInProgressError(err) {
+		logger.Infof("Postponing scheduled application snapshot for app %s: %s", a.ID, err.Error())
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to check app backup concurrency")
+	}
+
+	maxConcurrent, err := store.GetStore().GetMaxConcurrentScheduledSnapshots()
 	if err != nil {
-		return errors.Wrap(err, "failed to to check if app has unfinished backups")
+		return errors.Wrap(err, "failed to get max concurrent scheduled snapshots")
 	}
-	if hasUnfinished {
-		logger.Infof("Postponing scheduled application snapshot for app %s because one is in progress", a.ID)
-		return nil
+	if maxConcurrent > 0 {
+		running, err := snapshot.CountRunningScheduledBackups()
+		if err != nil {
+			return errors.Wrap(err, "failed to count running scheduled backups")
+		}
+		if running >= maxConcurrent {
+			if err := store.GetStore().MarkScheduledSnapshotQueued(next.ID); err != nil {
+				return errors.Wrap(err, "failed to mark scheduled snapshot queued")
+			}
+			logger.Infof("Queueing scheduled application snapshot for app %s because %d of %d scheduled snapshots are running", a.ID, running, maxConcurrent)
+			return nil
+		}
 	}
 
 	backup, err := snapshot.CreateApplicationBackup(context.TODO(), a, true)
-	if err != nil {
+	if snapshot.IsBackupInProgressError(err) {
+		// a manual backup was started since the check above
+		logger.Infof("Postponing scheduled application snapshot for app %s: %s", a.ID, err.Error())
+		return nil
+	} else if err != nil {
 		return errors.Wrap(err, "failed to create backup")
 	}
 
@@ -136,7 +202,7 @@ func handleApp(a *apptypes.App) error {
 		}
 	}
 
-	queued, err := nextScheduledApplicationSnapshot(a.ID, a.SnapshotSchedule)
+	queued, err := nextScheduledApplicationSnapshot(a.ID, a.SnapshotSchedule, a.SnapshotScheduleJitterMinutes)
 	if err != nil {
 		return errors.Wrap(err, "failed to get next schedule")
 	}
@@ -149,6 +215,69 @@ func handleApp(a *apptypes.App) error {
 	return nil
 }
 
+// ReconcileApplicationSchedule leaves exactly one pending scheduled snapshot for the app after its schedule
+// or jitter changes. A run that's already due is kept so the edit can't skip it, and the next run is pushed
+// back when it would fire within one scheduler tick of the last scheduled backup.
+func ReconcileApplicationSchedule(a *apptypes.App) error {
+	cronSchedule, err := cron.ParseStandard(a.SnapshotSchedule)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse cron expression")
+	}
+
+	pending, err := store.GetStore().ListPendingScheduledSnapshots(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pending scheduled snapshots")
+	}
+
+	lastHandled, err := store.GetStore().GetLastHandledScheduledSnapshot(a.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get last handled scheduled snapshot")
+	}
+
+	next, isDue := nextApplicationRun(cronSchedule, pending, lastHandled, time.Now())
+	if !isDue {
+		next = snapshot.ApplyScheduleJitter(next, a.SnapshotScheduleJitterMinutes)
+	}
+
+	if err := store.GetStore().DeletePendingScheduledSnapshots(a.ID); err != nil {
+		return errors.Wrap(err, "failed to delete pending scheduled snapshots")
+	}
+
+	id := strings.ToLower(rand.String(32))
+	if err := store.GetStore().CreateScheduledSnapshot(id, a.ID, next); err != nil {
+		return errors.Wrap(err, "failed to create scheduled snapshot")
+	}
+
+	return nil
+}
+
+// nextApplicationRun returns the time of the single pending snapshot, and whether it's a run that was already
+// due under the previous schedule
+func nextApplicationRun(schedule cron.Schedule, pending []snapshottypes.ScheduledSnapshot, lastHandled *snapshottypes.ScheduledSnapshot, now time.Time) (time.Time, bool) {
+	var due *time.Time
+	for _, p := range pending {
+		if p.ScheduledTimestamp.After(now) {
+			continue
+		}
+		if due == nil || p.ScheduledTimestamp.Before(*due) {
+			timestamp := p.ScheduledTimestamp
+			due = &timestamp
+		}
+	}
+	if due != nil {
+		return *due, true
+	}
+
+	next := schedule.Next(now)
+
+	// the last backup may have been taken up to a tick after its scheduled time
+	if lastHandled != nil && next.Sub(lastHandled.ScheduledTimestamp) <= scheduleLoopIntervalSeconds*time.Second {
+		next = schedule.Next(next)
+	}
+
+	return next, false
+}
+
 /* Cluster/Instance Level Scheduled Snapshots */
 func handleCluster(c *downstreamtypes.Downstream) error {
 	if c.SnapshotSchedule == "" {
@@ -164,10 +293,10 @@ func handleCluster(c *downstreamtypes.Downstream) error {
 	* 0 or 2+ pending snapshots this routine will fix it up so there's exactly 1 when it finishes.)
 	*
 	* Before taking a snapshot, first check that it's not scheduled for a time in the future, then
-	* check that there is not already another snapshot in progress for the cluster. If both of those
-	* checks pass, then create the Backup CR for velero, save the Backup name to the row to
-	* mark that it has been handled, then schedule the next snapshot from the cluster's cron schedule
-	* expression.
+	* check that there is not already another snapshot in progress for the cluster and that the global
+	* limit on concurrent scheduled snapshots, if set, hasn't been reached. If all of those checks pass,
+	* then create the Backup CR for velero, save the Backup name to the row to mark that it has been
+	* handled, then schedule the next snapshot from the cluster's cron schedule expression.
 	 */
 
 	pending, err := store.GetStore().ListPendingScheduledInstanceSnapshots(c.ClusterID)
@@ -202,6 +331,21 @@ func handleCluster(c *downstreamtypes.Downstream) error {
 		return nil
 	}
 
+	maxConcurrent, err := store.GetStore().GetMaxConcurrentScheduledSnapshots()
+	if err != nil {
+		return errors.Wrap(err, "failed to get max concurrent scheduled snapshots")
+	}
+	if maxConcurrent > 0 {
+		running, err := snapshot.CountRunningScheduledBackups()
+		if err != nil {
+			return errors.Wrap(err, "failed to count running scheduled backups")
+		}
+		if running >= maxConcurrent {
+			logger.Infof("Postponing scheduled instance snapshot for cluster %s because %d of %d scheduled snapshots are running", c.ClusterID, running, maxConcurrent)
+			return nil
+		}
+	}
+
 	backup, err := snapshot.CreateInstanceBackup(context.TODO(), c, true)
 	if err != nil {
 		return errors.Wrap(err, "failed to create instance backup")
@@ -232,7 +376,7 @@ func handleCluster(c *downstreamtypes.Downstream) error {
 	return nil
 }
 
-func nextScheduledApplicationSnapshot(appID string, cronExpression string) (*snapshottypes.ScheduledSnapshot, error) {
+func nextScheduledApplicationSnapshot(appID string, cronExpression string, jitterMinutes int) (*snapshottypes.ScheduledSnapshot, error) {
 	cronSchedule, err := cron.ParseStandard(cronExpression)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse cron expression")
@@ -241,7 +385,7 @@ func nextScheduledApplicationSnapshot(appID string, cronExpression string) (*sna
 	scheduledSnapshot := &snapshottypes.ScheduledSnapshot{
 		AppID:              appID,
 		ID:                 strings.ToLower(rand.String(32)),
-		ScheduledTimestamp: cronSchedule.Next(time.Now()),
+		ScheduledTimestamp: snapshot.ApplyScheduleJitter(cronSchedule.Next(time.Now()), jitterMinutes),
 	}
 
 	return scheduledSnapshot, nil
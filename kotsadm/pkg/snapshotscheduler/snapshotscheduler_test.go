@@ -0,0 +1,103 @@
+package snapshotscheduler
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	cron "github.com/robfig/cron/v3"
+)
+
+func TestNextApplicationRun(t *testing.T) {
+	now := time.Date(2020, 10, 1, 2, 0, 20, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		schedule    string
+		pending     []snapshottypes.ScheduledSnapshot
+		lastHandled *snapshottypes.ScheduledSnapshot
+		want        time.Time
+		wantDue     bool
+	}{
+		{
+			name:     "next run from the new schedule",
+			schedule: "0 4 * * *",
+			pending: []snapshottypes.ScheduledSnapshot{
+				{ScheduledTimestamp: time.Date(2020, 10, 2, 2, 0, 0, 0, time.UTC)},
+			},
+			want: time.Date(2020, 10, 1, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "old run already due is kept",
+			schedule: "0 3 * * *",
+			pending: []snapshottypes.ScheduledSnapshot{
+				{ScheduledTimestamp: time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)},
+			},
+			want:    time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC),
+			wantDue: true,
+		},
+		{
+			name:        "old and new runs in the same tick",
+			schedule:    "1 2 * * *",
+			lastHandled: &snapshottypes.ScheduledSnapshot{ScheduledTimestamp: time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)},
+			want:        time.Date(2020, 10, 2, 2, 1, 0, 0, time.UTC),
+		},
+		{
+			name:        "last run more than a tick before the next run",
+			schedule:    "5 2 * * *",
+			lastHandled: &snapshottypes.ScheduledSnapshot{ScheduledTimestamp: time.Date(2020, 10, 1, 2, 0, 0, 0, time.UTC)},
+			want:        time.Date(2020, 10, 1, 2, 5, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := cron.ParseStandard(test.schedule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, gotDue := nextApplicationRun(schedule, test.pending, test.lastHandled, now)
+			if !got.Equal(test.want) {
+				t.Errorf("Expected %s, got %s", test.want, got)
+			}
+			if gotDue != test.wantDue {
+				t.Errorf("Expected due %v, got %v", test.wantDue, gotDue)
+			}
+		})
+	}
+}
+
+func TestSortAppSchedulesByQueuedAt(t *testing.T) {
+	queuedAt := func(minute int) *time.Time {
+		t := time.Date(2020, 10, 1, 2, minute, 0, 0, time.UTC)
+		return &t
+	}
+	schedule := func(appID string, queued ...*time.Time) appSchedule {
+		pending := []snapshottypes.ScheduledSnapshot{}
+		for _, q := range queued {
+			pending = append(pending, snapshottypes.ScheduledSnapshot{AppID: appID, QueuedAt: q})
+		}
+		return appSchedule{app: &apptypes.App{ID: appID}, pending: pending}
+	}
+
+	schedules := []appSchedule{
+		schedule("not-queued-1", nil),
+		schedule("queued-last", queuedAt(30)),
+		schedule("no-pending"),
+		schedule("queued-first", nil, queuedAt(10)),
+		schedule("not-queued-2", nil),
+		schedule("queued-second", queuedAt(20)),
+	}
+	sortAppSchedulesByQueuedAt(schedules)
+
+	got := []string{}
+	for _, s := range schedules {
+		got = append(got, s.app.ID)
+	}
+	want := []string{"queued-first", "queued-second", "queued-last", "not-queued-1", "no-pending", "not-queued-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -1,6 +1,7 @@
 package socketservice
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -549,7 +550,28 @@ func startVeleroRestore(snapshotName string, appSlug string) error {
 	return nil
 }
 
+func logRestoreWarnings(a *apptypes.App, restoreName string) {
+	warnings, err := snapshot.GetRestoreWarnings(context.TODO(), a.ID, a.Slug, restoreName)
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to get restore warnings"))
+		return
+	}
+
+	counts := map[string]int{}
+	for _, warning := range warnings {
+		counts[warning.Category]++
+	}
+	logger.Info(fmt.Sprintf("restore %s completed with %d warnings: %v", restoreName, len(warnings), counts))
+}
+
 func checkRestoreComplete(clusterSocket *ClusterSocket, a *apptypes.App, restore *velerov1.Restore) error {
+	switch restore.Status.Phase {
+	case velerov1.RestorePhaseCompleted, velerov1.RestorePhaseFailed, velerov1.RestorePhasePartiallyFailed:
+		if err := snapshot.RevertRestoreHelperImage(context.TODO(), restore.Name); err != nil {
+			logger.Error(errors.Wrap(err, "failed to revert restore helper image"))
+		}
+	}
+
 	switch restore.Status.Phase {
 	case velerov1.RestorePhaseCompleted:
 		backup, err := snapshot.GetBackup(restore.Spec.BackupName)
@@ -562,6 +584,10 @@ func checkRestoreComplete(clusterSocket *ClusterSocket, a *apptypes.App, restore
 			return errors.New("backup is missing required annotations")
 		}
 
+		if err := snapshot.UnquiesceRestoredWorkloads(context.TODO(), backup, restore); err != nil {
+			logger.Error(errors.Wrap(err, "failed to scale back restored workloads that were quiesced for the backup"))
+		}
+
 		var sequence int64 = 0
 		if backupAnnotations["kots.io/instance"] == "true" {
 			b, ok := backupAnnotations["kots.io/apps-sequences"]
@@ -592,6 +618,10 @@ func checkRestoreComplete(clusterSocket *ClusterSocket, a *apptypes.App, restore
 			sequence = s
 		}
 
+		if restore.Status.Warnings > 0 {
+			logRestoreWarnings(a, restore.Name)
+		}
+
 		logger.Info(fmt.Sprintf("restore complete, re-deploying version %d", sequence))
 
 		if err := RedeployAppVersion(a.ID, sequence, clusterSocket); err != nil {
@@ -611,6 +641,12 @@ func checkRestoreComplete(clusterSocket *ClusterSocket, a *apptypes.App, restore
 	case velerov1.RestorePhaseFailed, velerov1.RestorePhasePartiallyFailed:
 		logger.Info("restore failed, resetting app restore")
 
+		if backup, err := snapshot.GetBackup(restore.Spec.BackupName); err != nil {
+			logger.Error(errors.Wrap(err, "failed to get backup"))
+		} else if err := snapshot.UnquiesceRestoredWorkloads(context.TODO(), backup, restore); err != nil {
+			logger.Error(errors.Wrap(err, "failed to scale back restored workloads that were quiesced for the backup"))
+		}
+
 		if err := app.ResetRestore(a.ID); err != nil {
 			return errors.Wrap(err, "failed to reset restore")
 		}
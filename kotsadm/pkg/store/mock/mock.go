@@ -10,19 +10,20 @@ import (
 	types "github.com/replicatedhq/kots/kotsadm/pkg/airgap/types"
 	types0 "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
 	types1 "github.com/replicatedhq/kots/kotsadm/pkg/gitops/types"
-	types2 "github.com/replicatedhq/kots/kotsadm/pkg/online/types"
-	types3 "github.com/replicatedhq/kots/kotsadm/pkg/preflight/types"
-	types4 "github.com/replicatedhq/kots/kotsadm/pkg/registry/types"
-	types5 "github.com/replicatedhq/kots/kotsadm/pkg/render/types"
-	types6 "github.com/replicatedhq/kots/kotsadm/pkg/session/types"
-	types7 "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
-	types8 "github.com/replicatedhq/kots/kotsadm/pkg/supportbundle/types"
-	types9 "github.com/replicatedhq/kots/kotsadm/pkg/user/types"
+	types2 "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
+	types3 "github.com/replicatedhq/kots/kotsadm/pkg/online/types"
+	types4 "github.com/replicatedhq/kots/kotsadm/pkg/preflight/types"
+	types5 "github.com/replicatedhq/kots/kotsadm/pkg/registry/types"
+	types6 "github.com/replicatedhq/kots/kotsadm/pkg/render/types"
+	types7 "github.com/replicatedhq/kots/kotsadm/pkg/session/types"
+	types8 "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
+	types9 "github.com/replicatedhq/kots/kotsadm/pkg/supportbundle/types"
+	types10 "github.com/replicatedhq/kots/kotsadm/pkg/user/types"
 	v1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
-	types10 "github.com/replicatedhq/kots/pkg/api/appstatus/types"
-	types11 "github.com/replicatedhq/kots/pkg/api/downstream/types"
-	types12 "github.com/replicatedhq/kots/pkg/api/version/types"
-	types13 "github.com/replicatedhq/kots/pkg/upstream/types"
+	types11 "github.com/replicatedhq/kots/pkg/api/appstatus/types"
+	types12 "github.com/replicatedhq/kots/pkg/api/downstream/types"
+	types13 "github.com/replicatedhq/kots/pkg/api/version/types"
+	types14 "github.com/replicatedhq/kots/pkg/upstream/types"
 	redact "github.com/replicatedhq/troubleshoot/pkg/redact"
 	reflect "reflect"
 	time "time"
@@ -64,10 +65,10 @@ func (mr *MockKOTSStoreMockRecorder) RunMigrations() *gomock.Call {
 }
 
 // GetRegistryDetailsForApp mocks base method
-func (m *MockKOTSStore) GetRegistryDetailsForApp(appID string) (*types4.RegistrySettings, error) {
+func (m *MockKOTSStore) GetRegistryDetailsForApp(appID string) (*types5.RegistrySettings, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRegistryDetailsForApp", appID)
-	ret0, _ := ret[0].(*types4.RegistrySettings)
+	ret0, _ := ret[0].(*types5.RegistrySettings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -93,10 +94,10 @@ func (mr *MockKOTSStoreMockRecorder) UpdateRegistry(appID, hostname, username, p
 }
 
 // ListSupportBundles mocks base method
-func (m *MockKOTSStore) ListSupportBundles(appID string) ([]*types8.SupportBundle, error) {
+func (m *MockKOTSStore) ListSupportBundles(appID string) ([]*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSupportBundles", appID)
-	ret0, _ := ret[0].([]*types8.SupportBundle)
+	ret0, _ := ret[0].([]*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -108,10 +109,10 @@ func (mr *MockKOTSStoreMockRecorder) ListSupportBundles(appID interface{}) *gomo
 }
 
 // ListPendingSupportBundlesForApp mocks base method
-func (m *MockKOTSStore) ListPendingSupportBundlesForApp(appID string) ([]*types8.PendingSupportBundle, error) {
+func (m *MockKOTSStore) ListPendingSupportBundlesForApp(appID string) ([]*types9.PendingSupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingSupportBundlesForApp", appID)
-	ret0, _ := ret[0].([]*types8.PendingSupportBundle)
+	ret0, _ := ret[0].([]*types9.PendingSupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -123,10 +124,10 @@ func (mr *MockKOTSStoreMockRecorder) ListPendingSupportBundlesForApp(appID inter
 }
 
 // GetSupportBundleFromSlug mocks base method
-func (m *MockKOTSStore) GetSupportBundleFromSlug(slug string) (*types8.SupportBundle, error) {
+func (m *MockKOTSStore) GetSupportBundleFromSlug(slug string) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleFromSlug", slug)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -138,10 +139,10 @@ func (mr *MockKOTSStoreMockRecorder) GetSupportBundleFromSlug(slug interface{})
 }
 
 // GetSupportBundle mocks base method
-func (m *MockKOTSStore) GetSupportBundle(bundleID string) (*types8.SupportBundle, error) {
+func (m *MockKOTSStore) GetSupportBundle(bundleID string) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundle", bundleID)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -167,10 +168,10 @@ func (mr *MockKOTSStoreMockRecorder) CreatePendingSupportBundle(bundleID, appID,
 }
 
 // CreateSupportBundle mocks base method
-func (m *MockKOTSStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types8.SupportBundle, error) {
+func (m *MockKOTSStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSupportBundle", bundleID, appID, archivePath, marshalledTree)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -197,10 +198,10 @@ func (mr *MockKOTSStoreMockRecorder) GetSupportBundleArchive(bundleID interface{
 }
 
 // GetSupportBundleAnalysis mocks base method
-func (m *MockKOTSStore) GetSupportBundleAnalysis(bundleID string) (*types8.SupportBundleAnalysis, error) {
+func (m *MockKOTSStore) GetSupportBundleAnalysis(bundleID string) (*types9.SupportBundleAnalysis, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleAnalysis", bundleID)
-	ret0, _ := ret[0].(*types8.SupportBundleAnalysis)
+	ret0, _ := ret[0].(*types9.SupportBundleAnalysis)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -284,10 +285,10 @@ func (mr *MockKOTSStoreMockRecorder) SetPreflightResults(appID, sequence, result
 }
 
 // GetPreflightResults mocks base method
-func (m *MockKOTSStore) GetPreflightResults(appID string, sequence int64) (*types3.PreflightResult, error) {
+func (m *MockKOTSStore) GetPreflightResults(appID string, sequence int64) (*types4.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPreflightResults", appID, sequence)
-	ret0, _ := ret[0].(*types3.PreflightResult)
+	ret0, _ := ret[0].(*types4.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -299,10 +300,10 @@ func (mr *MockKOTSStoreMockRecorder) GetPreflightResults(appID, sequence interfa
 }
 
 // GetLatestPreflightResultsForSequenceZero mocks base method
-func (m *MockKOTSStore) GetLatestPreflightResultsForSequenceZero() (*types3.PreflightResult, error) {
+func (m *MockKOTSStore) GetLatestPreflightResultsForSequenceZero() (*types4.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetLatestPreflightResultsForSequenceZero")
-	ret0, _ := ret[0].(*types3.PreflightResult)
+	ret0, _ := ret[0].(*types4.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -487,10 +488,10 @@ func (mr *MockKOTSStoreMockRecorder) GetTaskStatus(taskID interface{}) *gomock.C
 }
 
 // CreateSession mocks base method
-func (m *MockKOTSStore) CreateSession(user *types9.User, issuedAt, expiresAt time.Time, roles []string) (*types6.Session, error) {
+func (m *MockKOTSStore) CreateSession(user *types10.User, issuedAt, expiresAt time.Time, roles []string) (*types7.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", user, issuedAt, expiresAt, roles)
-	ret0, _ := ret[0].(*types6.Session)
+	ret0, _ := ret[0].(*types7.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -516,10 +517,10 @@ func (mr *MockKOTSStoreMockRecorder) DeleteSession(sessionID interface{}) *gomoc
 }
 
 // GetSession mocks base method
-func (m *MockKOTSStore) GetSession(sessionID string) (*types6.Session, error) {
+func (m *MockKOTSStore) GetSession(sessionID string) (*types7.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", sessionID)
-	ret0, _ := ret[0].(*types6.Session)
+	ret0, _ := ret[0].(*types7.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -531,10 +532,10 @@ func (mr *MockKOTSStoreMockRecorder) GetSession(sessionID interface{}) *gomock.C
 }
 
 // GetAppStatus mocks base method
-func (m *MockKOTSStore) GetAppStatus(appID string) (*types10.AppStatus, error) {
+func (m *MockKOTSStore) GetAppStatus(appID string) (*types11.AppStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppStatus", appID)
-	ret0, _ := ret[0].(*types10.AppStatus)
+	ret0, _ := ret[0].(*types11.AppStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -664,10 +665,10 @@ func (mr *MockKOTSStoreMockRecorder) CreateApp(name, upstreamURI, licenseData, i
 }
 
 // ListDownstreamsForApp mocks base method
-func (m *MockKOTSStore) ListDownstreamsForApp(appID string) ([]types11.Downstream, error) {
+func (m *MockKOTSStore) ListDownstreamsForApp(appID string) ([]types12.Downstream, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListDownstreamsForApp", appID)
-	ret0, _ := ret[0].([]types11.Downstream)
+	ret0, _ := ret[0].([]types12.Downstream)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -694,10 +695,10 @@ func (mr *MockKOTSStoreMockRecorder) ListAppsForDownstream(clusterID interface{}
 }
 
 // GetDownstream mocks base method
-func (m *MockKOTSStore) GetDownstream(clusterID string) (*types11.Downstream, error) {
+func (m *MockKOTSStore) GetDownstream(clusterID string) (*types12.Downstream, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetDownstream", clusterID)
-	ret0, _ := ret[0].(*types11.Downstream)
+	ret0, _ := ret[0].(*types12.Downstream)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -737,6 +738,20 @@ func (mr *MockKOTSStoreMockRecorder) SetUpdateCheckerSpec(appID, updateCheckerSp
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUpdateCheckerSpec", reflect.TypeOf((*MockKOTSStore)(nil).SetUpdateCheckerSpec), appID, updateCheckerSpec)
 }
 
+// SetLicenseSyncInterval mocks base method
+func (m *MockKOTSStore) SetLicenseSyncInterval(appID string, intervalMinutes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLicenseSyncInterval", appID, intervalMinutes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLicenseSyncInterval indicates an expected call of SetLicenseSyncInterval
+func (mr *MockKOTSStoreMockRecorder) SetLicenseSyncInterval(appID, intervalMinutes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLicenseSyncInterval", reflect.TypeOf((*MockKOTSStore)(nil).SetLicenseSyncInterval), appID, intervalMinutes)
+}
+
 // SetSnapshotTTL mocks base method
 func (m *MockKOTSStore) SetSnapshotTTL(appID, snapshotTTL string) error {
 	m.ctrl.T.Helper()
@@ -765,6 +780,160 @@ func (mr *MockKOTSStoreMockRecorder) SetSnapshotSchedule(appID, snapshotSchedule
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotSchedule", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotSchedule), appID, snapshotSchedule)
 }
 
+// SetSnapshotScheduleTTL mocks base method
+func (m *MockKOTSStore) SetSnapshotScheduleTTL(appID, snapshotScheduleTTL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotScheduleTTL", appID, snapshotScheduleTTL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotScheduleTTL indicates an expected call of SetSnapshotScheduleTTL
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotScheduleTTL(appID, snapshotScheduleTTL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotScheduleTTL", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotScheduleTTL), appID, snapshotScheduleTTL)
+}
+
+// SetSnapshotScheduleJitter mocks base method
+func (m *MockKOTSStore) SetSnapshotScheduleJitter(appID string, jitterMinutes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotScheduleJitter", appID, jitterMinutes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotScheduleJitter indicates an expected call of SetSnapshotScheduleJitter
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotScheduleJitter(appID, jitterMinutes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotScheduleJitter", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotScheduleJitter), appID, jitterMinutes)
+}
+
+// SetSnapshotDefaultVolumesToRestic mocks base method
+func (m *MockKOTSStore) SetSnapshotDefaultVolumesToRestic(appID string, defaultVolumesToRestic *bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotDefaultVolumesToRestic", appID, defaultVolumesToRestic)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotDefaultVolumesToRestic indicates an expected call of SetSnapshotDefaultVolumesToRestic
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotDefaultVolumesToRestic(appID, defaultVolumesToRestic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotDefaultVolumesToRestic", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotDefaultVolumesToRestic), appID, defaultVolumesToRestic)
+}
+
+// SetSnapshotQuiesceActions mocks base method
+func (m *MockKOTSStore) SetSnapshotQuiesceActions(appID string, actions []types8.QuiesceAction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotQuiesceActions", appID, actions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotQuiesceActions indicates an expected call of SetSnapshotQuiesceActions
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotQuiesceActions(appID, actions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotQuiesceActions", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotQuiesceActions), appID, actions)
+}
+
+// SetSnapshotChecksumTargets mocks base method
+func (m *MockKOTSStore) SetSnapshotChecksumTargets(appID string, targets []types8.ChecksumTarget) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotChecksumTargets", appID, targets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotChecksumTargets indicates an expected call of SetSnapshotChecksumTargets
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotChecksumTargets(appID, targets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotChecksumTargets", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotChecksumTargets), appID, targets)
+}
+
+// SetSnapshotNamespaces mocks base method
+func (m *MockKOTSStore) SetSnapshotNamespaces(appID string, included, excluded []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotNamespaces", appID, included, excluded)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotNamespaces indicates an expected call of SetSnapshotNamespaces
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotNamespaces(appID, included, excluded interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotNamespaces", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotNamespaces), appID, included, excluded)
+}
+
+// SetSnapshotHookSettings mocks base method
+func (m *MockKOTSStore) SetSnapshotHookSettings(appID string, settings *types8.HookSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotHookSettings", appID, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotHookSettings indicates an expected call of SetSnapshotHookSettings
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotHookSettings(appID, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotHookSettings", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotHookSettings), appID, settings)
+}
+
+// SetSnapshotExcludedPVCs mocks base method
+func (m *MockKOTSStore) SetSnapshotExcludedPVCs(appID string, excludedPVCs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotExcludedPVCs", appID, excludedPVCs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotExcludedPVCs indicates an expected call of SetSnapshotExcludedPVCs
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotExcludedPVCs(appID, excludedPVCs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotExcludedPVCs", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotExcludedPVCs), appID, excludedPVCs)
+}
+
+// SetSnapshotFanOutLocations mocks base method
+func (m *MockKOTSStore) SetSnapshotFanOutLocations(appID string, locations []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotFanOutLocations", appID, locations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotFanOutLocations indicates an expected call of SetSnapshotFanOutLocations
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotFanOutLocations(appID, locations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotFanOutLocations", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotFanOutLocations), appID, locations)
+}
+
+// SetSnapshotResticPodSelector mocks base method
+func (m *MockKOTSStore) SetSnapshotResticPodSelector(appID string, selector string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotResticPodSelector", appID, selector)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotResticPodSelector indicates an expected call of SetSnapshotResticPodSelector
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotResticPodSelector(appID, selector interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotResticPodSelector", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotResticPodSelector), appID, selector)
+}
+
+// SetSnapshotMaxConcurrentBackups mocks base method
+func (m *MockKOTSStore) SetSnapshotMaxConcurrentBackups(appID string, maxConcurrent int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotMaxConcurrentBackups", appID, maxConcurrent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotMaxConcurrentBackups indicates an expected call of SetSnapshotMaxConcurrentBackups
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotMaxConcurrentBackups(appID, maxConcurrent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotMaxConcurrentBackups", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotMaxConcurrentBackups), appID, maxConcurrent)
+}
+
 // RemoveApp mocks base method
 func (m *MockKOTSStore) RemoveApp(appID string) error {
 	m.ctrl.T.Helper()
@@ -810,7 +979,7 @@ func (mr *MockKOTSStoreMockRecorder) IsRollbackSupportedForVersion(appID, sequen
 }
 
 // IsSnapshotsSupportedForVersion mocks base method
-func (m *MockKOTSStore) IsSnapshotsSupportedForVersion(a *types0.App, sequence int64, renderer types5.Renderer) (bool, error) {
+func (m *MockKOTSStore) IsSnapshotsSupportedForVersion(a *types0.App, sequence int64, renderer types6.Renderer) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsSnapshotsSupportedForVersion", a, sequence, renderer)
 	ret0, _ := ret[0].(bool)
@@ -868,10 +1037,10 @@ func (mr *MockKOTSStoreMockRecorder) CreateAppVersion(appID, currentSequence, fi
 }
 
 // GetAppVersion mocks base method
-func (m *MockKOTSStore) GetAppVersion(arg0 string, arg1 int64) (*types12.AppVersion, error) {
+func (m *MockKOTSStore) GetAppVersion(arg0 string, arg1 int64) (*types13.AppVersion, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppVersion", arg0, arg1)
-	ret0, _ := ret[0].(*types12.AppVersion)
+	ret0, _ := ret[0].(*types13.AppVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -883,10 +1052,10 @@ func (mr *MockKOTSStoreMockRecorder) GetAppVersion(arg0, arg1 interface{}) *gomo
 }
 
 // GetAppVersionsAfter mocks base method
-func (m *MockKOTSStore) GetAppVersionsAfter(arg0 string, arg1 int64) ([]*types12.AppVersion, error) {
+func (m *MockKOTSStore) GetAppVersionsAfter(arg0 string, arg1 int64) ([]*types13.AppVersion, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppVersionsAfter", arg0, arg1)
-	ret0, _ := ret[0].([]*types12.AppVersion)
+	ret0, _ := ret[0].([]*types13.AppVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -943,7 +1112,7 @@ func (mr *MockKOTSStoreMockRecorder) GetAllAppLicenses() *gomock.Call {
 }
 
 // UpdateAppLicense mocks base method
-func (m *MockKOTSStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops types1.DownstreamGitOps, renderer types5.Renderer) (int64, error) {
+func (m *MockKOTSStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops types1.DownstreamGitOps, renderer types6.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppLicense", appID, sequence, archiveDir, newLicense, originalLicenseData, failOnVersionCreate, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -957,11 +1126,40 @@ func (mr *MockKOTSStoreMockRecorder) UpdateAppLicense(appID, sequence, archiveDi
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppLicense", reflect.TypeOf((*MockKOTSStore)(nil).UpdateAppLicense), appID, sequence, archiveDir, newLicense, originalLicenseData, failOnVersionCreate, gitops, renderer)
 }
 
+// CreateLicenseChange mocks base method
+func (m *MockKOTSStore) CreateLicenseChange(change *types2.LicenseChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLicenseChange", change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLicenseChange indicates an expected call of CreateLicenseChange
+func (mr *MockKOTSStoreMockRecorder) CreateLicenseChange(change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLicenseChange", reflect.TypeOf((*MockKOTSStore)(nil).CreateLicenseChange), change)
+}
+
+// ListLicenseChanges mocks base method
+func (m *MockKOTSStore) ListLicenseChanges(appID string, limit int) ([]types2.LicenseChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLicenseChanges", appID, limit)
+	ret0, _ := ret[0].([]types2.LicenseChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLicenseChanges indicates an expected call of ListLicenseChanges
+func (mr *MockKOTSStoreMockRecorder) ListLicenseChanges(appID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLicenseChanges", reflect.TypeOf((*MockKOTSStore)(nil).ListLicenseChanges), appID, limit)
+}
+
 // ListClusters mocks base method
-func (m *MockKOTSStore) ListClusters() ([]*types11.Downstream, error) {
+func (m *MockKOTSStore) ListClusters() ([]*types12.Downstream, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListClusters")
-	ret0, _ := ret[0].([]*types11.Downstream)
+	ret0, _ := ret[0].([]*types12.Downstream)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1045,11 +1243,67 @@ func (mr *MockKOTSStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, snap
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockKOTSStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
 }
 
+// SetInstanceSnapshotClusterResources mocks base method
+func (m *MockKOTSStore) SetInstanceSnapshotClusterResources(clusterID string, includedResources []string, excludedResources []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotClusterResources", clusterID, includedResources, excludedResources)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotClusterResources indicates an expected call of SetInstanceSnapshotClusterResources
+func (mr *MockKOTSStoreMockRecorder) SetInstanceSnapshotClusterResources(clusterID, includedResources, excludedResources interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotClusterResources", reflect.TypeOf((*MockKOTSStore)(nil).SetInstanceSnapshotClusterResources), clusterID, includedResources, excludedResources)
+}
+
+// SetInstanceSnapshotExcludedPVCs mocks base method
+func (m *MockKOTSStore) SetInstanceSnapshotExcludedPVCs(clusterID string, excludedPVCs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotExcludedPVCs", clusterID, excludedPVCs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotExcludedPVCs indicates an expected call of SetInstanceSnapshotExcludedPVCs
+func (mr *MockKOTSStoreMockRecorder) SetInstanceSnapshotExcludedPVCs(clusterID, excludedPVCs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotExcludedPVCs", reflect.TypeOf((*MockKOTSStore)(nil).SetInstanceSnapshotExcludedPVCs), clusterID, excludedPVCs)
+}
+
+// SetInstanceSnapshotFanOutLocations mocks base method
+func (m *MockKOTSStore) SetInstanceSnapshotFanOutLocations(clusterID string, locations []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotFanOutLocations", clusterID, locations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotFanOutLocations indicates an expected call of SetInstanceSnapshotFanOutLocations
+func (mr *MockKOTSStoreMockRecorder) SetInstanceSnapshotFanOutLocations(clusterID, locations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotFanOutLocations", reflect.TypeOf((*MockKOTSStore)(nil).SetInstanceSnapshotFanOutLocations), clusterID, locations)
+}
+
+// SetInstanceSnapshotIncludeRegistryData mocks base method
+func (m *MockKOTSStore) SetInstanceSnapshotIncludeRegistryData(clusterID string, include bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotIncludeRegistryData", clusterID, include)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotIncludeRegistryData indicates an expected call of SetInstanceSnapshotIncludeRegistryData
+func (mr *MockKOTSStoreMockRecorder) SetInstanceSnapshotIncludeRegistryData(clusterID, include interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotIncludeRegistryData", reflect.TypeOf((*MockKOTSStore)(nil).SetInstanceSnapshotIncludeRegistryData), clusterID, include)
+}
+
 // ListPendingScheduledSnapshots mocks base method
-func (m *MockKOTSStore) ListPendingScheduledSnapshots(appID string) ([]types7.ScheduledSnapshot, error) {
+func (m *MockKOTSStore) ListPendingScheduledSnapshots(appID string) ([]types8.ScheduledSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledSnapshots", appID)
-	ret0, _ := ret[0].([]types7.ScheduledSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1060,6 +1314,21 @@ func (mr *MockKOTSStoreMockRecorder) ListPendingScheduledSnapshots(appID interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledSnapshots", reflect.TypeOf((*MockKOTSStore)(nil).ListPendingScheduledSnapshots), appID)
 }
 
+// GetLastHandledScheduledSnapshot mocks base method
+func (m *MockKOTSStore) GetLastHandledScheduledSnapshot(appID string) (*types8.ScheduledSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastHandledScheduledSnapshot", appID)
+	ret0, _ := ret[0].(*types8.ScheduledSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastHandledScheduledSnapshot indicates an expected call of GetLastHandledScheduledSnapshot
+func (mr *MockKOTSStoreMockRecorder) GetLastHandledScheduledSnapshot(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastHandledScheduledSnapshot", reflect.TypeOf((*MockKOTSStore)(nil).GetLastHandledScheduledSnapshot), appID)
+}
+
 // UpdateScheduledSnapshot mocks base method
 func (m *MockKOTSStore) UpdateScheduledSnapshot(snapshotID, backupName string) error {
 	m.ctrl.T.Helper()
@@ -1102,11 +1371,25 @@ func (mr *MockKOTSStoreMockRecorder) CreateScheduledSnapshot(snapshotID, appID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledSnapshot", reflect.TypeOf((*MockKOTSStore)(nil).CreateScheduledSnapshot), snapshotID, appID, timestamp)
 }
 
+// MarkScheduledSnapshotQueued mocks base method
+func (m *MockKOTSStore) MarkScheduledSnapshotQueued(snapshotID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkScheduledSnapshotQueued", snapshotID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkScheduledSnapshotQueued indicates an expected call of MarkScheduledSnapshotQueued
+func (mr *MockKOTSStoreMockRecorder) MarkScheduledSnapshotQueued(snapshotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkScheduledSnapshotQueued", reflect.TypeOf((*MockKOTSStore)(nil).MarkScheduledSnapshotQueued), snapshotID)
+}
+
 // ListPendingScheduledInstanceSnapshots mocks base method
-func (m *MockKOTSStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types7.ScheduledInstanceSnapshot, error) {
+func (m *MockKOTSStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types8.ScheduledInstanceSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledInstanceSnapshots", clusterID)
-	ret0, _ := ret[0].([]types7.ScheduledInstanceSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledInstanceSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1159,11 +1442,229 @@ func (mr *MockKOTSStoreMockRecorder) CreateScheduledInstanceSnapshot(snapshotID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledInstanceSnapshot", reflect.TypeOf((*MockKOTSStore)(nil).CreateScheduledInstanceSnapshot), snapshotID, clusterID, timestamp)
 }
 
+// ListScheduledSnapshotBackupNames mocks base method
+func (m *MockKOTSStore) ListScheduledSnapshotBackupNames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledSnapshotBackupNames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledSnapshotBackupNames indicates an expected call of ListScheduledSnapshotBackupNames
+func (mr *MockKOTSStoreMockRecorder) ListScheduledSnapshotBackupNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledSnapshotBackupNames", reflect.TypeOf((*MockKOTSStore)(nil).ListScheduledSnapshotBackupNames))
+}
+
+// GetBackupWebhook mocks base method
+func (m *MockKOTSStore) GetBackupWebhook() (*types8.BackupWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBackupWebhook")
+	ret0, _ := ret[0].(*types8.BackupWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackupWebhook indicates an expected call of GetBackupWebhook
+func (mr *MockKOTSStoreMockRecorder) GetBackupWebhook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupWebhook", reflect.TypeOf((*MockKOTSStore)(nil).GetBackupWebhook))
+}
+
+// SetBackupWebhook mocks base method
+func (m *MockKOTSStore) SetBackupWebhook(webhook *types8.BackupWebhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBackupWebhook", webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBackupWebhook indicates an expected call of SetBackupWebhook
+func (mr *MockKOTSStoreMockRecorder) SetBackupWebhook(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBackupWebhook", reflect.TypeOf((*MockKOTSStore)(nil).SetBackupWebhook), webhook)
+}
+
+// GetMaxConcurrentScheduledSnapshots mocks base method
+func (m *MockKOTSStore) GetMaxConcurrentScheduledSnapshots() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxConcurrentScheduledSnapshots")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxConcurrentScheduledSnapshots indicates an expected call of GetMaxConcurrentScheduledSnapshots
+func (mr *MockKOTSStoreMockRecorder) GetMaxConcurrentScheduledSnapshots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxConcurrentScheduledSnapshots", reflect.TypeOf((*MockKOTSStore)(nil).GetMaxConcurrentScheduledSnapshots))
+}
+
+// SetMaxConcurrentScheduledSnapshots mocks base method
+func (m *MockKOTSStore) SetMaxConcurrentScheduledSnapshots(max int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaxConcurrentScheduledSnapshots", max)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaxConcurrentScheduledSnapshots indicates an expected call of SetMaxConcurrentScheduledSnapshots
+func (mr *MockKOTSStoreMockRecorder) SetMaxConcurrentScheduledSnapshots(max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxConcurrentScheduledSnapshots", reflect.TypeOf((*MockKOTSStore)(nil).SetMaxConcurrentScheduledSnapshots), max)
+}
+
+// GetSnapshotDefaults mocks base method
+func (m *MockKOTSStore) GetSnapshotDefaults() (*types8.SnapshotDefaults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshotDefaults")
+	ret0, _ := ret[0].(*types8.SnapshotDefaults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshotDefaults indicates an expected call of GetSnapshotDefaults
+func (mr *MockKOTSStoreMockRecorder) GetSnapshotDefaults() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotDefaults", reflect.TypeOf((*MockKOTSStore)(nil).GetSnapshotDefaults))
+}
+
+// SetSnapshotDefaults mocks base method
+func (m *MockKOTSStore) SetSnapshotDefaults(defaults *types8.SnapshotDefaults) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotDefaults", defaults)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotDefaults indicates an expected call of SetSnapshotDefaults
+func (mr *MockKOTSStoreMockRecorder) SetSnapshotDefaults(defaults interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotDefaults", reflect.TypeOf((*MockKOTSStore)(nil).SetSnapshotDefaults), defaults)
+}
+
+// GetBackupResourceFilter mocks base method
+func (m *MockKOTSStore) GetBackupResourceFilter() (*types8.BackupResourceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBackupResourceFilter")
+	ret0, _ := ret[0].(*types8.BackupResourceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackupResourceFilter indicates an expected call of GetBackupResourceFilter
+func (mr *MockKOTSStoreMockRecorder) GetBackupResourceFilter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupResourceFilter", reflect.TypeOf((*MockKOTSStore)(nil).GetBackupResourceFilter))
+}
+
+// SetBackupResourceFilter mocks base method
+func (m *MockKOTSStore) SetBackupResourceFilter(filter *types8.BackupResourceFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBackupResourceFilter", filter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBackupResourceFilter indicates an expected call of SetBackupResourceFilter
+func (mr *MockKOTSStoreMockRecorder) SetBackupResourceFilter(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBackupResourceFilter", reflect.TypeOf((*MockKOTSStore)(nil).SetBackupResourceFilter), filter)
+}
+
+// GetVeleroExtraContainers mocks base method
+func (m *MockKOTSStore) GetVeleroExtraContainers() (*types8.VeleroExtraContainers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVeleroExtraContainers")
+	ret0, _ := ret[0].(*types8.VeleroExtraContainers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVeleroExtraContainers indicates an expected call of GetVeleroExtraContainers
+func (mr *MockKOTSStoreMockRecorder) GetVeleroExtraContainers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroExtraContainers", reflect.TypeOf((*MockKOTSStore)(nil).GetVeleroExtraContainers))
+}
+
+// SetVeleroExtraContainers mocks base method
+func (m *MockKOTSStore) SetVeleroExtraContainers(extra *types8.VeleroExtraContainers) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVeleroExtraContainers", extra)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVeleroExtraContainers indicates an expected call of SetVeleroExtraContainers
+func (mr *MockKOTSStoreMockRecorder) SetVeleroExtraContainers(extra interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVeleroExtraContainers", reflect.TypeOf((*MockKOTSStore)(nil).SetVeleroExtraContainers), extra)
+}
+
+// GetVeleroImageRegistry mocks base method
+func (m *MockKOTSStore) GetVeleroImageRegistry() (*types8.VeleroImageRegistry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVeleroImageRegistry")
+	ret0, _ := ret[0].(*types8.VeleroImageRegistry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVeleroImageRegistry indicates an expected call of GetVeleroImageRegistry
+func (mr *MockKOTSStoreMockRecorder) GetVeleroImageRegistry() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroImageRegistry", reflect.TypeOf((*MockKOTSStore)(nil).GetVeleroImageRegistry))
+}
+
+// SetVeleroImageRegistry mocks base method
+func (m *MockKOTSStore) SetVeleroImageRegistry(veleroRegistry *types8.VeleroImageRegistry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVeleroImageRegistry", veleroRegistry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVeleroImageRegistry indicates an expected call of SetVeleroImageRegistry
+func (mr *MockKOTSStoreMockRecorder) SetVeleroImageRegistry(veleroRegistry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVeleroImageRegistry", reflect.TypeOf((*MockKOTSStore)(nil).SetVeleroImageRegistry), veleroRegistry)
+}
+
+// CreateSnapshotAuditEvent mocks base method
+func (m *MockKOTSStore) CreateSnapshotAuditEvent(event *types8.SnapshotAuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSnapshotAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSnapshotAuditEvent indicates an expected call of CreateSnapshotAuditEvent
+func (mr *MockKOTSStoreMockRecorder) CreateSnapshotAuditEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSnapshotAuditEvent", reflect.TypeOf((*MockKOTSStore)(nil).CreateSnapshotAuditEvent), event)
+}
+
+// ListSnapshotAuditEvents mocks base method
+func (m *MockKOTSStore) ListSnapshotAuditEvents(appID string, limit int) ([]types8.SnapshotAuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSnapshotAuditEvents", appID, limit)
+	ret0, _ := ret[0].([]types8.SnapshotAuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSnapshotAuditEvents indicates an expected call of ListSnapshotAuditEvents
+func (mr *MockKOTSStoreMockRecorder) ListSnapshotAuditEvents(appID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshotAuditEvents", reflect.TypeOf((*MockKOTSStore)(nil).ListSnapshotAuditEvents), appID, limit)
+}
+
 // GetPendingInstallationStatus mocks base method
-func (m *MockKOTSStore) GetPendingInstallationStatus() (*types2.InstallStatus, error) {
+func (m *MockKOTSStore) GetPendingInstallationStatus() (*types3.InstallStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPendingInstallationStatus")
-	ret0, _ := ret[0].(*types2.InstallStatus)
+	ret0, _ := ret[0].(*types3.InstallStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1175,10 +1676,10 @@ func (mr *MockKOTSStoreMockRecorder) GetPendingInstallationStatus() *gomock.Call
 }
 
 // GetReportingInfo mocks base method
-func (m *MockKOTSStore) GetReportingInfo(appID string) *types13.ReportingInfo {
+func (m *MockKOTSStore) GetReportingInfo(appID string) *types14.ReportingInfo {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetReportingInfo", appID)
-	ret0, _ := ret[0].(*types13.ReportingInfo)
+	ret0, _ := ret[0].(*types14.ReportingInfo)
 	return ret0
 }
 
@@ -1289,10 +1790,10 @@ func (m *MockRegistryStore) EXPECT() *MockRegistryStoreMockRecorder {
 }
 
 // GetRegistryDetailsForApp mocks base method
-func (m *MockRegistryStore) GetRegistryDetailsForApp(appID string) (*types4.RegistrySettings, error) {
+func (m *MockRegistryStore) GetRegistryDetailsForApp(appID string) (*types5.RegistrySettings, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRegistryDetailsForApp", appID)
-	ret0, _ := ret[0].(*types4.RegistrySettings)
+	ret0, _ := ret[0].(*types5.RegistrySettings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1341,10 +1842,10 @@ func (m *MockSupportBundleStore) EXPECT() *MockSupportBundleStoreMockRecorder {
 }
 
 // ListSupportBundles mocks base method
-func (m *MockSupportBundleStore) ListSupportBundles(appID string) ([]*types8.SupportBundle, error) {
+func (m *MockSupportBundleStore) ListSupportBundles(appID string) ([]*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListSupportBundles", appID)
-	ret0, _ := ret[0].([]*types8.SupportBundle)
+	ret0, _ := ret[0].([]*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1356,10 +1857,10 @@ func (mr *MockSupportBundleStoreMockRecorder) ListSupportBundles(appID interface
 }
 
 // ListPendingSupportBundlesForApp mocks base method
-func (m *MockSupportBundleStore) ListPendingSupportBundlesForApp(appID string) ([]*types8.PendingSupportBundle, error) {
+func (m *MockSupportBundleStore) ListPendingSupportBundlesForApp(appID string) ([]*types9.PendingSupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingSupportBundlesForApp", appID)
-	ret0, _ := ret[0].([]*types8.PendingSupportBundle)
+	ret0, _ := ret[0].([]*types9.PendingSupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1371,10 +1872,10 @@ func (mr *MockSupportBundleStoreMockRecorder) ListPendingSupportBundlesForApp(ap
 }
 
 // GetSupportBundleFromSlug mocks base method
-func (m *MockSupportBundleStore) GetSupportBundleFromSlug(slug string) (*types8.SupportBundle, error) {
+func (m *MockSupportBundleStore) GetSupportBundleFromSlug(slug string) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleFromSlug", slug)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1386,10 +1887,10 @@ func (mr *MockSupportBundleStoreMockRecorder) GetSupportBundleFromSlug(slug inte
 }
 
 // GetSupportBundle mocks base method
-func (m *MockSupportBundleStore) GetSupportBundle(bundleID string) (*types8.SupportBundle, error) {
+func (m *MockSupportBundleStore) GetSupportBundle(bundleID string) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundle", bundleID)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1415,10 +1916,10 @@ func (mr *MockSupportBundleStoreMockRecorder) CreatePendingSupportBundle(bundleI
 }
 
 // CreateSupportBundle mocks base method
-func (m *MockSupportBundleStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types8.SupportBundle, error) {
+func (m *MockSupportBundleStore) CreateSupportBundle(bundleID, appID, archivePath string, marshalledTree []byte) (*types9.SupportBundle, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSupportBundle", bundleID, appID, archivePath, marshalledTree)
-	ret0, _ := ret[0].(*types8.SupportBundle)
+	ret0, _ := ret[0].(*types9.SupportBundle)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1445,10 +1946,10 @@ func (mr *MockSupportBundleStoreMockRecorder) GetSupportBundleArchive(bundleID i
 }
 
 // GetSupportBundleAnalysis mocks base method
-func (m *MockSupportBundleStore) GetSupportBundleAnalysis(bundleID string) (*types8.SupportBundleAnalysis, error) {
+func (m *MockSupportBundleStore) GetSupportBundleAnalysis(bundleID string) (*types9.SupportBundleAnalysis, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSupportBundleAnalysis", bundleID)
-	ret0, _ := ret[0].(*types8.SupportBundleAnalysis)
+	ret0, _ := ret[0].(*types9.SupportBundleAnalysis)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1555,10 +2056,10 @@ func (mr *MockPreflightStoreMockRecorder) SetPreflightResults(appID, sequence, r
 }
 
 // GetPreflightResults mocks base method
-func (m *MockPreflightStore) GetPreflightResults(appID string, sequence int64) (*types3.PreflightResult, error) {
+func (m *MockPreflightStore) GetPreflightResults(appID string, sequence int64) (*types4.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPreflightResults", appID, sequence)
-	ret0, _ := ret[0].(*types3.PreflightResult)
+	ret0, _ := ret[0].(*types4.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1570,10 +2071,10 @@ func (mr *MockPreflightStoreMockRecorder) GetPreflightResults(appID, sequence in
 }
 
 // GetLatestPreflightResultsForSequenceZero mocks base method
-func (m *MockPreflightStore) GetLatestPreflightResultsForSequenceZero() (*types3.PreflightResult, error) {
+func (m *MockPreflightStore) GetLatestPreflightResultsForSequenceZero() (*types4.PreflightResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetLatestPreflightResultsForSequenceZero")
-	ret0, _ := ret[0].(*types3.PreflightResult)
+	ret0, _ := ret[0].(*types4.PreflightResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1850,10 +2351,10 @@ func (m *MockSessionStore) EXPECT() *MockSessionStoreMockRecorder {
 }
 
 // CreateSession mocks base method
-func (m *MockSessionStore) CreateSession(user *types9.User, issuedAt, expiresAt time.Time, roles []string) (*types6.Session, error) {
+func (m *MockSessionStore) CreateSession(user *types10.User, issuedAt, expiresAt time.Time, roles []string) (*types7.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateSession", user, issuedAt, expiresAt, roles)
-	ret0, _ := ret[0].(*types6.Session)
+	ret0, _ := ret[0].(*types7.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1879,10 +2380,10 @@ func (mr *MockSessionStoreMockRecorder) DeleteSession(sessionID interface{}) *go
 }
 
 // GetSession mocks base method
-func (m *MockSessionStore) GetSession(sessionID string) (*types6.Session, error) {
+func (m *MockSessionStore) GetSession(sessionID string) (*types7.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", sessionID)
-	ret0, _ := ret[0].(*types6.Session)
+	ret0, _ := ret[0].(*types7.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -1917,10 +2418,10 @@ func (m *MockAppStatusStore) EXPECT() *MockAppStatusStoreMockRecorder {
 }
 
 // GetAppStatus mocks base method
-func (m *MockAppStatusStore) GetAppStatus(appID string) (*types10.AppStatus, error) {
+func (m *MockAppStatusStore) GetAppStatus(appID string) (*types11.AppStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppStatus", appID)
-	ret0, _ := ret[0].(*types10.AppStatus)
+	ret0, _ := ret[0].(*types11.AppStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2054,124 +2555,292 @@ func (m *MockAppStore) GetAppFromSlug(slug string) (*types0.App, error) {
 // GetAppFromSlug indicates an expected call of GetAppFromSlug
 func (mr *MockAppStoreMockRecorder) GetAppFromSlug(slug interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppFromSlug", reflect.TypeOf((*MockAppStore)(nil).GetAppFromSlug), slug)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppFromSlug", reflect.TypeOf((*MockAppStore)(nil).GetAppFromSlug), slug)
+}
+
+// CreateApp mocks base method
+func (m *MockAppStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush bool) (*types0.App, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApp", name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush)
+	ret0, _ := ret[0].(*types0.App)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApp indicates an expected call of CreateApp
+func (mr *MockAppStoreMockRecorder) CreateApp(name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApp", reflect.TypeOf((*MockAppStore)(nil).CreateApp), name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush)
+}
+
+// ListDownstreamsForApp mocks base method
+func (m *MockAppStore) ListDownstreamsForApp(appID string) ([]types12.Downstream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDownstreamsForApp", appID)
+	ret0, _ := ret[0].([]types12.Downstream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDownstreamsForApp indicates an expected call of ListDownstreamsForApp
+func (mr *MockAppStoreMockRecorder) ListDownstreamsForApp(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDownstreamsForApp", reflect.TypeOf((*MockAppStore)(nil).ListDownstreamsForApp), appID)
+}
+
+// ListAppsForDownstream mocks base method
+func (m *MockAppStore) ListAppsForDownstream(clusterID string) ([]*types0.App, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppsForDownstream", clusterID)
+	ret0, _ := ret[0].([]*types0.App)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppsForDownstream indicates an expected call of ListAppsForDownstream
+func (mr *MockAppStoreMockRecorder) ListAppsForDownstream(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsForDownstream", reflect.TypeOf((*MockAppStore)(nil).ListAppsForDownstream), clusterID)
+}
+
+// GetDownstream mocks base method
+func (m *MockAppStore) GetDownstream(clusterID string) (*types12.Downstream, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDownstream", clusterID)
+	ret0, _ := ret[0].(*types12.Downstream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDownstream indicates an expected call of GetDownstream
+func (mr *MockAppStoreMockRecorder) GetDownstream(clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstream", reflect.TypeOf((*MockAppStore)(nil).GetDownstream), clusterID)
+}
+
+// IsGitOpsEnabledForApp mocks base method
+func (m *MockAppStore) IsGitOpsEnabledForApp(appID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsGitOpsEnabledForApp", appID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsGitOpsEnabledForApp indicates an expected call of IsGitOpsEnabledForApp
+func (mr *MockAppStoreMockRecorder) IsGitOpsEnabledForApp(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsGitOpsEnabledForApp", reflect.TypeOf((*MockAppStore)(nil).IsGitOpsEnabledForApp), appID)
+}
+
+// SetUpdateCheckerSpec mocks base method
+func (m *MockAppStore) SetUpdateCheckerSpec(appID, updateCheckerSpec string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUpdateCheckerSpec", appID, updateCheckerSpec)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUpdateCheckerSpec indicates an expected call of SetUpdateCheckerSpec
+func (mr *MockAppStoreMockRecorder) SetUpdateCheckerSpec(appID, updateCheckerSpec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUpdateCheckerSpec", reflect.TypeOf((*MockAppStore)(nil).SetUpdateCheckerSpec), appID, updateCheckerSpec)
+}
+
+// SetLicenseSyncInterval mocks base method
+func (m *MockAppStore) SetLicenseSyncInterval(appID string, intervalMinutes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLicenseSyncInterval", appID, intervalMinutes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLicenseSyncInterval indicates an expected call of SetLicenseSyncInterval
+func (mr *MockAppStoreMockRecorder) SetLicenseSyncInterval(appID, intervalMinutes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLicenseSyncInterval", reflect.TypeOf((*MockAppStore)(nil).SetLicenseSyncInterval), appID, intervalMinutes)
+}
+
+// SetSnapshotTTL mocks base method
+func (m *MockAppStore) SetSnapshotTTL(appID, snapshotTTL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotTTL", appID, snapshotTTL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotTTL indicates an expected call of SetSnapshotTTL
+func (mr *MockAppStoreMockRecorder) SetSnapshotTTL(appID, snapshotTTL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotTTL", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotTTL), appID, snapshotTTL)
+}
+
+// SetSnapshotSchedule mocks base method
+func (m *MockAppStore) SetSnapshotSchedule(appID, snapshotSchedule string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotSchedule", appID, snapshotSchedule)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotSchedule indicates an expected call of SetSnapshotSchedule
+func (mr *MockAppStoreMockRecorder) SetSnapshotSchedule(appID, snapshotSchedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotSchedule", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotSchedule), appID, snapshotSchedule)
+}
+
+// SetSnapshotScheduleTTL mocks base method
+func (m *MockAppStore) SetSnapshotScheduleTTL(appID, snapshotScheduleTTL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotScheduleTTL", appID, snapshotScheduleTTL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotScheduleTTL indicates an expected call of SetSnapshotScheduleTTL
+func (mr *MockAppStoreMockRecorder) SetSnapshotScheduleTTL(appID, snapshotScheduleTTL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotScheduleTTL", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotScheduleTTL), appID, snapshotScheduleTTL)
+}
+
+// SetSnapshotScheduleJitter mocks base method
+func (m *MockAppStore) SetSnapshotScheduleJitter(appID string, jitterMinutes int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotScheduleJitter", appID, jitterMinutes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotScheduleJitter indicates an expected call of SetSnapshotScheduleJitter
+func (mr *MockAppStoreMockRecorder) SetSnapshotScheduleJitter(appID, jitterMinutes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotScheduleJitter", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotScheduleJitter), appID, jitterMinutes)
+}
+
+// SetSnapshotDefaultVolumesToRestic mocks base method
+func (m *MockAppStore) SetSnapshotDefaultVolumesToRestic(appID string, defaultVolumesToRestic *bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotDefaultVolumesToRestic", appID, defaultVolumesToRestic)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotDefaultVolumesToRestic indicates an expected call of SetSnapshotDefaultVolumesToRestic
+func (mr *MockAppStoreMockRecorder) SetSnapshotDefaultVolumesToRestic(appID, defaultVolumesToRestic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotDefaultVolumesToRestic", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotDefaultVolumesToRestic), appID, defaultVolumesToRestic)
 }
 
-// CreateApp mocks base method
-func (m *MockAppStore) CreateApp(name, upstreamURI, licenseData string, isAirgapEnabled, skipImagePush bool) (*types0.App, error) {
+// SetSnapshotQuiesceActions mocks base method
+func (m *MockAppStore) SetSnapshotQuiesceActions(appID string, actions []types8.QuiesceAction) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateApp", name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush)
-	ret0, _ := ret[0].(*types0.App)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetSnapshotQuiesceActions", appID, actions)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// CreateApp indicates an expected call of CreateApp
-func (mr *MockAppStoreMockRecorder) CreateApp(name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush interface{}) *gomock.Call {
+// SetSnapshotQuiesceActions indicates an expected call of SetSnapshotQuiesceActions
+func (mr *MockAppStoreMockRecorder) SetSnapshotQuiesceActions(appID, actions interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApp", reflect.TypeOf((*MockAppStore)(nil).CreateApp), name, upstreamURI, licenseData, isAirgapEnabled, skipImagePush)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotQuiesceActions", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotQuiesceActions), appID, actions)
 }
 
-// ListDownstreamsForApp mocks base method
-func (m *MockAppStore) ListDownstreamsForApp(appID string) ([]types11.Downstream, error) {
+// SetSnapshotChecksumTargets mocks base method
+func (m *MockAppStore) SetSnapshotChecksumTargets(appID string, targets []types8.ChecksumTarget) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListDownstreamsForApp", appID)
-	ret0, _ := ret[0].([]types11.Downstream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetSnapshotChecksumTargets", appID, targets)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListDownstreamsForApp indicates an expected call of ListDownstreamsForApp
-func (mr *MockAppStoreMockRecorder) ListDownstreamsForApp(appID interface{}) *gomock.Call {
+// SetSnapshotChecksumTargets indicates an expected call of SetSnapshotChecksumTargets
+func (mr *MockAppStoreMockRecorder) SetSnapshotChecksumTargets(appID, targets interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDownstreamsForApp", reflect.TypeOf((*MockAppStore)(nil).ListDownstreamsForApp), appID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotChecksumTargets", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotChecksumTargets), appID, targets)
 }
 
-// ListAppsForDownstream mocks base method
-func (m *MockAppStore) ListAppsForDownstream(clusterID string) ([]*types0.App, error) {
+// SetSnapshotNamespaces mocks base method
+func (m *MockAppStore) SetSnapshotNamespaces(appID string, included, excluded []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAppsForDownstream", clusterID)
-	ret0, _ := ret[0].([]*types0.App)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetSnapshotNamespaces", appID, included, excluded)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// ListAppsForDownstream indicates an expected call of ListAppsForDownstream
-func (mr *MockAppStoreMockRecorder) ListAppsForDownstream(clusterID interface{}) *gomock.Call {
+// SetSnapshotNamespaces indicates an expected call of SetSnapshotNamespaces
+func (mr *MockAppStoreMockRecorder) SetSnapshotNamespaces(appID, included, excluded interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppsForDownstream", reflect.TypeOf((*MockAppStore)(nil).ListAppsForDownstream), clusterID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotNamespaces", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotNamespaces), appID, included, excluded)
 }
 
-// GetDownstream mocks base method
-func (m *MockAppStore) GetDownstream(clusterID string) (*types11.Downstream, error) {
+// SetSnapshotHookSettings mocks base method
+func (m *MockAppStore) SetSnapshotHookSettings(appID string, settings *types8.HookSettings) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetDownstream", clusterID)
-	ret0, _ := ret[0].(*types11.Downstream)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetSnapshotHookSettings", appID, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetDownstream indicates an expected call of GetDownstream
-func (mr *MockAppStoreMockRecorder) GetDownstream(clusterID interface{}) *gomock.Call {
+// SetSnapshotHookSettings indicates an expected call of SetSnapshotHookSettings
+func (mr *MockAppStoreMockRecorder) SetSnapshotHookSettings(appID, settings interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDownstream", reflect.TypeOf((*MockAppStore)(nil).GetDownstream), clusterID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotHookSettings", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotHookSettings), appID, settings)
 }
 
-// IsGitOpsEnabledForApp mocks base method
-func (m *MockAppStore) IsGitOpsEnabledForApp(appID string) (bool, error) {
+// SetSnapshotExcludedPVCs mocks base method
+func (m *MockAppStore) SetSnapshotExcludedPVCs(appID string, excludedPVCs []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsGitOpsEnabledForApp", appID)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "SetSnapshotExcludedPVCs", appID, excludedPVCs)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// IsGitOpsEnabledForApp indicates an expected call of IsGitOpsEnabledForApp
-func (mr *MockAppStoreMockRecorder) IsGitOpsEnabledForApp(appID interface{}) *gomock.Call {
+// SetSnapshotExcludedPVCs indicates an expected call of SetSnapshotExcludedPVCs
+func (mr *MockAppStoreMockRecorder) SetSnapshotExcludedPVCs(appID, excludedPVCs interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsGitOpsEnabledForApp", reflect.TypeOf((*MockAppStore)(nil).IsGitOpsEnabledForApp), appID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotExcludedPVCs", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotExcludedPVCs), appID, excludedPVCs)
 }
 
-// SetUpdateCheckerSpec mocks base method
-func (m *MockAppStore) SetUpdateCheckerSpec(appID, updateCheckerSpec string) error {
+// SetSnapshotFanOutLocations mocks base method
+func (m *MockAppStore) SetSnapshotFanOutLocations(appID string, locations []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetUpdateCheckerSpec", appID, updateCheckerSpec)
+	ret := m.ctrl.Call(m, "SetSnapshotFanOutLocations", appID, locations)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetUpdateCheckerSpec indicates an expected call of SetUpdateCheckerSpec
-func (mr *MockAppStoreMockRecorder) SetUpdateCheckerSpec(appID, updateCheckerSpec interface{}) *gomock.Call {
+// SetSnapshotFanOutLocations indicates an expected call of SetSnapshotFanOutLocations
+func (mr *MockAppStoreMockRecorder) SetSnapshotFanOutLocations(appID, locations interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUpdateCheckerSpec", reflect.TypeOf((*MockAppStore)(nil).SetUpdateCheckerSpec), appID, updateCheckerSpec)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotFanOutLocations", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotFanOutLocations), appID, locations)
 }
 
-// SetSnapshotTTL mocks base method
-func (m *MockAppStore) SetSnapshotTTL(appID, snapshotTTL string) error {
+// SetSnapshotResticPodSelector mocks base method
+func (m *MockAppStore) SetSnapshotResticPodSelector(appID string, selector string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetSnapshotTTL", appID, snapshotTTL)
+	ret := m.ctrl.Call(m, "SetSnapshotResticPodSelector", appID, selector)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetSnapshotTTL indicates an expected call of SetSnapshotTTL
-func (mr *MockAppStoreMockRecorder) SetSnapshotTTL(appID, snapshotTTL interface{}) *gomock.Call {
+// SetSnapshotResticPodSelector indicates an expected call of SetSnapshotResticPodSelector
+func (mr *MockAppStoreMockRecorder) SetSnapshotResticPodSelector(appID, selector interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotTTL", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotTTL), appID, snapshotTTL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotResticPodSelector", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotResticPodSelector), appID, selector)
 }
 
-// SetSnapshotSchedule mocks base method
-func (m *MockAppStore) SetSnapshotSchedule(appID, snapshotSchedule string) error {
+// SetSnapshotMaxConcurrentBackups mocks base method
+func (m *MockAppStore) SetSnapshotMaxConcurrentBackups(appID string, maxConcurrent int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetSnapshotSchedule", appID, snapshotSchedule)
+	ret := m.ctrl.Call(m, "SetSnapshotMaxConcurrentBackups", appID, maxConcurrent)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetSnapshotSchedule indicates an expected call of SetSnapshotSchedule
-func (mr *MockAppStoreMockRecorder) SetSnapshotSchedule(appID, snapshotSchedule interface{}) *gomock.Call {
+// SetSnapshotMaxConcurrentBackups indicates an expected call of SetSnapshotMaxConcurrentBackups
+func (mr *MockAppStoreMockRecorder) SetSnapshotMaxConcurrentBackups(appID, maxConcurrent interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotSchedule", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotSchedule), appID, snapshotSchedule)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotMaxConcurrentBackups", reflect.TypeOf((*MockAppStore)(nil).SetSnapshotMaxConcurrentBackups), appID, maxConcurrent)
 }
 
 // RemoveApp mocks base method
@@ -2212,10 +2881,10 @@ func (m *MockSnapshotStore) EXPECT() *MockSnapshotStoreMockRecorder {
 }
 
 // ListPendingScheduledSnapshots mocks base method
-func (m *MockSnapshotStore) ListPendingScheduledSnapshots(appID string) ([]types7.ScheduledSnapshot, error) {
+func (m *MockSnapshotStore) ListPendingScheduledSnapshots(appID string) ([]types8.ScheduledSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledSnapshots", appID)
-	ret0, _ := ret[0].([]types7.ScheduledSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2226,6 +2895,21 @@ func (mr *MockSnapshotStoreMockRecorder) ListPendingScheduledSnapshots(appID int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingScheduledSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).ListPendingScheduledSnapshots), appID)
 }
 
+// GetLastHandledScheduledSnapshot mocks base method
+func (m *MockSnapshotStore) GetLastHandledScheduledSnapshot(appID string) (*types8.ScheduledSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastHandledScheduledSnapshot", appID)
+	ret0, _ := ret[0].(*types8.ScheduledSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastHandledScheduledSnapshot indicates an expected call of GetLastHandledScheduledSnapshot
+func (mr *MockSnapshotStoreMockRecorder) GetLastHandledScheduledSnapshot(appID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastHandledScheduledSnapshot", reflect.TypeOf((*MockSnapshotStore)(nil).GetLastHandledScheduledSnapshot), appID)
+}
+
 // UpdateScheduledSnapshot mocks base method
 func (m *MockSnapshotStore) UpdateScheduledSnapshot(snapshotID, backupName string) error {
 	m.ctrl.T.Helper()
@@ -2268,11 +2952,25 @@ func (mr *MockSnapshotStoreMockRecorder) CreateScheduledSnapshot(snapshotID, app
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledSnapshot", reflect.TypeOf((*MockSnapshotStore)(nil).CreateScheduledSnapshot), snapshotID, appID, timestamp)
 }
 
+// MarkScheduledSnapshotQueued mocks base method
+func (m *MockSnapshotStore) MarkScheduledSnapshotQueued(snapshotID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkScheduledSnapshotQueued", snapshotID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkScheduledSnapshotQueued indicates an expected call of MarkScheduledSnapshotQueued
+func (mr *MockSnapshotStoreMockRecorder) MarkScheduledSnapshotQueued(snapshotID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkScheduledSnapshotQueued", reflect.TypeOf((*MockSnapshotStore)(nil).MarkScheduledSnapshotQueued), snapshotID)
+}
+
 // ListPendingScheduledInstanceSnapshots mocks base method
-func (m *MockSnapshotStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types7.ScheduledInstanceSnapshot, error) {
+func (m *MockSnapshotStore) ListPendingScheduledInstanceSnapshots(clusterID string) ([]types8.ScheduledInstanceSnapshot, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListPendingScheduledInstanceSnapshots", clusterID)
-	ret0, _ := ret[0].([]types7.ScheduledInstanceSnapshot)
+	ret0, _ := ret[0].([]types8.ScheduledInstanceSnapshot)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2325,6 +3023,224 @@ func (mr *MockSnapshotStoreMockRecorder) CreateScheduledInstanceSnapshot(snapsho
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledInstanceSnapshot", reflect.TypeOf((*MockSnapshotStore)(nil).CreateScheduledInstanceSnapshot), snapshotID, clusterID, timestamp)
 }
 
+// ListScheduledSnapshotBackupNames mocks base method
+func (m *MockSnapshotStore) ListScheduledSnapshotBackupNames() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledSnapshotBackupNames")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledSnapshotBackupNames indicates an expected call of ListScheduledSnapshotBackupNames
+func (mr *MockSnapshotStoreMockRecorder) ListScheduledSnapshotBackupNames() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledSnapshotBackupNames", reflect.TypeOf((*MockSnapshotStore)(nil).ListScheduledSnapshotBackupNames))
+}
+
+// GetBackupWebhook mocks base method
+func (m *MockSnapshotStore) GetBackupWebhook() (*types8.BackupWebhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBackupWebhook")
+	ret0, _ := ret[0].(*types8.BackupWebhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackupWebhook indicates an expected call of GetBackupWebhook
+func (mr *MockSnapshotStoreMockRecorder) GetBackupWebhook() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupWebhook", reflect.TypeOf((*MockSnapshotStore)(nil).GetBackupWebhook))
+}
+
+// SetBackupWebhook mocks base method
+func (m *MockSnapshotStore) SetBackupWebhook(webhook *types8.BackupWebhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBackupWebhook", webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBackupWebhook indicates an expected call of SetBackupWebhook
+func (mr *MockSnapshotStoreMockRecorder) SetBackupWebhook(webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBackupWebhook", reflect.TypeOf((*MockSnapshotStore)(nil).SetBackupWebhook), webhook)
+}
+
+// GetMaxConcurrentScheduledSnapshots mocks base method
+func (m *MockSnapshotStore) GetMaxConcurrentScheduledSnapshots() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaxConcurrentScheduledSnapshots")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaxConcurrentScheduledSnapshots indicates an expected call of GetMaxConcurrentScheduledSnapshots
+func (mr *MockSnapshotStoreMockRecorder) GetMaxConcurrentScheduledSnapshots() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaxConcurrentScheduledSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).GetMaxConcurrentScheduledSnapshots))
+}
+
+// SetMaxConcurrentScheduledSnapshots mocks base method
+func (m *MockSnapshotStore) SetMaxConcurrentScheduledSnapshots(max int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaxConcurrentScheduledSnapshots", max)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaxConcurrentScheduledSnapshots indicates an expected call of SetMaxConcurrentScheduledSnapshots
+func (mr *MockSnapshotStoreMockRecorder) SetMaxConcurrentScheduledSnapshots(max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaxConcurrentScheduledSnapshots", reflect.TypeOf((*MockSnapshotStore)(nil).SetMaxConcurrentScheduledSnapshots), max)
+}
+
+// GetSnapshotDefaults mocks base method
+func (m *MockSnapshotStore) GetSnapshotDefaults() (*types8.SnapshotDefaults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshotDefaults")
+	ret0, _ := ret[0].(*types8.SnapshotDefaults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshotDefaults indicates an expected call of GetSnapshotDefaults
+func (mr *MockSnapshotStoreMockRecorder) GetSnapshotDefaults() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshotDefaults", reflect.TypeOf((*MockSnapshotStore)(nil).GetSnapshotDefaults))
+}
+
+// SetSnapshotDefaults mocks base method
+func (m *MockSnapshotStore) SetSnapshotDefaults(defaults *types8.SnapshotDefaults) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSnapshotDefaults", defaults)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSnapshotDefaults indicates an expected call of SetSnapshotDefaults
+func (mr *MockSnapshotStoreMockRecorder) SetSnapshotDefaults(defaults interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSnapshotDefaults", reflect.TypeOf((*MockSnapshotStore)(nil).SetSnapshotDefaults), defaults)
+}
+
+// GetBackupResourceFilter mocks base method
+func (m *MockSnapshotStore) GetBackupResourceFilter() (*types8.BackupResourceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBackupResourceFilter")
+	ret0, _ := ret[0].(*types8.BackupResourceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBackupResourceFilter indicates an expected call of GetBackupResourceFilter
+func (mr *MockSnapshotStoreMockRecorder) GetBackupResourceFilter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBackupResourceFilter", reflect.TypeOf((*MockSnapshotStore)(nil).GetBackupResourceFilter))
+}
+
+// SetBackupResourceFilter mocks base method
+func (m *MockSnapshotStore) SetBackupResourceFilter(filter *types8.BackupResourceFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBackupResourceFilter", filter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBackupResourceFilter indicates an expected call of SetBackupResourceFilter
+func (mr *MockSnapshotStoreMockRecorder) SetBackupResourceFilter(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBackupResourceFilter", reflect.TypeOf((*MockSnapshotStore)(nil).SetBackupResourceFilter), filter)
+}
+
+// GetVeleroExtraContainers mocks base method
+func (m *MockSnapshotStore) GetVeleroExtraContainers() (*types8.VeleroExtraContainers, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVeleroExtraContainers")
+	ret0, _ := ret[0].(*types8.VeleroExtraContainers)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVeleroExtraContainers indicates an expected call of GetVeleroExtraContainers
+func (mr *MockSnapshotStoreMockRecorder) GetVeleroExtraContainers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroExtraContainers", reflect.TypeOf((*MockSnapshotStore)(nil).GetVeleroExtraContainers))
+}
+
+// SetVeleroExtraContainers mocks base method
+func (m *MockSnapshotStore) SetVeleroExtraContainers(extra *types8.VeleroExtraContainers) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVeleroExtraContainers", extra)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVeleroExtraContainers indicates an expected call of SetVeleroExtraContainers
+func (mr *MockSnapshotStoreMockRecorder) SetVeleroExtraContainers(extra interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVeleroExtraContainers", reflect.TypeOf((*MockSnapshotStore)(nil).SetVeleroExtraContainers), extra)
+}
+
+// GetVeleroImageRegistry mocks base method
+func (m *MockSnapshotStore) GetVeleroImageRegistry() (*types8.VeleroImageRegistry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVeleroImageRegistry")
+	ret0, _ := ret[0].(*types8.VeleroImageRegistry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVeleroImageRegistry indicates an expected call of GetVeleroImageRegistry
+func (mr *MockSnapshotStoreMockRecorder) GetVeleroImageRegistry() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVeleroImageRegistry", reflect.TypeOf((*MockSnapshotStore)(nil).GetVeleroImageRegistry))
+}
+
+// SetVeleroImageRegistry mocks base method
+func (m *MockSnapshotStore) SetVeleroImageRegistry(veleroRegistry *types8.VeleroImageRegistry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVeleroImageRegistry", veleroRegistry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVeleroImageRegistry indicates an expected call of SetVeleroImageRegistry
+func (mr *MockSnapshotStoreMockRecorder) SetVeleroImageRegistry(veleroRegistry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVeleroImageRegistry", reflect.TypeOf((*MockSnapshotStore)(nil).SetVeleroImageRegistry), veleroRegistry)
+}
+
+// CreateSnapshotAuditEvent mocks base method
+func (m *MockSnapshotStore) CreateSnapshotAuditEvent(event *types8.SnapshotAuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSnapshotAuditEvent", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSnapshotAuditEvent indicates an expected call of CreateSnapshotAuditEvent
+func (mr *MockSnapshotStoreMockRecorder) CreateSnapshotAuditEvent(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSnapshotAuditEvent", reflect.TypeOf((*MockSnapshotStore)(nil).CreateSnapshotAuditEvent), event)
+}
+
+// ListSnapshotAuditEvents mocks base method
+func (m *MockSnapshotStore) ListSnapshotAuditEvents(appID string, limit int) ([]types8.SnapshotAuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSnapshotAuditEvents", appID, limit)
+	ret0, _ := ret[0].([]types8.SnapshotAuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSnapshotAuditEvents indicates an expected call of ListSnapshotAuditEvents
+func (mr *MockSnapshotStoreMockRecorder) ListSnapshotAuditEvents(appID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSnapshotAuditEvents", reflect.TypeOf((*MockSnapshotStore)(nil).ListSnapshotAuditEvents), appID, limit)
+}
+
 // MockVersionStore is a mock of VersionStore interface
 type MockVersionStore struct {
 	ctrl     *gomock.Controller
@@ -2379,7 +3295,7 @@ func (mr *MockVersionStoreMockRecorder) IsRollbackSupportedForVersion(appID, seq
 }
 
 // IsSnapshotsSupportedForVersion mocks base method
-func (m *MockVersionStore) IsSnapshotsSupportedForVersion(a *types0.App, sequence int64, renderer types5.Renderer) (bool, error) {
+func (m *MockVersionStore) IsSnapshotsSupportedForVersion(a *types0.App, sequence int64, renderer types6.Renderer) (bool, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "IsSnapshotsSupportedForVersion", a, sequence, renderer)
 	ret0, _ := ret[0].(bool)
@@ -2437,10 +3353,10 @@ func (mr *MockVersionStoreMockRecorder) CreateAppVersion(appID, currentSequence,
 }
 
 // GetAppVersion mocks base method
-func (m *MockVersionStore) GetAppVersion(arg0 string, arg1 int64) (*types12.AppVersion, error) {
+func (m *MockVersionStore) GetAppVersion(arg0 string, arg1 int64) (*types13.AppVersion, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppVersion", arg0, arg1)
-	ret0, _ := ret[0].(*types12.AppVersion)
+	ret0, _ := ret[0].(*types13.AppVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2452,10 +3368,10 @@ func (mr *MockVersionStoreMockRecorder) GetAppVersion(arg0, arg1 interface{}) *g
 }
 
 // GetAppVersionsAfter mocks base method
-func (m *MockVersionStore) GetAppVersionsAfter(arg0 string, arg1 int64) ([]*types12.AppVersion, error) {
+func (m *MockVersionStore) GetAppVersionsAfter(arg0 string, arg1 int64) ([]*types13.AppVersion, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetAppVersionsAfter", arg0, arg1)
-	ret0, _ := ret[0].([]*types12.AppVersion)
+	ret0, _ := ret[0].([]*types13.AppVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2535,7 +3451,7 @@ func (mr *MockLicenseStoreMockRecorder) GetAllAppLicenses() *gomock.Call {
 }
 
 // UpdateAppLicense mocks base method
-func (m *MockLicenseStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops types1.DownstreamGitOps, renderer types5.Renderer) (int64, error) {
+func (m *MockLicenseStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *v1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops types1.DownstreamGitOps, renderer types6.Renderer) (int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateAppLicense", appID, sequence, archiveDir, newLicense, originalLicenseData, failOnVersionCreate, gitops, renderer)
 	ret0, _ := ret[0].(int64)
@@ -2549,6 +3465,35 @@ func (mr *MockLicenseStoreMockRecorder) UpdateAppLicense(appID, sequence, archiv
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppLicense", reflect.TypeOf((*MockLicenseStore)(nil).UpdateAppLicense), appID, sequence, archiveDir, newLicense, originalLicenseData, failOnVersionCreate, gitops, renderer)
 }
 
+// CreateLicenseChange mocks base method
+func (m *MockLicenseStore) CreateLicenseChange(change *types2.LicenseChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLicenseChange", change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateLicenseChange indicates an expected call of CreateLicenseChange
+func (mr *MockLicenseStoreMockRecorder) CreateLicenseChange(change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLicenseChange", reflect.TypeOf((*MockLicenseStore)(nil).CreateLicenseChange), change)
+}
+
+// ListLicenseChanges mocks base method
+func (m *MockLicenseStore) ListLicenseChanges(appID string, limit int) ([]types2.LicenseChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLicenseChanges", appID, limit)
+	ret0, _ := ret[0].([]types2.LicenseChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLicenseChanges indicates an expected call of ListLicenseChanges
+func (mr *MockLicenseStoreMockRecorder) ListLicenseChanges(appID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLicenseChanges", reflect.TypeOf((*MockLicenseStore)(nil).ListLicenseChanges), appID, limit)
+}
+
 // MockClusterStore is a mock of ClusterStore interface
 type MockClusterStore struct {
 	ctrl     *gomock.Controller
@@ -2573,10 +3518,10 @@ func (m *MockClusterStore) EXPECT() *MockClusterStoreMockRecorder {
 }
 
 // ListClusters mocks base method
-func (m *MockClusterStore) ListClusters() ([]*types11.Downstream, error) {
+func (m *MockClusterStore) ListClusters() ([]*types12.Downstream, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListClusters")
-	ret0, _ := ret[0].([]*types11.Downstream)
+	ret0, _ := ret[0].([]*types12.Downstream)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2660,6 +3605,62 @@ func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotSchedule(clusterID, s
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotSchedule", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotSchedule), clusterID, snapshotSchedule)
 }
 
+// SetInstanceSnapshotClusterResources mocks base method
+func (m *MockClusterStore) SetInstanceSnapshotClusterResources(clusterID string, includedResources []string, excludedResources []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotClusterResources", clusterID, includedResources, excludedResources)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotClusterResources indicates an expected call of SetInstanceSnapshotClusterResources
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotClusterResources(clusterID, includedResources, excludedResources interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotClusterResources", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotClusterResources), clusterID, includedResources, excludedResources)
+}
+
+// SetInstanceSnapshotExcludedPVCs mocks base method
+func (m *MockClusterStore) SetInstanceSnapshotExcludedPVCs(clusterID string, excludedPVCs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotExcludedPVCs", clusterID, excludedPVCs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotExcludedPVCs indicates an expected call of SetInstanceSnapshotExcludedPVCs
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotExcludedPVCs(clusterID, excludedPVCs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotExcludedPVCs", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotExcludedPVCs), clusterID, excludedPVCs)
+}
+
+// SetInstanceSnapshotFanOutLocations mocks base method
+func (m *MockClusterStore) SetInstanceSnapshotFanOutLocations(clusterID string, locations []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotFanOutLocations", clusterID, locations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotFanOutLocations indicates an expected call of SetInstanceSnapshotFanOutLocations
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotFanOutLocations(clusterID, locations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotFanOutLocations", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotFanOutLocations), clusterID, locations)
+}
+
+// SetInstanceSnapshotIncludeRegistryData mocks base method
+func (m *MockClusterStore) SetInstanceSnapshotIncludeRegistryData(clusterID string, include bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetInstanceSnapshotIncludeRegistryData", clusterID, include)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetInstanceSnapshotIncludeRegistryData indicates an expected call of SetInstanceSnapshotIncludeRegistryData
+func (mr *MockClusterStoreMockRecorder) SetInstanceSnapshotIncludeRegistryData(clusterID, include interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetInstanceSnapshotIncludeRegistryData", reflect.TypeOf((*MockClusterStore)(nil).SetInstanceSnapshotIncludeRegistryData), clusterID, include)
+}
+
 // MockInstallationStore is a mock of InstallationStore interface
 type MockInstallationStore struct {
 	ctrl     *gomock.Controller
@@ -2684,10 +3685,10 @@ func (m *MockInstallationStore) EXPECT() *MockInstallationStoreMockRecorder {
 }
 
 // GetPendingInstallationStatus mocks base method
-func (m *MockInstallationStore) GetPendingInstallationStatus() (*types2.InstallStatus, error) {
+func (m *MockInstallationStore) GetPendingInstallationStatus() (*types3.InstallStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetPendingInstallationStatus")
-	ret0, _ := ret[0].(*types2.InstallStatus)
+	ret0, _ := ret[0].(*types3.InstallStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -2722,10 +3723,10 @@ func (m *MockReportingStore) EXPECT() *MockReportingStoreMockRecorder {
 }
 
 // GetReportingInfo mocks base method
-func (m *MockReportingStore) GetReportingInfo(appID string) *types13.ReportingInfo {
+func (m *MockReportingStore) GetReportingInfo(appID string) *types14.ReportingInfo {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetReportingInfo", appID)
-	ret0, _ := ret[0].(*types13.ReportingInfo)
+	ret0, _ := ret[0].(*types14.ReportingInfo)
 	return ret0
 }
 
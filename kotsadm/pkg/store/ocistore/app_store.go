@@ -9,6 +9,7 @@ import (
 	"github.com/gosimple/slug"
 	"github.com/pkg/errors"
 	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
+	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
 	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
 	"github.com/segmentio/ksuid"
 )
@@ -316,6 +317,10 @@ func (c OCIStore) SetUpdateCheckerSpec(appID string, updateCheckerSpec string) e
 	return ErrNotImplemented
 }
 
+func (c OCIStore) SetLicenseSyncInterval(appID string, intervalMinutes int) error {
+	return ErrNotImplemented
+}
+
 func (c OCIStore) SetSnapshotSchedule(appID string, snapshotSchedule string) error {
 	return ErrNotImplemented
 }
@@ -324,6 +329,50 @@ func (c OCIStore) SetSnapshotTTL(appID string, snapshotTTL string) error {
 	return ErrNotImplemented
 }
 
+func (c OCIStore) SetSnapshotScheduleTTL(appID string, snapshotScheduleTTL string) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotScheduleJitter(appID string, jitterMinutes int) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotDefaultVolumesToRestic(appID string, defaultVolumesToRestic *bool) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotQuiesceActions(appID string, actions []snapshottypes.QuiesceAction) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotChecksumTargets(appID string, targets []snapshottypes.ChecksumTarget) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotNamespaces(appID string, included []string, excluded []string) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotHookSettings(appID string, settings *snapshottypes.HookSettings) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotExcludedPVCs(appID string, excludedPVCs []string) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotFanOutLocations(appID string, locations []string) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotResticPodSelector(appID string, selector string) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotMaxConcurrentBackups(appID string, maxConcurrent int) error {
+	return ErrNotImplemented
+}
+
 func (s OCIStore) updateApp(app *apptypes.App) error {
 	b, err := json.Marshal(app)
 	if err != nil {
@@ -129,3 +129,19 @@ func (s OCIStore) SetInstanceSnapshotTTL(clusterID string, snapshotTTL string) e
 func (s OCIStore) SetInstanceSnapshotSchedule(clusterID string, snapshotSchedule string) error {
 	return ErrNotImplemented
 }
+
+func (s OCIStore) SetInstanceSnapshotClusterResources(clusterID string, includedResources []string, excludedResources []string) error {
+	return ErrNotImplemented
+}
+
+func (s OCIStore) SetInstanceSnapshotExcludedPVCs(clusterID string, excludedPVCs []string) error {
+	return ErrNotImplemented
+}
+
+func (s OCIStore) SetInstanceSnapshotFanOutLocations(clusterID string, locations []string) error {
+	return ErrNotImplemented
+}
+
+func (s OCIStore) SetInstanceSnapshotIncludeRegistryData(clusterID string, include bool) error {
+	return ErrNotImplemented
+}
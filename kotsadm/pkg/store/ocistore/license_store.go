@@ -3,6 +3,7 @@ package ocistore
 import (
 	"github.com/pkg/errors"
 	gitopstypes "github.com/replicatedhq/kots/kotsadm/pkg/gitops/types"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
 	rendertypes "github.com/replicatedhq/kots/kotsadm/pkg/render/types"
 	kotsv1beta1 "github.com/replicatedhq/kots/kotskinds/apis/kots/v1beta1"
 )
@@ -31,3 +32,11 @@ func (s OCIStore) GetAllAppLicenses() ([]*kotsv1beta1.License, error) {
 func (s OCIStore) UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *kotsv1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) (int64, error) {
 	return int64(0), ErrNotImplemented
 }
+
+func (s OCIStore) CreateLicenseChange(change *licensetypes.LicenseChange) error {
+	return ErrNotImplemented
+}
+
+func (s OCIStore) ListLicenseChanges(appID string, limit int) ([]licensetypes.LicenseChange, error) {
+	return nil, ErrNotImplemented
+}
@@ -10,10 +10,18 @@ func (c OCIStore) ListPendingScheduledSnapshots(appID string) ([]snapshottypes.S
 	return nil, ErrNotImplemented
 }
 
+func (c OCIStore) GetLastHandledScheduledSnapshot(appID string) (*snapshottypes.ScheduledSnapshot, error) {
+	return nil, ErrNotImplemented
+}
+
 func (c OCIStore) UpdateScheduledSnapshot(snapshotID string, backupName string) error {
 	return ErrNotImplemented
 }
 
+func (c OCIStore) MarkScheduledSnapshotQueued(snapshotID string) error {
+	return ErrNotImplemented
+}
+
 func (c OCIStore) DeletePendingScheduledSnapshots(appID string) error {
 	return ErrNotImplemented
 }
@@ -37,3 +45,63 @@ func (c OCIStore) DeletePendingScheduledInstanceSnapshots(clusterID string) erro
 func (c OCIStore) CreateScheduledInstanceSnapshot(snapshotID string, clusterID string, timestamp time.Time) error {
 	return ErrNotImplemented
 }
+
+func (c OCIStore) ListScheduledSnapshotBackupNames() ([]string, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) GetBackupWebhook() (*snapshottypes.BackupWebhook, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) SetBackupWebhook(webhook *snapshottypes.BackupWebhook) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) GetMaxConcurrentScheduledSnapshots() (int, error) {
+	return 0, ErrNotImplemented
+}
+
+func (c OCIStore) SetMaxConcurrentScheduledSnapshots(max int) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) GetSnapshotDefaults() (*snapshottypes.SnapshotDefaults, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) SetSnapshotDefaults(defaults *snapshottypes.SnapshotDefaults) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) GetBackupResourceFilter() (*snapshottypes.BackupResourceFilter, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) SetBackupResourceFilter(filter *snapshottypes.BackupResourceFilter) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) GetVeleroExtraContainers() (*snapshottypes.VeleroExtraContainers, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) SetVeleroExtraContainers(extra *snapshottypes.VeleroExtraContainers) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) GetVeleroImageRegistry() (*snapshottypes.VeleroImageRegistry, error) {
+	return nil, ErrNotImplemented
+}
+
+func (c OCIStore) SetVeleroImageRegistry(veleroRegistry *snapshottypes.VeleroImageRegistry) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) CreateSnapshotAuditEvent(event *snapshottypes.SnapshotAuditEvent) error {
+	return ErrNotImplemented
+}
+
+func (c OCIStore) ListSnapshotAuditEvents(appID string, limit int) ([]snapshottypes.SnapshotAuditEvent, error) {
+	return nil, ErrNotImplemented
+}
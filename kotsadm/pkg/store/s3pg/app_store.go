@@ -2,6 +2,7 @@ package s3pg
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/replicatedhq/kots/kotsadm/pkg/gitops"
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/persistence"
+	snapshottypes "github.com/replicatedhq/kots/kotsadm/pkg/snapshot/types"
 	downstreamtypes "github.com/replicatedhq/kots/pkg/api/downstream/types"
 	"github.com/segmentio/ksuid"
 	"go.uber.org/zap"
@@ -112,7 +114,7 @@ func (s S3PGStore) GetApp(id string) (*apptypes.App, error) {
 	// 	zap.String("id", id))
 
 	db := persistence.MustGetPGSession()
-	query := `select id, name, license, upstream_uri, icon_uri, created_at, updated_at, slug, current_sequence, last_update_check_at, is_airgap, snapshot_ttl_new, snapshot_schedule, restore_in_progress_name, restore_undeploy_status, update_checker_spec, install_state from app where id = $1`
+	query := `select id, name, license, upstream_uri, icon_uri, created_at, updated_at, slug, current_sequence, last_update_check_at, is_airgap, snapshot_ttl_new, snapshot_schedule, snapshot_schedule_ttl, snapshot_schedule_jitter_minutes, snapshot_default_volumes_to_restic, snapshot_quiesce_actions, snapshot_checksum_targets, snapshot_included_namespaces, snapshot_excluded_namespaces, snapshot_hook_settings, snapshot_excluded_pvcs, snapshot_fan_out_locations, snapshot_restic_pod_selector, snapshot_max_concurrent_backups, restore_in_progress_name, restore_undeploy_status, update_checker_spec, license_sync_interval_minutes, install_state from app where id = $1`
 	row := db.QueryRow(query, id)
 
 	app := apptypes.App{}
@@ -125,11 +127,24 @@ func (s S3PGStore) GetApp(id string) (*apptypes.App, error) {
 	var lastUpdateCheckAt sql.NullString
 	var snapshotTTLNew sql.NullString
 	var snapshotSchedule sql.NullString
+	var snapshotScheduleTTL sql.NullString
+	var snapshotScheduleJitterMinutes sql.NullInt64
+	var snapshotDefaultVolumesToRestic sql.NullBool
+	var snapshotQuiesceActions sql.NullString
+	var snapshotChecksumTargets sql.NullString
+	var snapshotIncludedNamespaces sql.NullString
+	var snapshotExcludedNamespaces sql.NullString
+	var snapshotHookSettings sql.NullString
+	var snapshotExcludedPVCs sql.NullString
+	var snapshotFanOutLocations sql.NullString
+	var snapshotResticPodSelector sql.NullString
+	var snapshotMaxConcurrentBackups sql.NullInt64
 	var restoreInProgressName sql.NullString
 	var restoreUndeployStatus sql.NullString
 	var updateCheckerSpec sql.NullString
+	var licenseSyncIntervalMinutes sql.NullInt64
 
-	if err := row.Scan(&app.ID, &app.Name, &licenseStr, &upstreamURI, &iconURI, &app.CreatedAt, &updatedAt, &app.Slug, &currentSequence, &lastUpdateCheckAt, &app.IsAirgap, &snapshotTTLNew, &snapshotSchedule, &restoreInProgressName, &restoreUndeployStatus, &updateCheckerSpec, &app.InstallState); err != nil {
+	if err := row.Scan(&app.ID, &app.Name, &licenseStr, &upstreamURI, &iconURI, &app.CreatedAt, &updatedAt, &app.Slug, &currentSequence, &lastUpdateCheckAt, &app.IsAirgap, &snapshotTTLNew, &snapshotSchedule, &snapshotScheduleTTL, &snapshotScheduleJitterMinutes, &snapshotDefaultVolumesToRestic, &snapshotQuiesceActions, &snapshotChecksumTargets, &snapshotIncludedNamespaces, &snapshotExcludedNamespaces, &snapshotHookSettings, &snapshotExcludedPVCs, &snapshotFanOutLocations, &snapshotResticPodSelector, &snapshotMaxConcurrentBackups, &restoreInProgressName, &restoreUndeployStatus, &updateCheckerSpec, &licenseSyncIntervalMinutes, &app.InstallState); err != nil {
 		return nil, errors.Wrap(err, "failed to scan app")
 	}
 
@@ -139,9 +154,53 @@ func (s S3PGStore) GetApp(id string) (*apptypes.App, error) {
 	app.LastUpdateCheckAt = lastUpdateCheckAt.String
 	app.SnapshotTTL = snapshotTTLNew.String
 	app.SnapshotSchedule = snapshotSchedule.String
+	app.SnapshotScheduleTTL = snapshotScheduleTTL.String
+	app.SnapshotScheduleJitterMinutes = int(snapshotScheduleJitterMinutes.Int64)
+	if snapshotDefaultVolumesToRestic.Valid {
+		app.SnapshotDefaultVolumesToRestic = &snapshotDefaultVolumesToRestic.Bool
+	}
+	if snapshotQuiesceActions.String != "" {
+		if err := json.Unmarshal([]byte(snapshotQuiesceActions.String), &app.SnapshotQuiesceActions); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot quiesce actions")
+		}
+	}
+	if snapshotChecksumTargets.String != "" {
+		if err := json.Unmarshal([]byte(snapshotChecksumTargets.String), &app.SnapshotChecksumTargets); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot checksum targets")
+		}
+	}
+	if snapshotIncludedNamespaces.String != "" {
+		if err := json.Unmarshal([]byte(snapshotIncludedNamespaces.String), &app.SnapshotIncludedNamespaces); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot included namespaces")
+		}
+	}
+	if snapshotExcludedNamespaces.String != "" {
+		if err := json.Unmarshal([]byte(snapshotExcludedNamespaces.String), &app.SnapshotExcludedNamespaces); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot excluded namespaces")
+		}
+	}
+	if snapshotHookSettings.String != "" {
+		app.SnapshotHookSettings = &snapshottypes.HookSettings{}
+		if err := json.Unmarshal([]byte(snapshotHookSettings.String), app.SnapshotHookSettings); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot hook settings")
+		}
+	}
+	if snapshotExcludedPVCs.String != "" {
+		if err := json.Unmarshal([]byte(snapshotExcludedPVCs.String), &app.SnapshotExcludedPVCs); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot excluded pvcs")
+		}
+	}
+	if snapshotFanOutLocations.String != "" {
+		if err := json.Unmarshal([]byte(snapshotFanOutLocations.String), &app.SnapshotFanOutLocations); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal snapshot fan-out locations")
+		}
+	}
+	app.SnapshotResticPodSelector = snapshotResticPodSelector.String
+	app.SnapshotMaxConcurrentBackups = int(snapshotMaxConcurrentBackups.Int64)
 	app.RestoreInProgressName = restoreInProgressName.String
 	app.RestoreUndeployStatus = apptypes.UndeployStatus(restoreUndeployStatus.String)
 	app.UpdateCheckerSpec = updateCheckerSpec.String
+	app.LicenseSyncIntervalMinutes = int(licenseSyncIntervalMinutes.Int64)
 
 	if updatedAt.Valid {
 		app.UpdatedAt = &updatedAt.Time
@@ -364,6 +423,21 @@ func (s S3PGStore) SetUpdateCheckerSpec(appID string, updateCheckerSpec string)
 	return nil
 }
 
+func (s S3PGStore) SetLicenseSyncInterval(appID string, intervalMinutes int) error {
+	logger.Debug("setting license sync interval",
+		zap.String("appID", appID),
+		zap.Int("intervalMinutes", intervalMinutes))
+
+	db := persistence.MustGetPGSession()
+	query := `update app set license_sync_interval_minutes = $1 where id = $2`
+	_, err := db.Exec(query, intervalMinutes, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
 func (c S3PGStore) SetSnapshotTTL(appID string, snapshotTTL string) error {
 	logger.Debug("Setting snapshot TTL",
 		zap.String("appID", appID))
@@ -390,6 +464,222 @@ func (c S3PGStore) SetSnapshotSchedule(appID string, snapshotSchedule string) er
 	return nil
 }
 
+func (c S3PGStore) SetSnapshotScheduleTTL(appID string, snapshotScheduleTTL string) error {
+	logger.Debug("Setting snapshot schedule TTL",
+		zap.String("appID", appID))
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_schedule_ttl = $1 where id = $2`
+	_, err := db.Exec(query, snapshotScheduleTTL, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotScheduleJitter(appID string, jitterMinutes int) error {
+	logger.Debug("Setting snapshot schedule jitter",
+		zap.String("appID", appID))
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_schedule_jitter_minutes = $1 where id = $2`
+	_, err := db.Exec(query, jitterMinutes, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotDefaultVolumesToRestic(appID string, defaultVolumesToRestic *bool) error {
+	logger.Debug("Setting snapshot default volumes to restic",
+		zap.String("appID", appID))
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_default_volumes_to_restic = $1 where id = $2`
+	_, err := db.Exec(query, defaultVolumesToRestic, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotQuiesceActions(appID string, actions []snapshottypes.QuiesceAction) error {
+	logger.Debug("Setting snapshot quiesce actions",
+		zap.String("appID", appID))
+
+	var value sql.NullString
+	if len(actions) > 0 {
+		b, err := json.Marshal(actions)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal actions")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_quiesce_actions = $1 where id = $2`
+	_, err := db.Exec(query, value, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotChecksumTargets(appID string, targets []snapshottypes.ChecksumTarget) error {
+	logger.Debug("Setting snapshot checksum targets",
+		zap.String("appID", appID))
+
+	var value sql.NullString
+	if len(targets) > 0 {
+		b, err := json.Marshal(targets)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal targets")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_checksum_targets = $1 where id = $2`
+	_, err := db.Exec(query, value, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotNamespaces(appID string, included []string, excluded []string) error {
+	logger.Debug("Setting snapshot namespaces",
+		zap.String("appID", appID))
+
+	var includedValue sql.NullString
+	if len(included) > 0 {
+		b, err := json.Marshal(included)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal included namespaces")
+		}
+		includedValue = sql.NullString{String: string(b), Valid: true}
+	}
+
+	var excludedValue sql.NullString
+	if len(excluded) > 0 {
+		b, err := json.Marshal(excluded)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal excluded namespaces")
+		}
+		excludedValue = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_included_namespaces = $1, snapshot_excluded_namespaces = $2 where id = $3`
+	_, err := db.Exec(query, includedValue, excludedValue, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotHookSettings(appID string, settings *snapshottypes.HookSettings) error {
+	logger.Debug("Setting snapshot hook settings",
+		zap.String("appID", appID))
+
+	var value sql.NullString
+	if settings != nil {
+		b, err := json.Marshal(settings)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal settings")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_hook_settings = $1 where id = $2`
+	_, err := db.Exec(query, value, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotExcludedPVCs(appID string, excludedPVCs []string) error {
+	logger.Debug("Setting snapshot excluded pvcs",
+		zap.String("appID", appID))
+
+	var value sql.NullString
+	if len(excludedPVCs) > 0 {
+		b, err := json.Marshal(excludedPVCs)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal excluded pvcs")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_excluded_pvcs = $1 where id = $2`
+	_, err := db.Exec(query, value, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotFanOutLocations(appID string, locations []string) error {
+	logger.Debug("Setting snapshot fan-out locations",
+		zap.String("appID", appID))
+
+	var value sql.NullString
+	if len(locations) > 0 {
+		b, err := json.Marshal(locations)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal fan-out locations")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_fan_out_locations = $1 where id = $2`
+	_, err := db.Exec(query, value, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotResticPodSelector(appID string, selector string) error {
+	logger.Debug("Setting snapshot restic pod selector",
+		zap.String("appID", appID),
+		zap.String("selector", selector))
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_restic_pod_selector = $1 where id = $2`
+	_, err := db.Exec(query, selector, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetSnapshotMaxConcurrentBackups(appID string, maxConcurrent int) error {
+	logger.Debug("Setting snapshot max concurrent backups",
+		zap.String("appID", appID),
+		zap.Int("maxConcurrent", maxConcurrent))
+
+	db := persistence.MustGetPGSession()
+	query := `update app set snapshot_max_concurrent_backups = $1 where id = $2`
+	_, err := db.Exec(query, maxConcurrent, appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
 func (c S3PGStore) RemoveApp(appID string) error {
 	logger.Debug("Removing app",
 		zap.String("appID", appID))
@@ -2,7 +2,9 @@ package s3pg
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gosimple/slug"
@@ -17,7 +19,7 @@ import (
 func (s S3PGStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 	db := persistence.MustGetPGSession()
 
-	query := `select id, slug, title, snapshot_schedule, snapshot_ttl from cluster` // TODO the current sequence
+	query := `select id, slug, title, snapshot_schedule, snapshot_ttl, snapshot_included_cluster_resources, snapshot_excluded_cluster_resources, snapshot_excluded_pvcs, snapshot_fan_out_locations, snapshot_include_registry_data from cluster` // TODO the current sequence
 	rows, err := db.Query(query)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query clusters")
@@ -30,13 +32,35 @@ func (s S3PGStore) ListClusters() ([]*downstreamtypes.Downstream, error) {
 
 		var snapshotSchedule sql.NullString
 		var snapshotTTL sql.NullString
+		var includedClusterResources sql.NullString
+		var excludedClusterResources sql.NullString
+		var excludedPVCs sql.NullString
+		var fanOutLocations sql.NullString
+		var includeRegistryData sql.NullBool
 
-		if err := rows.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL); err != nil {
+		if err := rows.Scan(&cluster.ClusterID, &cluster.ClusterSlug, &cluster.Name, &snapshotSchedule, &snapshotTTL, &includedClusterResources, &excludedClusterResources, &excludedPVCs, &fanOutLocations, &includeRegistryData); err != nil {
 			return nil, errors.Wrap(err, "failed to scan row")
 		}
 
 		cluster.SnapshotSchedule = snapshotSchedule.String
 		cluster.SnapshotTTL = snapshotTTL.String
+		if includedClusterResources.String != "" {
+			cluster.SnapshotIncludedClusterResources = strings.Split(includedClusterResources.String, ",")
+		}
+		if excludedClusterResources.String != "" {
+			cluster.SnapshotExcludedClusterResources = strings.Split(excludedClusterResources.String, ",")
+		}
+		if excludedPVCs.String != "" {
+			if err := json.Unmarshal([]byte(excludedPVCs.String), &cluster.SnapshotExcludedPVCs); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal snapshot excluded pvcs")
+			}
+		}
+		if fanOutLocations.String != "" {
+			if err := json.Unmarshal([]byte(fanOutLocations.String), &cluster.SnapshotFanOutLocations); err != nil {
+				return nil, errors.Wrap(err, "failed to unmarshal snapshot fan-out locations")
+			}
+		}
+		cluster.SnapshotIncludeRegistryData = includeRegistryData.Bool
 
 		clusters = append(clusters, &cluster)
 	}
@@ -153,3 +177,76 @@ func (c S3PGStore) SetInstanceSnapshotSchedule(clusterID string, snapshotSchedul
 
 	return nil
 }
+
+func (c S3PGStore) SetInstanceSnapshotClusterResources(clusterID string, includedResources []string, excludedResources []string) error {
+	logger.Debug("Setting instance snapshot cluster resources",
+		zap.String("clusterID", clusterID))
+	db := persistence.MustGetPGSession()
+	query := `update cluster set snapshot_included_cluster_resources = $1, snapshot_excluded_cluster_resources = $2 where id = $3`
+	_, err := db.Exec(query, strings.Join(includedResources, ","), strings.Join(excludedResources, ","), clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetInstanceSnapshotExcludedPVCs(clusterID string, excludedPVCs []string) error {
+	logger.Debug("Setting instance snapshot excluded pvcs",
+		zap.String("clusterID", clusterID))
+
+	var value sql.NullString
+	if len(excludedPVCs) > 0 {
+		b, err := json.Marshal(excludedPVCs)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal excluded pvcs")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update cluster set snapshot_excluded_pvcs = $1 where id = $2`
+	_, err := db.Exec(query, value, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetInstanceSnapshotFanOutLocations(clusterID string, locations []string) error {
+	logger.Debug("Setting instance snapshot fan-out locations",
+		zap.String("clusterID", clusterID))
+
+	var value sql.NullString
+	if len(locations) > 0 {
+		b, err := json.Marshal(locations)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal fan-out locations")
+		}
+		value = sql.NullString{String: string(b), Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `update cluster set snapshot_fan_out_locations = $1 where id = $2`
+	_, err := db.Exec(query, value, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) SetInstanceSnapshotIncludeRegistryData(clusterID string, include bool) error {
+	logger.Debug("Setting instance snapshot include registry data",
+		zap.String("clusterID", clusterID),
+		zap.Bool("include", include))
+	db := persistence.MustGetPGSession()
+	query := `update cluster set snapshot_include_registry_data = $1 where id = $2`
+	_, err := db.Exec(query, include, clusterID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec db query")
+	}
+
+	return nil
+}
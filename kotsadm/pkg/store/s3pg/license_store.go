@@ -3,11 +3,13 @@ package s3pg
 import (
 	"bytes"
 	"database/sql"
+	"encoding/json"
 	"io/ioutil"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 	gitopstypes "github.com/replicatedhq/kots/kotsadm/pkg/gitops/types"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
 	"github.com/replicatedhq/kots/kotsadm/pkg/logger"
 	"github.com/replicatedhq/kots/kotsadm/pkg/persistence"
 	rendertypes "github.com/replicatedhq/kots/kotsadm/pkg/render/types"
@@ -157,3 +159,45 @@ func (s S3PGStore) createNewVersionForLicenseChange(tx *sql.Tx, appID string, se
 
 	return newSequence, nil
 }
+
+func (s S3PGStore) CreateLicenseChange(change *licensetypes.LicenseChange) error {
+	entitlements, err := json.Marshal(change.Entitlements)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal entitlements")
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `INSERT INTO license_change (id, app_id, created_at, previous_license_sequence, license_sequence, sequence, entitlements) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err = db.Exec(query, change.ID, change.AppID, change.CreatedAt, change.PreviousLicenseSequence, change.LicenseSequence, change.Sequence, string(entitlements))
+	if err != nil {
+		return errors.Wrap(err, "failed to exec")
+	}
+
+	return nil
+}
+
+// ListLicenseChanges returns the app's most recent license changes first
+func (s S3PGStore) ListLicenseChanges(appID string, limit int) ([]licensetypes.LicenseChange, error) {
+	db := persistence.MustGetPGSession()
+	query := `SELECT id, app_id, created_at, previous_license_sequence, license_sequence, sequence, entitlements FROM license_change WHERE app_id = $1 ORDER BY created_at DESC LIMIT $2`
+	rows, err := db.Query(query, appID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	changes := []licensetypes.LicenseChange{}
+	for rows.Next() {
+		change := licensetypes.LicenseChange{}
+		var entitlements string
+		if err := rows.Scan(&change.ID, &change.AppID, &change.CreatedAt, &change.PreviousLicenseSequence, &change.LicenseSequence, &change.Sequence, &entitlements); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		if err := json.Unmarshal([]byte(entitlements), &change.Entitlements); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal entitlements")
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
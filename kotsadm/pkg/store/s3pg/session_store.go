@@ -48,14 +48,14 @@ func (s S3PGStore) GetSession(id string) (*sessiontypes.Session, error) {
 	// 	zap.String("id", id))
 
 	db := persistence.MustGetPGSession()
-	query := `select id, metadata, issued_at, expire_at from session where id = $1`
+	query := `select id, user_id, metadata, issued_at, expire_at from session where id = $1`
 	row := db.QueryRow(query, id)
 	session := sessiontypes.Session{}
 
 	var issuedAt sql.NullTime
 	var expiresAt time.Time
 	var metadataStr string
-	if err := row.Scan(&session.ID, &metadataStr, &issuedAt, &expiresAt); err != nil {
+	if err := row.Scan(&session.ID, &session.UserID, &metadataStr, &issuedAt, &expiresAt); err != nil {
 		return nil, errors.Wrap(err, "failed to get session")
 	}
 
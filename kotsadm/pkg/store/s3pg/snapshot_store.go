@@ -1,6 +1,10 @@
 package s3pg
 
 import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,7 +19,7 @@ func (c S3PGStore) ListPendingScheduledSnapshots(appID string) ([]snapshottypes.
 		zap.String("appID", appID))
 
 	db := persistence.MustGetPGSession()
-	query := `SELECT id, app_id, scheduled_timestamp FROM scheduled_snapshots WHERE app_id = $1 AND backup_name IS NULL;`
+	query := `SELECT id, app_id, scheduled_timestamp, queued_at FROM scheduled_snapshots WHERE app_id = $1 AND backup_name IS NULL;`
 	rows, err := db.Query(query, appID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to query")
@@ -25,15 +29,40 @@ func (c S3PGStore) ListPendingScheduledSnapshots(appID string) ([]snapshottypes.
 	scheduledSnapshots := []snapshottypes.ScheduledSnapshot{}
 	for rows.Next() {
 		s := snapshottypes.ScheduledSnapshot{}
-		if err := rows.Scan(&s.ID, &s.AppID, &s.ScheduledTimestamp); err != nil {
+		var queuedAt sql.NullTime
+		if err := rows.Scan(&s.ID, &s.AppID, &s.ScheduledTimestamp, &queuedAt); err != nil {
 			return nil, errors.Wrap(err, "failed to scan")
 		}
+		if queuedAt.Valid {
+			s.QueuedAt = &queuedAt.Time
+		}
 		scheduledSnapshots = append(scheduledSnapshots, s)
 	}
 
 	return scheduledSnapshots, nil
 }
 
+// GetLastHandledScheduledSnapshot returns the most recent scheduled snapshot that a backup was created for,
+// or nil if there hasn't been one
+func (c S3PGStore) GetLastHandledScheduledSnapshot(appID string) (*snapshottypes.ScheduledSnapshot, error) {
+	logger.Debug("Getting last handled scheduled snapshot",
+		zap.String("appID", appID))
+
+	db := persistence.MustGetPGSession()
+	query := `SELECT id, app_id, scheduled_timestamp, backup_name FROM scheduled_snapshots WHERE app_id = $1 AND backup_name IS NOT NULL ORDER BY scheduled_timestamp DESC LIMIT 1`
+	row := db.QueryRow(query, appID)
+
+	s := snapshottypes.ScheduledSnapshot{}
+	if err := row.Scan(&s.ID, &s.AppID, &s.ScheduledTimestamp, &s.BackupName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	return &s, nil
+}
+
 func (c S3PGStore) UpdateScheduledSnapshot(snapshotID string, backupName string) error {
 	logger.Debug("Updating scheduled snapshot",
 		zap.String("ID", snapshotID))
@@ -47,6 +76,21 @@ func (c S3PGStore) UpdateScheduledSnapshot(snapshotID string, backupName string)
 	return nil
 }
 
+// MarkScheduledSnapshotQueued records when a due scheduled snapshot was first held back by the concurrency
+// limit. Later calls for the same snapshot keep the original time.
+func (c S3PGStore) MarkScheduledSnapshotQueued(snapshotID string) error {
+	logger.Debug("Marking scheduled snapshot queued",
+		zap.String("ID", snapshotID))
+
+	db := persistence.MustGetPGSession()
+	query := `UPDATE scheduled_snapshots SET queued_at = $1 WHERE id = $2 AND queued_at IS NULL`
+	_, err := db.Exec(query, time.Now(), snapshotID)
+	if err != nil {
+		return errors.Wrap(err, "failed to exec")
+	}
+	return nil
+}
+
 func (c S3PGStore) DeletePendingScheduledSnapshots(appID string) error {
 	logger.Debug("Deleting pending scheduled snapshots",
 		zap.String("appID", appID))
@@ -159,3 +203,383 @@ func (c S3PGStore) CreateScheduledInstanceSnapshot(id string, clusterID string,
 
 	return nil
 }
+
+// ListScheduledSnapshotBackupNames returns the names of the backups the scheduler created, for apps and the instance
+func (c S3PGStore) ListScheduledSnapshotBackupNames() ([]string, error) {
+	logger.Debug("Listing scheduled snapshot backup names")
+
+	db := persistence.MustGetPGSession()
+	query := `SELECT backup_name FROM scheduled_snapshots WHERE backup_name IS NOT NULL
+	UNION SELECT backup_name FROM scheduled_instance_snapshots WHERE backup_name IS NOT NULL`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	backupNames := []string{}
+	for rows.Next() {
+		var backupName string
+		if err := rows.Scan(&backupName); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		backupNames = append(backupNames, backupName)
+	}
+
+	return backupNames, nil
+}
+
+func (c S3PGStore) GetBackupWebhook() (*snapshottypes.BackupWebhook, error) {
+	db := persistence.MustGetPGSession()
+	query := `select key, value from kotsadm_params where key in ($1, $2, $3)`
+	rows, err := db.Query(query, "BACKUP_WEBHOOK_URL", "BACKUP_WEBHOOK_SECRET", "BACKUP_WEBHOOK_CONFIGURED_AT")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	webhook := snapshottypes.BackupWebhook{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		switch key {
+		case "BACKUP_WEBHOOK_URL":
+			webhook.URL = value
+		case "BACKUP_WEBHOOK_SECRET":
+			webhook.Secret = value
+		case "BACKUP_WEBHOOK_CONFIGURED_AT":
+			configuredAt, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to parse webhook configured at")
+			}
+			webhook.ConfiguredAt = &configuredAt
+		}
+	}
+
+	return &webhook, nil
+}
+
+func (c S3PGStore) SetBackupWebhook(webhook *snapshottypes.BackupWebhook) error {
+	logger.Debug("Setting backup webhook")
+
+	db := persistence.MustGetPGSession()
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+	if _, err := tx.Exec(query, "BACKUP_WEBHOOK_URL", webhook.URL); err != nil {
+		return errors.Wrap(err, "failed to set webhook url")
+	}
+	if _, err := tx.Exec(query, "BACKUP_WEBHOOK_SECRET", webhook.Secret); err != nil {
+		return errors.Wrap(err, "failed to set webhook secret")
+	}
+	if webhook.ConfiguredAt != nil {
+		if _, err := tx.Exec(query, "BACKUP_WEBHOOK_CONFIGURED_AT", webhook.ConfiguredAt.UTC().Format(time.RFC3339)); err != nil {
+			return errors.Wrap(err, "failed to set webhook configured at")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// GetMaxConcurrentScheduledSnapshots returns the maximum number of scheduled backups that may run at once,
+// 0 means there is no limit
+func (c S3PGStore) GetMaxConcurrentScheduledSnapshots() (int, error) {
+	db := persistence.MustGetPGSession()
+	query := `select value from kotsadm_params where key = $1`
+	row := db.QueryRow(query, "MAX_CONCURRENT_SCHEDULED_SNAPSHOTS")
+
+	var value string
+	if err := row.Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "failed to scan")
+	}
+
+	max, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse max concurrent scheduled snapshots")
+	}
+
+	return max, nil
+}
+
+func (c S3PGStore) SetMaxConcurrentScheduledSnapshots(max int) error {
+	logger.Debug("Setting max concurrent scheduled snapshots",
+		zap.Int("max", max))
+
+	db := persistence.MustGetPGSession()
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+
+	_, err := db.Exec(query, "MAX_CONCURRENT_SCHEDULED_SNAPSHOTS", strconv.Itoa(max))
+	if err != nil {
+		return errors.Wrap(err, "failed to exec")
+	}
+
+	return nil
+}
+
+// GetSnapshotDefaults returns the instance snapshot defaults, fields that haven't been set are empty
+func (c S3PGStore) GetSnapshotDefaults() (*snapshottypes.SnapshotDefaults, error) {
+	db := persistence.MustGetPGSession()
+	query := `select key, value from kotsadm_params where key in ($1, $2)`
+	rows, err := db.Query(query, "DEFAULT_SNAPSHOT_TTL", "DEFAULT_SNAPSHOT_SCHEDULE")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	defaults := snapshottypes.SnapshotDefaults{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		switch key {
+		case "DEFAULT_SNAPSHOT_TTL":
+			defaults.TTL = value
+		case "DEFAULT_SNAPSHOT_SCHEDULE":
+			defaults.Schedule = value
+		}
+	}
+
+	return &defaults, nil
+}
+
+func (c S3PGStore) SetSnapshotDefaults(defaults *snapshottypes.SnapshotDefaults) error {
+	logger.Debug("Setting snapshot defaults",
+		zap.String("ttl", defaults.TTL),
+		zap.String("schedule", defaults.Schedule))
+
+	db := persistence.MustGetPGSession()
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+	if _, err := tx.Exec(query, "DEFAULT_SNAPSHOT_TTL", defaults.TTL); err != nil {
+		return errors.Wrap(err, "failed to set default snapshot ttl")
+	}
+	if _, err := tx.Exec(query, "DEFAULT_SNAPSHOT_SCHEDULE", defaults.Schedule); err != nil {
+		return errors.Wrap(err, "failed to set default snapshot schedule")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) GetBackupResourceFilter() (*snapshottypes.BackupResourceFilter, error) {
+	db := persistence.MustGetPGSession()
+	query := `select key, value from kotsadm_params where key in ($1, $2)`
+	rows, err := db.Query(query, "BACKUP_INCLUDED_RESOURCES", "BACKUP_EXCLUDED_RESOURCES")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	filter := snapshottypes.BackupResourceFilter{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		if value == "" {
+			continue
+		}
+		switch key {
+		case "BACKUP_INCLUDED_RESOURCES":
+			filter.IncludedResources = strings.Split(value, ",")
+		case "BACKUP_EXCLUDED_RESOURCES":
+			filter.ExcludedResources = strings.Split(value, ",")
+		}
+	}
+
+	return &filter, nil
+}
+
+func (c S3PGStore) SetBackupResourceFilter(filter *snapshottypes.BackupResourceFilter) error {
+	logger.Debug("Setting backup resource filter",
+		zap.Strings("includedResources", filter.IncludedResources),
+		zap.Strings("excludedResources", filter.ExcludedResources))
+
+	db := persistence.MustGetPGSession()
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+	if _, err := tx.Exec(query, "BACKUP_INCLUDED_RESOURCES", strings.Join(filter.IncludedResources, ",")); err != nil {
+		return errors.Wrap(err, "failed to set included resources")
+	}
+	if _, err := tx.Exec(query, "BACKUP_EXCLUDED_RESOURCES", strings.Join(filter.ExcludedResources, ",")); err != nil {
+		return errors.Wrap(err, "failed to set excluded resources")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) GetVeleroExtraContainers() (*snapshottypes.VeleroExtraContainers, error) {
+	db := persistence.MustGetPGSession()
+	query := `select value from kotsadm_params where key = $1`
+	row := db.QueryRow(query, "VELERO_EXTRA_CONTAINERS")
+
+	extra := snapshottypes.VeleroExtraContainers{}
+
+	var value string
+	if err := row.Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return &extra, nil
+		}
+		return nil, errors.Wrap(err, "failed to scan")
+	}
+
+	if err := json.Unmarshal([]byte(value), &extra); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal velero extra containers")
+	}
+
+	return &extra, nil
+}
+
+func (c S3PGStore) SetVeleroExtraContainers(extra *snapshottypes.VeleroExtraContainers) error {
+	logger.Debug("Setting velero extra containers")
+
+	value, err := json.Marshal(extra)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal velero extra containers")
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+	if _, err := db.Exec(query, "VELERO_EXTRA_CONTAINERS", string(value)); err != nil {
+		return errors.Wrap(err, "failed to set velero extra containers")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) GetVeleroImageRegistry() (*snapshottypes.VeleroImageRegistry, error) {
+	db := persistence.MustGetPGSession()
+	query := `select key, value from kotsadm_params where key in ($1, $2)`
+	rows, err := db.Query(query, "VELERO_IMAGE_REGISTRY_HOSTNAME", "VELERO_IMAGE_REGISTRY_NAMESPACE")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	veleroRegistry := snapshottypes.VeleroImageRegistry{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		switch key {
+		case "VELERO_IMAGE_REGISTRY_HOSTNAME":
+			veleroRegistry.Hostname = value
+		case "VELERO_IMAGE_REGISTRY_NAMESPACE":
+			veleroRegistry.Namespace = value
+		}
+	}
+
+	return &veleroRegistry, nil
+}
+
+func (c S3PGStore) SetVeleroImageRegistry(veleroRegistry *snapshottypes.VeleroImageRegistry) error {
+	logger.Debug("Setting velero image registry",
+		zap.String("hostname", veleroRegistry.Hostname),
+		zap.String("namespace", veleroRegistry.Namespace))
+
+	db := persistence.MustGetPGSession()
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `insert into kotsadm_params (key, value) values ($1, $2) on conflict (key) do update set value = $2`
+	if _, err := tx.Exec(query, "VELERO_IMAGE_REGISTRY_HOSTNAME", veleroRegistry.Hostname); err != nil {
+		return errors.Wrap(err, "failed to set hostname")
+	}
+	if _, err := tx.Exec(query, "VELERO_IMAGE_REGISTRY_NAMESPACE", veleroRegistry.Namespace); err != nil {
+		return errors.Wrap(err, "failed to set namespace")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+func (c S3PGStore) CreateSnapshotAuditEvent(event *snapshottypes.SnapshotAuditEvent) error {
+	logger.Debug("Creating snapshot audit event",
+		zap.String("action", event.Action))
+
+	changes, err := json.Marshal(event.Changes)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal changes")
+	}
+
+	var appID sql.NullString
+	if event.AppID != "" {
+		appID = sql.NullString{String: event.AppID, Valid: true}
+	}
+
+	db := persistence.MustGetPGSession()
+	query := `INSERT INTO snapshot_audit_event (id, created_at, actor, action, app_id, changes) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = db.Exec(query, event.ID, event.CreatedAt, event.Actor, event.Action, appID, string(changes))
+	if err != nil {
+		return errors.Wrap(err, "failed to exec")
+	}
+
+	return nil
+}
+
+// ListSnapshotAuditEvents returns the most recent audit events first. An empty appID lists the events for
+// all apps and the global and instance settings.
+func (c S3PGStore) ListSnapshotAuditEvents(appID string, limit int) ([]snapshottypes.SnapshotAuditEvent, error) {
+	db := persistence.MustGetPGSession()
+	query := `SELECT id, created_at, actor, action, app_id, changes FROM snapshot_audit_event WHERE ($1 = '' OR app_id = $1) ORDER BY created_at DESC LIMIT $2`
+	rows, err := db.Query(query, appID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query")
+	}
+	defer rows.Close()
+
+	events := []snapshottypes.SnapshotAuditEvent{}
+	for rows.Next() {
+		event := snapshottypes.SnapshotAuditEvent{}
+		var eventAppID sql.NullString
+		var changes string
+		if err := rows.Scan(&event.ID, &event.CreatedAt, &event.Actor, &event.Action, &eventAppID, &changes); err != nil {
+			return nil, errors.Wrap(err, "failed to scan")
+		}
+		event.AppID = eventAppID.String
+		if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal changes")
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
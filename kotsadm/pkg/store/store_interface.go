@@ -7,6 +7,7 @@ import (
 	airgaptypes "github.com/replicatedhq/kots/kotsadm/pkg/airgap/types"
 	apptypes "github.com/replicatedhq/kots/kotsadm/pkg/app/types"
 	gitopstypes "github.com/replicatedhq/kots/kotsadm/pkg/gitops/types"
+	licensetypes "github.com/replicatedhq/kots/kotsadm/pkg/license/types"
 	installationtypes "github.com/replicatedhq/kots/kotsadm/pkg/online/types"
 	preflighttypes "github.com/replicatedhq/kots/kotsadm/pkg/preflight/types"
 	registrytypes "github.com/replicatedhq/kots/kotsadm/pkg/registry/types"
@@ -121,21 +122,57 @@ type AppStore interface {
 	GetDownstream(clusterID string) (*downstreamtypes.Downstream, error)
 	IsGitOpsEnabledForApp(appID string) (bool, error)
 	SetUpdateCheckerSpec(appID string, updateCheckerSpec string) error
+	SetLicenseSyncInterval(appID string, intervalMinutes int) error
 	SetSnapshotTTL(appID string, snapshotTTL string) error
 	SetSnapshotSchedule(appID string, snapshotSchedule string) error
+	SetSnapshotScheduleTTL(appID string, snapshotScheduleTTL string) error
+	SetSnapshotScheduleJitter(appID string, jitterMinutes int) error
+	SetSnapshotDefaultVolumesToRestic(appID string, defaultVolumesToRestic *bool) error
+	SetSnapshotQuiesceActions(appID string, actions []snapshottypes.QuiesceAction) error
+	SetSnapshotChecksumTargets(appID string, targets []snapshottypes.ChecksumTarget) error
+	SetSnapshotNamespaces(appID string, included []string, excluded []string) error
+	SetSnapshotHookSettings(appID string, settings *snapshottypes.HookSettings) error
+	SetSnapshotExcludedPVCs(appID string, excludedPVCs []string) error
+	SetSnapshotFanOutLocations(appID string, locations []string) error
+	SetSnapshotResticPodSelector(appID string, selector string) error
+	SetSnapshotMaxConcurrentBackups(appID string, maxConcurrent int) error
 	RemoveApp(appID string) error
 }
 
 type SnapshotStore interface {
 	ListPendingScheduledSnapshots(appID string) ([]snapshottypes.ScheduledSnapshot, error)
+	GetLastHandledScheduledSnapshot(appID string) (*snapshottypes.ScheduledSnapshot, error)
 	UpdateScheduledSnapshot(snapshotID string, backupName string) error
 	DeletePendingScheduledSnapshots(appID string) error
 	CreateScheduledSnapshot(snapshotID string, appID string, timestamp time.Time) error
+	MarkScheduledSnapshotQueued(snapshotID string) error
 
 	ListPendingScheduledInstanceSnapshots(clusterID string) ([]snapshottypes.ScheduledInstanceSnapshot, error)
 	UpdateScheduledInstanceSnapshot(snapshotID string, backupName string) error
 	DeletePendingScheduledInstanceSnapshots(clusterID string) error
 	CreateScheduledInstanceSnapshot(snapshotID string, clusterID string, timestamp time.Time) error
+	ListScheduledSnapshotBackupNames() ([]string, error)
+
+	GetBackupWebhook() (*snapshottypes.BackupWebhook, error)
+	SetBackupWebhook(webhook *snapshottypes.BackupWebhook) error
+
+	GetMaxConcurrentScheduledSnapshots() (int, error)
+	SetMaxConcurrentScheduledSnapshots(max int) error
+
+	GetSnapshotDefaults() (*snapshottypes.SnapshotDefaults, error)
+	SetSnapshotDefaults(defaults *snapshottypes.SnapshotDefaults) error
+
+	GetBackupResourceFilter() (*snapshottypes.BackupResourceFilter, error)
+	SetBackupResourceFilter(filter *snapshottypes.BackupResourceFilter) error
+
+	GetVeleroExtraContainers() (*snapshottypes.VeleroExtraContainers, error)
+	SetVeleroExtraContainers(extra *snapshottypes.VeleroExtraContainers) error
+
+	GetVeleroImageRegistry() (*snapshottypes.VeleroImageRegistry, error)
+	SetVeleroImageRegistry(veleroRegistry *snapshottypes.VeleroImageRegistry) error
+
+	CreateSnapshotAuditEvent(event *snapshottypes.SnapshotAuditEvent) error
+	ListSnapshotAuditEvents(appID string, limit int) ([]snapshottypes.SnapshotAuditEvent, error)
 }
 
 type VersionStore interface {
@@ -156,6 +193,9 @@ type LicenseStore interface {
 
 	// originalLicenseData is the data received from the replicated API that was never marshalled locally so all fields are intact
 	UpdateAppLicense(appID string, sequence int64, archiveDir string, newLicense *kotsv1beta1.License, originalLicenseData string, failOnVersionCreate bool, gitops gitopstypes.DownstreamGitOps, renderer rendertypes.Renderer) (int64, error)
+
+	CreateLicenseChange(change *licensetypes.LicenseChange) error
+	ListLicenseChanges(appID string, limit int) ([]licensetypes.LicenseChange, error)
 }
 
 type ClusterStore interface {
@@ -165,6 +205,10 @@ type ClusterStore interface {
 	CreateNewCluster(userID string, isAllUsers bool, title string, token string) (clusterID string, err error)
 	SetInstanceSnapshotTTL(clusterID string, snapshotTTL string) error
 	SetInstanceSnapshotSchedule(clusterID string, snapshotSchedule string) error
+	SetInstanceSnapshotClusterResources(clusterID string, includedResources []string, excludedResources []string) error
+	SetInstanceSnapshotExcludedPVCs(clusterID string, excludedPVCs []string) error
+	SetInstanceSnapshotFanOutLocations(clusterID string, locations []string) error
+	SetInstanceSnapshotIncludeRegistryData(clusterID string, include bool) error
 }
 
 type InstallationStore interface {
@@ -47,10 +47,25 @@ func Start() error {
 	return nil
 }
 
-// Configure will check if the app has scheduled update checks enabled and:
-// if enabled, and cron job was NOT found: add a new cron job to check app updates
-// if enabled, and a cron job was found, update the existing cron job with the latest cron spec
-// if disabled: stop the current running cron job (if exists)
+// MinLicenseSyncIntervalMinutes is the shortest interval an app's license can be synced on
+const MinLicenseSyncIntervalMinutes = 15
+
+// ValidateLicenseSyncInterval checks an app's license sync interval. 0 syncs the license only when checking
+// for updates.
+func ValidateLicenseSyncInterval(intervalMinutes int) error {
+	if intervalMinutes < 0 {
+		return errors.New("license sync interval cannot be negative")
+	}
+	if intervalMinutes > 0 && intervalMinutes < MinLicenseSyncIntervalMinutes {
+		return errors.Errorf("license sync interval must be at least %d minutes", MinLicenseSyncIntervalMinutes)
+	}
+	return nil
+}
+
+// Configure will check if the app has scheduled update checks or license syncs enabled and:
+// if enabled, and cron job was NOT found: add a new cron job to check app updates and sync the license
+// if enabled, and a cron job was found, update the existing cron job with the latest cron spec and interval
+// if both are disabled: stop the current running cron job (if exists)
 // no-op for airgap applications
 func Configure(appID string) error {
 	a, err := store.GetStore().GetApp(appID)
@@ -69,8 +84,10 @@ func Configure(appID string) error {
 	defer mtx.Unlock()
 
 	cronSpec := a.UpdateCheckerSpec
+	checkUpdates := cronSpec != "@never" && cronSpec != ""
+	syncLicense := a.LicenseSyncIntervalMinutes > 0
 
-	if cronSpec == "@never" || cronSpec == "" {
+	if !checkUpdates && !syncLicense {
 		Stop(a.ID)
 		return nil
 	}
@@ -99,25 +116,43 @@ func Configure(appID string) error {
 
 	jobAppID := a.ID
 	jobAppSlug := a.Slug
-	_, err = job.AddFunc(cronSpec, func() {
-		logger.Debug("checking updates for app", zap.String("slug", jobAppSlug))
 
-		availableUpdates, err := CheckForUpdates(jobAppID, false, false)
+	if checkUpdates {
+		_, err = job.AddFunc(cronSpec, func() {
+			logger.Debug("checking updates for app", zap.String("slug", jobAppSlug))
+
+			availableUpdates, err := CheckForUpdates(jobAppID, false, false)
+			if err != nil {
+				logger.Error(errors.Wrapf(err, "failed to check updates for app %s", jobAppSlug))
+				return
+			}
+
+			if availableUpdates > 0 {
+				logger.Debug("updates found for app",
+					zap.String("slug", jobAppSlug),
+					zap.Int64("available updates", availableUpdates))
+			} else {
+				logger.Debug("no updates found for app", zap.String("slug", jobAppSlug))
+			}
+		})
 		if err != nil {
-			logger.Error(errors.Wrapf(err, "failed to check updates for app %s", jobAppSlug))
-			return
+			return errors.Wrap(err, "failed to add func")
 		}
+	}
 
-		if availableUpdates > 0 {
-			logger.Debug("updates found for app",
-				zap.String("slug", jobAppSlug),
-				zap.Int64("available updates", availableUpdates))
-		} else {
-			logger.Debug("no updates found for app", zap.String("slug", jobAppSlug))
+	if syncLicense {
+		// the license is also synced on every update check, this is for apps that need it synced more or less often
+		licenseSyncSpec := fmt.Sprintf("@every %dm", a.LicenseSyncIntervalMinutes)
+		_, err = job.AddFunc(licenseSyncSpec, func() {
+			logger.Debug("syncing license for app", zap.String("slug", jobAppSlug))
+
+			if err := SyncLicense(jobAppID); err != nil {
+				logger.Error(errors.Wrapf(err, "failed to sync license for app %s", jobAppSlug))
+			}
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to add license sync func")
 		}
-	})
-	if err != nil {
-		return errors.Wrap(err, "failed to add func")
 	}
 
 	job.Start()
@@ -126,6 +161,30 @@ func Configure(appID string) error {
 	return nil
 }
 
+// SyncLicense syncs the app's license with the api, unless an update is being downloaded, which syncs it too
+func SyncLicense(appID string) error {
+	currentStatus, _, err := store.GetStore().GetTaskStatus("update-download")
+	if err != nil {
+		return errors.Wrap(err, "failed to get task status")
+	}
+
+	if currentStatus == "running" {
+		logger.Debug("update-download is running, not syncing the license")
+		return nil
+	}
+
+	a, err := store.GetStore().GetApp(appID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get app")
+	}
+
+	if _, _, err := license.Sync(a, "", false, license.PreflightPolicyBlock); err != nil {
+		return errors.Wrap(err, "failed to sync license")
+	}
+
+	return nil
+}
+
 // Stop will stop a running cron job (if exists) for a specific app
 func Stop(appID string) {
 	if jobs == nil {
@@ -163,7 +222,7 @@ func CheckForUpdates(appID string, deploy bool, skipPreflights bool) (int64, err
 	}
 
 	// sync license, this method is only called when online
-	_, err = license.Sync(a, "", false)
+	_, _, err = license.Sync(a, "", false, license.PreflightPolicyBlock)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to sync license")
 	}
@@ -7,12 +7,17 @@ import (
 )
 
 type Downstream struct {
-	ClusterID        string `json:"id"`
-	ClusterSlug      string `json:"slug"`
-	Name             string `json:"name"`
-	CurrentSequence  int64  `json:"currentSequence"`
-	SnapshotSchedule string `json:"snapshotSchedule,omitempty"`
-	SnapshotTTL      string `json:"snapshotTtl,omitempty"`
+	ClusterID                        string   `json:"id"`
+	ClusterSlug                      string   `json:"slug"`
+	Name                             string   `json:"name"`
+	CurrentSequence                  int64    `json:"currentSequence"`
+	SnapshotSchedule                 string   `json:"snapshotSchedule,omitempty"`
+	SnapshotTTL                      string   `json:"snapshotTtl,omitempty"`
+	SnapshotIncludedClusterResources []string `json:"snapshotIncludedClusterResources,omitempty"`
+	SnapshotExcludedClusterResources []string `json:"snapshotExcludedClusterResources,omitempty"`
+	SnapshotExcludedPVCs             []string `json:"snapshotExcludedPvcs,omitempty"`
+	SnapshotFanOutLocations          []string `json:"snapshotFanOutLocations,omitempty"`
+	SnapshotIncludeRegistryData      bool     `json:"snapshotIncludeRegistryData,omitempty"`
 }
 
 type DownstreamVersion struct {
@@ -15,6 +15,7 @@ import (
 var (
 	ErrSignatureInvalid = errors.New("signature is invalid")
 	ErrSignatureMissing = errors.New("signature is missing")
+	ErrUntrustedKey     = errors.New("license is not signed with a trusted key")
 )
 
 type InnerSignature struct {
@@ -34,6 +35,13 @@ type KeySignature struct {
 }
 
 func VerifySignature(license *kotsv1beta1.License) (*kotsv1beta1.License, error) {
+	return VerifySignatureWithKeys(license, nil)
+}
+
+// VerifySignatureWithKeys verifies the license like VerifySignature, but only trusts the given global keys,
+// PEM encoded public keys by key id, instead of the built-in ones. Licenses signed with any other key are
+// rejected with ErrUntrustedKey. If trustedKeys is nil, the built-in keys are used.
+func VerifySignatureWithKeys(license *kotsv1beta1.License, trustedKeys map[string][]byte) (*kotsv1beta1.License, error) {
 	outerSignature := &OuterSignature{}
 	if err := json.Unmarshal(license.Spec.Signature, outerSignature); err != nil {
 		return nil, errors.Wrap(err, "failed to unmarshal license outer signature")
@@ -41,7 +49,7 @@ func VerifySignature(license *kotsv1beta1.License) (*kotsv1beta1.License, error)
 
 	isOldFormat := len(outerSignature.InnerSignature) == 0
 	if isOldFormat {
-		return VerifyOldSignature(license)
+		return verifyOldSignature(license, trustedKeys)
 	}
 
 	innerSignature := &InnerSignature{}
@@ -54,9 +62,9 @@ func VerifySignature(license *kotsv1beta1.License) (*kotsv1beta1.License, error)
 		return nil, errors.Wrap(err, "failed to unmarshal key signature")
 	}
 
-	globalKeyPEM, ok := publicKeys[keySignature.GlobalKeyId]
-	if !ok {
-		return nil, errors.New("unknown global key")
+	globalKeyPEM, err := getGlobalKey(keySignature.GlobalKeyId, trustedKeys)
+	if err != nil {
+		return nil, err
 	}
 
 	// verify that the app public key is properly signed with a replicated private key
@@ -84,8 +92,28 @@ func VerifySignature(license *kotsv1beta1.License) (*kotsv1beta1.License, error)
 	return verifiedLicense, nil
 }
 
+// getGlobalKey returns the PEM of the global key that signed the app's key, from the trusted keys if set
+func getGlobalKey(globalKeyID string, trustedKeys map[string][]byte) ([]byte, error) {
+	if trustedKeys == nil {
+		globalKeyPEM, ok := publicKeys[globalKeyID]
+		if !ok {
+			return nil, errors.New("unknown global key")
+		}
+		return globalKeyPEM, nil
+	}
+
+	globalKeyPEM, ok := trustedKeys[globalKeyID]
+	if !ok {
+		return nil, errors.Wrapf(ErrUntrustedKey, "global key %s is not trusted", globalKeyID)
+	}
+	return globalKeyPEM, nil
+}
+
 func verify(message, signature, publicKeyPEM []byte) error {
 	pubBlock, _ := pem.Decode(publicKeyPEM)
+	if pubBlock == nil {
+		return errors.New("failed to decode public key PEM")
+	}
 	publicKey, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
 	if err != nil {
 		return errors.Wrap(err, "failed to load public key from PEM")
@@ -173,6 +201,10 @@ func verifyLicenseData(outerLicense *kotsv1beta1.License, innerLicense *kotsv1be
 }
 
 func VerifyOldSignature(license *kotsv1beta1.License) (*kotsv1beta1.License, error) {
+	return verifyOldSignature(license, nil)
+}
+
+func verifyOldSignature(license *kotsv1beta1.License, trustedKeys map[string][]byte) (*kotsv1beta1.License, error) {
 	signature := &InnerSignature{}
 	if err := json.Unmarshal(license.Spec.Signature, signature); err != nil {
 		// old licenses's signature is a single space character
@@ -187,9 +219,9 @@ func VerifyOldSignature(license *kotsv1beta1.License) (*kotsv1beta1.License, err
 		return nil, errors.Wrap(err, "failed to unmarshal key signature")
 	}
 
-	globalKeyPEM, ok := publicKeys[keySignature.GlobalKeyId]
-	if !ok {
-		return nil, errors.New("unknown global key")
+	globalKeyPEM, err := getGlobalKey(keySignature.GlobalKeyId, trustedKeys)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := verify([]byte(signature.PublicKey), keySignature.Signature, globalKeyPEM); err != nil {
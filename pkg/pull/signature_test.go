@@ -0,0 +1,36 @@
+package pull
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getGlobalKey(t *testing.T) {
+	var builtInKeyID string
+	for keyID := range publicKeys {
+		builtInKeyID = keyID
+		break
+	}
+
+	// built-in keys
+	key, err := getGlobalKey(builtInKeyID, nil)
+	require.NoError(t, err)
+	require.Equal(t, publicKeys[builtInKeyID], key)
+
+	_, err = getGlobalKey("unknown", nil)
+	require.Error(t, err)
+
+	// pinned keys replace the built-in ones
+	trustedKeys := map[string][]byte{
+		"pinned": []byte("pinned key"),
+	}
+
+	key, err = getGlobalKey("pinned", trustedKeys)
+	require.NoError(t, err)
+	require.Equal(t, []byte("pinned key"), key)
+
+	_, err = getGlobalKey(builtInKeyID, trustedKeys)
+	require.Equal(t, ErrUntrustedKey, errors.Cause(err))
+}
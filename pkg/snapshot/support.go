@@ -0,0 +1,104 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/replicatedhq/kots/pkg/auth"
+	"github.com/replicatedhq/kots/pkg/k8sutil"
+	"github.com/replicatedhq/kots/pkg/logger"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type DownloadSupportBundleOptions struct {
+	Namespace             string
+	KubernetesConfigFlags *genericclioptions.ConfigFlags
+	OutputFile            string
+}
+
+// DownloadSupportBundle saves the velero support data collected by the admin console to a tarball
+func DownloadSupportBundle(options DownloadSupportBundleOptions) error {
+	log := logger.NewLogger()
+	log.ActionWithSpinner("Connecting to cluster")
+
+	clientset, err := k8sutil.GetClientset(options.KubernetesConfigFlags)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to get clientset")
+	}
+
+	podName, err := k8sutil.FindKotsadm(clientset, options.Namespace)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to find kotsadm pod")
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	localPort, errChan, err := k8sutil.PortForward(options.KubernetesConfigFlags, 0, 3000, options.Namespace, podName, false, stopCh, log)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to start port forwarding")
+	}
+
+	go func() {
+		select {
+		case err := <-errChan:
+			if err != nil {
+				log.Error(err)
+			}
+		case <-stopCh:
+		}
+	}()
+
+	log.FinishSpinner()
+	log.ActionWithSpinner("Collecting snapshot support data")
+
+	authSlug, err := auth.GetOrCreateAuthSlug(options.KubernetesConfigFlags, options.Namespace)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to get kotsadm auth slug")
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/snapshots/support-data", localPort)
+
+	newRequest, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to create support data request")
+	}
+	newRequest.Header.Add("Authorization", authSlug)
+
+	resp, err := http.DefaultClient.Do(newRequest)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to get from kotsadm")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.FinishSpinnerWithError()
+		return errors.Errorf("unexpected status code from %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(options.OutputFile)
+	if err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to create output file")
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		log.FinishSpinnerWithError()
+		return errors.Wrap(err, "failed to write output file")
+	}
+
+	log.FinishSpinner()
+	log.ActionWithoutSpinner(fmt.Sprintf("Snapshot support bundle saved to %s", options.OutputFile))
+
+	return nil
+}